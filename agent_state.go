@@ -0,0 +1,332 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// AgentState is the state of an agent of the contact center.
+type AgentState string
+
+const (
+	// AgentLoggedOut agents are not members of their queues.
+	AgentLoggedOut AgentState = "logged_out"
+	// AgentAvailable agents are offered calls.
+	AgentAvailable AgentState = "available"
+	// AgentOnCall agents are ringing or talking on a queue call.
+	AgentOnCall AgentState = "on_call"
+	// AgentWrapUp agents are finishing the work of their last call.
+	AgentWrapUp AgentState = "wrap_up"
+	// AgentPaused agents are logged in but not offered calls, for a reason such as "lunch".
+	AgentPaused AgentState = "paused"
+)
+
+// ErrUnknownAgent is returned for an agent that is not logged in.
+var ErrUnknownAgent = errors.New("agent is not logged in")
+
+// AgentStateChange is a transition of an agent.
+type AgentStateChange struct {
+	AgentId string     `json:"agent_id"`
+	From    AgentState `json:"from"`
+	To      AgentState `json:"to"`
+	// Reason is the pause reason, when To is AgentPaused.
+	Reason string    `json:"reason,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// AgentInfo is the state of a logged in agent.
+type AgentInfo struct {
+	Agent
+	State  AgentState `json:"state"`
+	Reason string     `json:"reason,omitempty"`
+	Since  time.Time  `json:"since"`
+	// DeviceState is the last state of the device of the agent, e.g. "NOT_INUSE"; empty until
+	// Asterisk reported it.
+	DeviceState string `json:"device_state,omitempty"`
+	// Routable reports whether the agent is offered calls: available, with a free device.
+	Routable bool `json:"routable"`
+}
+
+type managedAgent struct {
+	info   AgentInfo
+	queues []*Queue
+	// wrapUp identifies the current wrap-up, so that an expired one that was cut short is ignored.
+	wrapUp int
+}
+
+// AgentManager holds the state of the agents of one or more queues: login and logout, pauses
+// with a reason, and a wrap-up period after each call. Queues only offer calls to available
+// agents whose device is free, so an agent on a personal call, or with a device offline, is
+// skipped. Every event must be fed to HandleEvent, which requires the application to be
+// subscribed to the device states of the agents, see App.
+type AgentManager struct {
+	client *APIClient
+
+	// App, if set, is subscribed to the device state of agents at login.
+	App string
+	// WrapUp is the time after a call before an agent is available again. 0 disables wrap-up.
+	WrapUp time.Duration
+	// OnStateChange, if set, is called with every transition, outside of the lock.
+	OnStateChange func(c AgentStateChange)
+
+	mu     sync.Mutex
+	agents map[string]*managedAgent
+}
+
+// NewAgentManager creates a manager without agents.
+func NewAgentManager(client *APIClient) *AgentManager {
+	return &AgentManager{client: client, agents: make(map[string]*managedAgent)}
+}
+
+// Login makes an agent available in queues. Logging in again updates the agent and its queues,
+// keeping its state: a paused agent is not offered the callers of the queues it joins.
+func (m *AgentManager) Login(ctx context.Context, a Agent, queues ...*Queue) error {
+	if m.App != "" {
+		if _, _, err := m.client.ApplicationsApi.Subscribe(ctx, m.App, []string{"deviceState:" + a.Endpoint}); err != nil {
+			return err
+		}
+	}
+	m.mu.Lock()
+	ma, ok := m.agents[a.Id]
+	var left []*Queue
+	var change *AgentStateChange
+	if ok {
+		left = removedQueues(ma.queues, queues)
+		ma.info.Agent = a
+		ma.queues = queues
+	} else {
+		ma = &managedAgent{info: AgentInfo{Agent: a, State: AgentLoggedOut}, queues: queues}
+		m.agents[a.Id] = ma
+		change = m.transitionLocked(ma, AgentAvailable, "")
+	}
+	// The queues block an agent that is paused, or not routable otherwise, before offering it a
+	// call.
+	ma.info.Routable = ma.info.State == AgentAvailable && deviceFree(ma.info.DeviceState)
+	routable := ma.info.Routable
+	m.mu.Unlock()
+
+	for _, q := range left {
+		q.RemoveAgent(a.Id)
+	}
+	for _, q := range queues {
+		q.mu.Lock()
+		q.agentHook = m.queueHook
+		q.mu.Unlock()
+		q.addAgentBlocked(a, !routable)
+	}
+	m.apply(a.Id)
+	m.notify(change)
+	return nil
+}
+
+func removedQueues(old []*Queue, current []*Queue) []*Queue {
+	var removed []*Queue
+	for _, q := range old {
+		found := false
+		for _, c := range current {
+			found = found || c == q
+		}
+		if !found {
+			removed = append(removed, q)
+		}
+	}
+	return removed
+}
+
+// Logout removes an agent from its queues. A call in progress is not affected.
+func (m *AgentManager) Logout(agentId string) error {
+	m.mu.Lock()
+	ma, ok := m.agents[agentId]
+	if !ok {
+		m.mu.Unlock()
+		return ErrUnknownAgent
+	}
+	change := m.transitionLocked(ma, AgentLoggedOut, "")
+	delete(m.agents, agentId)
+	m.mu.Unlock()
+
+	for _, q := range ma.queues {
+		q.RemoveAgent(agentId)
+	}
+	m.notify(change)
+	return nil
+}
+
+// Pause stops offering calls to an agent. An agent on a call or in wrap-up is paused right away;
+// the call is not affected.
+func (m *AgentManager) Pause(agentId string, reason string) error {
+	return m.set(agentId, AgentPaused, reason)
+}
+
+// Unpause makes a paused agent available again.
+func (m *AgentManager) Unpause(agentId string) error {
+	m.mu.Lock()
+	ma, ok := m.agents[agentId]
+	paused := ok && ma.info.State == AgentPaused
+	m.mu.Unlock()
+	if !paused {
+		if !ok {
+			return ErrUnknownAgent
+		}
+		return nil
+	}
+	return m.set(agentId, AgentAvailable, "")
+}
+
+// Get returns the state of an agent.
+func (m *AgentManager) Get(agentId string) (AgentInfo, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ma, ok := m.agents[agentId]
+	if !ok {
+		return AgentInfo{}, false
+	}
+	return ma.info, true
+}
+
+// Agents returns the state of the logged in agents.
+func (m *AgentManager) Agents() []AgentInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	agents := make([]AgentInfo, 0, len(m.agents))
+	for _, ma := range m.agents {
+		agents = append(agents, ma.info)
+	}
+	return agents
+}
+
+// HandleEvent tracks the device state of the agents.
+func (m *AgentManager) HandleEvent(ev StasisEvent) {
+	if ev.Type != "DeviceStateChanged" || ev.DeviceState == nil {
+		return
+	}
+	var changed []string
+	m.mu.Lock()
+	for id, ma := range m.agents {
+		if ma.info.Endpoint == ev.DeviceState.Name && ma.info.DeviceState != ev.DeviceState.State {
+			ma.info.DeviceState = ev.DeviceState.State
+			changed = append(changed, id)
+		}
+	}
+	m.mu.Unlock()
+	for _, id := range changed {
+		m.apply(id)
+	}
+}
+
+// deviceFree reports whether a device state allows offering a call.
+func deviceFree(state string) bool {
+	switch state {
+	case "", "NOT_INUSE", "UNKNOWN":
+		return true
+	}
+	return false
+}
+
+// queueHook is told by the queues of the agents when they start and end calls.
+func (m *AgentManager) queueHook(agentId string, busy bool, answered bool) {
+	m.mu.Lock()
+	ma, ok := m.agents[agentId]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+	var change *AgentStateChange
+	switch {
+	case busy && ma.info.State == AgentAvailable:
+		change = m.transitionLocked(ma, AgentOnCall, "")
+	case !busy && ma.info.State == AgentOnCall && answered && m.WrapUp > 0:
+		change = m.transitionLocked(ma, AgentWrapUp, "")
+		ma.wrapUp++
+		wrapUp := ma.wrapUp
+		m.client.goTracked("agents", func() {
+			<-m.client.clock().After(m.WrapUp)
+			m.endWrapUp(agentId, wrapUp)
+		})
+	case !busy && ma.info.State == AgentOnCall:
+		change = m.transitionLocked(ma, AgentAvailable, "")
+	}
+	m.mu.Unlock()
+	m.apply(agentId)
+	m.notify(change)
+}
+
+func (m *AgentManager) endWrapUp(agentId string, wrapUp int) {
+	m.mu.Lock()
+	ma, ok := m.agents[agentId]
+	var change *AgentStateChange
+	if ok && ma.info.State == AgentWrapUp && ma.wrapUp == wrapUp {
+		change = m.transitionLocked(ma, AgentAvailable, "")
+	}
+	m.mu.Unlock()
+	m.apply(agentId)
+	m.notify(change)
+}
+
+func (m *AgentManager) set(agentId string, state AgentState, reason string) error {
+	m.mu.Lock()
+	ma, ok := m.agents[agentId]
+	if !ok {
+		m.mu.Unlock()
+		return ErrUnknownAgent
+	}
+	change := m.transitionLocked(ma, state, reason)
+	m.mu.Unlock()
+	m.apply(agentId)
+	m.notify(change)
+	return nil
+}
+
+// transitionLocked moves an agent to state; it returns nil if the agent already was in it.
+func (m *AgentManager) transitionLocked(ma *managedAgent, state AgentState, reason string) *AgentStateChange {
+	if ma.info.State == state && ma.info.Reason == reason {
+		return nil
+	}
+	now := m.client.clock().Now()
+	change := &AgentStateChange{AgentId: ma.info.Id, From: ma.info.State, To: state, Reason: reason, Time: now}
+	ma.info.State, ma.info.Reason, ma.info.Since = state, reason, now
+	return change
+}
+
+// apply includes or excludes an agent from routing in its queues.
+func (m *AgentManager) apply(agentId string) {
+	m.mu.Lock()
+	ma, ok := m.agents[agentId]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+	// An agent on a call stays blocked in the other queues; the queue of the call tracks it
+	// as busy on its own.
+	ma.info.Routable = ma.info.State == AgentAvailable && deviceFree(ma.info.DeviceState)
+	routable, queues := ma.info.Routable, ma.queues
+	m.mu.Unlock()
+	for _, q := range queues {
+		q.setAgentBlocked(agentId, !routable)
+	}
+}
+
+func (m *AgentManager) notify(change *AgentStateChange) {
+	if change == nil {
+		return
+	}
+	m.client.logger.Infof("agents: %s is %s (was %s) %s", change.AgentId, change.To, change.From, change.Reason)
+	m.report()
+	if m.OnStateChange != nil {
+		m.OnStateChange(*change)
+	}
+}
+
+func (m *AgentManager) report() {
+	counts := map[AgentState]int{AgentAvailable: 0, AgentOnCall: 0, AgentWrapUp: 0, AgentPaused: 0}
+	m.mu.Lock()
+	for _, ma := range m.agents {
+		counts[ma.info.State]++
+	}
+	m.mu.Unlock()
+	for state, n := range counts {
+		m.client.metrics().SetGauge("ari_agents", map[string]string{"state": string(state)}, float64(n))
+	}
+}
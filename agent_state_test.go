@@ -0,0 +1,36 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+)
+
+// TestAgentLoginPaused covers a paused agent logging in again with another queue: the callers
+// waiting there are not offered to it.
+func TestAgentLoginPaused(t *testing.T) {
+	cfg := NewConfiguration("http://asterisk.invalid:8088/ari")
+	cfg.DryRun = true
+	client := NewAPIClient(cfg, NewStdLogger(ioutil.Discard))
+	agents := NewAgentManager(client)
+	sales, support := NewQueue(client, "sales", "ivr", nil), NewQueue(client, "support", "ivr", nil)
+	support.waiting = append(support.waiting, QueuedCall{ChannelId: "c1"})
+
+	alice := Agent{Id: "alice", Endpoint: "PJSIP/alice"}
+	if err := agents.Login(context.Background(), alice, sales); err != nil {
+		t.Fatal(err)
+	}
+	if err := agents.Pause("alice", "lunch"); err != nil {
+		t.Fatal(err)
+	}
+	if err := agents.Login(context.Background(), alice, sales, support); err != nil {
+		t.Fatal(err)
+	}
+
+	if waiting := support.Waiting(); len(waiting) != 1 {
+		t.Fatalf("%d callers waiting, want the caller not offered to the paused agent", len(waiting))
+	}
+	if info, _ := agents.Get("alice"); info.State != AgentPaused || info.Routable {
+		t.Errorf("state = %s, routable = %v, want paused and not routable", info.State, info.Routable)
+	}
+}
@@ -2,6 +2,7 @@ package asterisk_ari_go
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/gorilla/websocket"
 	"io"
@@ -31,14 +32,33 @@ WebsocketApiService WebSocket connection for events.
 
 // StasisEvent represents an event in the Stasis application.
 type StasisEvent struct {
-	Application string               `json:"application"`        // Application name
-	Args        []string             `json:"args,omitempty"`     // Optional arguments
-	AsteriskID  string               `json:"asterisk_id"`        // Asterisk instance ID
-	Channel     Channel              `json:"channel"`            // Channel information
-	Timestamp   StasisTimestampEvent `json:"timestamp"`          // Event timestamp
-	Type        string               `json:"type"`               // Event type
-	Value       string               `json:"value,omitempty"`    // Optional value
-	Variable    string               `json:"variable,omitempty"` // Optional variable
+	Application  string                 `json:"application"`            // Application name
+	Args         []string               `json:"args,omitempty"`         // Optional arguments
+	AsteriskID   string                 `json:"asterisk_id"`            // Asterisk instance ID
+	Channel      Channel                `json:"channel"`                // Channel information
+	Timestamp    StasisTimestampEvent   `json:"timestamp"`              // Event timestamp
+	Type         string                 `json:"type"`                   // Event type
+	Value        string                 `json:"value,omitempty"`        // Optional value
+	Variable     string                 `json:"variable,omitempty"`     // Optional variable
+	Cause        int32                  `json:"cause,omitempty"`        // Hangup cause code (ChannelDestroyed, ChannelHangupRequest)
+	Soft         bool                   `json:"soft,omitempty"`         // Soft hangup request, e.g. to run a dialplan redirect (ChannelHangupRequest)
+	CauseTxt     string                 `json:"cause_txt,omitempty"`    // Hangup cause text (ChannelDestroyed)
+	Dialstatus   string                 `json:"dialstatus,omitempty"`   // Dial status (Dial)
+	Dialstring   string                 `json:"dialstring,omitempty"`   // Dial string used to call the peer (Dial)
+	Peer         *Channel               `json:"peer,omitempty"`         // Dialed channel (Dial)
+	Caller       *Channel               `json:"caller,omitempty"`       // Calling channel (Dial)
+	Digit        string                 `json:"digit,omitempty"`        // DTMF digit (ChannelDtmfReceived)
+	DurationMs   int32                  `json:"duration_ms,omitempty"`  // DTMF duration (ChannelDtmfReceived)
+	Playback     *Playback              `json:"playback,omitempty"`     // Playback (Playback* events)
+	Recording    *LiveRecording         `json:"recording,omitempty"`    // Recording (Recording* events)
+	Enrichment   map[string]interface{} `json:"enrichment,omitempty"`   // Derived data attached by an Enricher, never sent by Asterisk
+	Eventname    string                 `json:"eventname,omitempty"`    // User event name (ChannelUserevent)
+	Userevent    map[string]interface{} `json:"userevent,omitempty"`    // User event data (ChannelUserevent)
+	DeviceState  *DeviceState           `json:"device_state,omitempty"` // Device state (DeviceStateChanged)
+	Bridge       *Bridge                `json:"bridge,omitempty"`       // Bridge (Bridge* events, ChannelEnteredBridge, ChannelLeftBridge)
+	Duration     int32                  `json:"duration,omitempty"`     // Talking duration in milliseconds (ChannelTalkingFinished)
+	Raw          json.RawMessage        `json:"-"`                      // Original payload, set by EventReader when Configuration.RawEvents is on
+	ConnectionId string                 `json:"-"`                      // Websocket connection the event was received on, set by EventReader
 }
 
 // StasisTimestampEvent represents a timestamp for a Stasis event.
@@ -48,22 +68,47 @@ type StasisTimestampEvent struct {
 
 const eventTimeLayout = "2006-01-02T15:04:05.000-0700"
 
+// eventTimeLayouts are the timestamp formats emitted by the supported Asterisk versions.
+var eventTimeLayouts = []string{
+	eventTimeLayout,
+	"2006-01-02T15:04:05-0700",
+	time.RFC3339Nano,
+}
+
 // UnmarshalJSON custom parsing for the timestamp
 // UnmarshalJSON parses the JSON-encoded data and stores the result in the value pointed to by s.
+// A null or empty timestamp leaves the zero time.
 func (s *StasisTimestampEvent) UnmarshalJSON(b []byte) error {
-	// Remove the surrounding quotes
-	timestampStr := string(b)
-	timestampStr = timestampStr[1 : len(timestampStr)-1]
+	if string(b) == "null" {
+		return nil
+	}
+	var timestampStr string
+	if err := json.Unmarshal(b, &timestampStr); err != nil {
+		return err
+	}
+	if timestampStr == "" {
+		return nil
+	}
 
 	// Parse the timestamp
-	parsedTime, err := time.Parse(eventTimeLayout, timestampStr)
-	if err != nil {
-		return err
+	var err error
+	for _, layout := range eventTimeLayouts {
+		var parsedTime time.Time
+		if parsedTime, err = time.Parse(layout, timestampStr); err == nil {
+			// Assign the parsed time to the Timestamp field
+			s.Timestamp = parsedTime
+			return nil
+		}
 	}
+	return err
+}
 
-	// Assign the parsed time to the Timestamp field
-	s.Timestamp = parsedTime
-	return nil
+// MarshalJSON encodes the timestamp in the format used by Asterisk, so that events round-trip.
+func (s StasisTimestampEvent) MarshalJSON() ([]byte, error) {
+	if s.Timestamp.IsZero() {
+		return []byte(`""`), nil
+	}
+	return json.Marshal(s.Timestamp.Format(eventTimeLayout))
 }
 
 // WebsocketConnect establishes a WebSocket connection for events.
@@ -102,7 +147,10 @@ func (a *WebsocketApiService) WebsocketConnect(ctx context.Context, app []string
 	//a.client.logger.Debugf("full URL: %s", u.String())
 	//a.client.logger.Debugf("headers: %v", headers)
 
-	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, u.String(), headers)
+	dialer := *websocket.DefaultDialer
+	dialer.EnableCompression = a.client.cfg.WebsocketCompression
+
+	conn, resp, err := dialer.DialContext(ctx, u.String(), headers)
 	if err != nil {
 		var fullErrorMsg string
 		if resp != nil && resp.Body != nil {
@@ -112,6 +160,9 @@ func (a *WebsocketApiService) WebsocketConnect(ctx context.Context, app []string
 		}
 		return nil, resp, err
 	}
+	if a.client.cfg.PingInterval > 0 {
+		a.keepalive(conn, a.client.cfg.PingInterval, a.client.cfg.PongTimeout)
+	}
 
 	return conn, resp, nil
 }
@@ -0,0 +1,209 @@
+package asterisk_ari_go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditEntry records a mutating REST call, answering "who hung up this call and when".
+type AuditEntry struct {
+	Time time.Time `json:"time"`
+	// Operation is "<resource>.<action>", e.g. "channels.hangup", "channels.play",
+	// "bridges.create" or "bridges.addChannel".
+	Operation  string `json:"operation"`
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	ResourceId string `json:"resource_id,omitempty"`
+	// Parameters are the query parameters of the call.
+	Parameters map[string]string `json:"parameters,omitempty"`
+	// Body is the JSON body of the call, e.g. channel variables.
+	Body json.RawMessage `json:"body,omitempty"`
+	// Caller is set with WithAuditCaller on the context of the call.
+	Caller   string        `json:"caller,omitempty"`
+	Status   int           `json:"status,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+	// DryRun is set for calls that were not sent, see Configuration.DryRun.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// AuditSink receives the audit log. Implementations must be safe for concurrent use and should
+// not block, since they are called on the path of every mutating call.
+type AuditSink interface {
+	Record(e AuditEntry)
+}
+
+// AuditSinkFunc adapts a function to AuditSink.
+type AuditSinkFunc func(e AuditEntry)
+
+// Record calls f.
+func (f AuditSinkFunc) Record(e AuditEntry) { f(e) }
+
+// JSONAuditSink writes the audit log as one JSON object per line, e.g. to os.Stdout or a file.
+type JSONAuditSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONAuditSink creates a sink writing to w.
+func NewJSONAuditSink(w io.Writer) *JSONAuditSink {
+	return &JSONAuditSink{w: w}
+}
+
+// Record writes e.
+func (s *JSONAuditSink) Record(e AuditEntry) {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.w.Write(append(line, '\n'))
+}
+
+type auditCallerKey struct{}
+
+// WithAuditCaller returns a context attributing the calls made with it to caller, e.g. the user
+// of a management console or the component of the application.
+func WithAuditCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, auditCallerKey{}, caller)
+}
+
+type auditRedactedKey struct{}
+
+// WithAuditRedacted returns a context recording the calls made with it without the values of the
+// query parameters params, e.g. "media" for a playback saying a one-time code.
+func WithAuditRedacted(ctx context.Context, params ...string) context.Context {
+	return context.WithValue(ctx, auditRedactedKey{}, params)
+}
+
+// auditRedacted is the value recorded in place of a redacted parameter.
+const auditRedacted = "[redacted]"
+
+// isMutating reports whether method changes state on Asterisk.
+func isMutating(method string) bool {
+	return method != http.MethodGet && method != http.MethodHead && method != http.MethodOptions
+}
+
+// namedRoutes are the routes "<resource>/<action>" whose second segment names an action rather
+// than a resource, with the query parameter carrying the ID of the resource, if any.
+var namedRoutes = map[string]string{
+	"channels/create":        "channelId",
+	"channels/externalMedia": "channelId",
+	"endpoints/sendMessage":  "",
+}
+
+// createIdParams are the query parameters carrying the ID chosen by the client on creation, e.g.
+// "POST /channels?channelId=...".
+var createIdParams = map[string]string{
+	"channels": "channelId",
+	"bridges":  "bridgeId",
+}
+
+// auditOperation derives the operation and resource ID of a call from its path, relative to the
+// base path, and its query: "/channels/{id}/play" is channels.play of {id}, "DELETE
+// /channels/{id}" is channels.delete and "POST /channels/create?channelId={id}" is
+// channels.create of {id}.
+func auditOperation(method string, path string, query url.Values) (operation string, resourceId string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	resource := segments[0]
+	if resource == "recordings" && len(segments) > 2 {
+		// "/recordings/live/{name}" and "/recordings/stored/{name}" are both recordings.
+		segments = append([]string{resource}, segments[2:]...)
+	}
+	var action string
+	switch {
+	case len(segments) == 1:
+		// POST /bridges, POST /channels.
+		action = "create"
+		resourceId = query.Get(createIdParams[resource])
+	case len(segments) == 2:
+		if param, ok := namedRoutes[resource+"/"+segments[1]]; ok {
+			if param != "" {
+				resourceId = query.Get(param)
+			}
+			return resource + "." + segments[1], resourceId
+		}
+		resourceId = segments[1]
+		switch method {
+		case http.MethodDelete:
+			action = "delete"
+		case http.MethodPut:
+			action = "update"
+		default:
+			// POST /channels/{id} creates with an ID.
+			action = "create"
+		}
+	default:
+		resourceId = segments[1]
+		action = segments[len(segments)-1]
+		if method == http.MethodDelete {
+			action = "stop" + strings.ToUpper(action[:1]) + action[1:]
+		}
+	}
+	return resource + "." + action, resourceId
+}
+
+// apiPath returns the path of req relative to the base path, e.g. "/channels/{id}/play".
+func (c *APIClient) apiPath(req *http.Request) string {
+	if base, err := url.Parse(c.cfg.BasePath); err == nil {
+		return strings.TrimPrefix(req.URL.Path, base.Path)
+	}
+	return req.URL.Path
+}
+
+// audit records a mutating call.
+func (c *APIClient) audit(req *http.Request, resp *http.Response, err error, start time.Time) {
+	path := c.apiPath(req)
+	e := AuditEntry{
+		Time:     start,
+		Method:   req.Method,
+		Path:     path,
+		Duration: c.clock().Now().Sub(start),
+	}
+	q := req.URL.Query()
+	e.Operation, e.ResourceId = auditOperation(req.Method, path, q)
+	if len(q) > 0 {
+		e.Parameters = make(map[string]string, len(q))
+		for k, v := range q {
+			if k == "api_key" {
+				continue
+			}
+			e.Parameters[k] = strings.Join(v, ",")
+		}
+		redacted, _ := req.Context().Value(auditRedactedKey{}).([]string)
+		for _, k := range redacted {
+			if _, ok := e.Parameters[k]; ok {
+				e.Parameters[k] = auditRedacted
+			}
+		}
+	}
+	if req.GetBody != nil {
+		if body, berr := req.GetBody(); berr == nil {
+			data, _ := ioutil.ReadAll(body)
+			body.Close()
+			if json.Valid(data) && len(bytes.TrimSpace(data)) > 0 {
+				e.Body = data
+			}
+		}
+	}
+	if caller, ok := req.Context().Value(auditCallerKey{}).(string); ok {
+		e.Caller = caller
+	}
+	if resp != nil {
+		e.Status = resp.StatusCode
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	e.DryRun = c.cfg.DryRun
+	c.cfg.AuditSink.Record(e)
+}
@@ -0,0 +1,55 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestAuditOperation(t *testing.T) {
+	for _, tc := range []struct {
+		method, path, query string
+		operation, id       string
+	}{
+		{http.MethodPost, "/channels", "endpoint=PJSIP/alice", "channels.create", ""},
+		{http.MethodPost, "/channels", "endpoint=PJSIP/alice&channelId=c1", "channels.create", "c1"},
+		{http.MethodPost, "/channels/c1", "endpoint=PJSIP/alice", "channels.create", "c1"},
+		{http.MethodPost, "/channels/create", "endpoint=PJSIP/alice&channelId=c1", "channels.create", "c1"},
+		{http.MethodPost, "/channels/create", "endpoint=PJSIP/alice", "channels.create", ""},
+		{http.MethodPost, "/channels/externalMedia", "app=ivr&channelId=c2", "channels.externalMedia", "c2"},
+		{http.MethodDelete, "/channels/c1", "reason=normal", "channels.delete", "c1"},
+		{http.MethodPost, "/channels/c1/play", "media=sound:beep", "channels.play", "c1"},
+		{http.MethodDelete, "/channels/c1/moh", "", "channels.stopMoh", "c1"},
+		{http.MethodPost, "/bridges", "bridgeId=b1", "bridges.create", "b1"},
+		{http.MethodPost, "/bridges/b1/addChannel", "channel=c1", "bridges.addChannel", "b1"},
+		{http.MethodPut, "/endpoints/sendMessage", "to=pjsip:alice", "endpoints.sendMessage", ""},
+		{http.MethodPost, "/recordings/live/r1/stop", "", "recordings.stop", "r1"},
+		{http.MethodDelete, "/recordings/stored/r1", "", "recordings.delete", "r1"},
+		{http.MethodPost, "/recordings/stored/r1/copy", "destinationRecordingName=r2", "recordings.copy", "r1"},
+	} {
+		query, _ := url.ParseQuery(tc.query)
+		operation, id := auditOperation(tc.method, tc.path, query)
+		if operation != tc.operation || id != tc.id {
+			t.Errorf("%s %s?%s = %s of %q, want %s of %q", tc.method, tc.path, tc.query, operation, id, tc.operation, tc.id)
+		}
+	}
+}
+
+func TestAuditRedacted(t *testing.T) {
+	var entries []AuditEntry
+	cfg := NewConfiguration("http://asterisk.invalid:8088/ari")
+	cfg.DryRun = true
+	cfg.AuditSink = AuditSinkFunc(func(e AuditEntry) { entries = append(entries, e) })
+	client := NewAPIClient(cfg, NewStdLogger(ioutil.Discard))
+
+	ctx := WithAuditRedacted(context.Background(), "media")
+	client.ChannelsApi.PlaySoundWithId(ctx, "c1", "p1", []string{SayDigits("123456")}, nil)
+	if len(entries) != 1 {
+		t.Fatalf("%d entries, want 1", len(entries))
+	}
+	if media := entries[0].Parameters["media"]; media != auditRedacted {
+		t.Errorf("media = %q, want it redacted", media)
+	}
+}
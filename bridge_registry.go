@@ -0,0 +1,247 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antihax/optional"
+)
+
+// DefaultBridgeIdleTimeout is how long an empty bridge of a BridgeRegistry is kept by default.
+const DefaultBridgeIdleTimeout = time.Minute
+
+type namedBridge struct {
+	name string
+	id   string
+	// ready is closed once the bridge is created, or its creation failed with err.
+	ready   chan struct{}
+	err     error
+	members map[string]bool
+	// idle is closed to stop the idle timer of the bridge, nil while no timer runs.
+	idle chan struct{}
+}
+
+// BridgeRegistry hands out bridges by logical name, e.g. "conf:sales": the bridge of a name is
+// created on first use with an ID derived from the name, so that every instance of the
+// application agrees on it, and destroyed once it has stayed empty for IdleTimeout. Concurrent
+// requests for the same name share a single creation. Every event must be fed to HandleEvent.
+type BridgeRegistry struct {
+	client *APIClient
+
+	// Prefix of the bridge IDs. Defaults to "ari-bridge".
+	Prefix string
+	// Type of the created bridges, e.g. "mixing" or "holding". Defaults to "mixing".
+	Type string
+	// IdleTimeout after which an empty bridge is destroyed. Defaults to DefaultBridgeIdleTimeout;
+	// negative keeps empty bridges.
+	IdleTimeout time.Duration
+
+	mu     sync.Mutex
+	byName map[string]*namedBridge
+	byId   map[string]*namedBridge
+	// collecting holds the names whose bridge is being destroyed, closed once it is.
+	collecting map[string]chan struct{}
+	// destroying holds the IDs of the bridges destroyed by the registry whose BridgeDestroyed is
+	// still expected, so that it is not taken for the end of a new bridge with the same ID.
+	destroying map[string]bool
+}
+
+// NewBridgeRegistry creates a registry without bridges.
+func NewBridgeRegistry(client *APIClient) *BridgeRegistry {
+	return &BridgeRegistry{
+		client:      client,
+		Prefix:      "ari-bridge",
+		Type:        "mixing",
+		IdleTimeout: DefaultBridgeIdleTimeout,
+		byName:      make(map[string]*namedBridge),
+		byId:        make(map[string]*namedBridge),
+		collecting:  make(map[string]chan struct{}),
+		destroying:  make(map[string]bool),
+	}
+}
+
+// BridgeId returns the ID of the bridge of name: the name restricted to characters safe in URLs,
+// followed by a hash of the name, which keeps names differing only by unsafe characters apart.
+func (r *BridgeRegistry) BridgeId(name string) string {
+	safe := strings.Map(func(c rune) rune {
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.' {
+			return c
+		}
+		return '-'
+	}, name)
+	sum := sha256.Sum256([]byte(name))
+	return r.Prefix + "-" + safe + "-" + hex.EncodeToString(sum[:4])
+}
+
+// Get returns the ID of the bridge of name, creating the bridge if needed.
+func (r *BridgeRegistry) Get(ctx context.Context, name string) (string, error) {
+	r.mu.Lock()
+	b, ok := r.byName[name]
+	if ok {
+		r.mu.Unlock()
+		select {
+		case <-b.ready:
+			return b.id, b.err
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	b = &namedBridge{name: name, id: r.BridgeId(name), ready: make(chan struct{}), members: make(map[string]bool)}
+	r.byName[name] = b
+	r.byId[b.id] = b
+	collecting := r.collecting[name]
+	r.mu.Unlock()
+
+	if collecting != nil {
+		// The previous bridge of the name is being destroyed; creating it now would find it.
+		select {
+		case <-collecting:
+		case <-ctx.Done():
+			r.created(b, ctx.Err())
+			return "", ctx.Err()
+		}
+	}
+	_, created, err := r.client.BridgesApi.EnsureExists(ctx, b.id, &BridgesApiCreateWithIdOpts{
+		Type_: optional.NewString(r.Type),
+		Name:  optional.NewString(name),
+	})
+	if created {
+		r.client.logger.Debugf("bridge registry: created bridge %s for %s", b.id, name)
+	}
+	r.created(b, err)
+	return b.id, err
+}
+
+// created completes the creation of b, forgetting it if it failed.
+func (r *BridgeRegistry) created(b *namedBridge, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b.err = err
+	close(b.ready)
+	if err != nil {
+		r.forgetLocked(b)
+		return
+	}
+	if len(b.members) == 0 {
+		r.startIdleLocked(b)
+	}
+}
+
+func (r *BridgeRegistry) forgetLocked(b *namedBridge) {
+	if r.byName[b.name] == b {
+		delete(r.byName, b.name)
+	}
+	if r.byId[b.id] == b {
+		delete(r.byId, b.id)
+	}
+	r.stopIdleLocked(b)
+}
+
+// Names returns the names of the bridges of the registry.
+func (r *BridgeRegistry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(r.byName))
+	for name := range r.byName {
+		names = append(names, name)
+	}
+	return names
+}
+
+// HandleEvent tracks the members of the bridges, to collect the empty ones.
+func (r *BridgeRegistry) HandleEvent(ev StasisEvent) {
+	if ev.Bridge == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if ev.Type == "BridgeDestroyed" && r.destroying[ev.Bridge.Id] {
+		delete(r.destroying, ev.Bridge.Id)
+		return
+	}
+	b, ok := r.byId[ev.Bridge.Id]
+	if !ok {
+		return
+	}
+	switch ev.Type {
+	case "ChannelEnteredBridge":
+		b.members[ev.Channel.Id] = true
+		r.stopIdleLocked(b)
+	case "ChannelLeftBridge":
+		delete(b.members, ev.Channel.Id)
+		select {
+		case <-b.ready:
+			if len(b.members) == 0 && b.err == nil {
+				r.startIdleLocked(b)
+			}
+		default:
+		}
+	case "BridgeDestroyed":
+		r.client.logger.Debugf("bridge registry: bridge %s of %s destroyed", b.id, b.name)
+		r.forgetLocked(b)
+	}
+}
+
+func (r *BridgeRegistry) startIdleLocked(b *namedBridge) {
+	if r.IdleTimeout < 0 || b.idle != nil {
+		return
+	}
+	timeout := r.IdleTimeout
+	if timeout == 0 {
+		timeout = DefaultBridgeIdleTimeout
+	}
+	stop := make(chan struct{})
+	b.idle = stop
+	timer := r.client.clock().NewTimer(timeout)
+	r.client.goTracked("bridge_registry", func() {
+		select {
+		case <-timer.C():
+			r.collect(b, stop)
+		case <-stop:
+			timer.Stop()
+		}
+	})
+}
+
+func (r *BridgeRegistry) stopIdleLocked(b *namedBridge) {
+	if b.idle != nil {
+		close(b.idle)
+		b.idle = nil
+	}
+}
+
+// collect destroys b, empty since its idle timer stop was started.
+func (r *BridgeRegistry) collect(b *namedBridge, stop chan struct{}) {
+	r.mu.Lock()
+	if b.idle != stop {
+		// Stopped while firing: a channel entered.
+		r.mu.Unlock()
+		return
+	}
+	b.idle = nil
+	r.forgetLocked(b)
+	done := make(chan struct{})
+	r.collecting[b.name] = done
+	r.destroying[b.id] = true
+	r.mu.Unlock()
+
+	_, err := r.client.BridgesApi.Destroy(context.Background(), b.id)
+	if err != nil {
+		r.client.logger.Warnf("bridge registry: destroying idle bridge %s of %s: %v", b.id, b.name, err)
+	} else {
+		r.client.logger.Debugf("bridge registry: destroyed idle bridge %s of %s", b.id, b.name)
+		r.client.metrics().IncCounter("ari_bridges_collected_total", nil, 1)
+	}
+
+	r.mu.Lock()
+	if err != nil {
+		delete(r.destroying, b.id)
+	}
+	delete(r.collecting, b.name)
+	r.mu.Unlock()
+	close(done)
+}
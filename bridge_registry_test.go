@@ -0,0 +1,90 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBridgeRegistry(t *testing.T) {
+	var mu sync.Mutex
+	exists := false
+	var requests []string
+	clock := NewFakeClock(time.Unix(0, 0))
+	node := clusterNode(t, "a", clock, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodGet {
+			requests = append(requests, r.Method)
+		}
+		switch r.Method {
+		case http.MethodGet:
+			if !exists {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"message":"Bridge not found"}`))
+				return
+			}
+		case http.MethodPost:
+			exists = true
+		case http.MethodDelete:
+			exists = false
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Write([]byte(`{"id":"` + strings.TrimPrefix(r.URL.Path, "/ari/bridges/") + `"}`))
+	})
+	registry := NewBridgeRegistry(node.Client)
+	ctx := context.Background()
+
+	id := registry.BridgeId("conf:sales")
+	if !strings.HasPrefix(id, "ari-bridge-conf-sales-") || id == registry.BridgeId("conf/sales") {
+		t.Errorf("BridgeId = %s, want a safe ID distinct from conf/sales", id)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got, err := registry.Get(ctx, "conf:sales"); err != nil || got != id {
+				t.Errorf("Get = %s, %v, want %s", got, err, id)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// A member keeps the bridge past the idle timeout.
+	bridge := &Bridge{Id: id}
+	registry.HandleEvent(StasisEvent{Type: "ChannelEnteredBridge", Channel: Channel{Id: "c1"}, Bridge: bridge})
+	waitTimers(t, clock, 0)
+	clock.Advance(2 * DefaultBridgeIdleTimeout)
+	registry.HandleEvent(StasisEvent{Type: "ChannelLeftBridge", Channel: Channel{Id: "c1"}, Bridge: bridge})
+	waitTimers(t, clock, 1)
+	clock.Advance(DefaultBridgeIdleTimeout)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(registry.Names()) != 0 || func() bool { mu.Lock(); defer mu.Unlock(); return exists }() {
+		if time.Now().After(deadline) {
+			t.Fatal("idle bridge not collected")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	// The BridgeDestroyed of the collected bridge does not end the next one of the name.
+	if _, err := registry.Get(ctx, "conf:sales"); err != nil {
+		t.Fatal(err)
+	}
+	registry.HandleEvent(StasisEvent{Type: "BridgeDestroyed", Bridge: bridge})
+	if names := registry.Names(); len(names) != 1 {
+		t.Errorf("names = %q, want the recreated bridge", names)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got := strings.Join(requests, " "); got != "POST DELETE POST" {
+		t.Errorf("requests = %s, want POST DELETE POST", got)
+	}
+}
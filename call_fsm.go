@@ -0,0 +1,139 @@
+package asterisk_ari_go
+
+import (
+	"sync"
+	"time"
+)
+
+// AnyState is the From of a transition taken from every state.
+const AnyState = "*"
+
+// FSMTransition moves a call from state From to state To on an event of type On.
+type FSMTransition struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	On   string `json:"on"`
+}
+
+// ObservedTransition is a transition taken by a call.
+type ObservedTransition struct {
+	FSMTransition
+	Time time.Time `json:"time"`
+}
+
+// DefaultCallTransitions is the life cycle of a channel in a Stasis application.
+var DefaultCallTransitions = []FSMTransition{
+	{From: "new", To: "in_app", On: "StasisStart"},
+	{From: "in_app", To: "bridged", On: "ChannelEnteredBridge"},
+	{From: "bridged", To: "in_app", On: "ChannelLeftBridge"},
+	{From: "in_app", To: "held", On: "ChannelHold"},
+	{From: "bridged", To: "held", On: "ChannelHold"},
+	{From: "held", To: "in_app", On: "ChannelUnhold"},
+	{From: AnyState, To: "hanging_up", On: "ChannelHangupRequest"},
+	{From: AnyState, To: "ended", On: "StasisEnd"},
+}
+
+// CallFSM tracks the state of every call through a state machine driven by events. Every event
+// must be fed to HandleEvent; events without a matching transition leave the state unchanged.
+// The definition and the transitions observed per call can be exported, see DOT and Mermaid.
+type CallFSM struct {
+	client *APIClient
+
+	// Initial is the state of a call before its first transition.
+	Initial string
+	// Transitions are tried in order; the first one matching the state and the event is taken.
+	Transitions []FSMTransition
+	// OnTransition, if set, is called with every transition taken, outside of the lock.
+	OnTransition func(channelId string, t ObservedTransition)
+
+	mu    sync.Mutex
+	calls map[string]*fsmCall
+}
+
+type fsmCall struct {
+	state   string
+	history []ObservedTransition
+}
+
+// NewCallFSM creates a state machine starting in initial. Without transitions it uses
+// DefaultCallTransitions, starting in "new".
+func NewCallFSM(client *APIClient, initial string, transitions ...FSMTransition) *CallFSM {
+	if len(transitions) == 0 {
+		initial, transitions = "new", DefaultCallTransitions
+	}
+	return &CallFSM{
+		client:      client,
+		Initial:     initial,
+		Transitions: transitions,
+		calls:       make(map[string]*fsmCall),
+	}
+}
+
+// HandleEvent moves the call of ev to its next state.
+func (f *CallFSM) HandleEvent(ev StasisEvent) {
+	channelId := ev.Channel.Id
+	if channelId == "" {
+		return
+	}
+	f.mu.Lock()
+	call, ok := f.calls[channelId]
+	if !ok {
+		call = &fsmCall{state: f.Initial}
+	}
+	t, ok := f.next(call.state, ev.Type)
+	if !ok {
+		f.mu.Unlock()
+		return
+	}
+	observed := ObservedTransition{
+		FSMTransition: FSMTransition{From: call.state, To: t.To, On: ev.Type},
+		Time:          f.client.clock().Now(),
+	}
+	call.state = t.To
+	call.history = append(call.history, observed)
+	f.calls[channelId] = call
+	f.mu.Unlock()
+
+	f.client.metrics().IncCounter("ari_fsm_transitions_total", map[string]string{"from": observed.From, "to": observed.To}, 1)
+	if f.OnTransition != nil {
+		f.OnTransition(channelId, observed)
+	}
+}
+
+func (f *CallFSM) next(state string, eventType string) (FSMTransition, bool) {
+	for _, t := range f.Transitions {
+		if t.On == eventType && (t.From == state || (t.From == AnyState && t.To != state)) {
+			return t, true
+		}
+	}
+	return FSMTransition{}, false
+}
+
+// State returns the current state of a call, Initial if it has not transitioned.
+func (f *CallFSM) State(channelId string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if call, ok := f.calls[channelId]; ok {
+		return call.state
+	}
+	return f.Initial
+}
+
+// History returns the transitions taken by a call, oldest first.
+func (f *CallFSM) History(channelId string) []ObservedTransition {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	call, ok := f.calls[channelId]
+	if !ok {
+		return nil
+	}
+	return append([]ObservedTransition(nil), call.history...)
+}
+
+// Forget drops a call. The history of ended calls is kept until then, so that it can be
+// exported; call it once the call is done with.
+func (f *CallFSM) Forget(channelId string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.calls, channelId)
+}
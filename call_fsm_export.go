@@ -0,0 +1,118 @@
+package asterisk_ari_go
+
+import (
+	"fmt"
+	"strings"
+)
+
+// states returns the states of the definition in order of appearance, without AnyState.
+func (f *CallFSM) states() []string {
+	seen := map[string]bool{}
+	var states []string
+	add := func(s string) {
+		if s != AnyState && !seen[s] {
+			seen[s] = true
+			states = append(states, s)
+		}
+	}
+	add(f.Initial)
+	for _, t := range f.Transitions {
+		add(t.From)
+		add(t.To)
+	}
+	return states
+}
+
+// edges returns the transitions of the definition with AnyState expanded to every other state.
+func (f *CallFSM) edges() []FSMTransition {
+	var edges []FSMTransition
+	for _, t := range f.Transitions {
+		if t.From != AnyState {
+			edges = append(edges, t)
+			continue
+		}
+		for _, s := range f.states() {
+			if s != t.To {
+				edges = append(edges, FSMTransition{From: s, To: t.To, On: t.On})
+			}
+		}
+	}
+	return edges
+}
+
+// DOT renders the definition as a Graphviz digraph.
+func (f *CallFSM) DOT() string {
+	return f.dot("CallFSM", nil)
+}
+
+// CallDOT renders the definition with the transitions observed for a call highlighted and
+// numbered in the order they were taken.
+func (f *CallFSM) CallDOT(channelId string) string {
+	return f.dot(channelId, f.History(channelId))
+}
+
+func (f *CallFSM) dot(name string, history []ObservedTransition) string {
+	steps := map[FSMTransition][]string{}
+	for i, t := range history {
+		steps[t.FSMTransition] = append(steps[t.FSMTransition], fmt.Sprint(i+1))
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %q {\n", name)
+	b.WriteString("  rankdir=LR;\n  node [shape=ellipse];\n")
+	b.WriteString("  __start [shape=point];\n")
+	fmt.Fprintf(&b, "  __start -> %q;\n", f.Initial)
+	for _, e := range f.edges() {
+		if n, ok := steps[e]; ok {
+			fmt.Fprintf(&b, "  %q -> %q [label=%q, color=red, penwidth=2];\n", e.From, e.To, strings.Join(n, ",")+". "+e.On)
+			delete(steps, e)
+			continue
+		}
+		fmt.Fprintf(&b, "  %q -> %q [label=%q", e.From, e.To, e.On)
+		if history != nil {
+			b.WriteString(", color=gray")
+		}
+		b.WriteString("];\n")
+	}
+	// Transitions observed but no longer in the definition, e.g. after it changed.
+	for _, t := range history {
+		if n, ok := steps[t.FSMTransition]; ok {
+			fmt.Fprintf(&b, "  %q -> %q [label=%q, color=red, style=dashed];\n", t.From, t.To, strings.Join(n, ",")+". "+t.On)
+			delete(steps, t.FSMTransition)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid renders the definition as a Mermaid state diagram.
+func (f *CallFSM) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("stateDiagram-v2\n")
+	fmt.Fprintf(&b, "  [*] --> %s\n", mermaidId(f.Initial))
+	for _, e := range f.edges() {
+		fmt.Fprintf(&b, "  %s --> %s : %s\n", mermaidId(e.From), mermaidId(e.To), e.On)
+	}
+	return b.String()
+}
+
+// CallMermaid renders the transitions observed for a call as a Mermaid sequence of states, each
+// labelled with its step number, event and time.
+func (f *CallFSM) CallMermaid(channelId string) string {
+	var b strings.Builder
+	b.WriteString("stateDiagram-v2\n")
+	fmt.Fprintf(&b, "  [*] --> %s\n", mermaidId(f.Initial))
+	for i, t := range f.History(channelId) {
+		fmt.Fprintf(&b, "  %s --> %s : %d. %s at %s\n", mermaidId(t.From), mermaidId(t.To), i+1, t.On, t.Time.Format("15:04:05.000"))
+	}
+	return b.String()
+}
+
+// mermaidId makes a state name usable as a Mermaid state ID.
+func mermaidId(state string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return '_'
+	}, state)
+}
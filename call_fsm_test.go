@@ -0,0 +1,69 @@
+package asterisk_ari_go
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCallFSM(t *testing.T) {
+	start := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	cfg := NewConfiguration("/")
+	cfg.Clock = clock
+	fsm := NewCallFSM(NewAPIClient(cfg, NewStdLogger(ioutil.Discard)), "")
+	var observed []string
+	fsm.OnTransition = func(channelId string, t ObservedTransition) { observed = append(observed, t.To) }
+
+	for _, typ := range []string{"StasisStart", "ChannelEnteredBridge", "ChannelDtmfReceived", "ChannelHold", "ChannelUnhold", "ChannelHangupRequest", "ChannelHangupRequest", "StasisEnd"} {
+		clock.Advance(time.Second)
+		fsm.HandleEvent(StasisEvent{Type: typ, Channel: Channel{Id: "c1"}})
+	}
+
+	// Unknown events and AnyState transitions to the current state are not taken.
+	want := []string{"in_app", "bridged", "held", "in_app", "hanging_up", "ended"}
+	if strings.Join(observed, " ") != strings.Join(want, " ") {
+		t.Errorf("transitions to %q, want %q", observed, want)
+	}
+	if fsm.State("c1") != "ended" || fsm.State("c2") != "new" {
+		t.Errorf("states = %s, %s, want ended, new", fsm.State("c1"), fsm.State("c2"))
+	}
+	history := fsm.History("c1")
+	if len(history) != 6 || history[1].From != "in_app" || history[1].On != "ChannelEnteredBridge" || !history[1].Time.Equal(start.Add(2*time.Second)) {
+		t.Errorf("history = %+v", history)
+	}
+
+	fsm.Forget("c1")
+	if fsm.History("c1") != nil || fsm.State("c1") != "new" {
+		t.Error("c1 not forgotten")
+	}
+}
+
+func TestCallFSMExport(t *testing.T) {
+	client := NewAPIClient(NewConfiguration("/"), NewStdLogger(ioutil.Discard))
+	fsm := NewCallFSM(client, "idle",
+		FSMTransition{From: "idle", To: "in-call", On: "StasisStart"},
+		FSMTransition{From: AnyState, To: "done", On: "StasisEnd"},
+	)
+	fsm.HandleEvent(StasisEvent{Type: "StasisStart", Channel: Channel{Id: "c1"}})
+
+	dot := fsm.CallDOT("c1")
+	for _, line := range []string{
+		`"idle" -> "in-call" [label="1. StasisStart", color=red, penwidth=2];`,
+		`"in-call" -> "done" [label="StasisEnd", color=gray];`,
+		`"idle" -> "done" [label="StasisEnd", color=gray];`,
+	} {
+		if !strings.Contains(dot, line) {
+			t.Errorf("DOT lacks %s:\n%s", line, dot)
+		}
+	}
+	if strings.Contains(fsm.DOT(), "color") {
+		t.Errorf("DOT of the definition highlights transitions:\n%s", fsm.DOT())
+	}
+
+	want := "stateDiagram-v2\n  [*] --> idle\n  idle --> in_call : StasisStart\n  idle --> done : StasisEnd\n  in_call --> done : StasisEnd\n"
+	if got := fsm.Mermaid(); got != want {
+		t.Errorf("Mermaid =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,220 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ErrCallLimitExceeded is returned when a new call would exceed the limit configured for one of its keys.
+type ErrCallLimitExceeded struct {
+	// Key is the limit key that is at capacity, e.g. "trunk:carrier-a".
+	Key string
+	// Limit is the configured maximum of concurrent calls for Key.
+	Limit int
+	// Active is the number of calls active for Key when the call was rejected.
+	Active int
+}
+
+// Error implements the error interface.
+func (e *ErrCallLimitExceeded) Error() string {
+	return fmt.Sprintf("call limit exceeded for %s: %d of %d active", e.Key, e.Active, e.Limit)
+}
+
+// EndpointLimitKey returns the limit key for an endpoint, e.g. "PJSIP/alice".
+func EndpointLimitKey(endpoint string) string {
+	return "endpoint:" + endpoint
+}
+
+// TrunkLimitKey returns the limit key for a trunk name.
+func TrunkLimitKey(trunk string) string {
+	return "trunk:" + trunk
+}
+
+// TenantLimitKey returns the limit key for a tenant name.
+func TenantLimitKey(tenant string) string {
+	return "tenant:" + tenant
+}
+
+// DIDLimitKey returns the limit key for a dialed number or extension.
+func DIDLimitKey(did string) string {
+	return "did:" + did
+}
+
+// ChannelEndpoint derives the endpoint of a channel from its name,
+// e.g. "PJSIP/alice-00000001" becomes "PJSIP/alice".
+func ChannelEndpoint(channelName string) string {
+	if i := strings.LastIndex(channelName, "-"); i > strings.Index(channelName, "/") {
+		return channelName[:i]
+	}
+	return channelName
+}
+
+// CallLimiter tracks active calls per limit key (endpoint, trunk, tenant or any other
+// grouping) and rejects or queues new calls beyond the configured limits.
+//
+// Calls are released when their channel is destroyed, so every event received on the
+// application websocket must be fed to HandleEvent.
+type CallLimiter struct {
+	client *APIClient
+
+	// Queue makes Acquire wait for a free slot until its context is done instead of
+	// failing immediately with ErrCallLimitExceeded.
+	Queue bool
+	// KeyFunc, if set, is used to track inbound calls: it returns the limit keys of the
+	// channel in a StasisStart event. Inbound calls are counted but never rejected.
+	KeyFunc func(ev StasisEvent) []string
+
+	mu       sync.Mutex
+	limits   map[string]int
+	active   map[string]int
+	channels map[string][]string
+	released chan struct{}
+}
+
+// NewCallLimiter creates a limiter with the given limits per key. Keys without a limit are unlimited.
+func NewCallLimiter(client *APIClient, limits map[string]int) *CallLimiter {
+	l := &CallLimiter{
+		client:   client,
+		limits:   make(map[string]int),
+		active:   make(map[string]int),
+		channels: make(map[string][]string),
+		released: make(chan struct{}),
+	}
+	for k, v := range limits {
+		l.limits[k] = v
+	}
+	return l
+}
+
+// SetLimit changes the limit of a key. A limit of zero or less removes it.
+func (l *CallLimiter) SetLimit(key string, limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if limit <= 0 {
+		delete(l.limits, key)
+	} else {
+		l.limits[key] = limit
+	}
+	l.wake()
+}
+
+// Active returns the number of active calls for key.
+func (l *CallLimiter) Active(key string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.active[key]
+}
+
+// TryAcquire reserves a slot for channelId under every key, or returns *ErrCallLimitExceeded
+// without reserving anything if one of the keys is at capacity.
+func (l *CallLimiter) TryAcquire(channelId string, keys ...string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.tryAcquireLocked(channelId, keys)
+}
+
+// Acquire reserves a slot for channelId under every key. If Queue is set it waits for
+// capacity until ctx is done, otherwise it behaves like TryAcquire.
+func (l *CallLimiter) Acquire(ctx context.Context, channelId string, keys ...string) error {
+	for {
+		l.mu.Lock()
+		err := l.tryAcquireLocked(channelId, keys)
+		released := l.released
+		l.mu.Unlock()
+		if err == nil || !l.Queue {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-released:
+		}
+	}
+}
+
+// Track counts channelId under keys without checking the limits.
+func (l *CallLimiter) Track(channelId string, keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.channels[channelId]; ok {
+		return
+	}
+	l.takeLocked(channelId, keys)
+}
+
+// Release frees the slots held by channelId. Releasing an unknown channel is a no-op.
+func (l *CallLimiter) Release(channelId string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	keys, ok := l.channels[channelId]
+	if !ok {
+		return
+	}
+	delete(l.channels, channelId)
+	l.client.TrackResource(ResourceChannel, "call_limiter", -1)
+	for _, k := range keys {
+		if l.active[k]--; l.active[k] <= 0 {
+			delete(l.active, k)
+		}
+	}
+	l.wake()
+}
+
+// HandleEvent feeds an event received from Asterisk into the limiter.
+func (l *CallLimiter) HandleEvent(ev StasisEvent) {
+	switch ev.Type {
+	case "StasisStart":
+		if l.KeyFunc != nil {
+			l.Track(ev.Channel.Id, l.KeyFunc(ev)...)
+		}
+	case "ChannelDestroyed":
+		l.Release(ev.Channel.Id)
+	}
+}
+
+// Originate creates a new channel after reserving a slot under keys. The slot is released
+// when the originate request fails or the channel is destroyed.
+func (l *CallLimiter) Originate(ctx context.Context, endpoint string, opts *ChannelsApiOriginateWithIdOpts, keys ...string) (Channel, *http.Response, error) {
+	channelId := newResourceId("call")
+	if err := l.Acquire(ctx, channelId, keys...); err != nil {
+		return Channel{}, nil, err
+	}
+
+	l.client.markOriginated(channelId)
+	channel, resp, err := l.client.ChannelsApi.OriginateWithId(ctx, channelId, endpoint, opts)
+	if err != nil {
+		l.client.originateFailed(channelId, resp)
+		l.Release(channelId)
+	}
+	return channel, resp, err
+}
+
+func (l *CallLimiter) tryAcquireLocked(channelId string, keys []string) error {
+	for _, k := range keys {
+		if limit, ok := l.limits[k]; ok && l.active[k] >= limit {
+			return &ErrCallLimitExceeded{Key: k, Limit: limit, Active: l.active[k]}
+		}
+	}
+	l.takeLocked(channelId, keys)
+	return nil
+}
+
+func (l *CallLimiter) takeLocked(channelId string, keys []string) {
+	if _, ok := l.channels[channelId]; !ok {
+		l.client.TrackResource(ResourceChannel, "call_limiter", 1)
+	}
+	l.channels[channelId] = append(l.channels[channelId], keys...)
+	for _, k := range keys {
+		l.active[k]++
+	}
+}
+
+// wake notifies queued Acquire calls that capacity may be available.
+func (l *CallLimiter) wake() {
+	close(l.released)
+	l.released = make(chan struct{})
+}
@@ -0,0 +1,90 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestCallLimiter(t *testing.T) {
+	client := NewAPIClient(NewConfiguration("/"), NewStdLogger(ioutil.Discard))
+	trunk := TrunkLimitKey("carrier-a")
+	l := NewCallLimiter(client, map[string]int{trunk: 2, TenantLimitKey("acme"): 1})
+
+	if err := l.TryAcquire("c1", trunk); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.TryAcquire("c2", trunk, TenantLimitKey("acme")); err != nil {
+		t.Fatal(err)
+	}
+	// The tenant has room, the trunk does not: nothing is reserved.
+	err := l.TryAcquire("c3", TenantLimitKey("globex"), trunk)
+	var exceeded *ErrCallLimitExceeded
+	if !errors.As(err, &exceeded) || exceeded.Key != trunk || exceeded.Active != 2 {
+		t.Fatalf("err = %v, want the trunk at capacity", err)
+	}
+	if l.Active(TenantLimitKey("globex")) != 0 {
+		t.Error("a key was reserved by a rejected call")
+	}
+
+	l.HandleEvent(StasisEvent{Type: "ChannelDestroyed", Channel: Channel{Id: "c1"}})
+	l.Release("c1")
+	if l.Active(trunk) != 1 {
+		t.Errorf("trunk active = %d after a release, want 1", l.Active(trunk))
+	}
+
+	// Inbound calls are counted, never rejected.
+	l.KeyFunc = func(ev StasisEvent) []string { return []string{TenantLimitKey("acme")} }
+	l.HandleEvent(StasisEvent{Type: "StasisStart", Channel: Channel{Id: "in1"}})
+	if l.Active(TenantLimitKey("acme")) != 2 {
+		t.Errorf("tenant active = %d, want the inbound call counted over the limit", l.Active(TenantLimitKey("acme")))
+	}
+}
+
+func TestCallLimiterQueue(t *testing.T) {
+	client := NewAPIClient(NewConfiguration("/"), NewStdLogger(ioutil.Discard))
+	key := EndpointLimitKey("PJSIP/alice")
+	l := NewCallLimiter(client, map[string]int{key: 1})
+	l.Queue = true
+	if err := l.Acquire(context.Background(), "c1", key); err != nil {
+		t.Fatal(err)
+	}
+
+	acquired := make(chan error, 1)
+	go func() { acquired <- l.Acquire(context.Background(), "c2", key) }()
+	select {
+	case err := <-acquired:
+		t.Fatalf("Acquire returned %v at capacity", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	l.Release("c1")
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("queued Acquire not woken by the release")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Acquire(ctx, "c3", key); err == nil {
+		t.Error("Acquire succeeded at capacity with a done context")
+	}
+}
+
+func TestChannelEndpoint(t *testing.T) {
+	for name, want := range map[string]string{
+		"PJSIP/alice-00000001":     "PJSIP/alice",
+		"PJSIP/trunk-a-0000002a":   "PJSIP/trunk-a",
+		"Local/1001@internal-0001": "Local/1001@internal",
+		"PJSIP/alice":              "PJSIP/alice",
+	} {
+		if got := ChannelEndpoint(name); got != want {
+			t.Errorf("ChannelEndpoint(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
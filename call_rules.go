@@ -0,0 +1,215 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/antihax/optional"
+)
+
+// DefaultNoAnswerTimeout is how long an endpoint rings before forward-on-no-answer by default.
+const DefaultNoAnswerTimeout = 20 * time.Second
+
+// MaxForwardHops bounds the chains of forwards, e.g. alice forwarding to bob forwarding back.
+const MaxForwardHops = 5
+
+var (
+	// ErrDoNotDisturb is returned by CallRules.Ring for an endpoint in do-not-disturb.
+	ErrDoNotDisturb = errors.New("endpoint is in do-not-disturb")
+	// ErrForwardLoop is returned when forwards chain for more than MaxForwardHops.
+	ErrForwardLoop = errors.New("forwarding loop")
+)
+
+// ForwardRules are the do-not-disturb and forwarding rules of an endpoint. Destinations are
+// endpoints, e.g. "PJSIP/bob" or "PJSIP/+15551234@trunk".
+type ForwardRules struct {
+	Endpoint string `json:"endpoint"`
+	// DND rejects the calls.
+	DND bool `json:"dnd,omitempty"`
+	// Always forwards every call without ringing the endpoint.
+	Always string `json:"always,omitempty"`
+	// Busy forwards the calls the endpoint rejects as busy.
+	Busy string `json:"busy,omitempty"`
+	// NoAnswer forwards the calls not answered within NoAnswerTimeout, or when the endpoint is
+	// unreachable.
+	NoAnswer        string        `json:"no_answer,omitempty"`
+	NoAnswerTimeout time.Duration `json:"no_answer_timeout,omitempty"`
+}
+
+// RouteAction is what routing does with a call to an endpoint.
+type RouteAction string
+
+const (
+	// RouteRing rings the endpoint.
+	RouteRing RouteAction = "ring"
+	// RouteReject rejects the call, the endpoint is in do-not-disturb.
+	RouteReject RouteAction = "reject"
+)
+
+// RouteDecision is the result of evaluating the rules of an endpoint.
+type RouteDecision struct {
+	Action RouteAction `json:"action"`
+	// Endpoint is the endpoint to ring, after following the forward-always rules.
+	Endpoint string `json:"endpoint"`
+	// Forwarded lists the endpoints forwarded from, in order.
+	Forwarded []string `json:"forwarded,omitempty"`
+	// Rules are the rules of Endpoint.
+	Rules ForwardRules `json:"rules"`
+}
+
+// CallRules holds per-endpoint do-not-disturb and forwarding rules, persisted in a StateStore,
+// and applies them when routing calls.
+type CallRules struct {
+	client *APIClient
+	store  StateStore
+
+	mu    sync.Mutex
+	cache map[string]ForwardRules
+}
+
+// NewCallRules creates a rules engine persisting to store; nil keeps the rules in memory.
+func NewCallRules(client *APIClient, store StateStore) *CallRules {
+	if store == nil {
+		store = NewMemoryStateStore()
+	}
+	return &CallRules{client: client, store: store, cache: make(map[string]ForwardRules)}
+}
+
+func callRulesKey(endpoint string) string {
+	return "rules:" + endpoint
+}
+
+// Get returns the rules of an endpoint; an endpoint without rules has zero rules.
+func (r *CallRules) Get(ctx context.Context, endpoint string) (ForwardRules, error) {
+	r.mu.Lock()
+	rules, ok := r.cache[endpoint]
+	r.mu.Unlock()
+	if ok {
+		return rules, nil
+	}
+	data, err := r.store.Load(ctx, callRulesKey(endpoint))
+	switch {
+	case errors.Is(err, ErrStateNotFound):
+		rules = ForwardRules{Endpoint: endpoint}
+	case err != nil:
+		return ForwardRules{}, err
+	default:
+		if err := json.Unmarshal(data, &rules); err != nil {
+			return ForwardRules{}, err
+		}
+	}
+	r.mu.Lock()
+	r.cache[endpoint] = rules
+	r.mu.Unlock()
+	return rules, nil
+}
+
+// Set stores the rules of rules.Endpoint, replacing the previous ones.
+func (r *CallRules) Set(ctx context.Context, rules ForwardRules) error {
+	if rules.Endpoint == "" {
+		return errors.New("call rules: endpoint is required")
+	}
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	if err := r.store.Save(ctx, callRulesKey(rules.Endpoint), data); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.cache[rules.Endpoint] = rules
+	r.mu.Unlock()
+	return nil
+}
+
+// Delete removes the rules of an endpoint.
+func (r *CallRules) Delete(ctx context.Context, endpoint string) error {
+	if err := r.store.Delete(ctx, callRulesKey(endpoint)); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	delete(r.cache, endpoint)
+	r.mu.Unlock()
+	return nil
+}
+
+// SetDND turns do-not-disturb on or off for an endpoint, keeping its other rules.
+func (r *CallRules) SetDND(ctx context.Context, endpoint string, on bool) error {
+	rules, err := r.Get(ctx, endpoint)
+	if err != nil {
+		return err
+	}
+	rules.DND = on
+	return r.Set(ctx, rules)
+}
+
+// Evaluate follows the forward-always rules from endpoint and returns what to do with a call.
+func (r *CallRules) Evaluate(ctx context.Context, endpoint string) (RouteDecision, error) {
+	d := RouteDecision{Endpoint: endpoint}
+	for hops := 0; ; hops++ {
+		rules, err := r.Get(ctx, d.Endpoint)
+		if err != nil {
+			return d, err
+		}
+		d.Rules = rules
+		switch {
+		case rules.DND:
+			d.Action = RouteReject
+			return d, nil
+		case rules.Always == "":
+			d.Action = RouteRing
+			return d, nil
+		case hops >= MaxForwardHops:
+			return d, ErrForwardLoop
+		}
+		d.Forwarded = append(d.Forwarded, d.Endpoint)
+		d.Endpoint = rules.Always
+	}
+}
+
+// Ring routes a call to endpoint according to the rules: it follows the forwards, rings the
+// resulting endpoint, and on busy or no answer follows the conditional forwards. opts are the
+// originate parameters, App being required; their Timeout is overridden by the no-answer
+// timeouts. It returns the answered channel and the endpoint that answered.
+func (r *CallRules) Ring(ctx context.Context, w *Waits, endpoint string, opts ChannelsApiOriginateWithIdOpts) (Channel, string, error) {
+	for hops := 0; hops <= MaxForwardHops; hops++ {
+		d, err := r.Evaluate(ctx, endpoint)
+		if err != nil {
+			return Channel{}, "", err
+		}
+		if d.Action == RouteReject {
+			return Channel{}, d.Endpoint, ErrDoNotDisturb
+		}
+		timeout := d.Rules.NoAnswerTimeout
+		if timeout <= 0 {
+			timeout = DefaultNoAnswerTimeout
+		}
+		opts.Timeout = optional.NewInt32(int32(timeout / time.Second))
+		ringCtx, cancel := context.WithTimeout(ctx, timeout+5*time.Second)
+		channel, err := w.OriginateAndWait(ringCtx, d.Endpoint, &opts)
+		cancel()
+		if err == nil {
+			return channel, d.Endpoint, nil
+		}
+		if ctx.Err() != nil {
+			return Channel{}, d.Endpoint, ctx.Err()
+		}
+
+		next := d.Rules.NoAnswer
+		var hangup *HangupError
+		if errors.As(err, &hangup) && (hangup.Cause == 17 || hangup.Cause == 34 || hangup.Cause == 42) {
+			// User busy, or congestion.
+			next = d.Rules.Busy
+		}
+		if next == "" {
+			return Channel{}, d.Endpoint, err
+		}
+		r.client.logger.Infof("call rules: %s did not answer (%v), forwarding to %s", d.Endpoint, err, next)
+		r.client.metrics().IncCounter("ari_call_forwards_total", nil, 1)
+		endpoint = next
+	}
+	return Channel{}, endpoint, ErrForwardLoop
+}
@@ -0,0 +1,52 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestCallRulesEvaluate(t *testing.T) {
+	ctx := context.Background()
+	client := NewAPIClient(NewConfiguration("/"), NewStdLogger(ioutil.Discard))
+	store := NewMemoryStateStore()
+	rules := NewCallRules(client, store)
+	for _, r := range []ForwardRules{
+		{Endpoint: "PJSIP/alice", Always: "PJSIP/bob"},
+		{Endpoint: "PJSIP/bob", Always: "PJSIP/carol"},
+		{Endpoint: "PJSIP/dave", DND: true},
+		{Endpoint: "PJSIP/erin", Always: "PJSIP/frank"},
+		{Endpoint: "PJSIP/frank", Always: "PJSIP/erin"},
+	} {
+		if err := rules.Set(ctx, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d, err := rules.Evaluate(ctx, "PJSIP/alice")
+	if err != nil || d.Action != RouteRing || d.Endpoint != "PJSIP/carol" || !reflect.DeepEqual(d.Forwarded, []string{"PJSIP/alice", "PJSIP/bob"}) {
+		t.Errorf("alice = %+v, %v, want carol rung after 2 forwards", d, err)
+	}
+	if d, err := rules.Evaluate(ctx, "PJSIP/dave"); err != nil || d.Action != RouteReject {
+		t.Errorf("dave = %+v, %v, want rejected", d, err)
+	}
+	if _, err := rules.Evaluate(ctx, "PJSIP/erin"); !errors.Is(err, ErrForwardLoop) {
+		t.Errorf("erin err = %v, want ErrForwardLoop", err)
+	}
+
+	// The rules are read back from the store by another instance.
+	if err := rules.SetDND(ctx, "PJSIP/carol", true); err != nil {
+		t.Fatal(err)
+	}
+	if d, err := NewCallRules(client, store).Evaluate(ctx, "PJSIP/alice"); err != nil || d.Action != RouteReject || d.Endpoint != "PJSIP/carol" {
+		t.Errorf("alice from the store = %+v, %v, want carol rejecting", d, err)
+	}
+	if err := rules.Delete(ctx, "PJSIP/alice"); err != nil {
+		t.Fatal(err)
+	}
+	if d, _ := rules.Evaluate(ctx, "PJSIP/alice"); d.Action != RouteRing || d.Endpoint != "PJSIP/alice" {
+		t.Errorf("alice without rules = %+v, want rung", d)
+	}
+}
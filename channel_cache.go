@@ -0,0 +1,115 @@
+package asterisk_ari_go
+
+import (
+	"sync"
+	"time"
+)
+
+// channelTombstoneTTL is how long destroyed channels are remembered.
+const channelTombstoneTTL = time.Minute
+
+// ChannelCache keeps a snapshot of the channels seen on the event stream, with the variables set
+// on them, so that their state can be read without a REST round trip. Destroyed channels are
+// remembered for a minute. Every event must be fed to HandleEvent.
+type ChannelCache struct {
+	// Clock times the memory of destroyed channels. Nil uses RealClock.
+	Clock Clock
+
+	mu        sync.RWMutex
+	channels  map[string]Channel
+	variables map[string]map[string]string
+	destroyed map[string]time.Time
+}
+
+// clock returns the configured clock.
+func (c *ChannelCache) clock() Clock {
+	if c.Clock != nil {
+		return c.Clock
+	}
+	return RealClock
+}
+
+// NewChannelCache creates an empty cache.
+func NewChannelCache() *ChannelCache {
+	return &ChannelCache{
+		channels:  make(map[string]Channel),
+		variables: make(map[string]map[string]string),
+		destroyed: make(map[string]time.Time),
+	}
+}
+
+// HandleEvent updates the cache from an event received from Asterisk.
+func (c *ChannelCache) HandleEvent(ev StasisEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch ev.Type {
+	case "ChannelDestroyed":
+		delete(c.channels, ev.Channel.Id)
+		delete(c.variables, ev.Channel.Id)
+		now := c.clock().Now()
+		c.destroyed[ev.Channel.Id] = now
+		for id, at := range c.destroyed {
+			if now.Sub(at) > channelTombstoneTTL {
+				delete(c.destroyed, id)
+			}
+		}
+		return
+	case "ChannelVarset":
+		if ev.Channel.Id == "" {
+			return
+		}
+		vars, ok := c.variables[ev.Channel.Id]
+		if !ok {
+			vars = make(map[string]string)
+			c.variables[ev.Channel.Id] = vars
+		}
+		vars[ev.Variable] = ev.Value
+	}
+
+	if ev.Channel.Id != "" {
+		if _, gone := c.destroyed[ev.Channel.Id]; !gone {
+			c.channels[ev.Channel.Id] = ev.Channel
+		}
+	}
+	if ev.Peer != nil && ev.Peer.Id != "" {
+		if _, gone := c.destroyed[ev.Peer.Id]; !gone {
+			c.channels[ev.Peer.Id] = *ev.Peer
+		}
+	}
+}
+
+// Get returns the last known snapshot of channelId.
+func (c *ChannelCache) Get(channelId string) (Channel, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	channel, ok := c.channels[channelId]
+	return channel, ok
+}
+
+// Variable returns the last value set on channelId for name, as seen in ChannelVarset events.
+func (c *ChannelCache) Variable(channelId string, name string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	v, ok := c.variables[channelId][name]
+	return v, ok
+}
+
+// Destroyed reports whether channelId was destroyed recently.
+func (c *ChannelCache) Destroyed(channelId string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.destroyed[channelId]
+	return ok
+}
+
+// List returns the snapshots of the live channels.
+func (c *ChannelCache) List() []Channel {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	channels := make([]Channel, 0, len(c.channels))
+	for _, channel := range c.channels {
+		channels = append(channels, channel)
+	}
+	return channels
+}
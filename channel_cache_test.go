@@ -0,0 +1,50 @@
+package asterisk_ari_go
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChannelCache(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	cache := NewChannelCache()
+	cache.Clock = clock
+
+	cache.HandleEvent(StasisEvent{Type: "StasisStart", Channel: Channel{Id: "c1", State: "Ring"}})
+	cache.HandleEvent(StasisEvent{Type: "ChannelStateChange", Channel: Channel{Id: "c1", State: "Up"}})
+	cache.HandleEvent(StasisEvent{Type: "ChannelVarset", Channel: Channel{Id: "c1", State: "Up"}, Variable: "LANG", Value: "fr"})
+	cache.HandleEvent(StasisEvent{Type: "Dial", Peer: &Channel{Id: "c2", State: "Ringing"}})
+
+	if ch, ok := cache.Get("c1"); !ok || ch.State != "Up" {
+		t.Errorf("c1 = %+v, %v, want its last state Up", ch, ok)
+	}
+	if v, ok := cache.Variable("c1", "LANG"); !ok || v != "fr" {
+		t.Errorf("LANG = %q, %v, want fr", v, ok)
+	}
+	if ch, ok := cache.Get("c2"); !ok || ch.State != "Ringing" {
+		t.Errorf("peer c2 = %+v, %v, want it cached", ch, ok)
+	}
+	if n := len(cache.List()); n != 2 {
+		t.Errorf("%d channels listed, want 2", n)
+	}
+
+	cache.HandleEvent(StasisEvent{Type: "ChannelDestroyed", Channel: Channel{Id: "c1"}})
+	// A late event does not bring the destroyed channel back.
+	cache.HandleEvent(StasisEvent{Type: "StasisEnd", Channel: Channel{Id: "c1"}})
+	if _, ok := cache.Get("c1"); ok {
+		t.Error("destroyed channel still cached")
+	}
+	if _, ok := cache.Variable("c1", "LANG"); ok {
+		t.Error("variable of a destroyed channel still cached")
+	}
+	if !cache.Destroyed("c1") {
+		t.Error("c1 not reported destroyed")
+	}
+
+	// The tombstone is dropped once a later destruction finds it older than the TTL.
+	clock.Advance(channelTombstoneTTL + time.Second)
+	cache.HandleEvent(StasisEvent{Type: "ChannelDestroyed", Channel: Channel{Id: "c2"}})
+	if cache.Destroyed("c1") || !cache.Destroyed("c2") {
+		t.Errorf("destroyed c1 = %v, c2 = %v, want false, true", cache.Destroyed("c1"), cache.Destroyed("c2"))
+	}
+}
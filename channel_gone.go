@@ -0,0 +1,53 @@
+package asterisk_ari_go
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// channelOperationId returns the ID of the channel a request operates on, "" for requests that
+// do not operate on an existing channel, e.g. listing channels or originating one with an ID.
+func (c *APIClient) channelOperationId(req *http.Request) string {
+	parts := strings.Split(strings.Trim(c.apiPath(req), "/"), "/")
+	if len(parts) < 2 || parts[0] != "channels" {
+		return ""
+	}
+	if len(parts) == 2 && req.Method == http.MethodPost {
+		return ""
+	}
+	return parts[1]
+}
+
+// channelGone fails requests on channels the ChannelCache of the configuration saw destroyed,
+// sparing the round trip to a certain 404.
+func (c *APIClient) channelGone(req *http.Request) error {
+	cache := c.cfg.ChannelCache
+	if cache == nil {
+		return nil
+	}
+	if id := c.channelOperationId(req); id != "" && cache.Destroyed(id) {
+		c.metrics().IncCounter("ari_requests_channel_gone_total", map[string]string{"stage": "before"}, 1)
+		return ErrChannelGone
+	}
+	return nil
+}
+
+// channelGoneResponse turns the 404 of a request on a channel destroyed while the request was in
+// flight into ErrChannelGone. The response is returned with its body intact.
+func (c *APIClient) channelGoneResponse(req *http.Request, resp *http.Response) error {
+	cache := c.cfg.ChannelCache
+	if cache == nil || resp == nil || resp.StatusCode != http.StatusNotFound {
+		return nil
+	}
+	id := c.channelOperationId(req)
+	if id == "" || !cache.Destroyed(id) {
+		return nil
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	c.metrics().IncCounter("ari_requests_channel_gone_total", map[string]string{"stage": "after"}, 1)
+	return ErrChannelGone
+}
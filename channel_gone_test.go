@@ -0,0 +1,173 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+)
+
+// goneClient returns a client with a ChannelCache, talking to a server answering with handler.
+func goneClient(t *testing.T, handler http.HandlerFunc) (*APIClient, *ChannelCache) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	u, _ := url.Parse(server.URL)
+	cfg := NewConfiguration("/")
+	cfg.Host, cfg.Scheme = u.Host, u.Scheme
+	cfg.ChannelCache = NewChannelCache()
+	return NewAPIClient(cfg, NewStdLogger(ioutil.Discard)), cfg.ChannelCache
+}
+
+func channelEvent(typ string, id string) StasisEvent {
+	return StasisEvent{Type: typ, Channel: Channel{Id: id}, Cause: 16, CauseTxt: "Normal Clearing"}
+}
+
+func TestChannelGoneBeforeRequest(t *testing.T) {
+	var requests int32
+	client, cache := goneClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	cache.HandleEvent(channelEvent("StasisStart", "c1"))
+	cache.HandleEvent(channelEvent("ChannelDestroyed", "c1"))
+
+	for name, call := range map[string]func() error{
+		"answer": func() error { _, err := client.ChannelsApi.Answer(context.Background(), "c1"); return err },
+		"hangup": func() error { _, err := client.ChannelsApi.Hangup(context.Background(), "c1", nil); return err },
+		"play": func() error {
+			_, _, err := client.ChannelsApi.Playsound(context.Background(), "c1", []string{"sound:beep"}, nil)
+			return err
+		},
+		"get": func() error { _, _, err := client.ChannelsApi.Getchannel(context.Background(), "c1"); return err },
+	} {
+		if err := call(); !errors.Is(err, ErrChannelGone) {
+			t.Errorf("%s: err = %v, want ErrChannelGone", name, err)
+		}
+	}
+	if n := atomic.LoadInt32(&requests); n != 0 {
+		t.Errorf("%d requests sent for a destroyed channel, want 0", n)
+	}
+}
+
+func TestChannelGoneLiveChannel(t *testing.T) {
+	var requests int32
+	client, cache := goneClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	cache.HandleEvent(channelEvent("StasisStart", "c1"))
+	cache.HandleEvent(channelEvent("ChannelDestroyed", "c2"))
+
+	if _, err := client.ChannelsApi.Answer(context.Background(), "c1"); err != nil {
+		t.Fatalf("answer: %v", err)
+	}
+	// Originating with the ID of a destroyed channel is not an operation on it.
+	client.ChannelsApi.OriginateWithId(context.Background(), "c2", "PJSIP/alice", nil)
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("%d requests sent, want 2", n)
+	}
+}
+
+// TestChannelGoneRace covers the hangup racing with a request: the channel is destroyed while the
+// request is in flight and Asterisk answers 404.
+func TestChannelGoneRace(t *testing.T) {
+	var cache *ChannelCache
+	client, cache := goneClient(t, func(w http.ResponseWriter, r *http.Request) {
+		cache.HandleEvent(channelEvent("ChannelDestroyed", "c1"))
+		http.Error(w, `{"message":"Channel not found"}`, http.StatusNotFound)
+	})
+	cache.HandleEvent(channelEvent("StasisStart", "c1"))
+
+	resp, err := client.ChannelsApi.Answer(context.Background(), "c1")
+	if !errors.Is(err, ErrChannelGone) {
+		t.Fatalf("err = %v, want ErrChannelGone", err)
+	}
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("resp = %v, want the 404", resp)
+	}
+}
+
+// TestChannelGoneUnknown404 checks that a 404 for a channel not seen destroyed, e.g. a wrong ID,
+// stays a plain REST error.
+func TestChannelGoneUnknown404(t *testing.T) {
+	client, _ := goneClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Channel not found"}`, http.StatusNotFound)
+	})
+	_, err := client.ChannelsApi.Answer(context.Background(), "nope")
+	if err == nil || errors.Is(err, ErrChannelGone) {
+		t.Fatalf("err = %v, want a REST error", err)
+	}
+	if _, ok := err.(GenericSwaggerError); !ok {
+		t.Fatalf("err = %T, want GenericSwaggerError", err)
+	}
+}
+
+// TestChannelGoneHangupHandler checks that the hangup handlers run once whether the hangup is
+// revealed by an operation or by the events, in either order.
+func TestChannelGoneHangupHandler(t *testing.T) {
+	for _, operationFirst := range []bool{true, false} {
+		var cache *ChannelCache
+		client, cache := goneClient(t, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"message":"Channel not found"}`, http.StatusNotFound)
+		})
+		registry := NewCallRegistry(client)
+		feed := func(ev StasisEvent) {
+			cache.HandleEvent(ev)
+			registry.HandleEvent(ev)
+		}
+		feed(channelEvent("StasisStart", "c1"))
+		h, _ := registry.Get("c1")
+		var calls []HangupInfo
+		h.OnHangup(func(info HangupInfo) { calls = append(calls, info) })
+
+		if operationFirst {
+			if err := h.Answer(context.Background()); err == nil {
+				t.Fatal("answer of a hung up channel succeeded")
+			}
+			feed(channelEvent("ChannelHangupRequest", "c1"))
+			feed(channelEvent("StasisEnd", "c1"))
+			feed(channelEvent("ChannelDestroyed", "c1"))
+		} else {
+			feed(channelEvent("ChannelHangupRequest", "c1"))
+			feed(channelEvent("StasisEnd", "c1"))
+			feed(channelEvent("ChannelDestroyed", "c1"))
+			if err := h.Answer(context.Background()); !errors.Is(err, ErrChannelGone) {
+				t.Fatalf("answer: err = %v, want ErrChannelGone", err)
+			}
+		}
+		if len(calls) != 1 {
+			t.Fatalf("operationFirst=%v: hangup handler called %d times, want 1", operationFirst, len(calls))
+		}
+		want := "ChannelHangupRequest"
+		if operationFirst {
+			want = ""
+		}
+		if calls[0].Event != want {
+			t.Errorf("operationFirst=%v: hangup revealed by %q, want %q", operationFirst, calls[0].Event, want)
+		}
+
+		late := 0
+		h.OnHangup(func(HangupInfo) { late++ })
+		if late != 1 {
+			t.Errorf("handler registered after the hangup called %d times, want 1", late)
+		}
+	}
+}
+
+func TestChannelGoneSoftHangup(t *testing.T) {
+	client, _ := goneClient(t, func(w http.ResponseWriter, r *http.Request) {})
+	registry := NewCallRegistry(client)
+	registry.HandleEvent(channelEvent("StasisStart", "c1"))
+	h, _ := registry.Get("c1")
+	soft := channelEvent("ChannelHangupRequest", "c1")
+	soft.Soft = true
+	registry.HandleEvent(soft)
+	if h.HungUp() {
+		t.Fatal("soft hangup request counted as a hangup")
+	}
+}
@@ -0,0 +1,459 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/antihax/optional"
+)
+
+// CallRecord summarizes a finished call.
+type CallRecord struct {
+	ChannelId    string                 `json:"channel_id"`
+	Name         string                 `json:"name"`
+	CallerNumber string                 `json:"caller_number,omitempty"`
+	Dialed       string                 `json:"dialed,omitempty"`
+	Start        time.Time              `json:"start"`
+	Answer       time.Time              `json:"answer,omitempty"`
+	End          time.Time              `json:"end"`
+	Cause        int32                  `json:"cause,omitempty"`
+	CauseTxt     string                 `json:"cause_txt,omitempty"`
+	Data         map[string]interface{} `json:"data,omitempty"`
+	// Talk are the talk-time analytics of the call, when the registry has TalkAnalytics.
+	Talk *TalkStats `json:"talk,omitempty"`
+}
+
+// Duration is the time between the start and the end of the call.
+func (r CallRecord) Duration() time.Duration {
+	return r.End.Sub(r.Start)
+}
+
+// ChannelHandle is the handle of a call in the application. It carries a thread-safe data bag
+// scoped to the call, so that the handlers of a call share state without global maps keyed by
+// channel ID; the data is included in the CallRecord of the call.
+type ChannelHandle struct {
+	client *APIClient
+	id     string
+	store  StateStore
+
+	mu      sync.RWMutex
+	channel Channel
+	data    map[string]interface{}
+	state   string
+	start   time.Time
+	answer  time.Time
+	headers map[string]string
+	tech    ChannelTech
+	// hangup is set once the call hung up; onHangup are the handlers still to call.
+	hangup   *HangupInfo
+	onHangup []func(HangupInfo)
+}
+
+// HangupInfo tells the OnHangup handlers how the call ended.
+type HangupInfo struct {
+	ChannelId string `json:"channel_id"`
+	// Event is the event that revealed the hangup, "ChannelHangupRequest", "StasisEnd" or
+	// "ChannelDestroyed"; empty when a REST call of the handle found the channel gone.
+	Event    string    `json:"event,omitempty"`
+	Cause    int32     `json:"cause,omitempty"`
+	CauseTxt string    `json:"cause_txt,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// callContext is the persisted form of a ChannelHandle.
+type callContext struct {
+	State string                 `json:"state,omitempty"`
+	Data  map[string]interface{} `json:"data,omitempty"`
+	Start time.Time              `json:"start"`
+}
+
+// callContextKey is the StateStore key of the context of channelId.
+func callContextKey(channelId string) string {
+	return "call:" + channelId
+}
+
+// Id returns the channel ID.
+func (h *ChannelHandle) Id() string {
+	return h.id
+}
+
+// Channel returns the last known snapshot of the channel.
+func (h *ChannelHandle) Channel() Channel {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.channel
+}
+
+// Set stores value under key in the data bag.
+func (h *ChannelHandle) Set(key string, value interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.data[key] = value
+}
+
+// Get returns the value stored under key.
+func (h *ChannelHandle) Get(key string) (interface{}, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	v, ok := h.data[key]
+	return v, ok
+}
+
+// Delete removes key from the data bag.
+func (h *ChannelHandle) Delete(key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.data, key)
+}
+
+// Data returns a copy of the data bag.
+func (h *ChannelHandle) Data() map[string]interface{} {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	data := make(map[string]interface{}, len(h.data))
+	for k, v := range h.data {
+		data[k] = v
+	}
+	return data
+}
+
+// State returns the position of the call in the application flow, e.g. the current IVR menu.
+func (h *ChannelHandle) State() string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.state
+}
+
+// SetState records the position of the call in the application flow.
+func (h *ChannelHandle) SetState(state string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.state = state
+}
+
+// Persist saves the data bag and the state of the call to the StateStore of the registry, so that
+// an instance adopting the channel after a restart can restore them. Values must be JSON
+// serializable and are restored as JSON types: numbers become float64, structs become maps.
+// It is a no-op when the registry has no store.
+func (h *ChannelHandle) Persist(ctx context.Context) error {
+	if h.store == nil {
+		return nil
+	}
+	h.mu.RLock()
+	data, err := json.Marshal(callContext{State: h.state, Data: h.data, Start: h.start})
+	h.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	return h.store.Save(ctx, callContextKey(h.id), data)
+}
+
+// restore loads the persisted context of the call, reporting whether there was one.
+func (h *ChannelHandle) restore(ctx context.Context) (bool, error) {
+	data, err := h.store.Load(ctx, callContextKey(h.id))
+	if errors.Is(err, ErrStateNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	var saved callContext
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return false, err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.state = saved.State
+	for k, v := range saved.Data {
+		h.data[k] = v
+	}
+	if !saved.Start.IsZero() {
+		h.start = saved.Start
+	}
+	return true, nil
+}
+
+// Value returns the value stored under key in the data bag of h if it has type T.
+func Value[T any](h *ChannelHandle, key string) (T, bool) {
+	v, ok := h.Get(key)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	t, ok := v.(T)
+	return t, ok
+}
+
+// ValueOr returns the value stored under key in the data bag of h, or def if it is missing or
+// not of type T.
+func ValueOr[T any](h *ChannelHandle, key string, def T) T {
+	if v, ok := Value[T](h, key); ok {
+		return v
+	}
+	return def
+}
+
+// OnHangup registers f to be called when the call hangs up or leaves the application. f is
+// called exactly once, from whichever of ChannelHangupRequest, StasisEnd and ChannelDestroyed
+// comes first, or when an operation of the handle finds the channel gone, so it is the place
+// for cleanup. Registered after the hangup, f is called right away. Handlers run in the order
+// they were registered, on the goroutine feeding the registry; a panic in one is logged and does
+// not prevent the others.
+func (h *ChannelHandle) OnHangup(f func(HangupInfo)) {
+	h.mu.Lock()
+	if h.hangup == nil {
+		h.onHangup = append(h.onHangup, f)
+		h.mu.Unlock()
+		return
+	}
+	info := *h.hangup
+	h.mu.Unlock()
+	h.runHangupHandler(f, info)
+}
+
+// HungUp reports whether the call hung up or left the application.
+func (h *ChannelHandle) HungUp() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.hangup != nil
+}
+
+// hungUp records the hangup of the call; the first time, it calls the OnHangup handlers.
+func (h *ChannelHandle) hungUp(info HangupInfo) {
+	h.mu.Lock()
+	if h.hangup != nil {
+		h.mu.Unlock()
+		return
+	}
+	h.hangup = &info
+	handlers := h.onHangup
+	h.onHangup = nil
+	h.mu.Unlock()
+	for _, f := range handlers {
+		h.runHangupHandler(f, info)
+	}
+}
+
+func (h *ChannelHandle) runHangupHandler(f func(HangupInfo), info HangupInfo) {
+	defer func() {
+		if p := recover(); p != nil {
+			h.client.logger.Errorf("channel %s: hangup handler panicked: %v", h.id, p)
+		}
+	}()
+	f(info)
+}
+
+// checkGone treats a 404 or ErrChannelGone on an operation of the channel as its hangup, which
+// may have raced with the operation before its events were received.
+func (h *ChannelHandle) checkGone(resp *http.Response, err error) {
+	if errors.Is(err, ErrChannelGone) || (resp != nil && resp.StatusCode == http.StatusNotFound) {
+		h.hungUp(HangupInfo{ChannelId: h.id, Time: h.client.clock().Now()})
+	}
+}
+
+// Answer answers the channel.
+func (h *ChannelHandle) Answer(ctx context.Context) error {
+	resp, err := h.client.ChannelsApi.Answer(ctx, h.id)
+	h.checkGone(resp, err)
+	return err
+}
+
+// Hangup hangs the channel up with reason, e.g. "normal" or "busy". An empty reason uses "normal".
+func (h *ChannelHandle) Hangup(ctx context.Context, reason string) error {
+	var opts *ChannelsApiHangupOpts
+	if reason != "" {
+		opts = &ChannelsApiHangupOpts{Reason: optional.NewString(reason)}
+	}
+	resp, err := h.client.ChannelsApi.Hangup(ctx, h.id, opts)
+	h.checkGone(resp, err)
+	return err
+}
+
+// SetVar sets a channel variable.
+func (h *ChannelHandle) SetVar(ctx context.Context, name string, value string) error {
+	resp, err := h.client.ChannelsApi.SetChannelVar(ctx, h.id, name, &ChannelsApiSetChannelVarOpts{Value: optional.NewString(value)})
+	h.checkGone(resp, err)
+	return err
+}
+
+// GetVar reads a channel variable or dialplan function.
+func (h *ChannelHandle) GetVar(ctx context.Context, name string) (string, error) {
+	v, _, err := h.client.ChannelsApi.GetChannelVar(ctx, h.id, name)
+	return v.Value, err
+}
+
+// update records the channel snapshot carried by ev, received at.
+func (h *ChannelHandle) update(ev StasisEvent, at time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if ev.Channel.Id == h.id {
+		h.channel = ev.Channel
+		if ev.Channel.State == "Up" && h.answer.IsZero() {
+			h.answer = at
+		}
+	}
+}
+
+// record builds the CallRecord of the call ended by ev at.
+func (h *ChannelHandle) record(ev StasisEvent, at time.Time) CallRecord {
+	h.update(ev, at)
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	r := CallRecord{
+		ChannelId: h.id,
+		Name:      h.channel.Name,
+		Start:     h.start,
+		Answer:    h.answer,
+		End:       at,
+		Cause:     ev.Cause,
+		CauseTxt:  ev.CauseTxt,
+		Data:      make(map[string]interface{}, len(h.data)),
+	}
+	if h.channel.Caller != nil {
+		r.CallerNumber = h.channel.Caller.Number
+	}
+	if h.channel.Dialplan != nil {
+		r.Dialed = h.channel.Dialplan.Exten
+	}
+	for k, v := range h.data {
+		r.Data[k] = v
+	}
+	return r
+}
+
+// CallRegistry creates a ChannelHandle for every channel entering the application and drops it
+// when the channel is destroyed, emitting its CallRecord. Every event must be fed to HandleEvent.
+type CallRegistry struct {
+	client *APIClient
+
+	// OnStart is called with the handle of every new call.
+	OnStart func(h *ChannelHandle)
+	// OnEnd is called with the record of every finished call.
+	OnEnd func(r CallRecord)
+	// Store, if set, persists the context of calls saved with ChannelHandle.Persist. When a channel
+	// enters the application with a persisted context, e.g. after a restart, the context is
+	// restored before OnStart; it is deleted when the channel is destroyed.
+	Store StateStore
+	// Clock, if set, dates the calls with the corrected Asterisk event timestamps rather than the
+	// local receive time, so that durations are not inflated by event delivery delays.
+	Clock *ClockSkew
+	// Talk, if set, is fed the events by the registry, and the TalkStats of every call are
+	// attached to its CallRecord.
+	Talk *TalkAnalytics
+
+	mu      sync.RWMutex
+	handles map[string]*ChannelHandle
+}
+
+// NewCallRegistry creates an empty registry.
+func NewCallRegistry(client *APIClient) *CallRegistry {
+	return &CallRegistry{client: client, handles: make(map[string]*ChannelHandle)}
+}
+
+// HandleEvent feeds an event received from Asterisk into the registry.
+func (r *CallRegistry) HandleEvent(ev StasisEvent) {
+	if r.Talk != nil {
+		r.Talk.HandleEvent(ev)
+	}
+	id := ev.Channel.Id
+	if id == "" {
+		return
+	}
+	switch ev.Type {
+	case "StasisStart":
+		h, created := r.handle(id, r.now(ev))
+		h.update(ev, r.now(ev))
+		if created && r.Store != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			restored, err := h.restore(ctx)
+			cancel()
+			if err != nil {
+				r.client.logger.Warnf("call registry: restoring context of channel %s: %v", id, err)
+			} else if restored {
+				r.client.logger.Infof("call registry: restored context of channel %s in state %q", id, h.State())
+			}
+		}
+		if created && r.OnStart != nil {
+			r.OnStart(h)
+		}
+	case "ChannelHangupRequest", "StasisEnd":
+		h, ok := r.Get(id)
+		if !ok || ev.Soft {
+			return
+		}
+		h.update(ev, r.now(ev))
+		h.hungUp(HangupInfo{ChannelId: id, Event: ev.Type, Cause: ev.Cause, CauseTxt: ev.CauseTxt, Time: r.now(ev)})
+	case "ChannelDestroyed":
+		r.mu.Lock()
+		h, ok := r.handles[id]
+		delete(r.handles, id)
+		r.mu.Unlock()
+		if !ok {
+			return
+		}
+		r.client.TrackResource(ResourceChannel, "call_registry", -1)
+		h.hungUp(HangupInfo{ChannelId: id, Event: ev.Type, Cause: ev.Cause, CauseTxt: ev.CauseTxt, Time: r.now(ev)})
+		if r.Store != nil {
+			if err := r.Store.Delete(context.Background(), callContextKey(id)); err != nil {
+				r.client.logger.Warnf("call registry: deleting context of channel %s: %v", id, err)
+			}
+		}
+		if r.OnEnd != nil {
+			record := h.record(ev, r.now(ev))
+			if r.Talk != nil {
+				if stats, ok := r.Talk.Stats(id); ok {
+					record.Talk = &stats
+				}
+			}
+			r.OnEnd(record)
+		}
+	default:
+		if h, ok := r.Get(id); ok {
+			h.update(ev, r.now(ev))
+		}
+	}
+}
+
+// now returns the time of ev.
+func (r *CallRegistry) now(ev StasisEvent) time.Time {
+	if r.Clock != nil {
+		return r.Clock.LocalTime(ev)
+	}
+	return time.Now()
+}
+
+// Get returns the handle of a call in progress.
+func (r *CallRegistry) Get(channelId string) (*ChannelHandle, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	h, ok := r.handles[channelId]
+	return h, ok
+}
+
+// Handles returns the handles of the calls in progress.
+func (r *CallRegistry) Handles() []*ChannelHandle {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	handles := make([]*ChannelHandle, 0, len(r.handles))
+	for _, h := range r.handles {
+		handles = append(handles, h)
+	}
+	return handles
+}
+
+// handle returns the handle of channelId, creating it if needed.
+func (r *CallRegistry) handle(channelId string, start time.Time) (*ChannelHandle, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if h, ok := r.handles[channelId]; ok {
+		return h, false
+	}
+	h := &ChannelHandle{client: r.client, id: channelId, store: r.Store, data: make(map[string]interface{}), start: start}
+	r.handles[channelId] = h
+	r.client.TrackResource(ResourceChannel, "call_registry", 1)
+	return h, true
+}
@@ -0,0 +1,144 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/antihax/optional"
+)
+
+// Channel technologies reported by ChannelTech.Name.
+const (
+	TechPJSIP   = "PJSIP"
+	TechWebRTC  = "WebRTC"
+	TechLocal   = "Local"
+	TechDAHDI   = "DAHDI"
+	TechUnknown = "Unknown"
+)
+
+// ChannelTech is the technology of a channel. Capabilities that depend on the technology are
+// exposed through the optional HeaderReader and Transferrer interfaces, so that call flows test
+// for a capability instead of a technology:
+//
+//	if t, ok := tech.(Transferrer); ok {
+//		err = t.Transfer(ctx, "PJSIP/2000")
+//	}
+type ChannelTech interface {
+	// Name is one of the Tech* constants.
+	Name() string
+}
+
+// HeaderReader is implemented by technologies carrying signalling headers.
+type HeaderReader interface {
+	ChannelTech
+	// Header returns a header of the message that created the channel, "" if absent.
+	Header(ctx context.Context, name string) (string, error)
+}
+
+// Transferrer is implemented by technologies able to transfer a channel out of the application.
+type Transferrer interface {
+	ChannelTech
+	// Transfer sends the channel to target, whose format depends on the technology.
+	Transfer(ctx context.Context, target string) error
+}
+
+// pjsipTech is a PJSIP channel. Headers come from the INVITE; transfers are redirects, i.e. a
+// 302 before the answer and a REFER after.
+type pjsipTech struct {
+	h *ChannelHandle
+}
+
+func (t pjsipTech) Name() string { return TechPJSIP }
+
+func (t pjsipTech) Header(ctx context.Context, name string) (string, error) {
+	return t.h.SIPHeader(ctx, name)
+}
+
+// Transfer redirects the channel to target, an endpoint such as "PJSIP/2000".
+func (t pjsipTech) Transfer(ctx context.Context, target string) error {
+	_, err := t.h.client.ChannelsApi.Redirect(ctx, t.h.id, target)
+	return err
+}
+
+// webrtcTech is a PJSIP channel of an endpoint with webrtc=yes.
+type webrtcTech struct {
+	pjsipTech
+}
+
+func (t webrtcTech) Name() string { return TechWebRTC }
+
+// localTech is a Local channel. Transfers continue in the dialplan.
+type localTech struct {
+	h *ChannelHandle
+}
+
+func (t localTech) Name() string { return TechLocal }
+
+// Transfer continues the channel in the dialplan at target, given as "exten@context".
+func (t localTech) Transfer(ctx context.Context, target string) error {
+	i := strings.LastIndex(target, "@")
+	if i <= 0 || i == len(target)-1 {
+		return fmt.Errorf("local transfer target %q is not exten@context", target)
+	}
+	_, err := t.h.client.ChannelsApi.ContinueInDialplan(ctx, t.h.id, &ChannelsApiContinueInDialplanOpts{
+		Context:   optional.NewString(target[i+1:]),
+		Extension: optional.NewString(target[:i]),
+		Priority:  optional.NewInt32(1),
+	})
+	return err
+}
+
+// namedTech is a technology without capabilities.
+type namedTech string
+
+func (t namedTech) Name() string { return string(t) }
+
+// Tech returns the technology of the channel, determined from its name. Telling WebRTC from other
+// PJSIP endpoints costs a request, made once per call.
+func (h *ChannelHandle) Tech(ctx context.Context) (ChannelTech, error) {
+	h.mu.RLock()
+	tech, name := h.tech, h.channel.Name
+	h.mu.RUnlock()
+	if tech != nil {
+		return tech, nil
+	}
+
+	switch {
+	case strings.HasPrefix(name, "PJSIP/"):
+		endpoint, err := h.GetVar(ctx, "CHANNEL(endpoint)")
+		if err != nil {
+			return nil, err
+		}
+		webrtc, err := h.GetVar(ctx, "PJSIP_ENDPOINT("+endpoint+",webrtc)")
+		if err != nil {
+			return nil, err
+		}
+		if isTrue(webrtc) {
+			tech = webrtcTech{pjsipTech{h}}
+		} else {
+			tech = pjsipTech{h}
+		}
+	case strings.HasPrefix(name, "Local/"):
+		tech = localTech{h}
+	case strings.HasPrefix(name, "DAHDI/"):
+		tech = namedTech(TechDAHDI)
+	case name == "":
+		return nil, fmt.Errorf("technology of channel %s unknown until its snapshot is received", h.id)
+	default:
+		tech = namedTech(TechUnknown)
+	}
+	h.mu.Lock()
+	h.tech = tech
+	h.mu.Unlock()
+	return tech, nil
+}
+
+// isTrue interprets an Asterisk boolean option value.
+func isTrue(v string) bool {
+	switch strings.ToLower(v) {
+	case "yes", "true", "y", "t", "1", "on":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestChannelTech(t *testing.T) {
+	var requests int32
+	var continued string
+	client, _ := goneClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("variable") {
+		case "CHANNEL(endpoint)":
+			w.Write([]byte(`{"value":"browser"}`))
+		case "PJSIP_ENDPOINT(browser,webrtc)":
+			w.Write([]byte(`{"value":"yes"}`))
+		default:
+			q := r.URL.Query()
+			continued = q.Get("extension") + "@" + q.Get("context")
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	ctx := context.Background()
+	handle := func(name string) *ChannelHandle {
+		return &ChannelHandle{client: client, id: "c1", channel: Channel{Id: "c1", Name: name}}
+	}
+
+	h := handle("PJSIP/browser-00000001")
+	for i := 0; i < 2; i++ {
+		tech, err := h.Tech(ctx)
+		if err != nil || tech.Name() != TechWebRTC {
+			t.Fatalf("tech = %v, %v, want WebRTC", tech, err)
+		}
+		if _, ok := tech.(HeaderReader); !ok {
+			t.Error("WebRTC channel is not a HeaderReader")
+		}
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("%d requests, want 2 made once", n)
+	}
+
+	tech, err := handle("Local/100@default-00000002;1").Tech(ctx)
+	if err != nil || tech.Name() != TechLocal {
+		t.Fatalf("tech = %v, %v, want Local", tech, err)
+	}
+	if _, ok := tech.(HeaderReader); ok {
+		t.Error("Local channel is a HeaderReader")
+	}
+	transfer := tech.(Transferrer)
+	if err := transfer.Transfer(ctx, "200"); err == nil {
+		t.Error("transfer to a target without a context succeeded")
+	}
+	if err := transfer.Transfer(ctx, "200@sales"); err != nil || continued != "200@sales" {
+		t.Errorf("continued to %q, %v, want 200@sales", continued, err)
+	}
+
+	for name, want := range map[string]string{"DAHDI/1-1": TechDAHDI, "IAX2/peer-1": TechUnknown} {
+		if tech, err := handle(name).Tech(ctx); err != nil || tech.Name() != want {
+			t.Errorf("tech of %s = %v, %v, want %s", name, tech, err, want)
+		}
+	}
+	if _, err := handle("").Tech(ctx); err == nil {
+		t.Error("tech of a channel without snapshot is known")
+	}
+}
@@ -0,0 +1,96 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/antihax/optional"
+)
+
+// VarsError reports the variables that could not be set or read by SetVars or GetVars; the others
+// were.
+type VarsError struct {
+	ChannelId string
+	// Errs holds the error of every failed variable by name.
+	Errs map[string]error
+}
+
+// names returns the names of the failed variables, sorted.
+func (e *VarsError) names() []string {
+	names := make([]string, 0, len(e.Errs))
+	for name := range e.Errs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (e *VarsError) Error() string {
+	names := e.names()
+	return fmt.Sprintf("channel %s: %d variables failed %v, first: %s: %v", e.ChannelId, len(names), names, names[0], e.Errs[names[0]])
+}
+
+// Unwrap returns the error of the first failed variable by name, so that errors.Is matches it,
+// e.g. ErrChannelGone.
+func (e *VarsError) Unwrap() error {
+	return e.Errs[e.names()[0]]
+}
+
+// varsError converts the error of FanOut over names into a *VarsError.
+func varsError(channelId string, names []string, err error) error {
+	failed, ok := err.(*FanOutError)
+	if !ok {
+		return err
+	}
+	e := &VarsError{ChannelId: channelId, Errs: make(map[string]error, len(failed.Errs))}
+	for i, err := range failed.Errs {
+		e.Errs[names[i]] = err
+	}
+	return e
+}
+
+// SetVars sets the variables of a channel, one request per variable as ARI requires, sent
+// concurrently with FanOut. The variables are set in no particular order; use SetChannelVar in
+// sequence for variables depending on each other. If some fail, the others are still set and a
+// *VarsError lists the failures.
+func (a *ChannelsApiService) SetVars(ctx context.Context, channelId string, vars map[string]string, opts *FanOutOpts) error {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	_, err := FanOut(ctx, a.client, names, opts, func(ctx context.Context, name string) (struct{}, error) {
+		_, err := a.SetChannelVar(ctx, channelId, name, &ChannelsApiSetChannelVarOpts{Value: optional.NewString(vars[name])})
+		return struct{}{}, err
+	})
+	return varsError(channelId, names, err)
+}
+
+// GetVars reads the variables or dialplan functions names of a channel concurrently with FanOut.
+// The values are returned in the order of names. If some fail, their value is empty, the others
+// are returned and a *VarsError lists the failures.
+func (a *ChannelsApiService) GetVars(ctx context.Context, channelId string, names []string, opts *FanOutOpts) ([]string, error) {
+	results, err := FanOut(ctx, a.client, names, opts, func(ctx context.Context, name string) (string, error) {
+		v, _, err := a.GetChannelVar(ctx, channelId, name)
+		return v.Value, err
+	})
+	values := make([]string, len(results))
+	for i, r := range results {
+		values[i] = r.Value
+	}
+	return values, varsError(channelId, names, err)
+}
+
+// SetVars sets channel variables concurrently, see ChannelsApiService.SetVars.
+func (h *ChannelHandle) SetVars(ctx context.Context, vars map[string]string) error {
+	err := h.client.ChannelsApi.SetVars(ctx, h.id, vars, nil)
+	h.checkGone(nil, err)
+	return err
+}
+
+// GetVars reads channel variables or dialplan functions concurrently, see
+// ChannelsApiService.GetVars.
+func (h *ChannelHandle) GetVars(ctx context.Context, names ...string) ([]string, error) {
+	return h.client.ChannelsApi.GetVars(ctx, h.id, names, nil)
+}
@@ -0,0 +1,53 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestChannelVars(t *testing.T) {
+	var mu sync.Mutex
+	vars := map[string]string{"LANG": "fr"}
+	client, _ := goneClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		name := r.URL.Query().Get("variable")
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case name == "BROKEN":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"message":"broken"}`))
+		case r.Method == http.MethodPost:
+			vars[name] = r.URL.Query().Get("value")
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Write([]byte(`{"value":"` + vars[name] + `"}`))
+		}
+	})
+	ctx := context.Background()
+
+	err := client.ChannelsApi.SetVars(ctx, "c1", map[string]string{"A": "1", "B": "2", "BROKEN": "x"}, nil)
+	var failed *VarsError
+	if !errors.As(err, &failed) || failed.ChannelId != "c1" || len(failed.Errs) != 1 || failed.Errs["BROKEN"] == nil {
+		t.Fatalf("err = %v, want a *VarsError for BROKEN only", err)
+	}
+	if vars["A"] != "1" || vars["B"] != "2" {
+		t.Errorf("vars = %v, want A and B set despite the failure", vars)
+	}
+
+	values, err := client.ChannelsApi.GetVars(ctx, "c1", []string{"B", "BROKEN", "LANG"}, nil)
+	if want := []string{"2", "", "fr"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("values = %q, want %q", values, want)
+	}
+	if !errors.As(err, &failed) || len(failed.Errs) != 1 || failed.Errs["BROKEN"] == nil {
+		t.Errorf("err = %v, want a *VarsError for BROKEN only", err)
+	}
+
+	if values, err := client.ChannelsApi.GetVars(ctx, "c1", []string{"A"}, nil); err != nil || values[0] != "1" {
+		t.Errorf("values = %q, err = %v, want [1]", values, err)
+	}
+}
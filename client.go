@@ -16,7 +16,6 @@ import (
 	"encoding/xml"
 	"errors"
 	"fmt"
-	"github.com/sirupsen/logrus"
 	"golang.org/x/oauth2"
 	"io"
 	"mime/multipart"
@@ -42,7 +41,15 @@ var (
 type APIClient struct {
 	cfg    *Configuration
 	common service // Reuse a single struct instead of allocating one for each service on the heap.
-	logger *logrus.Logger
+	logger Logger
+
+	resources resourceAccounting
+	waiters   eventWaiters
+	handlers  eventHandlers
+	activity  clientActivity
+	ensures   bridgeEnsures
+	// originated holds the channels originated by the helpers, see OriginatedByHelpers.
+	originated originatedChannels
 
 	// API Services
 
@@ -69,6 +76,8 @@ type APIClient struct {
 	SoundsApi *SoundsApiService
 
 	WebsocketApi *WebsocketApiService
+
+	Diagnostics *DiagnosticsService
 }
 
 type service struct {
@@ -77,17 +86,18 @@ type service struct {
 
 // NewAPIClient creates a new API client. Requires a userAgent string describing your application.
 // optionally a custom http.Client to allow for advanced features such as caching.
-func NewAPIClient(cfg *Configuration, logger ...*logrus.Logger) *APIClient {
+// logger, e.g. a *logrus.Logger, receives the logs; defaults to a StdLogger writing to stdout.
+func NewAPIClient(cfg *Configuration, logger ...Logger) *APIClient {
 	if cfg.HTTPClient == nil {
 		cfg.HTTPClient = http.DefaultClient
 	}
+	if cfg.Flags == nil {
+		cfg.Flags = &Flags{}
+	}
 
-	var l *logrus.Logger
-	if len(logger) > 0 && logger[0] != nil {
+	l := defaultLogger()
+	if len(logger) > 0 && !isNilLogger(logger[0]) {
 		l = logger[0]
-	} else {
-		l = logrus.New()
-		l.SetOutput(os.Stdout)
 	}
 
 	c := APIClient{
@@ -109,6 +119,7 @@ func NewAPIClient(cfg *Configuration, logger ...*logrus.Logger) *APIClient {
 	c.RecordingsApi = (*RecordingsApiService)(&c.common)
 	c.SoundsApi = (*SoundsApiService)(&c.common)
 	c.WebsocketApi = (*WebsocketApiService)(&c.common)
+	c.Diagnostics = (*DiagnosticsService)(&c.common)
 
 	return &c
 }
@@ -189,7 +200,33 @@ func parameterToString(obj interface{}, collectionFormat string) string {
 
 // callAPI do the request.
 func (c *APIClient) callAPI(request *http.Request) (*http.Response, error) {
-	return c.cfg.HTTPClient.Do(request)
+	c.activity.begin(c.clock().Now())
+	defer func() { c.activity.end(c.clock().Now()) }()
+	if err := c.channelGone(request); err != nil {
+		return nil, err
+	}
+	if !isMutating(request.Method) || (c.cfg.AuditSink == nil && !c.cfg.DryRun) {
+		resp, err := c.send(request)
+		if err == nil {
+			err = c.channelGoneResponse(request, resp)
+		}
+		return resp, err
+	}
+	start := c.clock().Now()
+	var resp *http.Response
+	var err error
+	if c.cfg.DryRun {
+		resp = c.dryRunResponse(request)
+	} else {
+		resp, err = c.send(request)
+	}
+	if c.cfg.AuditSink != nil {
+		c.audit(request, resp, err, start)
+	}
+	if err == nil {
+		err = c.channelGoneResponse(request, resp)
+	}
+	return resp, err
 }
 
 // Change base path to allow switching to mocks
@@ -365,7 +402,7 @@ func (c *APIClient) decode(v interface{}, b []byte, contentType string) (err err
 		}
 		return nil
 	} else if strings.Contains(contentType, "application/json") {
-		if err = json.Unmarshal(b, v); err != nil {
+		if err = decodeJSON(b, v, c.cfg.StrictDecoding); err != nil {
 			return err
 		}
 		return nil
@@ -373,6 +410,19 @@ func (c *APIClient) decode(v interface{}, b []byte, contentType string) (err err
 	return errors.New("undefined response type")
 }
 
+// decodeJSON unmarshals b into v. In strict mode fields not modelled by v are an error.
+func decodeJSON(b []byte, v interface{}, strict bool) error {
+	if !strict {
+		return json.Unmarshal(b, v)
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("strict decoding: %w", err)
+	}
+	return nil
+}
+
 // Add a file to the multipart request
 func addFile(w *multipart.Writer, fieldName, path string) error {
 	file, err := os.Open(path)
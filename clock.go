@@ -0,0 +1,153 @@
+package asterisk_ari_go
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the source of time of the client helpers: DTMF timeouts, pacing, post-call retries,
+// PIN lockouts and schedules. Tests set Configuration.Clock to a FakeClock to run time-dependent
+// logic instantly and deterministically.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a timer created by a Clock.
+type Timer interface {
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, reporting whether it was active.
+	Stop() bool
+	// Reset restarts the timer with d, discarding an expiration that was not received yet.
+	Reset(d time.Duration)
+}
+
+// RealClock is the system clock.
+var RealClock Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) NewTimer(d time.Duration) Timer         { return realTimer{time.NewTimer(d)} }
+
+type realTimer struct {
+	t *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time   { return t.t.C }
+func (t realTimer) Stop() bool            { return t.t.Stop() }
+func (t realTimer) Reset(d time.Duration) { resetTimer(t.t, d) }
+
+// clock returns the configured clock.
+func (c *APIClient) clock() Clock {
+	if c.cfg.Clock != nil {
+		return c.cfg.Clock
+	}
+	return RealClock
+}
+
+// FakeClock is a Clock that only moves when told to. Timers fire when Advance moves the clock past
+// their deadline.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// NewFakeClock creates a fake clock set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the time of the fake clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel receiving the time once the clock is advanced by d.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// NewTimer creates a timer firing once the clock is advanced by d.
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, ch: make(chan time.Time, 1)}
+	c.scheduleLocked(t, d)
+	return t
+}
+
+// Advance moves the clock forward by d, firing the timers due in order.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	target := c.now.Add(d)
+	for {
+		sort.Slice(c.timers, func(i, j int) bool { return c.timers[i].deadline.Before(c.timers[j].deadline) })
+		if len(c.timers) == 0 || c.timers[0].deadline.After(target) {
+			break
+		}
+		t := c.timers[0]
+		c.timers = c.timers[1:]
+		c.now = t.deadline
+		select {
+		case t.ch <- t.deadline:
+		default:
+		}
+	}
+	c.now = target
+}
+
+// Timers returns the number of pending timers, to know when the code under test is waiting.
+func (c *FakeClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+func (c *FakeClock) scheduleLocked(t *fakeTimer, d time.Duration) {
+	t.deadline = c.now.Add(d)
+	c.timers = append(c.timers, t)
+}
+
+// unscheduleLocked removes t, reporting whether it was pending.
+func (c *FakeClock) unscheduleLocked(t *fakeTimer) bool {
+	for i, pending := range c.timers {
+		if pending == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTimer struct {
+	clock    *FakeClock
+	ch       chan time.Time
+	deadline time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.ch }
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.unscheduleLocked(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.clock.unscheduleLocked(t)
+	select {
+	case <-t.ch:
+	default:
+	}
+	t.clock.scheduleLocked(t, d)
+}
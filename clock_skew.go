@@ -0,0 +1,98 @@
+package asterisk_ari_go
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultClockSkewThreshold is the skew above which ClockSkew warns by default.
+const DefaultClockSkewThreshold = 2 * time.Second
+
+// clockSkewWindow is the number of recent samples the offset is estimated from.
+const clockSkewWindow = 64
+
+// ClockSkew estimates the offset between the Asterisk clock, which dates events, and the local
+// clock, for applications running on a different host than Asterisk. Every event must be fed to
+// HandleEvent as soon as it is received.
+//
+// Each event yields a sample of the local receive time minus the event timestamp, i.e. the skew
+// plus the delivery delay. The offset is the smallest sample of the recent ones, the one least
+// inflated by delivery delays.
+type ClockSkew struct {
+	client *APIClient
+
+	// Threshold above which a warning is logged, once per excursion. Defaults to
+	// DefaultClockSkewThreshold.
+	Threshold time.Duration
+
+	mu      sync.Mutex
+	samples [clockSkewWindow]time.Duration
+	n       int
+	next    int
+	warned  bool
+}
+
+// NewClockSkew creates an estimator without samples; the offset is 0 until events are received.
+func NewClockSkew(client *APIClient) *ClockSkew {
+	return &ClockSkew{client: client, Threshold: DefaultClockSkewThreshold}
+}
+
+// HandleEvent samples the offset from the timestamp of an event. Events without a timestamp are
+// ignored.
+func (c *ClockSkew) HandleEvent(ev StasisEvent) {
+	if ev.Timestamp.Timestamp.IsZero() {
+		return
+	}
+	sample := c.client.clock().Now().Sub(ev.Timestamp.Timestamp)
+
+	c.mu.Lock()
+	c.samples[c.next] = sample
+	c.next = (c.next + 1) % clockSkewWindow
+	if c.n < clockSkewWindow {
+		c.n++
+	}
+	offset := c.offsetLocked()
+	exceeded := offset > c.Threshold || -offset > c.Threshold
+	warn := exceeded && !c.warned
+	c.warned = exceeded
+	c.mu.Unlock()
+
+	c.client.metrics().SetGauge("ari_clock_skew_seconds", nil, offset.Seconds())
+	if warn {
+		c.client.logger.Warnf("clock skew: local clock is %s ahead of Asterisk, above the %s threshold; check NTP on both hosts", offset, c.Threshold)
+	}
+}
+
+// Offset returns how far the local clock is ahead of the Asterisk clock; negative if behind.
+func (c *ClockSkew) Offset() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.offsetLocked()
+}
+
+func (c *ClockSkew) offsetLocked() time.Duration {
+	if c.n == 0 {
+		return 0
+	}
+	min := c.samples[0]
+	for _, s := range c.samples[1:c.n] {
+		if s < min {
+			min = s
+		}
+	}
+	return min
+}
+
+// Correct converts an Asterisk timestamp to the local clock.
+func (c *ClockSkew) Correct(t time.Time) time.Time {
+	return t.Add(c.Offset())
+}
+
+// LocalTime returns when ev happened on the local clock: its corrected timestamp, or now if it
+// has none. Durations between local times are free of both skew and delivery delays.
+func (c *ClockSkew) LocalTime(ev StasisEvent) time.Time {
+	if ev.Timestamp.Timestamp.IsZero() {
+		return c.client.clock().Now()
+	}
+	return c.Correct(ev.Timestamp.Timestamp)
+}
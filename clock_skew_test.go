@@ -0,0 +1,50 @@
+package asterisk_ari_go
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClockSkew(t *testing.T) {
+	now := time.Unix(1000, 0)
+	var logs bytes.Buffer
+	cfg := NewConfiguration("/")
+	cfg.Clock = NewFakeClock(now)
+	skew := NewClockSkew(NewAPIClient(cfg, NewStdLogger(&logs)))
+	sent := func(ago time.Duration) StasisEvent {
+		return StasisEvent{Type: "ChannelStateChange", Timestamp: StasisTimestampEvent{now.Add(-ago)}}
+	}
+
+	if skew.Offset() != 0 {
+		t.Errorf("offset without samples = %v, want 0", skew.Offset())
+	}
+	// The least delayed sample is the estimate; events without a timestamp are ignored.
+	skew.HandleEvent(sent(3500 * time.Millisecond))
+	skew.HandleEvent(sent(3 * time.Second))
+	skew.HandleEvent(StasisEvent{Type: "ChannelStateChange"})
+	if skew.Offset() != 3*time.Second {
+		t.Errorf("offset = %v, want 3s", skew.Offset())
+	}
+	if got := skew.Correct(now.Add(-3 * time.Second)); !got.Equal(now) {
+		t.Errorf("Correct = %v, want %v", got, now)
+	}
+	if got := skew.LocalTime(StasisEvent{}); !got.Equal(now) {
+		t.Errorf("LocalTime without timestamp = %v, want now", got)
+	}
+
+	// One warning per excursion above the threshold.
+	skew.HandleEvent(sent(4 * time.Second))
+	if n := strings.Count(logs.String(), "clock skew"); n != 1 {
+		t.Errorf("%d warnings, want 1:\n%s", n, logs.String())
+	}
+
+	// The old samples age out of the window.
+	for i := 0; i < clockSkewWindow; i++ {
+		skew.HandleEvent(sent(-time.Second))
+	}
+	if skew.Offset() != -time.Second {
+		t.Errorf("offset = %v, want -1s once the window moved on", skew.Offset())
+	}
+}
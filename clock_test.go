@@ -0,0 +1,51 @@
+package asterisk_ari_go
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Unix(0, 0)
+	clock := NewFakeClock(start)
+	late, early := clock.NewTimer(3*time.Second), clock.NewTimer(time.Second)
+	stopped := clock.NewTimer(2 * time.Second)
+	if !stopped.Stop() || stopped.Stop() {
+		t.Error("Stop does not report the pending timer once")
+	}
+
+	clock.Advance(2 * time.Second)
+	select {
+	case at := <-early.C():
+		if !at.Equal(start.Add(time.Second)) {
+			t.Errorf("fired at %v, want its deadline", at)
+		}
+	default:
+		t.Fatal("due timer did not fire")
+	}
+	select {
+	case <-late.C():
+		t.Fatal("timer fired before its deadline")
+	case <-stopped.C():
+		t.Fatal("stopped timer fired")
+	default:
+	}
+	if clock.Timers() != 1 || !clock.Now().Equal(start.Add(2*time.Second)) {
+		t.Errorf("%d timers at %v, want 1 at 2s", clock.Timers(), clock.Now())
+	}
+
+	// Reset counts from now.
+	late.Reset(2 * time.Second)
+	clock.Advance(time.Second)
+	select {
+	case <-late.C():
+		t.Fatal("reset timer fired at its old deadline")
+	default:
+	}
+	clock.Advance(time.Second)
+	select {
+	case <-late.C():
+	default:
+		t.Fatal("reset timer did not fire")
+	}
+}
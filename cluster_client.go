@@ -0,0 +1,176 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrNoClusterNodes is returned when a cluster has no nodes.
+var ErrNoClusterNodes = errors.New("cluster has no nodes")
+
+// ClusterNode is an Asterisk node of a cluster.
+type ClusterNode struct {
+	Name   string
+	Client *APIClient
+}
+
+// NodeHealth is the health of a node as seen by the cluster client.
+type NodeHealth struct {
+	Healthy             bool
+	ConsecutiveFailures int
+	LastError           error
+	LastFailure         time.Time
+}
+
+// ClusterClient spreads control-plane operations over redundant Asterisk nodes. Nodes failing at
+// the control-plane level (network errors, 5xx responses) are marked unhealthy after
+// FailureThreshold consecutive failures and avoided for Cooldown.
+type ClusterClient struct {
+	nodes []ClusterNode
+
+	// FailureThreshold consecutive failures mark a node unhealthy. Defaults to 3.
+	FailureThreshold int
+	// Cooldown is how long an unhealthy node is avoided before being tried again. Defaults to 30 seconds.
+	Cooldown time.Duration
+
+	mu     sync.Mutex
+	health map[string]*NodeHealth
+}
+
+// NewClusterClient creates a cluster client over nodes, listed in order of preference.
+func NewClusterClient(nodes ...ClusterNode) *ClusterClient {
+	c := &ClusterClient{nodes: nodes, FailureThreshold: 3, Cooldown: 30 * time.Second, health: make(map[string]*NodeHealth)}
+	for _, n := range nodes {
+		c.health[n.Name] = &NodeHealth{Healthy: true}
+	}
+	return c
+}
+
+// Nodes returns the nodes of the cluster.
+func (c *ClusterClient) Nodes() []ClusterNode {
+	return c.nodes
+}
+
+// Health returns the health of every node, keyed by name.
+func (c *ClusterClient) Health() map[string]NodeHealth {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	health := make(map[string]NodeHealth, len(c.health))
+	for name, h := range c.health {
+		health[name] = *h
+	}
+	return health
+}
+
+// preferred returns the nodes in the order they should be tried: healthy nodes and nodes whose
+// cooldown expired first, in configuration order, then the others.
+func (c *ClusterClient) preferred() []ClusterNode {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cooldown := c.Cooldown
+	if cooldown <= 0 {
+		cooldown = 30 * time.Second
+	}
+	nodes := append([]ClusterNode(nil), c.nodes...)
+	usable := func(n ClusterNode) bool {
+		h := c.health[n.Name]
+		return h.Healthy || n.Client.clock().Now().Sub(h.LastFailure) > cooldown
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return usable(nodes[i]) && !usable(nodes[j])
+	})
+	return nodes
+}
+
+// record updates the health of node after an operation.
+func (c *ClusterClient) record(node string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	h := c.health[node]
+	if err == nil {
+		if !h.Healthy {
+			c.nodeClient(node).logger.Infof("cluster: node %s is healthy again", node)
+		}
+		*h = NodeHealth{Healthy: true}
+		return
+	}
+	h.ConsecutiveFailures++
+	h.LastError = err
+	h.LastFailure = c.nodeClient(node).clock().Now()
+	threshold := c.FailureThreshold
+	if threshold <= 0 {
+		threshold = 3
+	}
+	if h.Healthy && h.ConsecutiveFailures >= threshold {
+		h.Healthy = false
+		c.nodeClient(node).logger.Warnf("cluster: node %s is unhealthy: %v", node, err)
+	}
+	c.nodeClient(node).metrics().IncCounter("ari_cluster_node_failures_total", map[string]string{"node": node}, 1)
+}
+
+func (c *ClusterClient) nodeClient(name string) *APIClient {
+	for _, n := range c.nodes {
+		if n.Name == name {
+			return n.Client
+		}
+	}
+	return nil
+}
+
+// IsControlPlaneError reports whether err, returned with resp by an API call, means the node could
+// not process the request (network error or 5xx) rather than the request being invalid.
+func IsControlPlaneError(resp *http.Response, err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return resp == nil
+	}
+	return resp == nil || resp.StatusCode >= 500
+}
+
+// Originate originates a channel on the first node able to process the request. The channel ID is
+// chosen by the client (generated when channelId is empty), so a retry on another node cannot
+// create a duplicate call: the channel is hung up on the failed node in case the request reached
+// it. It returns the node that accepted the call.
+func (c *ClusterClient) Originate(ctx context.Context, channelId string, endpoint string, opts *ChannelsApiOriginateWithIdOpts) (Channel, ClusterNode, error) {
+	if len(c.nodes) == 0 {
+		return Channel{}, ClusterNode{}, ErrNoClusterNodes
+	}
+	if channelId == "" {
+		channelId = newResourceId("cluster")
+	}
+
+	var lastErr error
+	for _, node := range c.preferred() {
+		node.Client.markOriginated(channelId)
+		channel, resp, err := node.Client.ChannelsApi.OriginateWithId(ctx, channelId, endpoint, opts)
+		if err != nil {
+			node.Client.originateFailed(channelId, resp)
+		}
+		if !IsControlPlaneError(resp, err) {
+			c.record(node.Name, nil)
+			return channel, node, err
+		}
+		// The caller giving up is no fault of the node.
+		canceled := ctx.Err() != nil
+		if !canceled {
+			c.record(node.Name, err)
+			lastErr = err
+			node.Client.logger.Warnf("cluster: originate %s on node %s failed, trying next node: %v", channelId, node.Name, err)
+		}
+
+		// The request may have reached the node before the failure.
+		cleanup, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		node.Client.ChannelsApi.Hangup(cleanup, channelId, nil)
+		cancel()
+		if canceled {
+			return Channel{}, ClusterNode{}, err
+		}
+	}
+	return Channel{}, ClusterNode{}, lastErr
+}
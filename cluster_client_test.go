@@ -0,0 +1,88 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// clusterNode returns a node served by handler, on clock.
+func clusterNode(t *testing.T, name string, clock Clock, handler http.HandlerFunc) ClusterNode {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	u, _ := url.Parse(server.URL)
+	cfg := NewConfiguration("/")
+	cfg.Host, cfg.Scheme = u.Host, u.Scheme
+	cfg.Clock = clock
+	return ClusterNode{Name: name, Client: NewAPIClient(cfg, NewStdLogger(ioutil.Discard))}
+}
+
+// TestClusterOriginateCanceled covers a caller giving up while a node handles the request: the
+// channel is hung up on that node, which is not marked down, and no other node is tried.
+func TestClusterOriginateCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var mu sync.Mutex
+	var requests []string
+	a := clusterNode(t, "a", nil, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		if r.Method == http.MethodPost {
+			cancel()
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+	b := clusterNode(t, "b", nil, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("node b got %s %s", r.Method, r.URL.Path)
+	})
+	c := NewClusterClient(a, b)
+	c.FailureThreshold = 1
+
+	if _, _, err := c.Originate(ctx, "c1", "PJSIP/alice", nil); err == nil {
+		t.Fatal("originate succeeded, want the cancellation")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 2 || requests[1] != "DELETE /ari/channels/c1" {
+		t.Errorf("requests = %v, want the originate then the hangup of c1", requests)
+	}
+	if h := c.Health()["a"]; !h.Healthy || h.ConsecutiveFailures != 0 {
+		t.Errorf("health of a = %+v, want healthy", h)
+	}
+}
+
+// TestClusterCooldown covers a failed node avoided for the cooldown, on the clock of the client.
+func TestClusterCooldown(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	a := clusterNode(t, "a", clock, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	b := clusterNode(t, "b", clock, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+	})
+	c := NewClusterClient(a, b)
+	c.FailureThreshold, c.Cooldown = 1, 10*time.Second
+
+	if _, node, err := c.Originate(context.Background(), "c1", "PJSIP/alice", nil); err != nil || node.Name != "b" {
+		t.Fatalf("originated on %s: %v, want b", node.Name, err)
+	}
+	if h := c.Health()["a"]; h.Healthy || !h.LastFailure.Equal(time.Unix(0, 0)) {
+		t.Errorf("health of a = %+v, want unhealthy since the start", h)
+	}
+	clock.Advance(5 * time.Second)
+	if first := c.preferred()[0].Name; first != "b" {
+		t.Errorf("first node in the cooldown = %s, want b", first)
+	}
+	clock.Advance(6 * time.Second)
+	if first := c.preferred()[0].Name; first != "a" {
+		t.Errorf("first node after the cooldown = %s, want a", first)
+	}
+}
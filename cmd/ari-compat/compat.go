@@ -0,0 +1,258 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	ari "github.com/olegromanchuk/asterisk-ari-go"
+)
+
+// Section is the compatibility of one kind of element of the specification.
+type Section struct {
+	// Supported are the elements the library implements as specified.
+	Supported []string `json:"supported"`
+	// Missing are in the specification of Asterisk but not in the library.
+	Missing []string `json:"missing,omitempty"`
+	// Diverging are implemented by the library with different parameters or types.
+	Diverging []string `json:"diverging,omitempty"`
+	// Removed are in the library but no longer in the specification of Asterisk.
+	Removed []string `json:"removed,omitempty"`
+}
+
+func (s *Section) sort() {
+	for _, list := range [][]string{s.Supported, s.Missing, s.Diverging, s.Removed} {
+		sort.Strings(list)
+	}
+}
+
+// Report is the compatibility of the library with the specification of an Asterisk instance.
+type Report struct {
+	AsteriskVersion string  `json:"asterisk_version"`
+	LibraryVersion  string  `json:"library_version"`
+	Risk            string  `json:"risk"`
+	Operations      Section `json:"operations"`
+	Events          Section `json:"events"`
+	Models          Section `json:"models"`
+}
+
+// Incompatible reports whether anything is missing or diverging.
+func (r *Report) Incompatible() bool {
+	for _, s := range []Section{r.Operations, r.Events, r.Models} {
+		if len(s.Missing) > 0 || len(s.Diverging) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// compare checks the library against s.
+func compare(s *spec) *Report {
+	r := &Report{AsteriskVersion: s.ApiVersion, LibraryVersion: ari.ARIVersion}
+	r.Risk = versionRisk(s.ApiVersion, ari.ARIVersion)
+	compareOperations(s, &r.Operations)
+	compareEvents(s, &r.Events)
+	compareModels(s, &r.Models)
+	return r
+}
+
+// versionRisk compares the semantic versions of the ARI specifications: a newer major version
+// may remove or change operations, a newer minor version only adds.
+func versionRisk(asterisk string, library string) string {
+	a, b := semver(asterisk), semver(library)
+	switch {
+	case a[0] > b[0]:
+		return "high: Asterisk implements a newer major version, operations or fields may have changed or been removed"
+	case a[0] < b[0]:
+		return "high: Asterisk implements an older major version, the library may use operations it does not have"
+	case a[1] > b[1]:
+		return "low: Asterisk implements a newer minor version, additions are not available in the library"
+	case a[1] < b[1]:
+		return "medium: Asterisk implements an older minor version, some library operations may be missing"
+	}
+	return "none: same version"
+}
+
+func semver(v string) [3]int {
+	var n [3]int
+	for i, part := range strings.SplitN(v, ".", 3) {
+		n[i], _ = strconv.Atoi(part)
+	}
+	return n
+}
+
+func compareOperations(s *spec, sec *Section) {
+	client := reflect.ValueOf(ari.NewAPIClient(ari.NewConfiguration("/"))).Elem()
+	for key, op := range s.Operations {
+		name := op.Resource + "." + op.Nickname + " (" + key + ")"
+		lib, ok := libraryOperations[key]
+		if !ok {
+			sec.Missing = append(sec.Missing, name)
+			continue
+		}
+		method, ok := client.FieldByName(lib.Service).Type().MethodByName(lib.Method)
+		if !ok {
+			sec.Missing = append(sec.Missing, name)
+			continue
+		}
+		if missing := missingOptions(method.Type, op.Optional); len(missing) > 0 {
+			sec.Diverging = append(sec.Diverging, fmt.Sprintf("%s: parameters %s not supported", name, strings.Join(missing, ", ")))
+			continue
+		}
+		sec.Supported = append(sec.Supported, name)
+	}
+	for key, lib := range libraryOperations {
+		if _, ok := s.Operations[key]; !ok {
+			sec.Removed = append(sec.Removed, lib.Service+"."+lib.Method+" ("+key+")")
+		}
+	}
+	sec.sort()
+}
+
+// missingOptions returns the optional parameters without a field in the options struct of the
+// method, its last pointer to struct argument.
+func missingOptions(method reflect.Type, optional []string) []string {
+	var opts reflect.Type
+	if last := method.In(method.NumIn() - 1); last.Kind() == reflect.Ptr && last.Elem().Kind() == reflect.Struct {
+		opts = last.Elem()
+	}
+	var missing []string
+	for _, p := range optional {
+		if !hasOption(opts, p) {
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
+
+func hasOption(opts reflect.Type, param string) bool {
+	if opts == nil {
+		return false
+	}
+	// "appArgs" and "reason_code" are the fields AppArgs and ReasonCode.
+	var field string
+	for _, part := range strings.Split(param, "_") {
+		if part != "" {
+			field += strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	for _, name := range []string{field, field + "_"} {
+		if _, ok := opts.FieldByName(name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func compareEvents(s *spec, sec *Section) {
+	tags := jsonFields(reflect.TypeOf(ari.StasisEvent{}))
+	for _, event := range s.events() {
+		var diverging []string
+		for prop, p := range s.properties(event) {
+			field, ok := tags[prop]
+			if !ok {
+				diverging = append(diverging, prop+" missing")
+			} else if !compatible(p.Type, field) {
+				diverging = append(diverging, fmt.Sprintf("%s is %s, library %s", prop, p.Type, field))
+			}
+		}
+		sort.Strings(diverging)
+		switch {
+		case len(diverging) == 0:
+			sec.Supported = append(sec.Supported, event)
+		case libraryModels[event] == nil:
+			sec.Missing = append(sec.Missing, event+": "+strings.Join(diverging, ", "))
+		default:
+			sec.Diverging = append(sec.Diverging, event+": "+strings.Join(diverging, ", "))
+		}
+	}
+	sec.sort()
+}
+
+func compareModels(s *spec, sec *Section) {
+	events := make(map[string]bool)
+	for _, e := range s.events() {
+		events[e] = true
+	}
+	for name := range s.Models {
+		if events[name] || name == "Event" || name == "Message" {
+			continue
+		}
+		t, ok := libraryModels[name]
+		if !ok {
+			sec.Missing = append(sec.Missing, name)
+			continue
+		}
+		fields := jsonFields(t)
+		var diverging []string
+		for prop, p := range s.properties(name) {
+			field, ok := fields[prop]
+			if !ok {
+				diverging = append(diverging, prop+" missing")
+			} else if !compatible(p.Type, field) {
+				diverging = append(diverging, fmt.Sprintf("%s is %s, library %s", prop, p.Type, field))
+			}
+		}
+		sort.Strings(diverging)
+		if len(diverging) > 0 {
+			sec.Diverging = append(sec.Diverging, name+": "+strings.Join(diverging, ", "))
+		} else {
+			sec.Supported = append(sec.Supported, name)
+		}
+	}
+	for name := range libraryModels {
+		if _, ok := s.Models[name]; !ok {
+			sec.Removed = append(sec.Removed, name)
+		}
+	}
+	sec.sort()
+}
+
+// jsonFields returns the types of the fields of a struct by JSON name.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+// compatible reports whether a Go type decodes a property of a Swagger 1.2 type.
+func compatible(specType string, t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Interface {
+		return true
+	}
+	if strings.HasPrefix(specType, "List[") {
+		return t.Kind() == reflect.Slice && compatible(strings.TrimSuffix(strings.TrimPrefix(specType, "List["), "]"), t.Elem())
+	}
+	switch specType {
+	case "string":
+		return t.Kind() == reflect.String
+	case "int", "long":
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return true
+		}
+		return false
+	case "double", "float":
+		return t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64
+	case "boolean":
+		return t.Kind() == reflect.Bool
+	case "Date":
+		return t.Kind() == reflect.String || t.Kind() == reflect.Struct
+	case "object":
+		return t.Kind() == reflect.Map || t.Kind() == reflect.Struct
+	}
+	// A model.
+	return t.Kind() == reflect.Struct
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"reflect"
+
+	ari "github.com/olegromanchuk/asterisk-ari-go"
+)
+
+// libraryOperation is the method of an API service of the client implementing an operation.
+type libraryOperation struct {
+	Service string
+	Method  string
+}
+
+// libraryOperations are the operations of the ARI specification the library was generated from,
+// api/swagger.yaml, by "METHOD /path".
+var libraryOperations = map[string]libraryOperation{
+	"GET /applications":                                               {"ApplicationsApi", "List"},
+	"GET /applications/{applicationName}":                             {"ApplicationsApi", "Get"},
+	"PUT /applications/{applicationName}/eventFilter":                 {"ApplicationsApi", "Filter"},
+	"POST /applications/{applicationName}/subscription":               {"ApplicationsApi", "Subscribe"},
+	"DELETE /applications/{applicationName}/subscription":             {"ApplicationsApi", "Unsubscribe"},
+	"GET /asterisk/config/dynamic/{configClass}/{objectType}/{id}":    {"AsteriskApi", "GetObject"},
+	"PUT /asterisk/config/dynamic/{configClass}/{objectType}/{id}":    {"AsteriskApi", "UpdateObject"},
+	"DELETE /asterisk/config/dynamic/{configClass}/{objectType}/{id}": {"AsteriskApi", "DeleteObject"},
+	"GET /asterisk/info":                                              {"AsteriskApi", "GetInfo"},
+	"GET /asterisk/logging":                                           {"AsteriskApi", "ListLogChannels"},
+	"POST /asterisk/logging/{logChannelName}":                         {"AsteriskApi", "AddLog"},
+	"DELETE /asterisk/logging/{logChannelName}":                       {"AsteriskApi", "DeleteLog"},
+	"PUT /asterisk/logging/{logChannelName}/rotate":                   {"AsteriskApi", "RotateLog"},
+	"GET /asterisk/modules":                                           {"AsteriskApi", "ListModules"},
+	"GET /asterisk/modules/{moduleName}":                              {"AsteriskApi", "GetModule"},
+	"POST /asterisk/modules/{moduleName}":                             {"AsteriskApi", "LoadModule"},
+	"PUT /asterisk/modules/{moduleName}":                              {"AsteriskApi", "ReloadModule"},
+	"DELETE /asterisk/modules/{moduleName}":                           {"AsteriskApi", "UnloadModule"},
+	"GET /asterisk/ping":                                              {"AsteriskApi", "Ping"},
+	"GET /asterisk/variable":                                          {"AsteriskApi", "GetGlobalVar"},
+	"POST /asterisk/variable":                                         {"AsteriskApi", "SetGlobalVar"},
+	"GET /bridges":                                                    {"BridgesApi", "Listbridges"},
+	"POST /bridges":                                                   {"BridgesApi", "Create"},
+	"GET /bridges/{bridgeId}":                                         {"BridgesApi", "Getbridge"},
+	"POST /bridges/{bridgeId}":                                        {"BridgesApi", "CreateWithId"},
+	"DELETE /bridges/{bridgeId}":                                      {"BridgesApi", "Destroy"},
+	"POST /bridges/{bridgeId}/addChannel":                             {"BridgesApi", "AddChannel"},
+	"POST /bridges/{bridgeId}/moh":                                    {"BridgesApi", "StartMoh"},
+	"DELETE /bridges/{bridgeId}/moh":                                  {"BridgesApi", "StopMoh"},
+	"POST /bridges/{bridgeId}/play":                                   {"BridgesApi", "Play"},
+	"POST /bridges/{bridgeId}/play/{playbackId}":                      {"BridgesApi", "PlayWithId"},
+	"POST /bridges/{bridgeId}/record":                                 {"BridgesApi", "Record"},
+	"POST /bridges/{bridgeId}/removeChannel":                          {"BridgesApi", "RemoveChannel"},
+	"DELETE /bridges/{bridgeId}/videoSource":                          {"BridgesApi", "ClearVideoSource"},
+	"POST /bridges/{bridgeId}/videoSource/{channelId}":                {"BridgesApi", "SetVideoSource"},
+	"GET /channels":                                                   {"ChannelsApi", "Listchannels"},
+	"POST /channels":                                                  {"ChannelsApi", "Originate"},
+	"POST /channels/create":                                           {"ChannelsApi", "Createchannel"},
+	"POST /channels/externalMedia":                                    {"ChannelsApi", "ExternalMedia"},
+	"GET /channels/{channelId}":                                       {"ChannelsApi", "Getchannel"},
+	"POST /channels/{channelId}":                                      {"ChannelsApi", "OriginateWithId"},
+	"DELETE /channels/{channelId}":                                    {"ChannelsApi", "Hangup"},
+	"POST /channels/{channelId}/answer":                               {"ChannelsApi", "Answer"},
+	"POST /channels/{channelId}/continue":                             {"ChannelsApi", "ContinueInDialplan"},
+	"POST /channels/{channelId}/dial":                                 {"ChannelsApi", "Dial"},
+	"POST /channels/{channelId}/dtmf":                                 {"ChannelsApi", "SendDTMF"},
+	"POST /channels/{channelId}/hold":                                 {"ChannelsApi", "Hold"},
+	"DELETE /channels/{channelId}/hold":                               {"ChannelsApi", "Unhold"},
+	"POST /channels/{channelId}/moh":                                  {"ChannelsApi", "AddMoh"},
+	"DELETE /channels/{channelId}/moh":                                {"ChannelsApi", "Deletemoh"},
+	"POST /channels/{channelId}/move":                                 {"ChannelsApi", "Move"},
+	"POST /channels/{channelId}/mute":                                 {"ChannelsApi", "Mute"},
+	"DELETE /channels/{channelId}/mute":                               {"ChannelsApi", "Unmute"},
+	"POST /channels/{channelId}/play":                                 {"ChannelsApi", "Playsound"},
+	"POST /channels/{channelId}/play/{playbackId}":                    {"ChannelsApi", "PlaySoundWithId"},
+	"POST /channels/{channelId}/record":                               {"ChannelsApi", "Recordchannel"},
+	"POST /channels/{channelId}/redirect":                             {"ChannelsApi", "Redirect"},
+	"POST /channels/{channelId}/ring":                                 {"ChannelsApi", "Ring"},
+	"DELETE /channels/{channelId}/ring":                               {"ChannelsApi", "RingStop"},
+	"GET /channels/{channelId}/rtp_statistics":                        {"ChannelsApi", "Rtpstatistics"},
+	"POST /channels/{channelId}/silence":                              {"ChannelsApi", "StartSilence"},
+	"DELETE /channels/{channelId}/silence":                            {"ChannelsApi", "StopSilence"},
+	"POST /channels/{channelId}/snoop":                                {"ChannelsApi", "SnoopChannel"},
+	"POST /channels/{channelId}/snoop/{snoopId}":                      {"ChannelsApi", "SnoopChannelWithId"},
+	"GET /channels/{channelId}/variable":                              {"ChannelsApi", "GetChannelVar"},
+	"POST /channels/{channelId}/variable":                             {"ChannelsApi", "SetChannelVar"},
+	"GET /deviceStates":                                               {"DeviceStatesApi", "ListDeviceStates"},
+	"GET /deviceStates/{deviceName}":                                  {"DeviceStatesApi", "Getdevicestate"},
+	"PUT /deviceStates/{deviceName}":                                  {"DeviceStatesApi", "Update"},
+	"DELETE /deviceStates/{deviceName}":                               {"DeviceStatesApi", "Delete"},
+	"GET /endpoints":                                                  {"EndpointsApi", "Listendpoints"},
+	"PUT /endpoints/sendMessage":                                      {"EndpointsApi", "SendMessage"},
+	"GET /endpoints/{tech}":                                           {"EndpointsApi", "ListByTech"},
+	"GET /endpoints/{tech}/{resource}":                                {"EndpointsApi", "Getendpoint"},
+	"PUT /endpoints/{tech}/{resource}/sendMessage":                    {"EndpointsApi", "SendMessageToEndpoint"},
+	"GET /events":                                                     {"EventsApi", "EventWebsocket"},
+	"POST /events/user/{eventName}":                                   {"EventsApi", "UserEvent"},
+	"GET /mailboxes":                                                  {"MailboxesApi", "Listmailboxes"},
+	"GET /mailboxes/{mailboxName}":                                    {"MailboxesApi", "Getmailbox"},
+	"PUT /mailboxes/{mailboxName}":                                    {"MailboxesApi", "Updatemailbox"},
+	"DELETE /mailboxes/{mailboxName}":                                 {"MailboxesApi", "Deletemailbox"},
+	"GET /playbacks/{playbackId}":                                     {"PlaybacksApi", "Getplayback"},
+	"DELETE /playbacks/{playbackId}":                                  {"PlaybacksApi", "Stop"},
+	"POST /playbacks/{playbackId}/control":                            {"PlaybacksApi", "Control"},
+	"GET /recordings/live/{recordingName}":                            {"RecordingsApi", "GetLive"},
+	"DELETE /recordings/live/{recordingName}":                         {"RecordingsApi", "Cancel"},
+	"POST /recordings/live/{recordingName}/mute":                      {"RecordingsApi", "Muterecording"},
+	"DELETE /recordings/live/{recordingName}/mute":                    {"RecordingsApi", "Unmuterecording"},
+	"POST /recordings/live/{recordingName}/pause":                     {"RecordingsApi", "Pause"},
+	"DELETE /recordings/live/{recordingName}/pause":                   {"RecordingsApi", "Unpause"},
+	"POST /recordings/live/{recordingName}/stop":                      {"RecordingsApi", "Stoprecording"},
+	"GET /recordings/stored":                                          {"RecordingsApi", "ListStored"},
+	"GET /recordings/stored/{recordingName}":                          {"RecordingsApi", "GetStored"},
+	"DELETE /recordings/stored/{recordingName}":                       {"RecordingsApi", "DeleteStored"},
+	"POST /recordings/stored/{recordingName}/copy":                    {"RecordingsApi", "CopyStored"},
+	"GET /recordings/stored/{recordingName}/file":                     {"RecordingsApi", "GetStoredFile"},
+	"GET /sounds":                                                     {"SoundsApi", "Listsounds"},
+	"GET /sounds/{soundId}":                                           {"SoundsApi", "Getsound"},
+}
+
+// libraryModels are the models of the specification with the types decoding them.
+var libraryModels = map[string]reflect.Type{
+	"Application":              reflect.TypeOf(ari.Application{}),
+	"ApplicationMoveFailed":    reflect.TypeOf(ari.ApplicationMoveFailed{}),
+	"ApplicationReplaced":      reflect.TypeOf(ari.ApplicationReplaced{}),
+	"AsteriskInfo":             reflect.TypeOf(ari.AsteriskInfo{}),
+	"AsteriskPing":             reflect.TypeOf(ari.AsteriskPing{}),
+	"Bridge":                   reflect.TypeOf(ari.Bridge{}),
+	"BridgeAttendedTransfer":   reflect.TypeOf(ari.BridgeAttendedTransfer{}),
+	"BridgeBlindTransfer":      reflect.TypeOf(ari.BridgeBlindTransfer{}),
+	"BridgeCreated":            reflect.TypeOf(ari.BridgeCreated{}),
+	"BridgeDestroyed":          reflect.TypeOf(ari.BridgeDestroyed{}),
+	"BridgeMerged":             reflect.TypeOf(ari.BridgeMerged{}),
+	"BridgeVideoSourceChanged": reflect.TypeOf(ari.BridgeVideoSourceChanged{}),
+	"BuildInfo":                reflect.TypeOf(ari.BuildInfo{}),
+	"CallerID":                 reflect.TypeOf(ari.CallerId{}),
+	"Channel":                  reflect.TypeOf(ari.Channel{}),
+	"ChannelCallerId":          reflect.TypeOf(ari.ChannelCallerId{}),
+	"ChannelConnectedLine":     reflect.TypeOf(ari.ChannelConnectedLine{}),
+	"ChannelCreated":           reflect.TypeOf(ari.ChannelCreated{}),
+	"ChannelDestroyed":         reflect.TypeOf(ari.ChannelDestroyed{}),
+	"ChannelDialplan":          reflect.TypeOf(ari.ChannelDialplan{}),
+	"ChannelDtmfReceived":      reflect.TypeOf(ari.ChannelDtmfReceived{}),
+	"ChannelEnteredBridge":     reflect.TypeOf(ari.ChannelEnteredBridge{}),
+	"ChannelHangupRequest":     reflect.TypeOf(ari.ChannelHangupRequest{}),
+	"ChannelHold":              reflect.TypeOf(ari.ChannelHold{}),
+	"ChannelLeftBridge":        reflect.TypeOf(ari.ChannelLeftBridge{}),
+	"ChannelStateChange":       reflect.TypeOf(ari.ChannelStateChange{}),
+	"ChannelTalkingFinished":   reflect.TypeOf(ari.ChannelTalkingFinished{}),
+	"ChannelTalkingStarted":    reflect.TypeOf(ari.ChannelTalkingStarted{}),
+	"ChannelUnhold":            reflect.TypeOf(ari.ChannelUnhold{}),
+	"ChannelUserevent":         reflect.TypeOf(ari.ChannelUserevent{}),
+	"ChannelVarset":            reflect.TypeOf(ari.ChannelVarset{}),
+	"ConfigInfo":               reflect.TypeOf(ari.ConfigInfo{}),
+	"ConfigTuple":              reflect.TypeOf(ari.ConfigTuple{}),
+	"ContactInfo":              reflect.TypeOf(ari.ContactInfo{}),
+	"ContactStatusChange":      reflect.TypeOf(ari.ContactStatusChange{}),
+	"DeviceState":              reflect.TypeOf(ari.DeviceState{}),
+	"DeviceStateChanged":       reflect.TypeOf(ari.DeviceStateChanged{}),
+	"Dial":                     reflect.TypeOf(ari.Dial{}),
+	"Dialed":                   reflect.TypeOf(ari.Dialed{}),
+	"DialplanCEP":              reflect.TypeOf(ari.DialplanCep{}),
+	"Endpoint":                 reflect.TypeOf(ari.Endpoint{}),
+	"EndpointStateChange":      reflect.TypeOf(ari.EndpointStateChange{}),
+	"Event":                    reflect.TypeOf(ari.Event{}),
+	"FormatLangPair":           reflect.TypeOf(ari.FormatLangPair{}),
+	"LiveRecording":            reflect.TypeOf(ari.LiveRecording{}),
+	"LogChannel":               reflect.TypeOf(ari.LogChannel{}),
+	"Mailbox":                  reflect.TypeOf(ari.Mailbox{}),
+	"Message":                  reflect.TypeOf(ari.Message{}),
+	"MissingParams":            reflect.TypeOf(ari.MissingParams{}),
+	"Module":                   reflect.TypeOf(ari.Module{}),
+	"Peer":                     reflect.TypeOf(ari.Peer{}),
+	"PeerStatusChange":         reflect.TypeOf(ari.PeerStatusChange{}),
+	"Playback":                 reflect.TypeOf(ari.Playback{}),
+	"PlaybackContinuing":       reflect.TypeOf(ari.PlaybackContinuing{}),
+	"PlaybackFinished":         reflect.TypeOf(ari.PlaybackFinished{}),
+	"PlaybackStarted":          reflect.TypeOf(ari.PlaybackStarted{}),
+	"RTPstat":                  reflect.TypeOf(ari.RtPstat{}),
+	"RecordingFailed":          reflect.TypeOf(ari.RecordingFailed{}),
+	"RecordingFinished":        reflect.TypeOf(ari.RecordingFinished{}),
+	"RecordingStarted":         reflect.TypeOf(ari.RecordingStarted{}),
+	"SetId":                    reflect.TypeOf(ari.SetId{}),
+	"Sound":                    reflect.TypeOf(ari.Sound{}),
+	"StasisEnd":                reflect.TypeOf(ari.StasisEnd{}),
+	"StasisStart":              reflect.TypeOf(ari.StasisStart{}),
+	"StatusInfo":               reflect.TypeOf(ari.StatusInfo{}),
+	"StoredRecording":          reflect.TypeOf(ari.StoredRecording{}),
+	"SystemInfo":               reflect.TypeOf(ari.SystemInfo{}),
+	"TextMessage":              reflect.TypeOf(ari.TextMessage{}),
+	"TextMessageReceived":      reflect.TypeOf(ari.TextMessageReceived{}),
+	"Variable":                 reflect.TypeOf(ari.Variable{}),
+}
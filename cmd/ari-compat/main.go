@@ -0,0 +1,84 @@
+// Command ari-compat reports how well this version of the library supports an Asterisk instance:
+// which REST operations, events and model fields of its ARI specification the library implements,
+// misses, or implements with diverging parameters or types, and the risk implied by the
+// difference between the ARI versions. Run it against the target Asterisk before upgrading either.
+//
+//	ari-compat -url http://localhost:8088 -user asterisk -password secret
+//	ari-compat -dir /var/lib/asterisk/rest-api -json
+//
+// The specification is read from /ari/api-docs of a running Asterisk, or from a copy of its
+// rest-api directory. The exit status is 1 on error, and with -strict also when something is
+// missing or diverging.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+func main() {
+	url := flag.String("url", envOr("ARI_URL", "http://localhost:8088"), "URL of the Asterisk HTTP server, $ARI_URL")
+	user := flag.String("user", os.Getenv("ARI_USER"), "ARI user, $ARI_USER")
+	password := flag.String("password", os.Getenv("ARI_PASS"), "ARI password, $ARI_PASS")
+	dir := flag.String("dir", "", "read the specification from this rest-api directory instead of -url")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	verbose := flag.Bool("v", false, "list the supported elements too")
+	strict := flag.Bool("strict", false, "exit with status 1 when something is missing or diverging")
+	flag.Parse()
+
+	fetch := httpFetcher(*url, *user, *password)
+	if *dir != "" {
+		fetch = dirFetcher(*dir)
+	}
+	s, err := loadSpec(fetch)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ari-compat:", err)
+		os.Exit(1)
+	}
+	report := compare(s)
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else {
+		printReport(os.Stdout, report, *verbose)
+	}
+	if *strict && report.Incompatible() {
+		os.Exit(1)
+	}
+}
+
+func envOr(name string, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func printReport(w io.Writer, r *Report, verbose bool) {
+	fmt.Fprintf(w, "Asterisk ARI %s, library ARI %s\n", r.AsteriskVersion, r.LibraryVersion)
+	fmt.Fprintf(w, "Upgrade risk: %s\n", r.Risk)
+	for _, s := range []struct {
+		title   string
+		section Section
+	}{{"Operations", r.Operations}, {"Events", r.Events}, {"Models", r.Models}} {
+		sec := s.section
+		fmt.Fprintf(w, "\n%s: %d supported, %d missing, %d diverging, %d removed\n", s.title, len(sec.Supported), len(sec.Missing), len(sec.Diverging), len(sec.Removed))
+		printList(w, "missing", sec.Missing)
+		printList(w, "diverging", sec.Diverging)
+		printList(w, "removed", sec.Removed)
+		if verbose {
+			printList(w, "supported", sec.Supported)
+		}
+	}
+}
+
+func printList(w io.Writer, label string, items []string) {
+	for _, item := range items {
+		fmt.Fprintf(w, "  %-9s %s\n", label, item)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// resourceListing is the resources.json served by Asterisk, in Swagger 1.2.
+type resourceListing struct {
+	ApiVersion string `json:"apiVersion"`
+	Apis       []struct {
+		Path string `json:"path"`
+	} `json:"apis"`
+}
+
+// apiDeclaration is the declaration of a resource, e.g. channels.json.
+type apiDeclaration struct {
+	ApiVersion   string `json:"apiVersion"`
+	ResourcePath string `json:"resourcePath"`
+	Apis         []struct {
+		Path       string `json:"path"`
+		Operations []struct {
+			HttpMethod string `json:"httpMethod"`
+			Nickname   string `json:"nickname"`
+			Parameters []struct {
+				Name      string `json:"name"`
+				ParamType string `json:"paramType"`
+				Required  bool   `json:"required"`
+			} `json:"parameters"`
+		} `json:"operations"`
+	} `json:"apis"`
+	Models map[string]specModel `json:"models"`
+}
+
+type specModel struct {
+	Id         string                  `json:"id"`
+	Extends    string                  `json:"extends"`
+	SubTypes   []string                `json:"subTypes"`
+	Properties map[string]specProperty `json:"properties"`
+}
+
+type specProperty struct {
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+}
+
+// spec is the ARI specification of an Asterisk instance.
+type spec struct {
+	ApiVersion string
+	// Operations are by "METHOD /path".
+	Operations map[string]specOperation
+	Models     map[string]specModel
+}
+
+type specOperation struct {
+	Resource string
+	Nickname string
+	// Optional are the optional query and body parameters.
+	Optional []string
+}
+
+// fetcher reads a file of the specification, e.g. "resources.json".
+type fetcher func(name string) ([]byte, error)
+
+// httpFetcher reads the specification from the api-docs of a running Asterisk.
+func httpFetcher(baseURL string, user string, password string) fetcher {
+	client := &http.Client{Timeout: 10 * time.Second}
+	return func(name string) ([]byte, error) {
+		req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(baseURL, "/")+"/ari/api-docs/"+name, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.SetBasicAuth(user, password)
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("GET %s: %s", req.URL.Path, resp.Status)
+		}
+		return body, nil
+	}
+}
+
+// dirFetcher reads the specification from a copy of the api-docs, e.g.
+// /var/lib/asterisk/rest-api.
+func dirFetcher(dir string) fetcher {
+	return func(name string) ([]byte, error) {
+		return ioutil.ReadFile(filepath.Join(dir, name))
+	}
+}
+
+// loadSpec reads resources.json and the declarations it lists.
+func loadSpec(fetch fetcher) (*spec, error) {
+	data, err := fetch("resources.json")
+	if err != nil {
+		return nil, err
+	}
+	var listing resourceListing
+	if err := json.Unmarshal(data, &listing); err != nil {
+		return nil, fmt.Errorf("resources.json: %w", err)
+	}
+	s := &spec{ApiVersion: listing.ApiVersion, Operations: make(map[string]specOperation), Models: make(map[string]specModel)}
+	for _, api := range listing.Apis {
+		// "/api-docs/channels.{format}"
+		name := strings.Replace(path.Base(api.Path), "{format}", "json", 1)
+		data, err := fetch(name)
+		if err != nil {
+			return nil, err
+		}
+		var decl apiDeclaration
+		if err := json.Unmarshal(data, &decl); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		resource := strings.TrimSuffix(name, ".json")
+		for _, a := range decl.Apis {
+			for _, op := range a.Operations {
+				so := specOperation{Resource: resource, Nickname: op.Nickname}
+				for _, p := range op.Parameters {
+					if !p.Required && p.ParamType != "path" {
+						so.Optional = append(so.Optional, p.Name)
+					}
+				}
+				s.Operations[strings.ToUpper(op.HttpMethod)+" "+a.Path] = so
+			}
+		}
+		for id, m := range decl.Models {
+			s.Models[id] = m
+		}
+	}
+	return s, nil
+}
+
+// events returns the names of the event models, the subtypes of Event, recursively.
+func (s *spec) events() []string {
+	var events []string
+	var walk func(name string)
+	walk = func(name string) {
+		for _, sub := range s.Models[name].SubTypes {
+			events = append(events, sub)
+			walk(sub)
+		}
+	}
+	walk("Event")
+	sort.Strings(events)
+	return events
+}
+
+// properties returns the properties of a model, including the inherited ones.
+func (s *spec) properties(name string) map[string]specProperty {
+	props := make(map[string]specProperty)
+	for depth := 0; name != "" && depth < 8; depth++ {
+		m := s.Models[name]
+		for p, prop := range m.Properties {
+			if _, ok := props[p]; !ok {
+				props[p] = prop
+			}
+		}
+		name = m.Extends
+	}
+	return props
+}
@@ -0,0 +1,108 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// hasStatus reports whether resp is a response with the status code.
+func hasStatus(resp *http.Response, code int) bool {
+	return resp != nil && resp.StatusCode == code
+}
+
+// ExistsCtx reports whether the channel exists. A 404, or the channel having been seen destroyed,
+// is false without error; other failures are returned.
+func (a *ChannelsApiService) ExistsCtx(ctx context.Context, channelId string) (bool, error) {
+	_, resp, err := a.Getchannel(ctx, channelId)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, ErrChannelGone) || hasStatus(resp, http.StatusNotFound):
+		return false, nil
+	}
+	return false, err
+}
+
+// ExistsCtx reports whether the bridge exists. A 404 is false without error; other failures are
+// returned.
+func (a *BridgesApiService) ExistsCtx(ctx context.Context, bridgeId string) (bool, error) {
+	_, resp, err := a.Getbridge(ctx, bridgeId)
+	switch {
+	case err == nil:
+		return true, nil
+	case hasStatus(resp, http.StatusNotFound):
+		return false, nil
+	}
+	return false, err
+}
+
+// bridgeEnsures merges the concurrent EnsureExists calls of a client for the same bridge.
+type bridgeEnsures struct {
+	mu      sync.Mutex
+	pending map[string]*bridgeEnsure
+}
+
+type bridgeEnsure struct {
+	done   chan struct{}
+	bridge Bridge
+	err    error
+}
+
+// EnsureExists returns the bridge bridgeId, creating it with opts if it does not exist; created
+// reports whether this call created it. Concurrent calls of the client for the same bridge are
+// merged: one of them gets or creates the bridge, the others wait for it and get the bridge with
+// created false, so exactly one caller sees created true. A bridge created in the meantime
+// elsewhere, e.g. by another client, is detected from Asterisk refusing the creation, 409, or
+// 500 for opts with a type or name, and returned with created false.
+func (a *BridgesApiService) EnsureExists(ctx context.Context, bridgeId string, opts *BridgesApiCreateWithIdOpts) (bridge Bridge, created bool, err error) {
+	e := &a.client.ensures
+	e.mu.Lock()
+	for e.pending[bridgeId] != nil {
+		call := e.pending[bridgeId]
+		e.mu.Unlock()
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return Bridge{}, false, ctx.Err()
+		}
+		// The call that got the bridge may have been canceled by its own caller: try again.
+		if !errors.Is(call.err, context.Canceled) && !errors.Is(call.err, context.DeadlineExceeded) {
+			return call.bridge, false, call.err
+		}
+		e.mu.Lock()
+	}
+	if e.pending == nil {
+		e.pending = make(map[string]*bridgeEnsure)
+	}
+	call := &bridgeEnsure{done: make(chan struct{})}
+	e.pending[bridgeId] = call
+	e.mu.Unlock()
+
+	call.bridge, created, call.err = a.ensureExists(ctx, bridgeId, opts)
+	e.mu.Lock()
+	delete(e.pending, bridgeId)
+	e.mu.Unlock()
+	close(call.done)
+	return call.bridge, created, call.err
+}
+
+func (a *BridgesApiService) ensureExists(ctx context.Context, bridgeId string, opts *BridgesApiCreateWithIdOpts) (Bridge, bool, error) {
+	bridge, resp, err := a.Getbridge(ctx, bridgeId)
+	if err == nil || !hasStatus(resp, http.StatusNotFound) {
+		return bridge, false, err
+	}
+	bridge, resp, err = a.CreateWithId(ctx, bridgeId, opts)
+	if err == nil {
+		return bridge, true, nil
+	}
+	if !hasStatus(resp, http.StatusConflict) && !hasStatus(resp, http.StatusInternalServerError) {
+		return bridge, false, err
+	}
+	existing, _, getErr := a.Getbridge(ctx, bridgeId)
+	if getErr != nil {
+		return bridge, false, err
+	}
+	return existing, false, nil
+}
@@ -0,0 +1,72 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestEnsureExistsConcurrent covers callers racing on a missing bridge, on an Asterisk that
+// creates or updates on POST: exactly one of them creates it.
+func TestEnsureExistsConcurrent(t *testing.T) {
+	var mu sync.Mutex
+	exists, posts := false, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodGet:
+			if !exists {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"message":"Bridge not found"}`))
+				return
+			}
+		case http.MethodPost:
+			posts++
+			mu.Unlock()
+			time.Sleep(20 * time.Millisecond)
+			mu.Lock()
+			exists = true
+		}
+		w.Write([]byte(`{"id":"b1"}`))
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	cfg := NewConfiguration("/")
+	cfg.Host, cfg.Scheme = u.Host, u.Scheme
+	client := NewAPIClient(cfg, NewStdLogger(ioutil.Discard))
+
+	const callers = 8
+	created := make(chan bool, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bridge, c, err := client.BridgesApi.EnsureExists(context.Background(), "b1", nil)
+			if err != nil || bridge.Id != "b1" {
+				t.Errorf("bridge = %+v, err = %v", bridge, err)
+			}
+			created <- c
+		}()
+	}
+	wg.Wait()
+	close(created)
+	n := 0
+	for c := range created {
+		if c {
+			n++
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if n != 1 || posts != 1 {
+		t.Errorf("%d callers created the bridge with %d requests, want 1", n, posts)
+	}
+}
@@ -0,0 +1,272 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Config is the complete configuration of an application built on the library, one section per
+// subsystem. Start from Defaults, override what differs, and check it with Validate.
+type Config struct {
+	Transport  TransportConfig  `json:"transport" yaml:"transport"`
+	Reconnect  ReconnectConfig  `json:"reconnect" yaml:"reconnect"`
+	Dispatcher DispatcherConfig `json:"dispatcher" yaml:"dispatcher"`
+	Metrics    MetricsConfig    `json:"metrics" yaml:"metrics"`
+	Queue      QueueConfig      `json:"queue" yaml:"queue"`
+	Recording  RecordingConfig  `json:"recording" yaml:"recording"`
+	// Experimental lists the experimental behaviors to enable, see Flags.
+	Experimental []string `json:"experimental,omitempty" yaml:"experimental,omitempty"`
+}
+
+// TransportConfig is how the client reaches Asterisk.
+type TransportConfig struct {
+	// URL of the Asterisk HTTP server, e.g. "ws://localhost:8088"; the scheme is used for both
+	// the REST calls and the event websocket.
+	URL      string `json:"url" yaml:"url"`
+	Username string `json:"username" yaml:"username"`
+	Password string `json:"password" yaml:"password"`
+	// App is the Stasis application the websocket subscribes to.
+	App string `json:"app" yaml:"app"`
+	// RequestTimeout bounds every REST call; 0 does not.
+	RequestTimeout time.Duration `json:"request_timeout,omitempty" yaml:"request_timeout,omitempty"`
+	// WebsocketCompression, StrictDecoding and DryRun, see Configuration.
+	WebsocketCompression bool `json:"websocket_compression,omitempty" yaml:"websocket_compression,omitempty"`
+	StrictDecoding       bool `json:"strict_decoding,omitempty" yaml:"strict_decoding,omitempty"`
+	DryRun               bool `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+	// PingInterval and PongTimeout, see Configuration; a zero PingInterval disables the
+	// keepalive.
+	PingInterval time.Duration `json:"ping_interval,omitempty" yaml:"ping_interval,omitempty"`
+	PongTimeout  time.Duration `json:"pong_timeout,omitempty" yaml:"pong_timeout,omitempty"`
+}
+
+// ReconnectConfig is the exponential backoff between websocket connection attempts.
+type ReconnectConfig struct {
+	InitialDelay time.Duration `json:"initial_delay" yaml:"initial_delay"`
+	MaxDelay     time.Duration `json:"max_delay" yaml:"max_delay"`
+	// Multiplier grows the delay after every failed attempt; at least 1.
+	Multiplier float64 `json:"multiplier" yaml:"multiplier"`
+	// MaxAttempts gives up after that many consecutive failures; 0 retries forever.
+	MaxAttempts int `json:"max_attempts,omitempty" yaml:"max_attempts,omitempty"`
+}
+
+// DispatcherConfig is how events are read and delivered.
+type DispatcherConfig struct {
+	// SubscriptionBuffer is the default buffer of EventBus subscriptions.
+	SubscriptionBuffer int `json:"subscription_buffer" yaml:"subscription_buffer"`
+	// PoolBuffers and MaxPooledBufferSize, see EventReader.
+	PoolBuffers         bool `json:"pool_buffers,omitempty" yaml:"pool_buffers,omitempty"`
+	MaxPooledBufferSize int  `json:"max_pooled_buffer_size" yaml:"max_pooled_buffer_size"`
+	// RawEvents, see Configuration.
+	RawEvents bool `json:"raw_events,omitempty" yaml:"raw_events,omitempty"`
+	// QuiesceWindow, see Configuration.
+	QuiesceWindow time.Duration `json:"quiesce_window" yaml:"quiesce_window"`
+}
+
+// MetricsConfig configures the measurements of the client helpers.
+type MetricsConfig struct {
+	// Enabled reports metrics to the Metrics of the client configuration.
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// ClockSkewThreshold is the skew with Asterisk above which the clock is reported as skewed.
+	ClockSkewThreshold time.Duration `json:"clock_skew_threshold" yaml:"clock_skew_threshold"`
+}
+
+// QueueConfig holds the defaults of the ACD queues, see Queue.
+type QueueConfig struct {
+	RingTimeout        time.Duration `json:"ring_timeout" yaml:"ring_timeout"`
+	RetryDelay         time.Duration `json:"retry_delay" yaml:"retry_delay"`
+	RecheckInterval    time.Duration `json:"recheck_interval" yaml:"recheck_interval"`
+	ServiceLevelTarget time.Duration `json:"service_level_target" yaml:"service_level_target"`
+	MohClass           string        `json:"moh_class,omitempty" yaml:"moh_class,omitempty"`
+	// AnnounceInterval is the interval of the position announcements; 0 disables them.
+	AnnounceInterval time.Duration `json:"announce_interval" yaml:"announce_interval"`
+	// WrapUp is the time after a call before an agent is available again, see AgentManager.
+	WrapUp time.Duration `json:"wrap_up,omitempty" yaml:"wrap_up,omitempty"`
+}
+
+// RecordingConfig holds the defaults of the recordings.
+type RecordingConfig struct {
+	Format string `json:"format" yaml:"format"`
+	// Rotate splits bridge recordings into chunks; zero does not, see RotateOpts.
+	Rotate RotateOpts `json:"rotate" yaml:"rotate"`
+	// IfExists is the policy for recording names already taken: fail, overwrite or append.
+	IfExists IfExists `json:"if_exists" yaml:"if_exists"`
+}
+
+// Defaults returns the configuration with the default of every setting. Only the transport must
+// be completed.
+func Defaults() *Config {
+	return &Config{
+		Reconnect: ReconnectConfig{
+			InitialDelay: time.Second,
+			MaxDelay:     time.Minute,
+			Multiplier:   2,
+		},
+		Dispatcher: DispatcherConfig{
+			SubscriptionBuffer:  DefaultSubscriptionBuffer,
+			MaxPooledBufferSize: DefaultMaxPooledBufferSize,
+			QuiesceWindow:       DefaultQuiesceWindow,
+		},
+		Metrics: MetricsConfig{
+			Enabled:            true,
+			ClockSkewThreshold: DefaultClockSkewThreshold,
+		},
+		Queue: QueueConfig{
+			RingTimeout:        DefaultQueueRingTimeout,
+			RetryDelay:         DefaultQueueRetryDelay,
+			RecheckInterval:    DefaultQueueRecheckInterval,
+			ServiceLevelTarget: DefaultServiceLevelTarget,
+			AnnounceInterval:   DefaultQueueAnnounceInterval,
+		},
+		Recording: RecordingConfig{
+			Format:   DefaultRecordingFormat,
+			IfExists: IfExistsFail,
+		},
+	}
+}
+
+// ConfigError lists every problem found by Config.Validate.
+type ConfigError struct {
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	return "invalid configuration: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate checks the whole configuration and returns a *ConfigError listing all the problems,
+// or nil.
+func (c *Config) Validate() error {
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+	positive := func(name string, d time.Duration) {
+		check(d > 0, "%s must be positive, got %v", name, d)
+	}
+	notNegative := func(name string, d time.Duration) {
+		check(d >= 0, "%s must not be negative, got %v", name, d)
+	}
+
+	t := c.Transport
+	if t.URL == "" {
+		check(false, "transport.url is required")
+	} else if u, err := url.Parse(t.URL); err != nil || u.Host == "" || !strings.Contains(" http https ws wss ", " "+u.Scheme+" ") {
+		check(false, "transport.url must be an http, https, ws or wss URL, got %q", t.URL)
+	}
+	check(t.Username != "", "transport.username is required")
+	check(t.App != "", "transport.app is required")
+	notNegative("transport.request_timeout", t.RequestTimeout)
+	notNegative("transport.ping_interval", t.PingInterval)
+	notNegative("transport.pong_timeout", t.PongTimeout)
+
+	r := c.Reconnect
+	positive("reconnect.initial_delay", r.InitialDelay)
+	check(r.MaxDelay >= r.InitialDelay, "reconnect.max_delay (%v) must not be less than reconnect.initial_delay (%v)", r.MaxDelay, r.InitialDelay)
+	check(r.Multiplier >= 1, "reconnect.multiplier must be at least 1, got %v", r.Multiplier)
+	check(r.MaxAttempts >= 0, "reconnect.max_attempts must not be negative, got %d", r.MaxAttempts)
+
+	d := c.Dispatcher
+	check(d.SubscriptionBuffer > 0, "dispatcher.subscription_buffer must be positive, got %d", d.SubscriptionBuffer)
+	check(d.MaxPooledBufferSize > 0, "dispatcher.max_pooled_buffer_size must be positive, got %d", d.MaxPooledBufferSize)
+	positive("dispatcher.quiesce_window", d.QuiesceWindow)
+
+	positive("metrics.clock_skew_threshold", c.Metrics.ClockSkewThreshold)
+
+	q := c.Queue
+	positive("queue.ring_timeout", q.RingTimeout)
+	notNegative("queue.retry_delay", q.RetryDelay)
+	positive("queue.recheck_interval", q.RecheckInterval)
+	positive("queue.service_level_target", q.ServiceLevelTarget)
+	notNegative("queue.announce_interval", q.AnnounceInterval)
+	notNegative("queue.wrap_up", q.WrapUp)
+
+	rec := c.Recording
+	if _, ok := recordingByteRates[rec.Format]; !ok {
+		check(false, "recording.format %q is not a known format", rec.Format)
+	}
+	notNegative("recording.rotate.every", rec.Rotate.Every)
+	check(rec.Rotate.MaxBytes >= 0, "recording.rotate.max_bytes must not be negative, got %d", rec.Rotate.MaxBytes)
+	check(rec.IfExists.Valid(), "recording.if_exists %q is not one of fail, overwrite or append", rec.IfExists)
+
+	if len(problems) > 0 {
+		return &ConfigError{Problems: problems}
+	}
+	return nil
+}
+
+// Configuration returns the client configuration of the transport and dispatcher settings.
+// Metrics, if enabled, must still be set on it. The credentials and the application are not part
+// of it: see Context, Apps and ManagedConnection.
+func (c *Config) Configuration() *Configuration {
+	cfg := NewConfiguration("/")
+	if u, err := url.Parse(c.Transport.URL); err == nil {
+		cfg.Host, cfg.Scheme = u.Host, u.Scheme
+	}
+	if c.Transport.RequestTimeout > 0 {
+		cfg.HTTPClient = &http.Client{Timeout: c.Transport.RequestTimeout}
+	}
+	cfg.WebsocketCompression = c.Transport.WebsocketCompression
+	cfg.StrictDecoding = c.Transport.StrictDecoding
+	cfg.DryRun = c.Transport.DryRun
+	cfg.PingInterval = c.Transport.PingInterval
+	cfg.PongTimeout = c.Transport.PongTimeout
+	cfg.RawEvents = c.Dispatcher.RawEvents
+	cfg.QuiesceWindow = c.Dispatcher.QuiesceWindow
+	cfg.EnableExperimental(c.Experimental...)
+	return cfg
+}
+
+// Context returns ctx carrying the credentials of the transport, for the REST calls and the
+// websocket connection.
+func (c *Config) Context(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ContextBasicAuth, BasicAuth{UserName: c.Transport.Username, Password: c.Transport.Password})
+}
+
+// Apps returns the Stasis applications the websocket subscribes to.
+func (c *Config) Apps() []string {
+	return []string{c.Transport.App}
+}
+
+// ManagedConnection returns the connection of client receiving the events of the application with
+// the credentials, reconnect backoff and dispatcher settings of the configuration.
+func (c *Config) ManagedConnection(client *APIClient) *ManagedConnection {
+	m := client.WebsocketApi.NewManagedConnection(c.Apps(), c.Transport.Username, c.Transport.Password)
+	m.Reconnect = c.Reconnect
+	m.PoolBuffers = c.Dispatcher.PoolBuffers
+	return m
+}
+
+// Delay returns the backoff before the given attempt, the first retry being attempt 1.
+func (r ReconnectConfig) Delay(attempt int) time.Duration {
+	d := float64(r.InitialDelay)
+	for i := 1; i < attempt && d < float64(r.MaxDelay); i++ {
+		d *= r.Multiplier
+	}
+	if d > float64(r.MaxDelay) {
+		return r.MaxDelay
+	}
+	return time.Duration(d)
+}
+
+// Apply sets the queue settings on q, before it is used.
+func (c QueueConfig) Apply(q *Queue) {
+	q.RingTimeout = c.RingTimeout
+	q.RetryDelay = c.RetryDelay
+	q.RecheckInterval = c.RecheckInterval
+	q.ServiceLevelTarget = c.ServiceLevelTarget
+	q.MohClass = c.MohClass
+	if c.AnnounceInterval > 0 {
+		if q.Announcements == nil {
+			q.Announcements = DefaultQueueAnnouncements()
+		}
+		q.Announcements.Interval = c.AnnounceInterval
+	} else {
+		q.Announcements = nil
+	}
+}
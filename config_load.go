@@ -0,0 +1,70 @@
+package asterisk_ari_go
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configEnvRef matches the "${NAME}" and "${NAME:-default}" references of a config file.
+var configEnvRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// LoadConfig reads a YAML (.yaml, .yml) or JSON (.json) config file over Defaults and validates
+// it. References to environment variables, "${ARI_PASSWORD}" or "${ARI_URL:-ws://localhost:8088}"
+// with a default, are replaced before parsing; a variable that is unset and has no default is an
+// error. Durations are written as "20s" or "1m30s", and unknown keys are rejected so that typos
+// do not go unnoticed.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+	default:
+		return nil, fmt.Errorf("config %s: unsupported format, expected .yaml, .yml or .json", path)
+	}
+	data, err = expandConfigEnv(data)
+	if err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+
+	cfg := Defaults()
+	// JSON is a subset of YAML, so one decoder reads both, durations included.
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// expandConfigEnv replaces the references to environment variables.
+func expandConfigEnv(data []byte) ([]byte, error) {
+	var missing []string
+	expanded := configEnvRef.ReplaceAllFunc(data, func(ref []byte) []byte {
+		m := configEnvRef.FindSubmatch(ref)
+		if value, ok := os.LookupEnv(string(m[1])); ok {
+			return []byte(value)
+		}
+		if m[2] != nil {
+			return m[2]
+		}
+		missing = append(missing, string(m[1]))
+		return ref
+	})
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("environment variables not set: %s", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
@@ -0,0 +1,82 @@
+package asterisk_ari_go
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigYAML(t *testing.T) {
+	t.Setenv("TEST_ARI_PASSWORD", "secret")
+	path := writeConfig(t, "ari.yaml", `
+transport:
+  url: ${TEST_ARI_URL:-ws://localhost:8088}
+  username: asterisk
+  password: ${TEST_ARI_PASSWORD}
+  app: ivr
+  request_timeout: 20s
+reconnect:
+  max_delay: 1m30s
+`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := cfg.Transport
+	if tr.URL != "ws://localhost:8088" || tr.Password != "secret" || tr.RequestTimeout != 20*time.Second {
+		t.Errorf("transport = %+v, want the default URL, the password from the environment and 20s", tr)
+	}
+	// Keys absent from the file keep their defaults.
+	if cfg.Reconnect.MaxDelay != 90*time.Second || cfg.Reconnect.InitialDelay != Defaults().Reconnect.InitialDelay {
+		t.Errorf("reconnect = %+v, want max_delay 1m30s over the defaults", cfg.Reconnect)
+	}
+}
+
+func TestLoadConfigJSON(t *testing.T) {
+	path := writeConfig(t, "ari.json", `{"transport": {"url": "http://pbx:8088", "username": "u", "app": "a", "ping_interval": "5s"}}`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Transport.URL != "http://pbx:8088" || cfg.Transport.PingInterval != 5*time.Second {
+		t.Errorf("transport = %+v", cfg.Transport)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	os.Unsetenv("TEST_ARI_MISSING")
+	valid := "transport: {url: 'ws://pbx:8088', username: u, app: a}\n"
+	for _, tc := range []struct {
+		name, file, content, want string
+	}{
+		{"format", "ari.toml", valid, "unsupported format"},
+		{"env", "ari.yaml", "transport: {url: '${TEST_ARI_MISSING}', username: u, app: a}", "TEST_ARI_MISSING"},
+		{"unknown key", "ari.yaml", valid + "transprot: {}\n", "transprot"},
+		{"invalid", "ari.yaml", "transport: {url: 'ftp://pbx', app: a}", "transport.url"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadConfig(writeConfig(t, tc.file, tc.content))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("err = %v, want one mentioning %q", err, tc.want)
+			}
+		})
+	}
+
+	_, err := LoadConfig(writeConfig(t, "ari.yaml", "transport: {url: 'ftp://pbx', app: a}"))
+	var invalid *ConfigError
+	if !errors.As(err, &invalid) || len(invalid.Problems) != 2 {
+		t.Errorf("err = %v, want a *ConfigError with the URL and username problems", err)
+	}
+}
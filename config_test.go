@@ -0,0 +1,60 @@
+package asterisk_ari_go
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigValidate(t *testing.T) {
+	cfg := Defaults()
+	err := cfg.Validate()
+	var invalid *ConfigError
+	if !errors.As(err, &invalid) || len(invalid.Problems) != 3 {
+		t.Fatalf("err = %v, want the url, username and app required", err)
+	}
+
+	cfg.Transport = TransportConfig{URL: "wss://pbx:8089", Username: "u", App: "ivr"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("defaults with a transport: %v", err)
+	}
+
+	cfg.Reconnect.MaxDelay = time.Millisecond
+	cfg.Queue.RingTimeout = 0
+	cfg.Recording.Format = "mp4"
+	err = cfg.Validate()
+	if !errors.As(err, &invalid) || len(invalid.Problems) != 3 {
+		t.Fatalf("err = %v, want three problems", err)
+	}
+	for _, field := range []string{"reconnect.max_delay", "queue.ring_timeout", "recording.format"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("%v does not mention %s", err, field)
+		}
+	}
+}
+
+func TestConfigConfiguration(t *testing.T) {
+	cfg := Defaults()
+	cfg.Transport = TransportConfig{URL: "https://pbx:8089", Username: "u", App: "ivr", RequestTimeout: 3 * time.Second, DryRun: true}
+	cfg.Experimental = []string{ExperimentalOutboundWebsocket}
+
+	c := cfg.Configuration()
+	if c.Host != "pbx:8089" || c.Scheme != "https" || !c.DryRun || c.HTTPClient.Timeout != 3*time.Second {
+		t.Errorf("configuration = %s://%s dry run %v timeout %v", c.Scheme, c.Host, c.DryRun, c.HTTPClient.Timeout)
+	}
+	client := NewAPIClient(c, NewStdLogger(ioutil.Discard))
+	if !client.Experimental(ExperimentalOutboundWebsocket) || client.Experimental(ExperimentalRESTOverWebsocket) {
+		t.Errorf("experimental = %v, want only %s", client.Flags().List(), ExperimentalOutboundWebsocket)
+	}
+}
+
+func TestReconnectDelay(t *testing.T) {
+	r := ReconnectConfig{InitialDelay: time.Second, MaxDelay: 10 * time.Second, Multiplier: 2}
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 4: 8 * time.Second, 5: 10 * time.Second, 50: 10 * time.Second} {
+		if got := r.Delay(attempt); got != want {
+			t.Errorf("Delay(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
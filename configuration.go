@@ -12,8 +12,13 @@ package asterisk_ari_go
 import (
 	"net/http"
 	"regexp"
+	"time"
 )
 
+// ARIVersion is the version of the ARI specification the API services and models were generated
+// from, api/swagger.yaml.
+const ARIVersion = "6.0.0"
+
 // contextKeys are used to identify the type of value in the context.
 // Since these are string, it is possible to get a short description of the
 // context key for logging and debugging using key.String().
@@ -57,6 +62,47 @@ type Configuration struct {
 	DefaultHeader map[string]string `json:"defaultHeader,omitempty"`
 	UserAgent     string            `json:"userAgent,omitempty"`
 	HTTPClient    *http.Client
+	// Metrics receives measurements from the client helpers. Nil disables metrics.
+	Metrics Metrics
+	// WebsocketCompression negotiates permessage-deflate on the event websocket. It reduces the
+	// bandwidth used by large StasisStart and ChannelVarset payloads at the cost of some CPU.
+	// Asterisk falls back to uncompressed frames if it does not support the extension.
+	WebsocketCompression bool `json:"websocketCompression,omitempty"`
+	// PingInterval, if set, pings the event websocket at that interval, and PongTimeout is how
+	// long a pong is awaited, DefaultPongTimeout if not set. A connection that stays silent
+	// longer than both fails its read, so that dead TCP links behind NATs and firewalls are
+	// detected and reconnected instead of hanging forever.
+	PingInterval time.Duration `json:"pingInterval,omitempty"`
+	PongTimeout  time.Duration `json:"pongTimeout,omitempty"`
+	// StrictDecoding makes REST responses and events fail to decode when they contain fields
+	// this library does not model, or values of an unexpected type. Meant for development, to
+	// discover what a newer Asterisk emits; leave it off in production.
+	StrictDecoding bool `json:"strictDecoding,omitempty"`
+	// RawEvents keeps the original JSON payload of every event read by EventReader in
+	// StasisEvent.Raw, so that handlers can forward it downstream exactly as Asterisk sent it.
+	RawEvents bool `json:"rawEvents,omitempty"`
+	// QuiesceWindow is how long APIClient.Quiesce waits without activity. Defaults to
+	// DefaultQuiesceWindow.
+	QuiesceWindow time.Duration `json:"quiesceWindow,omitempty"`
+	// Clock is the source of time of the client helpers. Nil uses RealClock.
+	Clock Clock `json:"-"`
+	// AuditSink, if set, records every mutating REST call.
+	AuditSink AuditSink `json:"-"`
+	// DryRun logs and audits mutating REST calls instead of sending them, and answers them with a
+	// synthesized success. Reads are still sent. Meant to validate new call-flow logic against a
+	// production event stream without affecting live calls.
+	DryRun bool `json:"dryRun,omitempty"`
+	// PlayConflictWait, if set, retries the plays on channels answered with 409 Conflict, because
+	// the channel is not answered yet or busy with another operation, for up to this long instead
+	// of failing. See WithPlayConflictWait to set it per call.
+	PlayConflictWait time.Duration `json:"playConflictWait,omitempty"`
+	// ChannelCache, if set and fed with every event, makes the operations on a channel it saw
+	// destroyed fail with ErrChannelGone without a REST round trip. An operation answered with a
+	// 404 because the channel was destroyed while it was in flight fails with ErrChannelGone too.
+	ChannelCache *ChannelCache `json:"-"`
+	// Flags gate experimental behaviors, see EnableExperimental. NewAPIClient creates them if
+	// nil.
+	Flags *Flags `json:"-"`
 }
 
 // NewConfiguration creates a new Configuration object to be passed to the client.
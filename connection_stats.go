@@ -0,0 +1,176 @@
+package asterisk_ari_go
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultRateWindow is the window the event rates of ConnectionStats are computed over.
+const DefaultRateWindow = time.Second
+
+// ConnectionThroughput is a snapshot of the throughput of a websocket connection.
+type ConnectionThroughput struct {
+	ConnectionId string
+	// EventsPerSecond and DecodeErrorsPerSecond are the rates over the last complete window.
+	EventsPerSecond       float64
+	DecodeErrorsPerSecond float64
+	// Events and DecodeErrors are the totals since the connection was opened.
+	Events       uint64
+	DecodeErrors uint64
+	// QueueDepth is the number of events read but not dispatched yet, as last reported.
+	QueueDepth int
+	// Lag is the delay between the Asterisk timestamp of the last dispatched event and the start
+	// of its handler.
+	Lag time.Duration
+}
+
+// ConnectionStats measures how well the application keeps up with the events of a websocket
+// connection. EventReader counts the events and decode errors it reads and EventReader.Dispatch
+// reports the depth of its queue; the handling loop calls HandlerStart before handling each event.
+// The rates are refreshed every window while Dispatch runs, and when read.
+//
+// The measurements are exported as gauges labelled with the connection: "ari_events_per_second",
+// "ari_event_decode_errors_per_second", "ari_dispatch_queue_depth" and "ari_event_lag_seconds".
+// The totals are counted in "ari_events_received_total" and "ari_event_decode_errors_total".
+// A lag growing steadily means the application is falling behind Asterisk.
+type ConnectionStats struct {
+	client *APIClient
+	labels map[string]string
+
+	// RateWindow is the window the rates are computed over. Defaults to DefaultRateWindow.
+	RateWindow time.Duration
+	// Skew, if set, corrects the event timestamps to the local clock, so that the lag of an
+	// application running on a different host than Asterisk is not inflated by the clock skew.
+	Skew *ClockSkew
+
+	mu                         sync.Mutex
+	snapshot                   ConnectionThroughput
+	windowStart                time.Time
+	windowEvents, windowErrors uint64
+}
+
+// NewConnectionStats creates the stats of the connection connectionId.
+func NewConnectionStats(client *APIClient, connectionId string) *ConnectionStats {
+	return &ConnectionStats{
+		client:     client,
+		labels:     map[string]string{"connection": connectionId},
+		RateWindow: DefaultRateWindow,
+		snapshot:   ConnectionThroughput{ConnectionId: connectionId},
+	}
+}
+
+// recordEvent counts an event read from the connection.
+func (s *ConnectionStats) recordEvent() {
+	s.mu.Lock()
+	s.snapshot.Events++
+	s.windowEvents++
+	s.rollLocked()
+	s.mu.Unlock()
+	s.client.metrics().IncCounter("ari_events_received_total", s.labels, 1)
+}
+
+// recordDecodeError counts a frame of the connection that could not be decoded.
+func (s *ConnectionStats) recordDecodeError() {
+	s.mu.Lock()
+	s.snapshot.DecodeErrors++
+	s.windowErrors++
+	s.rollLocked()
+	s.mu.Unlock()
+	s.client.metrics().IncCounter("ari_event_decode_errors_total", s.labels, 1)
+}
+
+// window returns the window the rates are computed over.
+func (s *ConnectionStats) window() time.Duration {
+	if s.RateWindow <= 0 {
+		return DefaultRateWindow
+	}
+	return s.RateWindow
+}
+
+// refreshRates publishes the rates every window, so that they drop when the events stop, until
+// the returned function is called; it returns once the rates are no longer published.
+func (s *ConnectionStats) refreshRates() func() {
+	stop, done := make(chan struct{}), make(chan struct{})
+	s.client.goTracked("connection_stats", func() {
+		defer close(done)
+		timer := s.client.clock().NewTimer(s.window())
+		defer timer.Stop()
+		for {
+			select {
+			case <-timer.C():
+				s.mu.Lock()
+				s.rollLocked()
+				s.mu.Unlock()
+				timer.Reset(s.window())
+			case <-stop:
+				return
+			}
+		}
+	})
+	return func() {
+		close(stop)
+		<-done
+	}
+}
+
+// rollLocked publishes the rates when the window is complete and starts a new one.
+func (s *ConnectionStats) rollLocked() {
+	now := s.client.clock().Now()
+	if s.windowStart.IsZero() {
+		s.windowStart = now
+		return
+	}
+	elapsed := now.Sub(s.windowStart)
+	if elapsed < s.window() {
+		return
+	}
+	s.snapshot.EventsPerSecond = float64(s.windowEvents) / elapsed.Seconds()
+	s.snapshot.DecodeErrorsPerSecond = float64(s.windowErrors) / elapsed.Seconds()
+	s.windowStart, s.windowEvents, s.windowErrors = now, 0, 0
+	s.client.metrics().SetGauge("ari_events_per_second", s.labels, s.snapshot.EventsPerSecond)
+	s.client.metrics().SetGauge("ari_event_decode_errors_per_second", s.labels, s.snapshot.DecodeErrorsPerSecond)
+}
+
+// SetQueueDepth reports the number of events read from the connection but not dispatched yet.
+func (s *ConnectionStats) SetQueueDepth(n int) {
+	s.mu.Lock()
+	s.snapshot.QueueDepth = n
+	s.mu.Unlock()
+	s.client.metrics().SetGauge("ari_dispatch_queue_depth", s.labels, float64(n))
+}
+
+// HandlerStart measures the lag of an event whose handler is starting. Events without a
+// timestamp are ignored.
+func (s *ConnectionStats) HandlerStart(ev StasisEvent) {
+	if ev.Timestamp.Timestamp.IsZero() {
+		return
+	}
+	at := ev.Timestamp.Timestamp
+	if s.Skew != nil {
+		at = s.Skew.Correct(at)
+	}
+	lag := s.client.clock().Now().Sub(at)
+	if lag < 0 {
+		lag = 0
+	}
+	s.mu.Lock()
+	s.snapshot.Lag = lag
+	s.mu.Unlock()
+	s.client.metrics().SetGauge("ari_event_lag_seconds", s.labels, lag.Seconds())
+}
+
+// Throughput returns the current measurements.
+func (s *ConnectionStats) Throughput() ConnectionThroughput {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rollLocked()
+	return s.snapshot
+}
+
+// Close zeroes the gauges of a closed connection, so that they do not report stale values.
+func (s *ConnectionStats) Close() {
+	m := s.client.metrics()
+	for _, name := range []string{"ari_events_per_second", "ari_event_decode_errors_per_second", "ari_dispatch_queue_depth", "ari_event_lag_seconds"} {
+		m.SetGauge(name, s.labels, 0)
+	}
+}
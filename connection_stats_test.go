@@ -0,0 +1,30 @@
+package asterisk_ari_go
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+// TestConnectionStatsRatesDecay covers a connection going quiet: the rates must drop to zero
+// instead of freezing at the last busy window.
+func TestConnectionStatsRatesDecay(t *testing.T) {
+	cfg := NewConfiguration("/")
+	clock := NewFakeClock(time.Unix(0, 0))
+	cfg.Clock = clock
+	stats := NewConnectionStats(NewAPIClient(cfg, NewStdLogger(ioutil.Discard)), "conn-1")
+
+	stats.Throughput()
+	for i := 0; i < 10; i++ {
+		clock.Advance(100 * time.Millisecond)
+		stats.recordEvent()
+	}
+	if rate := stats.Throughput().EventsPerSecond; rate != 10 {
+		t.Fatalf("busy rate = %v, want 10", rate)
+	}
+
+	clock.Advance(2 * time.Second)
+	if rate := stats.Throughput().EventsPerSecond; rate != 0 {
+		t.Errorf("quiet rate = %v, want 0", rate)
+	}
+}
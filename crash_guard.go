@@ -0,0 +1,59 @@
+package asterisk_ari_go
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// CrashReport describes a panic of an event handler.
+type CrashReport struct {
+	Handler string      `json:"handler"`
+	Event   StasisEvent `json:"event"`
+	Panic   string      `json:"panic"`
+	Stack   string      `json:"stack"`
+	Time    time.Time   `json:"time"`
+}
+
+// CrashGuard isolates event handlers from each other: a panicking handler is recovered, the panic
+// is reported and the event loop carries on with the next event. Wrap every handler with it.
+type CrashGuard struct {
+	client *APIClient
+
+	// OnCrash receives the report of every recovered panic, e.g. to send it to an error tracker.
+	// Reports are logged and counted in ari_handler_panics_total either way.
+	OnCrash func(r CrashReport)
+}
+
+// NewCrashGuard creates a guard.
+func NewCrashGuard(client *APIClient) *CrashGuard {
+	return &CrashGuard{client: client}
+}
+
+// Wrap returns a handler calling next and recovering its panics. name identifies the handler in
+// the reports.
+func (g *CrashGuard) Wrap(name string, next func(StasisEvent)) func(StasisEvent) {
+	return func(ev StasisEvent) {
+		defer func() {
+			if p := recover(); p != nil {
+				g.report(name, ev, p)
+			}
+		}()
+		next(ev)
+	}
+}
+
+func (g *CrashGuard) report(name string, ev StasisEvent, p interface{}) {
+	r := CrashReport{
+		Handler: name,
+		Event:   ev,
+		Panic:   fmt.Sprint(p),
+		Stack:   string(debug.Stack()),
+		Time:    g.client.clock().Now(),
+	}
+	g.client.logger.Errorf("crash guard: handler %s panicked on %s event of channel %s: %s\n%s", name, ev.Type, ev.Channel.Id, r.Panic, r.Stack)
+	g.client.metrics().IncCounter("ari_handler_panics_total", map[string]string{"handler": name}, 1)
+	if g.OnCrash != nil {
+		g.OnCrash(r)
+	}
+}
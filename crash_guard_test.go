@@ -0,0 +1,41 @@
+package asterisk_ari_go
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCrashGuard(t *testing.T) {
+	now := time.Unix(1000, 0)
+	cfg := NewConfiguration("/")
+	cfg.Clock = NewFakeClock(now)
+	guard := NewCrashGuard(NewAPIClient(cfg, NewStdLogger(ioutil.Discard)))
+	var reports []CrashReport
+	guard.OnCrash = func(r CrashReport) { reports = append(reports, r) }
+
+	var handled []string
+	handler := guard.Wrap("ivr", func(ev StasisEvent) {
+		if ev.Channel.Id == "bad" {
+			panic("nil menu")
+		}
+		handled = append(handled, ev.Channel.Id)
+	})
+	handler(StasisEvent{Type: "StasisStart", Channel: Channel{Id: "bad"}})
+	handler(StasisEvent{Type: "StasisStart", Channel: Channel{Id: "good"}})
+
+	if len(handled) != 1 || handled[0] != "good" {
+		t.Errorf("handled %q, want the event after the panic", handled)
+	}
+	if len(reports) != 1 {
+		t.Fatalf("%d reports, want 1", len(reports))
+	}
+	r := reports[0]
+	if r.Handler != "ivr" || r.Event.Channel.Id != "bad" || r.Panic != "nil menu" || !r.Time.Equal(now) {
+		t.Errorf("report = %s %s %q %v", r.Handler, r.Event.Channel.Id, r.Panic, r.Time)
+	}
+	if !strings.Contains(r.Stack, "TestCrashGuard") {
+		t.Errorf("stack does not reach the handler:\n%s", r.Stack)
+	}
+}
@@ -0,0 +1,162 @@
+package asterisk_ari_go
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DiagnosticsService groups helpers for debugging calls and the Asterisk connection.
+type DiagnosticsService service
+
+// LogSessionOpts holds the optional parameters of StartLogSession.
+type LogSessionOpts struct {
+	// Levels is the log channel configuration, e.g. "notice,warning,error,verbose(5)".
+	// Defaults to "notice,warning,error,verbose".
+	Levels string
+	// Dir is the Asterisk log directory as seen from this process (usually /var/log/asterisk
+	// on the same host or a shared mount). When empty the log channel is created but not tailed.
+	Dir string
+	// Match limits tailed lines to the ones containing at least one of these strings,
+	// e.g. a channel name or a SIP Call-ID. All lines are delivered when empty.
+	Match []string
+	// PollInterval is how often the log file is checked for new lines. Defaults to 250ms.
+	PollInterval time.Duration
+}
+
+// LogSession is a temporary Asterisk log channel created for a debugging session.
+type LogSession struct {
+	// Name of the log channel, relative to the Asterisk log directory.
+	Name string
+	// Path of the tailed log file, empty if the log is not tailed.
+	Path string
+	// Lines delivers the matching log lines. It is closed when the session ends.
+	// Nil when the log is not tailed.
+	Lines <-chan string
+
+	client *APIClient
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// StartLogSession creates a temporary log channel in Asterisk and, if opts.Dir is set, tails it.
+// The session must be closed with Close to remove the log channel again.
+func (a *DiagnosticsService) StartLogSession(ctx context.Context, opts *LogSessionOpts) (*LogSession, error) {
+	if opts == nil {
+		opts = &LogSessionOpts{}
+	}
+	levels := opts.Levels
+	if levels == "" {
+		levels = "notice,warning,error,verbose"
+	}
+
+	name := newResourceId("ari-diag") + ".log"
+	if _, err := a.client.AsteriskApi.AddLog(ctx, name, levels); err != nil {
+		return nil, err
+	}
+
+	tailCtx, cancel := context.WithCancel(context.Background())
+	s := &LogSession{Name: name, client: a.client, cancel: cancel, done: make(chan struct{})}
+	if opts.Dir == "" {
+		close(s.done)
+		return s, nil
+	}
+
+	lines := make(chan string, 256)
+	s.Path = filepath.Join(opts.Dir, name)
+	s.Lines = lines
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = 250 * time.Millisecond
+	}
+	a.client.goTracked("log_session", func() { s.tail(tailCtx, lines, opts.Match, interval) })
+	return s, nil
+}
+
+// Close stops tailing and deletes the log channel from Asterisk.
+func (s *LogSession) Close(ctx context.Context) error {
+	var err error
+	s.once.Do(func() {
+		s.cancel()
+		<-s.done
+		_, err = s.client.AsteriskApi.DeleteLog(ctx, s.Name)
+	})
+	return err
+}
+
+// tail follows the log file until ctx is done, sending matching lines.
+func (s *LogSession) tail(ctx context.Context, lines chan<- string, match []string, interval time.Duration) {
+	defer close(s.done)
+	defer close(lines)
+
+	timer := s.client.clock().NewTimer(interval)
+	defer timer.Stop()
+
+	var file *os.File
+	var reader *bufio.Reader
+	var partial string
+	defer func() {
+		if file != nil {
+			file.Close()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C():
+			timer.Reset(interval)
+		}
+
+		if file == nil {
+			f, err := os.Open(s.Path)
+			if err != nil {
+				// Asterisk creates the file with the first logged line.
+				continue
+			}
+			file, reader = f, bufio.NewReader(f)
+		}
+
+		for {
+			chunk, err := reader.ReadString('\n')
+			partial += chunk
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				s.client.logger.Warnf("diagnostics: failed to read %s: %v", s.Path, err)
+				return
+			}
+			line := strings.TrimRight(partial, "\r\n")
+			partial = ""
+			if !matchesAny(line, match) {
+				continue
+			}
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// matchesAny reports whether line contains one of patterns, or patterns is empty.
+func matchesAny(line string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if strings.Contains(line, p) {
+			return true
+		}
+	}
+	return false
+}
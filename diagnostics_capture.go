@@ -0,0 +1,133 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// DefaultDiagnosticVariables are the channel variables captured by Capture when none are given.
+var DefaultDiagnosticVariables = []string{
+	"HANGUPCAUSE",
+	"DIALSTATUS",
+	"CHANNEL(language)",
+	"CHANNEL(audioreadformat)",
+	"CHANNEL(audiowriteformat)",
+	"CHANNEL(pjsip,remote_addr)",
+	"CHANNEL(pjsip,call-id)",
+}
+
+// CaptureOpts holds the optional parameters of Capture.
+type CaptureOpts struct {
+	// Variables to read from the channel. Defaults to DefaultDiagnosticVariables.
+	Variables []string
+	// Journal provides the recent events of the channel. Optional.
+	Journal *EventJournal
+}
+
+// DiagnosticBundle gathers everything known about a channel for a support ticket.
+type DiagnosticBundle struct {
+	CapturedAt    time.Time         `json:"captured_at"`
+	ChannelId     string            `json:"channel_id"`
+	Channel       *Channel          `json:"channel,omitempty"`
+	Variables     map[string]string `json:"variables,omitempty"`
+	RtpStatistics *RtPstat          `json:"rtp_statistics,omitempty"`
+	Events        []StasisEvent     `json:"events,omitempty"`
+	AsteriskInfo  *AsteriskInfo     `json:"asterisk_info,omitempty"`
+	// Errors lists the sections that could not be captured with the reason, e.g. {"rtp_statistics": "404 Not Found"}.
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// JSON returns the bundle as indented JSON.
+func (b *DiagnosticBundle) JSON() ([]byte, error) {
+	return json.MarshalIndent(b, "", "  ")
+}
+
+// Capture gathers channel details, variables, RTP statistics, recent events and Asterisk
+// information into a single bundle. Sections that fail are recorded in Errors rather than
+// failing the whole capture, since the channel may already be gone.
+func (a *DiagnosticsService) Capture(ctx context.Context, channelId string, opts *CaptureOpts) (*DiagnosticBundle, error) {
+	if opts == nil {
+		opts = &CaptureOpts{}
+	}
+	variables := opts.Variables
+	if variables == nil {
+		variables = DefaultDiagnosticVariables
+	}
+
+	bundle := &DiagnosticBundle{
+		CapturedAt: a.client.clock().Now(),
+		ChannelId:  channelId,
+		Variables:  make(map[string]string),
+		Errors:     make(map[string]string),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	fail := func(section string, err error) {
+		mu.Lock()
+		bundle.Errors[section] = err.Error()
+		mu.Unlock()
+	}
+	run := func(f func()) {
+		wg.Add(1)
+		a.client.goTracked("diagnostics_capture", func() {
+			defer wg.Done()
+			f()
+		})
+	}
+
+	run(func() {
+		channel, _, err := a.client.ChannelsApi.Getchannel(ctx, channelId)
+		if err != nil {
+			fail("channel", err)
+			return
+		}
+		mu.Lock()
+		bundle.Channel = &channel
+		mu.Unlock()
+	})
+	run(func() {
+		stats, _, err := a.client.ChannelsApi.Rtpstatistics(ctx, channelId)
+		if err != nil {
+			fail("rtp_statistics", err)
+			return
+		}
+		mu.Lock()
+		bundle.RtpStatistics = &stats
+		mu.Unlock()
+	})
+	run(func() {
+		info, _, err := a.client.AsteriskApi.GetInfo(ctx, nil)
+		if err != nil {
+			fail("asterisk_info", err)
+			return
+		}
+		mu.Lock()
+		bundle.AsteriskInfo = &info
+		mu.Unlock()
+	})
+	for _, name := range variables {
+		name := name
+		run(func() {
+			v, _, err := a.client.ChannelsApi.GetChannelVar(ctx, channelId, name)
+			if err != nil {
+				fail("variables."+name, err)
+				return
+			}
+			mu.Lock()
+			bundle.Variables[name] = v.Value
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+
+	if opts.Journal != nil {
+		bundle.Events = opts.Journal.ForChannel(channelId)
+	}
+	if len(bundle.Errors) == 0 {
+		bundle.Errors = nil
+	}
+	return bundle, ctx.Err()
+}
@@ -0,0 +1,165 @@
+package asterisk_ari_go
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidDestination is returned (wrapped) when a destination cannot be turned into a dial string.
+var ErrInvalidDestination = errors.New("invalid destination")
+
+var (
+	numberSeparators = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "")
+	digitsOnly       = regexp.MustCompile(`^[0-9]+$`)
+	endpointName     = regexp.MustCompile(`^[A-Za-z0-9_.+-]+$`)
+	techPrefix       = regexp.MustCompile(`^[A-Za-z0-9]+/`)
+)
+
+// minNumberDigits is the number of digits from which a destination of bare digits is a
+// telephone number rather than a numeric endpoint such as "1001".
+const minNumberDigits = 7
+
+// DestinationKind classifies a logical destination.
+type DestinationKind int
+
+const (
+	// DestinationEndpoint is a bare endpoint name such as "alice" or "1001".
+	DestinationEndpoint DestinationKind = iota
+	// DestinationNumber is a telephone number such as "+15551234567" or "tel:+15551234567".
+	DestinationNumber
+	// DestinationURI is a SIP URI such as "sip:alice@example.com".
+	DestinationURI
+	// DestinationExtension is a dialplan location such as "1001@from-internal".
+	DestinationExtension
+	// DestinationDialString is already an Asterisk dial string such as "PJSIP/alice".
+	DestinationDialString
+)
+
+// Destination is a parsed logical destination.
+type Destination struct {
+	Kind DestinationKind
+	// Value is the number (E.164 with "+" when it could be normalized), URI, extension,
+	// endpoint name or dial string.
+	Value string
+	// Context is the dialplan context of a DestinationExtension.
+	Context string
+}
+
+// NormalizeNumber removes formatting characters from number and converts it to E.164 where possible:
+// "00" international prefixes become "+", and national numbers get "+" and defaultCountryCode
+// prepended when defaultCountryCode is not empty.
+func NormalizeNumber(number string, defaultCountryCode string) (string, error) {
+	n := strings.TrimSpace(number)
+	if len(n) >= 4 && strings.EqualFold(n[:4], "tel:") {
+		n = n[4:]
+	}
+	n = numberSeparators.Replace(n)
+	switch {
+	case strings.HasPrefix(n, "+"):
+		n = "+" + strings.TrimPrefix(n, "+")
+	case strings.HasPrefix(n, "00"):
+		n = "+" + strings.TrimPrefix(n, "00")
+	case defaultCountryCode != "":
+		n = "+" + strings.TrimPrefix(defaultCountryCode, "+") + strings.TrimPrefix(n, "0")
+	}
+	if !digitsOnly.MatchString(strings.TrimPrefix(n, "+")) {
+		return "", fmt.Errorf("%w: %q is not a telephone number", ErrInvalidDestination, number)
+	}
+	return n, nil
+}
+
+// ParseDestination classifies dest. Numbers are normalized with NormalizeNumber using
+// defaultCountryCode. Bare digits are a number from 7 digits on; shorter ones, such as "1001",
+// are an endpoint.
+func ParseDestination(dest string, defaultCountryCode string) (Destination, error) {
+	dest = strings.TrimSpace(dest)
+	lower := strings.ToLower(dest)
+	switch {
+	case dest == "":
+		return Destination{}, fmt.Errorf("%w: empty destination", ErrInvalidDestination)
+	case strings.HasPrefix(lower, "sip:") || strings.HasPrefix(lower, "sips:"):
+		if !strings.Contains(dest, "@") && !strings.Contains(dest[strings.Index(dest, ":")+1:], ".") {
+			return Destination{}, fmt.Errorf("%w: %q has no host", ErrInvalidDestination, dest)
+		}
+		return Destination{Kind: DestinationURI, Value: dest}, nil
+	case strings.HasPrefix(lower, "tel:") || strings.HasPrefix(dest, "+"):
+		n, err := NormalizeNumber(dest, "")
+		return Destination{Kind: DestinationNumber, Value: n}, err
+	case techPrefix.MatchString(dest):
+		if strings.HasSuffix(dest, "/") {
+			return Destination{}, fmt.Errorf("%w: %q has no resource", ErrInvalidDestination, dest)
+		}
+		return Destination{Kind: DestinationDialString, Value: dest}, nil
+	case strings.Count(dest, "@") == 1:
+		parts := strings.SplitN(dest, "@", 2)
+		if parts[0] == "" || parts[1] == "" {
+			return Destination{}, fmt.Errorf("%w: %q is not extension@context", ErrInvalidDestination, dest)
+		}
+		return Destination{Kind: DestinationExtension, Value: parts[0], Context: parts[1]}, nil
+	case isBareNumber(dest):
+		n, err := NormalizeNumber(dest, defaultCountryCode)
+		return Destination{Kind: DestinationNumber, Value: n}, err
+	case endpointName.MatchString(dest):
+		return Destination{Kind: DestinationEndpoint, Value: dest}, nil
+	}
+	return Destination{}, fmt.Errorf("%w: cannot parse %q", ErrInvalidDestination, dest)
+}
+
+// isBareNumber reports whether dest, without "+" or "tel:", is a telephone number.
+func isBareNumber(dest string) bool {
+	digits := numberSeparators.Replace(dest)
+	if !digitsOnly.MatchString(digits) {
+		return false
+	}
+	// Formatting such as "(555) 1234" is no endpoint name.
+	return len(digits) >= minNumberDigits || !endpointName.MatchString(dest)
+}
+
+// DialStringBuilder converts logical destinations into ARI endpoint strings.
+type DialStringBuilder struct {
+	// Tech is the channel technology used for endpoints and URIs. Defaults to "PJSIP".
+	Tech string
+	// DefaultCountryCode is used to normalize national numbers, e.g. "1".
+	DefaultCountryCode string
+	// URIEndpoint is the PJSIP endpoint used to send calls to SIP URIs. Optional.
+	URIEndpoint string
+	// Trunk routes telephone numbers when no trunk is passed to Build.
+	Trunk *Trunk
+}
+
+// Build returns the dial string for dest. Telephone numbers are sent through trunk, or through
+// the builder's default trunk when trunk is nil.
+func (b *DialStringBuilder) Build(dest string, trunk *Trunk) (string, error) {
+	d, err := ParseDestination(dest, b.DefaultCountryCode)
+	if err != nil {
+		return "", err
+	}
+
+	tech := b.Tech
+	if tech == "" {
+		tech = "PJSIP"
+	}
+
+	switch d.Kind {
+	case DestinationEndpoint:
+		return tech + "/" + d.Value, nil
+	case DestinationURI:
+		if b.URIEndpoint != "" {
+			return tech + "/" + b.URIEndpoint + "/" + d.Value, nil
+		}
+		return tech + "/" + d.Value, nil
+	case DestinationExtension:
+		return "Local/" + d.Value + "@" + d.Context, nil
+	case DestinationNumber:
+		if trunk == nil {
+			trunk = b.Trunk
+		}
+		if trunk == nil {
+			return "", fmt.Errorf("%w: no trunk to route number %s", ErrInvalidDestination, d.Value)
+		}
+		return trunk.dialString(d.Value), nil
+	}
+	return d.Value, nil
+}
@@ -0,0 +1,50 @@
+package asterisk_ari_go
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseDestination(t *testing.T) {
+	for _, tc := range []struct {
+		dest  string
+		kind  DestinationKind
+		value string
+	}{
+		{"alice", DestinationEndpoint, "alice"},
+		{"1001", DestinationEndpoint, "1001"},
+		{"100-1", DestinationEndpoint, "100-1"},
+		{"5551234", DestinationNumber, "+15551234"},
+		{"(555) 123-4567", DestinationNumber, "+15551234567"},
+		{"0044 20 7946 0000", DestinationNumber, "+442079460000"},
+		{"+1 555 123 4567", DestinationNumber, "+15551234567"},
+		{"tel:+15551234567", DestinationNumber, "+15551234567"},
+		{"TEL:+15551234567", DestinationNumber, "+15551234567"},
+		{"sip:alice@example.com", DestinationURI, "sip:alice@example.com"},
+		{"1001@from-internal", DestinationExtension, "1001"},
+		{"PJSIP/alice", DestinationDialString, "PJSIP/alice"},
+	} {
+		d, err := ParseDestination(tc.dest, "1")
+		if err != nil {
+			t.Errorf("%q: %v", tc.dest, err)
+			continue
+		}
+		if d.Kind != tc.kind || d.Value != tc.value {
+			t.Errorf("%q = %d %q, want %d %q", tc.dest, d.Kind, d.Value, tc.kind, tc.value)
+		}
+	}
+
+	for _, dest := range []string{"", "sip:alice", "PJSIP/", "@from-internal", "tel:+1555abc"} {
+		if _, err := ParseDestination(dest, "1"); !errors.Is(err, ErrInvalidDestination) {
+			t.Errorf("%q: err = %v, want ErrInvalidDestination", dest, err)
+		}
+	}
+}
+
+func TestNormalizeNumberTelPrefix(t *testing.T) {
+	for _, number := range []string{"tel:+15551234567", "TEL:+15551234567", "Tel:+1 555 123 4567"} {
+		if n, err := NormalizeNumber(number, ""); err != nil || n != "+15551234567" {
+			t.Errorf("%q = %q, %v, want +15551234567", number, n, err)
+		}
+	}
+}
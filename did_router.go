@@ -0,0 +1,495 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/antihax/optional"
+)
+
+// RouteHandler handles a call entering the application. ctx is canceled when the channel leaves
+// the application; RouteMatchFrom returns the route that matched.
+type RouteHandler func(ctx context.Context, ev StasisStartEvent)
+
+// RouteMatch describes the route a call was dispatched to.
+type RouteMatch struct {
+	// Pattern is the number, prefix or regular expression of the route, "" for NotFound.
+	Pattern string
+	// Destination is the dialed number or extension the call was routed on.
+	Destination string
+	// Groups are the submatches of a regular expression route, Groups[0] being the whole match;
+	// for a prefix route, Groups[0] is the prefix and Groups[1] the rest of the destination.
+	Groups []string
+}
+
+type routeMatchKey struct{}
+
+// RouteMatchFrom returns the route set on the context of a RouteHandler by the Router.
+func RouteMatchFrom(ctx context.Context) (RouteMatch, bool) {
+	m, ok := ctx.Value(routeMatchKey{}).(RouteMatch)
+	return m, ok
+}
+
+// RouteKind is how a route matches the destinations.
+type RouteKind int
+
+const (
+	// RouteExact matches one number or extension.
+	RouteExact RouteKind = iota
+	// RoutePrefix matches the destinations starting with the pattern.
+	RoutePrefix
+	// RouteRegexp matches the destinations matching the regular expression.
+	RouteRegexp
+)
+
+func (k RouteKind) String() string {
+	switch k {
+	case RouteExact:
+		return "exact"
+	case RoutePrefix:
+		return "prefix"
+	case RouteRegexp:
+		return "regexp"
+	}
+	return fmt.Sprintf("RouteKind(%d)", int(k))
+}
+
+// Route is a route of a Router, see SetRoutes.
+type Route struct {
+	Kind    RouteKind
+	Pattern string
+	Handler RouteHandler
+}
+
+// routeEntry is a registered route and the count of its calls in flight.
+type routeEntry struct {
+	Route
+	re *regexp.Regexp
+
+	mu       sync.Mutex
+	inFlight int
+	removed  bool
+	// drained is closed once the route is removed and its last call is done.
+	drained chan struct{}
+}
+
+func newRouteEntry(route Route) (*routeEntry, error) {
+	e := &routeEntry{Route: route, drained: make(chan struct{})}
+	switch route.Kind {
+	case RouteExact, RoutePrefix:
+	case RouteRegexp:
+		re, err := regexp.Compile(route.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("router: %w", err)
+		}
+		e.re = re
+	default:
+		return nil, fmt.Errorf("router: unknown route kind %v", route.Kind)
+	}
+	return e, nil
+}
+
+// begin counts a call of the route.
+func (e *routeEntry) begin() {
+	e.mu.Lock()
+	e.inFlight++
+	e.mu.Unlock()
+}
+
+// end uncounts a call of the route.
+func (e *routeEntry) end() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.inFlight--; e.inFlight == 0 && e.removed {
+		close(e.drained)
+	}
+}
+
+// remove marks the route removed; drained is closed once its calls are done.
+func (e *routeEntry) remove() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.removed {
+		return
+	}
+	e.removed = true
+	if e.inFlight == 0 {
+		close(e.drained)
+	}
+}
+
+// routeTable holds the routes of a Router. It is never modified once installed, so that
+// SetRoutes swaps all the routes at once.
+type routeTable struct {
+	exact    map[string]*routeEntry
+	prefixes []*routeEntry
+	regexps  []*routeEntry
+}
+
+// entries returns the routes of the table, exact ones first, then the prefixes longest first
+// and the regular expressions in registration order.
+func (t *routeTable) entries() []*routeEntry {
+	entries := make([]*routeEntry, 0, len(t.exact)+len(t.prefixes)+len(t.regexps))
+	for _, e := range t.exact {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Pattern < entries[j].Pattern })
+	entries = append(entries, t.prefixes...)
+	return append(entries, t.regexps...)
+}
+
+// with returns a copy of the table with e added, or an error if its pattern is already routed.
+func (t *routeTable) with(e *routeEntry) (*routeTable, error) {
+	if t.find(e.Kind, e.Pattern) != nil {
+		return nil, fmt.Errorf("router: %s route %q is already registered", e.Kind, e.Pattern)
+	}
+	n := &routeTable{exact: make(map[string]*routeEntry, len(t.exact)+1)}
+	for k, v := range t.exact {
+		n.exact[k] = v
+	}
+	n.prefixes = append([]*routeEntry(nil), t.prefixes...)
+	n.regexps = append([]*routeEntry(nil), t.regexps...)
+	switch e.Kind {
+	case RouteExact:
+		n.exact[e.Pattern] = e
+	case RoutePrefix:
+		n.prefixes = append(n.prefixes, e)
+		sort.SliceStable(n.prefixes, func(i, j int) bool { return len(n.prefixes[i].Pattern) > len(n.prefixes[j].Pattern) })
+	case RouteRegexp:
+		n.regexps = append(n.regexps, e)
+	}
+	return n, nil
+}
+
+// without returns a copy of the table without the route of kind and pattern.
+func (t *routeTable) without(kind RouteKind, pattern string) *routeTable {
+	n := &routeTable{exact: make(map[string]*routeEntry, len(t.exact))}
+	for k, v := range t.exact {
+		if kind != RouteExact || k != pattern {
+			n.exact[k] = v
+		}
+	}
+	for _, e := range t.prefixes {
+		if kind != RoutePrefix || e.Pattern != pattern {
+			n.prefixes = append(n.prefixes, e)
+		}
+	}
+	for _, e := range t.regexps {
+		if kind != RouteRegexp || e.Pattern != pattern {
+			n.regexps = append(n.regexps, e)
+		}
+	}
+	return n
+}
+
+// find returns the route of kind and pattern, or nil.
+func (t *routeTable) find(kind RouteKind, pattern string) *routeEntry {
+	switch kind {
+	case RouteExact:
+		return t.exact[pattern]
+	case RoutePrefix:
+		for _, e := range t.prefixes {
+			if e.Pattern == pattern {
+				return e
+			}
+		}
+	case RouteRegexp:
+		for _, e := range t.regexps {
+			if e.Pattern == pattern {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+// match returns the route of destination, or nil.
+func (t *routeTable) match(destination string) (*routeEntry, RouteMatch) {
+	if e, ok := t.exact[destination]; ok {
+		return e, RouteMatch{Pattern: destination, Destination: destination, Groups: []string{destination}}
+	}
+	for _, e := range t.prefixes {
+		if strings.HasPrefix(destination, e.Pattern) {
+			groups := []string{e.Pattern, strings.TrimPrefix(destination, e.Pattern)}
+			return e, RouteMatch{Pattern: e.Pattern, Destination: destination, Groups: groups}
+		}
+	}
+	for _, e := range t.regexps {
+		if groups := e.re.FindStringSubmatch(destination); groups != nil {
+			return e, RouteMatch{Pattern: e.Pattern, Destination: destination, Groups: groups}
+		}
+	}
+	return nil, RouteMatch{Destination: destination}
+}
+
+// Router dispatches the calls entering the application to handlers registered per dialed number
+// or extension, like an HTTP mux does with paths. Exact routes take precedence over prefix
+// routes, the longest prefix winning, and prefix routes over regular expression routes, tried in
+// registration order. Every event must be fed to HandleEvent.
+//
+// Routes may be added, replaced and removed while calls are handled: the calls in flight on a
+// removed or replaced route carry on with its handler until they complete, see Drain, and the new
+// calls get the current routes.
+type Router struct {
+	client *APIClient
+
+	// Destination returns the number or extension a call is routed on. Defaults to the dialplan
+	// extension of the channel.
+	Destination func(ev StasisEvent) string
+	// NotFound handles the calls no route matches. Defaults to hanging them up with the
+	// "unallocated" cause.
+	NotFound RouteHandler
+	// Skip reports the channels the router leaves alone, typically the legs originated by the
+	// application itself, which enter it like incoming calls. Defaults to the channels the
+	// helpers of this package originated on the client, see APIClient.OriginatedByHelpers; set it
+	// to also skip the channels the application originates itself.
+	Skip func(ev StasisEvent) bool
+
+	mu     sync.RWMutex
+	routes *routeTable
+	// middlewares wrap every handler, see Use.
+	middlewares []RouteMiddleware
+	// calls cancels the context of the calls being handled, by channel ID.
+	calls map[string]context.CancelFunc
+}
+
+// NewRouter creates a router without routes.
+func NewRouter(client *APIClient) *Router {
+	r := &Router{
+		client:      client,
+		Destination: dialedExten,
+		Skip:        func(ev StasisEvent) bool { return client.OriginatedByHelpers(ev.Channel.Id) },
+		routes:      &routeTable{exact: make(map[string]*routeEntry)},
+		calls:       make(map[string]context.CancelFunc),
+	}
+	r.NotFound = r.hangupUnallocated
+	return r
+}
+
+// dialedExten returns the dialplan extension of the channel of ev.
+func dialedExten(ev StasisEvent) string {
+	if ev.Channel.Dialplan == nil {
+		return ""
+	}
+	return ev.Channel.Dialplan.Exten
+}
+
+// Add registers route, or returns an error if its pattern is already routed or does not compile.
+func (r *Router) Add(route Route) error {
+	e, err := newRouteEntry(route)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	routes, err := r.routes.with(e)
+	if err != nil {
+		return err
+	}
+	r.routes = routes
+	return nil
+}
+
+// Handle routes the calls to number, e.g. "+15551234567" or "1001". It panics if number is
+// already routed.
+func (r *Router) Handle(number string, handler RouteHandler) {
+	if err := r.Add(Route{Kind: RouteExact, Pattern: number, Handler: handler}); err != nil {
+		panic(err)
+	}
+}
+
+// HandlePrefix routes the calls to the destinations starting with prefix, e.g. "+1555". It
+// panics if prefix is already routed.
+func (r *Router) HandlePrefix(prefix string, handler RouteHandler) {
+	if err := r.Add(Route{Kind: RoutePrefix, Pattern: prefix, Handler: handler}); err != nil {
+		panic(err)
+	}
+}
+
+// HandleRegexp routes the calls to the destinations matching pattern, e.g. `^1(\d{3})$`. It
+// panics if pattern does not compile or is already routed; anchor it to match whole
+// destinations.
+func (r *Router) HandleRegexp(pattern string, handler RouteHandler) {
+	if err := r.Add(Route{Kind: RouteRegexp, Pattern: pattern, Handler: handler}); err != nil {
+		panic(err)
+	}
+}
+
+// Remove unregisters the route of kind and pattern and reports whether it was registered. Its
+// calls in flight carry on.
+func (r *Router) Remove(kind RouteKind, pattern string) bool {
+	r.mu.Lock()
+	e := r.routes.find(kind, pattern)
+	if e != nil {
+		r.routes = r.routes.without(kind, pattern)
+	}
+	r.mu.Unlock()
+	if e == nil {
+		return false
+	}
+	e.remove()
+	return true
+}
+
+// Drain removes the route of kind and pattern, if registered, and waits until its calls in
+// flight are done or ctx is done.
+func (r *Router) Drain(ctx context.Context, kind RouteKind, pattern string) error {
+	r.mu.Lock()
+	e := r.routes.find(kind, pattern)
+	if e != nil {
+		r.routes = r.routes.without(kind, pattern)
+	}
+	r.mu.Unlock()
+	if e == nil {
+		return nil
+	}
+	e.remove()
+	select {
+	case <-e.drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SetRoutes replaces all the routes at once, e.g. with call flows reloaded from configuration. A
+// route that is kept with another handler is replaced as well. The calls in flight on the
+// previous routes carry on with their handlers. Nothing changes if a route is invalid or
+// duplicated.
+func (r *Router) SetRoutes(routes []Route) error {
+	table := &routeTable{exact: make(map[string]*routeEntry)}
+	for _, route := range routes {
+		e, err := newRouteEntry(route)
+		if err != nil {
+			return err
+		}
+		if table, err = table.with(e); err != nil {
+			return err
+		}
+	}
+	r.mu.Lock()
+	previous := r.routes
+	r.routes = table
+	r.mu.Unlock()
+	for _, e := range previous.entries() {
+		e.remove()
+	}
+	return nil
+}
+
+// Routes returns the registered routes, exact ones first, then the prefixes longest first and the
+// regular expressions in registration order.
+func (r *Router) Routes() []Route {
+	r.mu.RLock()
+	entries := r.routes.entries()
+	r.mu.RUnlock()
+	routes := make([]Route, len(entries))
+	for i, e := range entries {
+		routes[i] = e.Route
+	}
+	return routes
+}
+
+// InFlight returns the number of calls being handled by the route of kind and pattern.
+func (r *Router) InFlight(kind RouteKind, pattern string) int {
+	r.mu.RLock()
+	e := r.routes.find(kind, pattern)
+	r.mu.RUnlock()
+	if e == nil {
+		return 0
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.inFlight
+}
+
+// Match returns the handler of destination and the route it matched, or false if no route does.
+func (r *Router) Match(destination string) (RouteHandler, RouteMatch, bool) {
+	r.mu.RLock()
+	e, match := r.routes.match(destination)
+	r.mu.RUnlock()
+	if e == nil {
+		return nil, match, false
+	}
+	return e.Handler, match, true
+}
+
+// HandleEvent feeds an event received from Asterisk into the router. Every StasisStart not
+// skipped is dispatched to its handler on a goroutine of its own, so that a handler may block for
+// the duration of the call.
+func (r *Router) HandleEvent(ev StasisEvent) {
+	id := ev.Channel.Id
+	if id == "" {
+		return
+	}
+	switch ev.Type {
+	case "StasisStart":
+		if r.Skip != nil && r.Skip(ev) {
+			return
+		}
+		r.dispatch(ev)
+	case "StasisEnd", "ChannelDestroyed":
+		r.mu.Lock()
+		cancel, ok := r.calls[id]
+		delete(r.calls, id)
+		r.mu.Unlock()
+		if ok {
+			cancel()
+		}
+	}
+}
+
+// dispatch runs the handler of a call entering the application.
+func (r *Router) dispatch(ev StasisEvent) {
+	destination := r.Destination(ev)
+	r.mu.RLock()
+	entry, match := r.routes.match(destination)
+	if entry != nil {
+		// Counted while the route cannot be removed, so that Drain waits for the call.
+		entry.begin()
+	}
+	r.mu.RUnlock()
+	handler, route := r.NotFound, "not_found"
+	if entry != nil {
+		handler, route = entry.Handler, match.Pattern
+	} else {
+		r.client.logger.Infof("router: no route for %q, channel %s", destination, ev.Channel.Id)
+	}
+	r.client.metrics().IncCounter("ari_router_calls_total", map[string]string{"route": route}, 1)
+	if handler == nil {
+		if entry != nil {
+			entry.end()
+		}
+		return
+	}
+	handler = r.chain(handler)
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), routeMatchKey{}, match))
+	r.mu.Lock()
+	if previous, ok := r.calls[ev.Channel.Id]; ok {
+		// The channel re-entered the application, e.g. after a move; its previous handler is done.
+		previous()
+	}
+	r.calls[ev.Channel.Id] = cancel
+	r.mu.Unlock()
+
+	call := NewCrashGuard(r.client).Wrap("router", func(ev StasisEvent) { handler(ctx, StasisStartEvent{ev}) })
+	r.client.goTracked("router", func() {
+		if entry != nil {
+			defer entry.end()
+		}
+		call(ev)
+	})
+}
+
+// hangupUnallocated hangs up an unrouted call with the "unallocated" cause.
+func (r *Router) hangupUnallocated(ctx context.Context, ev StasisStartEvent) {
+	_, err := r.client.ChannelsApi.Hangup(ctx, ev.Channel.Id, &ChannelsApiHangupOpts{Reason: optional.NewString("unallocated")})
+	if err != nil && ctx.Err() == nil {
+		r.client.logger.Warnf("router: hanging up unrouted channel %s: %v", ev.Channel.Id, err)
+	}
+}
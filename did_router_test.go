@@ -0,0 +1,48 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+// TestRouterSkip covers the legs originated by the helpers, which the router leaves alone, and a
+// channel whose ID the application chose with a helper-like prefix, which it routes.
+func TestRouterSkip(t *testing.T) {
+	cfg := NewConfiguration("http://asterisk.invalid:8088/ari")
+	cfg.DryRun = true
+	client := NewAPIClient(cfg, NewStdLogger(ioutil.Discard))
+	router := NewRouter(client)
+	routed := make(chan string, 4)
+	router.NotFound = func(ctx context.Context, ev StasisStartEvent) { routed <- ev.Channel.Id }
+
+	leg, _, err := NewCallLimiter(client, nil).Originate(context.Background(), "PJSIP/alice", nil)
+	if err != nil || leg.Id == "" {
+		t.Fatalf("originate: %+v, %v", leg, err)
+	}
+	if !client.OriginatedByHelpers(leg.Id) {
+		t.Fatalf("%s not recorded as originated by a helper", leg.Id)
+	}
+	router.HandleEvent(StasisEvent{Type: "StasisStart", Channel: Channel{Id: leg.Id}})
+	router.HandleEvent(StasisEvent{Type: "StasisStart", Channel: Channel{Id: "call-mine"}})
+
+	select {
+	case id := <-routed:
+		if id != "call-mine" {
+			t.Errorf("routed %s, want call-mine", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("call-mine not routed")
+	}
+	select {
+	case id := <-routed:
+		t.Errorf("routed %s too", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	client.HandleEvent(StasisEvent{Type: "ChannelDestroyed", Channel: Channel{Id: leg.Id}})
+	if client.OriginatedByHelpers(leg.Id) {
+		t.Errorf("%s still recorded once destroyed", leg.Id)
+	}
+}
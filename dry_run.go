@@ -0,0 +1,46 @@
+package asterisk_ari_go
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// dryRunResponse synthesizes the success of a mutating call that was not sent. The body carries
+// the ID of the resource, so that calls returning one (originate, bridge creation, play, record)
+// yield an object usable in the follow-up calls.
+func (c *APIClient) dryRunResponse(req *http.Request) *http.Response {
+	path := c.apiPath(req)
+	query := req.URL.Query()
+	operation, id := auditOperation(req.Method, path, query)
+	body := map[string]string{}
+	switch {
+	case strings.HasSuffix(operation, ".record"):
+		body["name"] = query.Get("name")
+	case strings.HasSuffix(operation, ".play"):
+		body["id"] = query.Get("playbackId")
+		if body["id"] == "" {
+			body["id"] = newResourceId("dryrun")
+		}
+	case id != "":
+		// Including the ID chosen by the client on creation, e.g. "POST /channels/create".
+		body["id"] = id
+	default:
+		body["id"] = newResourceId("dryrun")
+	}
+	data, _ := json.Marshal(body)
+	c.logger.Infof("dry run: %s %s not sent (%s)", req.Method, path, operation)
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(data)),
+		ContentLength: int64(len(data)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,30 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/antihax/optional"
+)
+
+func TestDryRunCreatedIds(t *testing.T) {
+	cfg := NewConfiguration("http://asterisk.invalid:8088/ari")
+	cfg.DryRun = true
+	client := NewAPIClient(cfg, NewStdLogger(ioutil.Discard))
+	ctx := context.Background()
+
+	channel, _, err := client.ChannelsApi.Createchannel(ctx, "PJSIP/alice", "ivr", &ChannelsApiCreatechannelOpts{ChannelId: optional.NewString("c1")})
+	if err != nil || channel.Id != "c1" {
+		t.Errorf("create = %q, %v, want c1", channel.Id, err)
+	}
+	channel, _, err = client.ChannelsApi.ExternalMedia(ctx, "ivr", "127.0.0.1:4000", "ulaw", nil)
+	if err != nil || !strings.HasPrefix(channel.Id, "dryrun-") {
+		t.Errorf("externalMedia = %q, %v, want a generated ID", channel.Id, err)
+	}
+	channel, _, err = client.ChannelsApi.OriginateWithId(ctx, "c2", "PJSIP/bob", nil)
+	if err != nil || channel.Id != "c2" {
+		t.Errorf("originate = %q, %v, want c2", channel.Id, err)
+	}
+}
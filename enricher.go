@@ -0,0 +1,129 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// EnrichFunc derives data from an event, e.g. the tenant owning the dialed number or the CRM
+// contact of the caller. The returned values are merged into StasisEvent.Enrichment.
+type EnrichFunc func(ctx context.Context, ev StasisEvent) (map[string]interface{}, error)
+
+type enrichStage struct {
+	name string
+	fn   EnrichFunc
+}
+
+// Enricher runs enrichment stages on events before they reach the handlers. The result of a stage
+// is cached per channel: it runs on the first event of a channel and its values are attached to
+// every later event of that channel, until ChannelDestroyed. Events without a channel are enriched
+// every time. A failing stage is logged and retried on the next event.
+type Enricher struct {
+	client *APIClient
+
+	// Timeout bounds every stage. Defaults to 2 seconds.
+	Timeout time.Duration
+
+	mu     sync.Mutex
+	stages []enrichStage
+	cache  map[string]map[string]map[string]interface{}
+}
+
+// NewEnricher creates an enricher without stages.
+func NewEnricher(client *APIClient) *Enricher {
+	return &Enricher{client: client, Timeout: 2 * time.Second, cache: make(map[string]map[string]map[string]interface{})}
+}
+
+// Add appends a stage. Stages run in the order they were added and see the values of the previous ones.
+func (e *Enricher) Add(name string, fn EnrichFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stages = append(e.stages, enrichStage{name: name, fn: fn})
+}
+
+// Enrich returns ev with the values of every stage attached.
+func (e *Enricher) Enrich(ctx context.Context, ev StasisEvent) StasisEvent {
+	e.mu.Lock()
+	stages := e.stages
+	e.mu.Unlock()
+
+	enrichment := make(map[string]interface{}, len(ev.Enrichment))
+	for k, v := range ev.Enrichment {
+		enrichment[k] = v
+	}
+	ev.Enrichment = enrichment
+	channelId := ev.Channel.Id
+
+	for _, stage := range stages {
+		values, ok := e.cached(channelId, stage.name)
+		if !ok {
+			var err error
+			values, err = e.run(ctx, stage, ev)
+			if err != nil {
+				e.client.logger.Warnf("enricher: stage %s on %s event: %v", stage.name, ev.Type, err)
+				continue
+			}
+			e.store(channelId, stage.name, values)
+		}
+		for k, v := range values {
+			enrichment[k] = v
+		}
+	}
+	return ev
+}
+
+// Wrap returns a handler that passes enriched events to next and drops the cache of destroyed channels.
+func (e *Enricher) Wrap(next func(StasisEvent)) func(StasisEvent) {
+	return func(ev StasisEvent) {
+		next(e.Enrich(context.Background(), ev))
+		if ev.Type == "ChannelDestroyed" {
+			e.Forget(ev.Channel.Id)
+		}
+	}
+}
+
+// Forget drops the cached values of channelId.
+func (e *Enricher) Forget(channelId string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.cache[channelId]; ok {
+		delete(e.cache, channelId)
+		e.client.TrackResource(ResourceChannel, "enricher", -1)
+	}
+}
+
+func (e *Enricher) run(ctx context.Context, stage enrichStage, ev StasisEvent) (map[string]interface{}, error) {
+	timeout := e.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return stage.fn(ctx, ev)
+}
+
+func (e *Enricher) cached(channelId string, stage string) (map[string]interface{}, bool) {
+	if channelId == "" {
+		return nil, false
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	values, ok := e.cache[channelId][stage]
+	return values, ok
+}
+
+func (e *Enricher) store(channelId string, stage string, values map[string]interface{}) {
+	if channelId == "" {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	stages, ok := e.cache[channelId]
+	if !ok {
+		stages = make(map[string]map[string]interface{})
+		e.cache[channelId] = stages
+		e.client.TrackResource(ResourceChannel, "enricher", 1)
+	}
+	stages[stage] = values
+}
@@ -0,0 +1,129 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultSubscriptionBuffer is the channel buffer of a subscription when SubscribeOpts.Buffer is 0.
+const DefaultSubscriptionBuffer = 64
+
+// EventBus fans events out to typed subscriptions created with Subscribe. Every event must be fed
+// to HandleEvent. A subscriber that falls behind by more than its buffer loses events rather than
+// stalling the others; lost events are counted in ari_events_dropped_total.
+type EventBus struct {
+	client *APIClient
+
+	mu     sync.Mutex
+	next   int
+	subs   map[int]*busSubscription
+	closed bool
+}
+
+type busSubscription struct {
+	deliver func(ev StasisEvent)
+	close   func()
+}
+
+// SubscribeOpts filters and sizes a subscription.
+type SubscribeOpts struct {
+	// Context ends the subscription when done, closing its channel. A nil Context keeps it until
+	// the bus is closed.
+	Context context.Context
+	// ResourceId restricts the subscription to the events of a channel, playback or recording.
+	ResourceId string
+	// Buffer is the channel buffer, DefaultSubscriptionBuffer if 0.
+	Buffer int
+}
+
+// NewEventBus creates a bus without subscriptions.
+func NewEventBus(client *APIClient) *EventBus {
+	return &EventBus{client: client, subs: make(map[int]*busSubscription)}
+}
+
+// HandleEvent delivers an event received from Asterisk to the matching subscriptions.
+func (b *EventBus) HandleEvent(ev StasisEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, sub := range b.subs {
+		sub.deliver(ev)
+	}
+}
+
+// Close ends every subscription and refuses new ones.
+func (b *EventBus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for id, sub := range b.subs {
+		sub.close()
+		delete(b.subs, id)
+	}
+}
+
+// Subscribe returns a channel receiving the events of type T, e.g. ChannelDtmfReceivedEvent, fed to
+// bus; StasisEvent receives every event. The channel is closed when opts.Context is done or the bus
+// is closed.
+func Subscribe[T TypedEvent](bus *EventBus, opts *SubscribeOpts) <-chan T {
+	if opts == nil {
+		opts = &SubscribeOpts{}
+	}
+	size := opts.Buffer
+	if size == 0 {
+		size = DefaultSubscriptionBuffer
+	}
+	var zero T
+	eventType := zero.EventType()
+	ch := make(chan T, size)
+
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	if bus.closed {
+		close(ch)
+		return ch
+	}
+	id := bus.next
+	bus.next++
+	bus.subs[id] = &busSubscription{
+		deliver: func(ev StasisEvent) {
+			if eventType != "" && ev.Type != eventType {
+				return
+			}
+			if opts.ResourceId != "" && !ev.concerns(opts.ResourceId) {
+				return
+			}
+			select {
+			case ch <- zero.fromStasis(ev).(T):
+			default:
+				bus.client.metrics().IncCounter("ari_events_dropped_total", map[string]string{"type": ev.Type}, 1)
+			}
+		},
+		close: func() {
+			close(ch)
+			bus.client.TrackResource(ResourceSubscription, "event_bus", -1)
+		},
+	}
+	bus.client.TrackResource(ResourceSubscription, "event_bus", 1)
+
+	if opts.Context != nil {
+		ctx := opts.Context
+		bus.client.goTracked("event_bus", func() {
+			<-ctx.Done()
+			bus.mu.Lock()
+			defer bus.mu.Unlock()
+			if sub, ok := bus.subs[id]; ok {
+				sub.close()
+				delete(bus.subs, id)
+			}
+		})
+	}
+	return ch
+}
+
+// concerns reports whether the event is about the channel, playback or recording id.
+func (ev StasisEvent) concerns(id string) bool {
+	return ev.Channel.Id == id ||
+		(ev.Peer != nil && ev.Peer.Id == id) ||
+		(ev.Playback != nil && ev.Playback.Id == id) ||
+		(ev.Recording != nil && ev.Recording.Name == id)
+}
@@ -0,0 +1,61 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestEventBus(t *testing.T) {
+	bus := NewEventBus(NewAPIClient(NewConfiguration("/"), NewStdLogger(ioutil.Discard)))
+	dtmf := Subscribe[ChannelDtmfReceivedEvent](bus, &SubscribeOpts{ResourceId: "c1"})
+	all := Subscribe[StasisEvent](bus, &SubscribeOpts{Buffer: 2})
+
+	bus.HandleEvent(StasisEvent{Type: "ChannelDtmfReceived", Channel: Channel{Id: "c2"}, Digit: "1"})
+	bus.HandleEvent(StasisEvent{Type: "ChannelDtmfReceived", Channel: Channel{Id: "c1"}, Digit: "2"})
+	// The third event overflows the buffer of all and is dropped.
+	bus.HandleEvent(StasisEvent{Type: "StasisEnd", Channel: Channel{Id: "c1"}})
+
+	if ev := <-dtmf; ev.Digit != "2" || ev.EventType() != "ChannelDtmfReceived" {
+		t.Errorf("dtmf = %+v, want the digit of c1", ev)
+	}
+	select {
+	case ev := <-dtmf:
+		t.Errorf("unexpected dtmf %+v", ev)
+	default:
+	}
+	if a, b := <-all, <-all; a.Digit != "1" || b.Digit != "2" {
+		t.Errorf("all = %s %s, want the two digits", a.Digit, b.Digit)
+	}
+	select {
+	case ev := <-all:
+		t.Errorf("event %s beyond the buffer was delivered", ev.Type)
+	default:
+	}
+
+	bus.Close()
+	if _, open := <-dtmf; open {
+		t.Error("subscription open after Close")
+	}
+	if _, open := <-Subscribe[StasisEvent](bus, nil); open {
+		t.Error("subscription to a closed bus is open")
+	}
+}
+
+func TestEventBusContext(t *testing.T) {
+	bus := NewEventBus(NewAPIClient(NewConfiguration("/"), NewStdLogger(ioutil.Discard)))
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := Subscribe[StasisStartEvent](bus, &SubscribeOpts{Context: ctx})
+	cancel()
+	select {
+	case _, open := <-ch:
+		if open {
+			t.Error("received an event, want the channel closed")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not closed when its context was done")
+	}
+	// Events after the end of the subscription are not delivered to it.
+	bus.HandleEvent(StasisEvent{Type: "StasisStart"})
+}
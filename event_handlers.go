@@ -0,0 +1,82 @@
+package asterisk_ari_go
+
+import "sync"
+
+// AnyEvent registers a catch-all handler with On.
+const AnyEvent = "*"
+
+type eventHandler struct {
+	id int
+	f  func(StasisEvent)
+}
+
+// eventHandlers holds the handlers registered with On, by event type.
+type eventHandlers struct {
+	mu     sync.RWMutex
+	next   int
+	byType map[string][]eventHandler
+}
+
+// On registers handler for the events of eventType, e.g. "ChannelDtmfReceived", or for every
+// event with AnyEvent. Handlers are called by HandleEvent on its goroutine, the ones of the type
+// in registration order, then the catch-all ones; several handlers may be registered per type.
+// The returned function unregisters the handler.
+func (c *APIClient) On(eventType string, handler func(ev StasisEvent)) (off func()) {
+	h := &c.handlers
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.byType == nil {
+		h.byType = make(map[string][]eventHandler)
+	}
+	id := h.next
+	h.next++
+	h.byType[eventType] = append(h.byType[eventType], eventHandler{id: id, f: handler})
+	c.TrackResource(ResourceSubscription, "event_handlers", 1)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			handlers := h.byType[eventType]
+			for i, registered := range handlers {
+				if registered.id == id {
+					// Copy, so that a dispatch iterating the old slice is not affected.
+					h.byType[eventType] = append(append([]eventHandler(nil), handlers[:i]...), handlers[i+1:]...)
+					break
+				}
+			}
+			c.TrackResource(ResourceSubscription, "event_handlers", -1)
+		})
+	}
+}
+
+// On registers a handler of the typed events T, e.g.
+//
+//	On(client, func(ev ChannelDtmfReceivedEvent) { ... })
+//
+// See APIClient.On; a handler of StasisEvent receives every event.
+func On[T TypedEvent](client *APIClient, handler func(ev T)) (off func()) {
+	var zero T
+	eventType := zero.EventType()
+	if eventType == "" {
+		eventType = AnyEvent
+	}
+	return client.On(eventType, func(ev StasisEvent) {
+		handler(zero.fromStasis(ev).(T))
+	})
+}
+
+// dispatch calls the handlers of ev.
+func (c *APIClient) dispatch(ev StasisEvent) {
+	h := &c.handlers
+	h.mu.RLock()
+	typed, catchAll := h.byType[ev.Type], h.byType[AnyEvent]
+	h.mu.RUnlock()
+	for _, handler := range typed {
+		handler.f(ev)
+	}
+	for _, handler := range catchAll {
+		handler.f(ev)
+	}
+}
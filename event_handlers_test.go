@@ -0,0 +1,35 @@
+package asterisk_ari_go
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestOn(t *testing.T) {
+	client := NewAPIClient(NewConfiguration("/"), NewStdLogger(ioutil.Discard))
+	var calls []string
+	record := func(name string) func(StasisEvent) {
+		return func(ev StasisEvent) { calls = append(calls, name+":"+ev.Type) }
+	}
+	client.On(AnyEvent, record("any"))
+	client.On("ChannelDtmfReceived", record("first"))
+	off := client.On("ChannelDtmfReceived", record("second"))
+	On(client, func(ev ChannelDtmfReceivedEvent) { calls = append(calls, "typed:"+ev.Digit) })
+
+	client.HandleEvent(StasisEvent{Type: "ChannelDtmfReceived", Digit: "5"})
+	client.HandleEvent(StasisEvent{Type: "StasisStart"})
+	want := "first:ChannelDtmfReceived second:ChannelDtmfReceived typed:5 any:ChannelDtmfReceived any:StasisStart"
+	if got := strings.Join(calls, " "); got != want {
+		t.Errorf("calls = %s, want %s", got, want)
+	}
+
+	calls = nil
+	off()
+	off()
+	client.HandleEvent(StasisEvent{Type: "ChannelDtmfReceived", Digit: "6"})
+	want = "first:ChannelDtmfReceived typed:6 any:ChannelDtmfReceived"
+	if got := strings.Join(calls, " "); got != want {
+		t.Errorf("calls after off = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,58 @@
+package asterisk_ari_go
+
+import "sync"
+
+// EventJournal keeps the most recent events in memory for diagnostics.
+// It is safe for concurrent use.
+type EventJournal struct {
+	mu     sync.Mutex
+	events []StasisEvent
+	next   int
+	full   bool
+}
+
+// NewEventJournal creates a journal keeping the last size events.
+func NewEventJournal(size int) *EventJournal {
+	if size < 1 {
+		size = 1
+	}
+	return &EventJournal{events: make([]StasisEvent, size)}
+}
+
+// HandleEvent records an event, evicting the oldest one when the journal is full.
+func (j *EventJournal) HandleEvent(ev StasisEvent) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.events[j.next] = ev
+	j.next = (j.next + 1) % len(j.events)
+	if j.next == 0 {
+		j.full = true
+	}
+}
+
+// Events returns the recorded events matching filter, oldest first. A nil filter matches all events.
+func (j *EventJournal) Events(filter func(StasisEvent) bool) []StasisEvent {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var ordered []StasisEvent
+	if j.full {
+		ordered = append(ordered, j.events[j.next:]...)
+	}
+	ordered = append(ordered, j.events[:j.next]...)
+
+	result := ordered[:0]
+	for _, ev := range ordered {
+		if filter == nil || filter(ev) {
+			result = append(result, ev)
+		}
+	}
+	return result
+}
+
+// ForChannel returns the recorded events concerning channelId, oldest first.
+func (j *EventJournal) ForChannel(channelId string) []StasisEvent {
+	return j.Events(func(ev StasisEvent) bool {
+		return ev.Channel.Id == channelId || (ev.Peer != nil && ev.Peer.Id == channelId)
+	})
+}
@@ -0,0 +1,36 @@
+package asterisk_ari_go
+
+import (
+	"strings"
+	"testing"
+)
+
+func journalTypes(events []StasisEvent) string {
+	types := make([]string, len(events))
+	for i, ev := range events {
+		types[i] = ev.Type
+	}
+	return strings.Join(types, " ")
+}
+
+func TestEventJournal(t *testing.T) {
+	j := NewEventJournal(3)
+	if got := j.Events(nil); len(got) != 0 {
+		t.Errorf("empty journal has %d events", len(got))
+	}
+	j.HandleEvent(StasisEvent{Type: "StasisStart", Channel: Channel{Id: "c1"}})
+	j.HandleEvent(StasisEvent{Type: "Dial", Channel: Channel{Id: "c2"}, Peer: &Channel{Id: "c1"}})
+	if got := journalTypes(j.Events(nil)); got != "StasisStart Dial" {
+		t.Errorf("events = %s, want StasisStart Dial", got)
+	}
+
+	// The oldest events are evicted once full.
+	j.HandleEvent(StasisEvent{Type: "ChannelStateChange", Channel: Channel{Id: "c2"}})
+	j.HandleEvent(StasisEvent{Type: "StasisEnd", Channel: Channel{Id: "c1"}})
+	if got := journalTypes(j.Events(nil)); got != "Dial ChannelStateChange StasisEnd" {
+		t.Errorf("events = %s, want the last three", got)
+	}
+	if got := journalTypes(j.ForChannel("c1")); got != "Dial StasisEnd" {
+		t.Errorf("events of c1 = %s, want Dial StasisEnd", got)
+	}
+}
@@ -0,0 +1,219 @@
+package asterisk_ari_go
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// DefaultMaxPooledBufferSize is the largest read buffer returned to the pool by default.
+const DefaultMaxPooledBufferSize = 64 << 10
+
+// EventSource is a websocket connection events are read from. Both *websocket.Conn and
+// *FaultConn implement it.
+type EventSource interface {
+	NextReader() (messageType int, r io.Reader, err error)
+}
+
+// restResponseMarker cheaply identifies frames that may be REST responses.
+var restResponseMarker = []byte(`"RESTResponse"`)
+
+// eventBufferPool holds the read buffers shared by all event readers.
+var eventBufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// EventReader decodes Stasis events from a websocket connection. It is not safe for concurrent use.
+type EventReader struct {
+	client *APIClient
+	source EventSource
+
+	// PoolBuffers reuses read buffers across events to reduce GC pressure. Recommended above
+	// 1k events/sec. When false every event is read into a fresh buffer.
+	PoolBuffers bool
+	// MaxPooledBufferSize caps the capacity of buffers returned to the pool, so that a single
+	// huge event does not pin memory forever. Defaults to DefaultMaxPooledBufferSize.
+	MaxPooledBufferSize int
+	// Transport, if set, receives the RESTResponse frames, which are not returned as events.
+	Transport *WebsocketRESTTransport
+	// ConnectionId identifies the connection in logs and in the events read from it.
+	ConnectionId string
+	// Stats counts the events and decode errors read from the connection.
+	Stats *ConnectionStats
+}
+
+// NewEventReader creates a reader decoding the events received on source.
+func (a *WebsocketApiService) NewEventReader(source EventSource) *EventReader {
+	id := newResourceId("conn")
+	return &EventReader{
+		client:              a.client,
+		source:              source,
+		MaxPooledBufferSize: DefaultMaxPooledBufferSize,
+		ConnectionId:        id,
+		Stats:               NewConnectionStats(a.client, id),
+	}
+}
+
+// DefaultDispatchQueueSize is the number of events Dispatch reads ahead of their handler by
+// default.
+const DefaultDispatchQueueSize = 256
+
+// Dispatch reads the events on a goroutine of its own and passes them to handle in order until the
+// connection fails, and returns the error of the connection. Up to queueSize events
+// (DefaultDispatchQueueSize if 0) are read ahead of handle and reported as the queue depth of
+// Stats, so that reading, and answering pings and REST responses, goes on while a handler is slow.
+// Undecodable events are passed to onDecodeError, if set, and skipped.
+func (r *EventReader) Dispatch(queueSize int, handle func(ev StasisEvent), onDecodeError func(err error)) error {
+	if queueSize <= 0 {
+		queueSize = DefaultDispatchQueueSize
+	}
+	queue := make(chan StasisEvent, queueSize)
+	var readErr error
+	r.client.goTracked("event_reader", func() {
+		defer close(queue)
+		for {
+			ev, err := r.Next()
+			if err != nil {
+				var decodeErr *EventDecodeError
+				if errors.As(err, &decodeErr) {
+					if onDecodeError != nil {
+						onDecodeError(err)
+					}
+					continue
+				}
+				readErr = err
+				return
+			}
+			queue <- ev
+			r.setQueueDepth(len(queue))
+		}
+	})
+	if r.Stats != nil {
+		defer r.Stats.refreshRates()()
+	}
+	for ev := range queue {
+		r.setQueueDepth(len(queue))
+		handle(ev)
+	}
+	return readErr
+}
+
+func (r *EventReader) setQueueDepth(n int) {
+	if r.Stats != nil {
+		r.Stats.SetQueueDepth(n)
+	}
+}
+
+// EventDecodeError is returned by EventReader.Next for a frame that is not a valid event.
+// Unlike connection errors, the reader remains usable.
+type EventDecodeError struct {
+	Payload []byte
+	Err     error
+}
+
+func (e *EventDecodeError) Error() string {
+	return "decoding event: " + e.Err.Error()
+}
+
+func (e *EventDecodeError) Unwrap() error {
+	return e.Err
+}
+
+// ErrApplicationReplaced matches the *ApplicationReplacedError returned by EventReader.Next.
+var ErrApplicationReplaced = errors.New("application replaced by another connection")
+
+// ApplicationReplacedError is returned by EventReader.Next when Asterisk reports that another
+// websocket connection subscribed to the application, which no longer receives events on this
+// connection. It usually means two instances run with the same application name.
+type ApplicationReplacedError struct {
+	Application  string
+	ConnectionId string
+}
+
+func (e *ApplicationReplacedError) Error() string {
+	return fmt.Sprintf("application %s replaced by another connection (connection %s)", e.Application, e.ConnectionId)
+}
+
+// Is makes errors.Is(err, ErrApplicationReplaced) match.
+func (e *ApplicationReplacedError) Is(target error) bool {
+	return target == ErrApplicationReplaced
+}
+
+// Next blocks until the next event is received and decodes it. A frame that cannot be decoded
+// yields an *EventDecodeError. An ApplicationReplaced event yields an *ApplicationReplacedError
+// along with the event. Other errors come from the connection; the reader must not be used after
+// one.
+func (r *EventReader) Next() (StasisEvent, error) {
+	for {
+		ev, handled, err := r.next()
+		if err != nil || !handled {
+			if err == nil && ev.Type == "ApplicationReplaced" {
+				r.client.logger.Warnf("event reader: application %s replaced by another connection on connection %s", ev.Application, r.ConnectionId)
+				r.client.metrics().IncCounter("ari_application_replaced_total", map[string]string{"application": ev.Application}, 1)
+				err = &ApplicationReplacedError{Application: ev.Application, ConnectionId: r.ConnectionId}
+			}
+			return ev, err
+		}
+	}
+}
+
+// next reads one frame. handled is true if the frame was consumed by the REST transport.
+func (r *EventReader) next() (ev StasisEvent, handled bool, err error) {
+	_, frame, err := r.source.NextReader()
+	if err != nil {
+		return ev, false, err
+	}
+
+	buf := r.getBuffer()
+	defer r.putBuffer(buf)
+	if _, err := buf.ReadFrom(frame); err != nil {
+		return ev, false, err
+	}
+	if r.Transport != nil && bytes.Contains(buf.Bytes(), restResponseMarker) && r.Transport.HandleMessage(buf.Bytes()) {
+		return ev, true, nil
+	}
+	if err := decodeJSON(buf.Bytes(), &ev, r.client.cfg.StrictDecoding); err != nil {
+		if r.Stats != nil {
+			r.Stats.recordDecodeError()
+		}
+		return ev, false, &EventDecodeError{Payload: append([]byte(nil), buf.Bytes()...), Err: err}
+	}
+	ev.ConnectionId = r.ConnectionId
+	if r.Stats != nil {
+		r.Stats.recordEvent()
+	}
+	if r.client.cfg.RawEvents {
+		if r.PoolBuffers {
+			// The buffer goes back to the pool: the payload must outlive it.
+			ev.Raw = append(json.RawMessage(nil), buf.Bytes()...)
+		} else {
+			// The buffer is never reused, the event can keep it.
+			ev.Raw = buf.Bytes()
+		}
+	}
+	return ev, false, nil
+}
+
+func (r *EventReader) getBuffer() *bytes.Buffer {
+	r.client.TrackResource(ResourceBuffer, "event_reader", 1)
+	if !r.PoolBuffers {
+		return new(bytes.Buffer)
+	}
+	return eventBufferPool.Get().(*bytes.Buffer)
+}
+
+func (r *EventReader) putBuffer(buf *bytes.Buffer) {
+	r.client.TrackResource(ResourceBuffer, "event_reader", -1)
+	max := r.MaxPooledBufferSize
+	if max <= 0 {
+		max = DefaultMaxPooledBufferSize
+	}
+	if !r.PoolBuffers || buf.Cap() > max {
+		return
+	}
+	buf.Reset()
+	eventBufferPool.Put(buf)
+}
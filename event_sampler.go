@@ -0,0 +1,102 @@
+package asterisk_ari_go
+
+import "sync"
+
+// SampleRule controls how many events of a type are kept.
+type SampleRule struct {
+	// Drop discards every event of the type.
+	Drop bool
+	// Every keeps one event out of Every. Zero or one keeps all events.
+	Every int
+}
+
+// EventSampler drops or samples chatty event types such as ChannelVarset or ChannelDialplan
+// before they reach the handlers. Dropped events are counted per type and exported as the
+// "ari_events_dropped_total" counter.
+type EventSampler struct {
+	client *APIClient
+
+	mu       sync.Mutex
+	rules    map[string]SampleRule
+	fallback *SampleRule
+	seen     map[string]uint64
+	dropped  map[string]uint64
+}
+
+// NewEventSampler creates a sampler keeping every event until rules are set.
+func NewEventSampler(client *APIClient) *EventSampler {
+	return &EventSampler{
+		client:  client,
+		rules:   make(map[string]SampleRule),
+		seen:    make(map[string]uint64),
+		dropped: make(map[string]uint64),
+	}
+}
+
+// Set configures the rule of eventType.
+func (s *EventSampler) Set(eventType string, rule SampleRule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules[eventType] = rule
+}
+
+// KeepOnly drops every event type except the given ones, e.g. the call lifecycle events
+// "StasisStart", "StasisEnd" and "ChannelDestroyed". Rules set with Set still apply.
+func (s *EventSampler) KeepOnly(eventTypes ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fallback = &SampleRule{Drop: true}
+	for _, t := range eventTypes {
+		if _, ok := s.rules[t]; !ok {
+			s.rules[t] = SampleRule{}
+		}
+	}
+}
+
+// Keep reports whether ev passes the sampling rules, counting it as dropped otherwise.
+func (s *EventSampler) Keep(ev StasisEvent) bool {
+	s.mu.Lock()
+	rule, ok := s.rules[ev.Type]
+	if !ok && s.fallback != nil {
+		rule, ok = *s.fallback, true
+	}
+	keep := true
+	if ok {
+		s.seen[ev.Type]++
+		switch {
+		case rule.Drop:
+			keep = false
+		case rule.Every > 1:
+			keep = (s.seen[ev.Type]-1)%uint64(rule.Every) == 0
+		}
+	}
+	if !keep {
+		s.dropped[ev.Type]++
+	}
+	s.mu.Unlock()
+
+	if !keep {
+		s.client.metrics().IncCounter("ari_events_dropped_total", map[string]string{"type": ev.Type}, 1)
+	}
+	return keep
+}
+
+// Wrap returns a handler that passes the kept events to next.
+func (s *EventSampler) Wrap(next func(StasisEvent)) func(StasisEvent) {
+	return func(ev StasisEvent) {
+		if s.Keep(ev) {
+			next(ev)
+		}
+	}
+}
+
+// Dropped returns the number of dropped events per type.
+func (s *EventSampler) Dropped() map[string]uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dropped := make(map[string]uint64, len(s.dropped))
+	for t, n := range s.dropped {
+		dropped[t] = n
+	}
+	return dropped
+}
@@ -0,0 +1,44 @@
+package asterisk_ari_go
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestEventSampler(t *testing.T) {
+	s := NewEventSampler(NewAPIClient(NewConfiguration("/"), NewStdLogger(ioutil.Discard)))
+	s.Set("ChannelVarset", SampleRule{Every: 3})
+	s.Set("ChannelDialplan", SampleRule{Drop: true})
+
+	var kept []string
+	handler := s.Wrap(func(ev StasisEvent) { kept = append(kept, ev.Type+"/"+ev.Variable) })
+	for _, v := range []string{"a", "b", "c", "d"} {
+		handler(StasisEvent{Type: "ChannelVarset", Variable: v})
+	}
+	handler(StasisEvent{Type: "ChannelDialplan"})
+	handler(StasisEvent{Type: "StasisStart"})
+
+	if want := []string{"ChannelVarset/a", "ChannelVarset/d", "StasisStart/"}; !reflect.DeepEqual(kept, want) {
+		t.Errorf("kept %q, want %q", kept, want)
+	}
+	if want := map[string]uint64{"ChannelVarset": 2, "ChannelDialplan": 1}; !reflect.DeepEqual(s.Dropped(), want) {
+		t.Errorf("dropped %v, want %v", s.Dropped(), want)
+	}
+}
+
+func TestEventSamplerKeepOnly(t *testing.T) {
+	s := NewEventSampler(NewAPIClient(NewConfiguration("/"), NewStdLogger(ioutil.Discard)))
+	s.Set("StasisStart", SampleRule{Every: 2})
+	s.KeepOnly("StasisStart", "StasisEnd")
+
+	for typ, want := range map[string]bool{"StasisEnd": true, "ChannelVarset": false, "Dial": false} {
+		if got := s.Keep(StasisEvent{Type: typ}); got != want {
+			t.Errorf("Keep(%s) = %v, want %v", typ, got, want)
+		}
+	}
+	// The rule set before KeepOnly still samples.
+	if !s.Keep(StasisEvent{Type: "StasisStart"}) || s.Keep(StasisEvent{Type: "StasisStart"}) {
+		t.Error("StasisStart not sampled one out of two")
+	}
+}
@@ -0,0 +1,89 @@
+package asterisk_ari_go
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestDecodeEventCorpus decodes the payloads recorded from every supported Asterisk version in
+// testdata/events/<version>/<type>.json. New payload variations belong in the corpus.
+func TestDecodeEventCorpus(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "events", "*", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("empty event corpus")
+	}
+
+	for _, file := range files {
+		file := file
+		version := filepath.Base(filepath.Dir(file))
+		eventType := strings.TrimSuffix(filepath.Base(file), ".json")
+		t.Run(version+"/"+eventType, func(t *testing.T) {
+			payload, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var ev StasisEvent
+			if err := json.Unmarshal(payload, &ev); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if ev.Type != eventType {
+				t.Errorf("type = %q, want %q", ev.Type, eventType)
+			}
+			if ev.Timestamp.Timestamp.IsZero() {
+				t.Error("timestamp not decoded")
+			}
+			if ev.Channel.Id == "" && (ev.Caller == nil || ev.Caller.Id == "") {
+				t.Error("channel not decoded")
+			}
+
+			encoded, err := json.Marshal(ev)
+			if err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			var again StasisEvent
+			if err := json.Unmarshal(encoded, &again); err != nil {
+				t.Fatalf("decode after encode: %v", err)
+			}
+			if !reflect.DeepEqual(ev, again) {
+				t.Errorf("event does not round-trip:\n%+v\n%+v", ev, again)
+			}
+		})
+	}
+}
+
+func TestDecodeEventTimestamp(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		zero    bool
+		wantErr bool
+	}{
+		{name: "milliseconds", payload: `{"timestamp":"2024-03-01T10:00:01.456+0000"}`},
+		{name: "seconds", payload: `{"timestamp":"2024-03-01T10:00:01+0000"}`},
+		{name: "rfc3339", payload: `{"timestamp":"2024-03-01T10:00:01.456Z"}`},
+		{name: "null", payload: `{"timestamp":null}`, zero: true},
+		{name: "empty", payload: `{"timestamp":""}`, zero: true},
+		{name: "absent", payload: `{}`, zero: true},
+		{name: "garbage", payload: `{"timestamp":"yesterday"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ev StasisEvent
+			err := json.Unmarshal([]byte(tt.payload), &ev)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && ev.Timestamp.Timestamp.IsZero() != tt.zero {
+				t.Errorf("zero = %v, want %v", ev.Timestamp.Timestamp.IsZero(), tt.zero)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+module github.com/olegromanchuk/asterisk-ari-go/examples
+
+go 1.18
+
+require (
+	github.com/joho/godotenv v1.5.1
+	github.com/olegromanchuk/asterisk-ari-go v0.0.0
+	github.com/sirupsen/logrus v1.9.3
+)
+
+require (
+	github.com/antihax/optional v1.0.0 // indirect
+	github.com/gorilla/websocket v1.5.3 // indirect
+	golang.org/x/net v0.0.0-20220624214902-1bab6f366d9e // indirect
+	golang.org/x/oauth2 v0.0.0-20220722155238-128564f6959c // indirect
+	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/olegromanchuk/asterisk-ari-go => ../
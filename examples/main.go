@@ -3,7 +3,6 @@ package main
 import (
 	"context"
 	"encoding/json"
-	"github.com/gorilla/websocket"
 	"github.com/joho/godotenv"
 	asterisk_ari_go "github.com/olegromanchuk/asterisk-ari-go"
 	"github.com/sirupsen/logrus"
@@ -36,7 +35,6 @@ func main() {
 		UserName: ariUser,
 		Password: ariPass,
 	}
-	authParam := ariUser + ":" + ariPass
 
 	//set up logger
 	logger := logrus.New()
@@ -54,6 +52,8 @@ func main() {
 	conf.Host = ariHost
 	conf.Scheme = "ws"
 	conf.UserAgent = "ARI_Client"
+	// Detect dead links behind NATs and firewalls, so that the managed connection reconnects.
+	conf.PingInterval = 30 * time.Second
 
 	logger.Infof("initializing ARI client app \"%s\" with next values: host: %s, user: %s, pass: %s", appName, ariHost, ariUser, "********")
 	ariClient := asterisk_ari_go.NewAPIClient(conf, logger)
@@ -63,13 +63,28 @@ func main() {
 	defer cancel() // Ensure the context is canceled when main exits
 	ctx = context.WithValue(ctx, asterisk_ari_go.ContextBasicAuth, basicAuth)
 
+	ariClient.On("StasisStart", func(event asterisk_ari_go.StasisEvent) {
+		handler.Logger.Debugf("Received StasisStart message, app:%s, chType:%s\n", event.Application, event.Type)
+	})
+	ariClient.On("StasisEnd", func(event asterisk_ari_go.StasisEvent) {
+		handler.Logger.Debugf("Received StasisEnd message, app:%s, chType:%s\n", event.Application, event.Type)
+	})
+	ariClient.On(asterisk_ari_go.AnyEvent, func(event asterisk_ari_go.StasisEvent) {
+		formattedJSON, _ := json.MarshalIndent(event, "", "  ")
+		handler.Logger.Debugf("Received message: \n%s\n", string(formattedJSON))
+	})
+
+	// The managed connection reconnects with exponential backoff when the websocket drops.
+	conn := ariClient.WebsocketApi.NewManagedConnection([]string{appName}, ariUser, ariPass)
+
 	var wg sync.WaitGroup
 	wg.Add(1)
 
 	go func(ctx context.Context) {
 		defer wg.Done()
-		//call function that will connect to Asterisk and start Stasis application
-		handler.connectWebSocketAndStartStasis(ctx, authParam, ariClient, appName)
+		if err := conn.Run(ctx); err != nil && ctx.Err() == nil {
+			logger.Errorf("event connection ended: %v", err)
+		}
 	}(ctx)
 	logger.Info("started Asterisk ARI application goroutine")
 
@@ -77,95 +92,3 @@ func main() {
 	logger.Info("\ntermination signal received. Shutting down gracefully.")
 	logger.Info("shutdown complete.")
 }
-
-func (h handler) connectWebSocketAndStartStasis(ctx context.Context, authParam string, APIRESTClient *asterisk_ari_go.APIClient, appName string) {
-	const (
-		initialDelay = 1 * time.Second
-		maxDelay     = 60 * time.Second
-	)
-
-	var wsConn *websocket.Conn
-	var err error
-	delay := initialDelay
-
-	for {
-		select {
-		case <-ctx.Done():
-			h.Logger.Debug("Context canceled, shutting down WebSocket connection.")
-			return
-		default:
-			// Connect via WebSocket
-			wsConn, _, err = APIRESTClient.WebsocketApi.WebsocketConnect(ctx, []string{appName}, []string{authParam})
-			if err != nil {
-				h.Logger.Errorf("Failed to connect via WebSocket: %v. Retrying in %v...", err, delay)
-				// Use context-aware sleep
-				select {
-				case <-ctx.Done():
-					h.Logger.Debug("Context canceled during backoff. Exiting.")
-					return
-				case <-time.After(delay):
-				}
-				delay = min(2*delay, maxDelay) // Exponential backoff
-				continue
-			}
-			defer wsConn.Close()
-			h.Logger.Debug("webSocket connection successfully established")
-
-			// Reset delay on successful connection
-			delay = initialDelay
-
-			// Listen for incoming messages
-		receiveLoop: //is needed to break to outer loop where socket reconnect is happening. If server restarts and socket closes, we need to reconnect.
-			for {
-				select {
-				case <-ctx.Done():
-					h.Logger.Debug("Context canceled, shutting down WebSocket connection.")
-					defer wsConn.Close()
-					return
-				default:
-					_, message, err := wsConn.ReadMessage()
-					if err != nil {
-						h.Logger.Errorf("Read error: %v. Attempting to reconnect...", err)
-						break receiveLoop
-					}
-
-					var event asterisk_ari_go.StasisEvent
-					err = json.Unmarshal(message, &event)
-					if err != nil {
-						h.Logger.Errorf("Error parsing JSON: %v", err)
-					} else {
-						formattedJSON, _ := json.MarshalIndent(event, "", "  ")
-						h.Logger.Debugf("Received message: \n%s\n", string(formattedJSON))
-
-						// Process StasisStart
-						switch event.Type {
-						case "StasisStart":
-							h.Logger.Debugf("Received StasisStart message, app:%s, chType:%s\n", event.Application, event.Type)
-						case "StasisEnd":
-							h.Logger.Debugf("Received StasisEnd message, app:%s, chType:%s\n", event.Application, event.Type)
-						default:
-							h.Logger.Debugf("Unhandled event type: %s", event.Type)
-						}
-					}
-				}
-			}
-		}
-
-		// Wait before attempting to reconnect
-		h.Logger.Infof("Reconnecting in %v...", delay)
-		select {
-		case <-ctx.Done():
-			h.Logger.Debug("Context canceled during reconnect wait. Exiting.")
-			return
-		case <-time.After(delay):
-		}
-		delay = min(2*delay, maxDelay) // Exponential backoff
-	}
-}
-
-func min(a, b time.Duration) time.Duration {
-	if a < b {
-		return a
-	}
-	return b
-}
@@ -0,0 +1,113 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// DefaultFanOutParallelism is the number of calls FanOut keeps in flight by default.
+const DefaultFanOutParallelism = 8
+
+// FanOutOpts configures FanOut.
+type FanOutOpts struct {
+	// Parallelism bounds the calls in flight. Defaults to DefaultFanOutParallelism.
+	Parallelism int
+	// Pacer, if set, paces the calls under PaceKey, e.g. to share the request budget of Asterisk
+	// with the originates of an OriginatePacer.
+	Pacer   *OriginatePacer
+	PaceKey string
+	// StopOnError cancels the calls not started yet, or waiting for the Pacer, after the first
+	// failure; they fail with context.Canceled. The calls in flight carry on.
+	StopOnError bool
+}
+
+// FanOutResult is the outcome of the call of one item.
+type FanOutResult[R any] struct {
+	Value R
+	Err   error
+}
+
+// FanOutError reports the items whose call failed.
+type FanOutError struct {
+	Total int
+	// Errs holds the error of every failed item by index.
+	Errs map[int]error
+}
+
+// first returns the index of the first failed item.
+func (e *FanOutError) first() int {
+	first := -1
+	for i := range e.Errs {
+		if first < 0 || i < first {
+			first = i
+		}
+	}
+	return first
+}
+
+func (e *FanOutError) Error() string {
+	first := e.first()
+	return fmt.Sprintf("%d of %d calls failed, first: item %d: %v", len(e.Errs), e.Total, first, e.Errs[first])
+}
+
+// Unwrap returns the error of the first failed item, so that errors.Is matches it.
+func (e *FanOutError) Unwrap() error {
+	return e.Errs[e.first()]
+}
+
+// FanOut calls call for every item concurrently, e.g. to set many variables or subscribe many
+// endpoints, with at most opts.Parallelism calls in flight. It returns the result of every item,
+// in the order of items, and a *FanOutError if any call failed. Items not called because ctx is
+// done fail with its error.
+func FanOut[T any, R any](ctx context.Context, client *APIClient, items []T, opts *FanOutOpts, call func(ctx context.Context, item T) (R, error)) ([]FanOutResult[R], error) {
+	if opts == nil {
+		opts = &FanOutOpts{}
+	}
+	parallelism := opts.Parallelism
+	if parallelism <= 0 {
+		parallelism = DefaultFanOutParallelism
+	}
+	// stop ends the calls not started yet on StopOnError; the calls in flight keep ctx.
+	stop, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]FanOutResult[R], len(items))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < parallelism && w < len(items); w++ {
+		wg.Add(1)
+		client.goTracked("fanout", func() {
+			defer wg.Done()
+			for i := range next {
+				r := &results[i]
+				if r.Err = stop.Err(); r.Err == nil && opts.Pacer != nil {
+					r.Err = opts.Pacer.Wait(stop, opts.PaceKey)
+				}
+				if r.Err == nil {
+					r.Value, r.Err = call(ctx, items[i])
+				}
+				if r.Err != nil && opts.StopOnError {
+					cancel()
+				}
+			}
+		})
+	}
+	for i := range items {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	failed := &FanOutError{Total: len(items), Errs: make(map[int]error)}
+	for i, r := range results {
+		if r.Err != nil {
+			failed.Errs[i] = r.Err
+		}
+	}
+	if len(failed.Errs) > 0 {
+		client.metrics().IncCounter("ari_fanout_failures_total", nil, float64(len(failed.Errs)))
+		return results, failed
+	}
+	return results, nil
+}
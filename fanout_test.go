@@ -0,0 +1,81 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFanOut(t *testing.T) {
+	client := NewAPIClient(NewConfiguration("/"), NewStdLogger(ioutil.Discard))
+	boom := errors.New("boom")
+	var inFlight, maxInFlight int32
+	results, err := FanOut(context.Background(), client, []int{1, 2, 3, 4, 5, 6}, &FanOutOpts{Parallelism: 2},
+		func(ctx context.Context, n int) (int, error) {
+			cur := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if cur <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, cur) {
+					break
+				}
+			}
+			if n%3 == 0 {
+				return 0, boom
+			}
+			return n * 10, nil
+		})
+
+	if max := atomic.LoadInt32(&maxInFlight); max > 2 {
+		t.Errorf("%d calls in flight, want at most 2", max)
+	}
+	for i, r := range results {
+		if n := i + 1; n%3 != 0 && (r.Err != nil || r.Value != n*10) {
+			t.Errorf("result %d = %v, %v, want %d", i, r.Value, r.Err, n*10)
+		}
+	}
+	var failed *FanOutError
+	if !errors.As(err, &failed) {
+		t.Fatalf("err = %v, want a *FanOutError", err)
+	}
+	if failed.Total != 6 || len(failed.Errs) != 2 || failed.Errs[2] != boom || failed.Errs[5] != boom {
+		t.Errorf("errors = %d of %d %v, want items 2 and 5 of 6", len(failed.Errs), failed.Total, failed.Errs)
+	}
+	if !errors.Is(err, boom) {
+		t.Errorf("errors.Is(%v, boom) = false", err)
+	}
+	if want := "2 of 6 calls failed, first: item 2: boom"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestFanOutStopOnError(t *testing.T) {
+	client := NewAPIClient(NewConfiguration("/"), NewStdLogger(ioutil.Discard))
+	boom := errors.New("boom")
+	var calls int32
+	results, err := FanOut(context.Background(), client, []int{0, 1, 2, 3}, &FanOutOpts{Parallelism: 1, StopOnError: true},
+		func(ctx context.Context, n int) (struct{}, error) {
+			atomic.AddInt32(&calls, 1)
+			if n == 1 {
+				return struct{}{}, boom
+			}
+			return struct{}{}, nil
+		})
+
+	if calls != 2 {
+		t.Errorf("%d calls, want 2", calls)
+	}
+	if results[0].Err != nil || results[1].Err != boom {
+		t.Errorf("results = %v, want the first to succeed and the second to fail", results)
+	}
+	for _, r := range results[2:] {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("skipped item err = %v, want context.Canceled", r.Err)
+		}
+	}
+	if !errors.Is(err, boom) {
+		t.Errorf("err = %v, want boom first", err)
+	}
+}
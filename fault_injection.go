@@ -0,0 +1,198 @@
+package asterisk_ari_go
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ErrInjectedDisconnect is returned by FaultConn.ReadMessage when a reconnect is forced.
+var ErrInjectedDisconnect = errors.New("fault injection: forced websocket disconnect")
+
+// FaultConfig describes the faults injected by a FaultInjector. Rates are probabilities between 0 and 1.
+type FaultConfig struct {
+	// RESTErrorRate is the share of REST requests answered with RESTErrorStatus without reaching Asterisk.
+	RESTErrorRate float64
+	// RESTErrorStatus defaults to 503.
+	RESTErrorStatus int
+	// RESTDelay is added to every REST request, plus a random share of RESTJitter.
+	RESTDelay  time.Duration
+	RESTJitter time.Duration
+	// FrameDropRate is the share of websocket frames silently discarded.
+	FrameDropRate float64
+	// FrameDelay is added before delivering every websocket frame.
+	FrameDelay time.Duration
+	// ReconnectEvery closes the websocket after it has been open for this long. Zero disables it.
+	ReconnectEvery time.Duration
+	// Seed makes the injected faults reproducible. Zero uses the current time.
+	Seed int64
+}
+
+// FaultStats counts the injected faults.
+type FaultStats struct {
+	RESTErrors    int
+	RESTDelayed   int
+	FramesDropped int
+	FramesDelayed int
+	Disconnects   int
+}
+
+// FaultInjector injects failures into the REST transport and the websocket connection so that
+// applications built on this client can be tested for resilience. Never use it in production.
+type FaultInjector struct {
+	cfg FaultConfig
+
+	mu    sync.Mutex
+	rnd   *rand.Rand
+	stats FaultStats
+}
+
+// NewFaultInjector creates an injector.
+func NewFaultInjector(cfg FaultConfig) *FaultInjector {
+	if cfg.RESTErrorStatus == 0 {
+		cfg.RESTErrorStatus = http.StatusServiceUnavailable
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &FaultInjector{cfg: cfg, rnd: rand.New(rand.NewSource(seed))}
+}
+
+// Stats returns the number of faults injected so far.
+func (f *FaultInjector) Stats() FaultStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.stats
+}
+
+// HTTPClient returns a client that injects REST faults before delegating to base.
+// Use it as Configuration.HTTPClient. A nil base uses http.DefaultTransport.
+func (f *FaultInjector) HTTPClient(base http.RoundTripper) *http.Client {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &http.Client{Transport: &faultTransport{f: f, base: base}}
+}
+
+// Conn wraps a websocket connection so that reads are subject to frame faults and forced disconnects.
+func (f *FaultInjector) Conn(conn *websocket.Conn) *FaultConn {
+	return &FaultConn{Conn: conn, f: f, opened: time.Now()}
+}
+
+// chance reports true with probability p and updates the counter selected by count.
+func (f *FaultInjector) chance(p float64, count func(*FaultStats)) bool {
+	if p <= 0 {
+		return false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.rnd.Float64() >= p {
+		return false
+	}
+	count(&f.stats)
+	return true
+}
+
+// restDelay returns the delay of the next REST request.
+func (f *FaultInjector) restDelay() time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	d := f.cfg.RESTDelay
+	if f.cfg.RESTJitter > 0 {
+		d += time.Duration(f.rnd.Int63n(int64(f.cfg.RESTJitter)))
+	}
+	if d > 0 {
+		f.stats.RESTDelayed++
+	}
+	return d
+}
+
+// faultTransport is the http.RoundTripper of FaultInjector.HTTPClient.
+type faultTransport struct {
+	f    *FaultInjector
+	base http.RoundTripper
+}
+
+func (t *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if d := t.f.restDelay(); d > 0 {
+		select {
+		case <-time.After(d):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+	if t.f.chance(t.f.cfg.RESTErrorRate, func(s *FaultStats) { s.RESTErrors++ }) {
+		status := t.f.cfg.RESTErrorStatus
+		body := fmt.Sprintf(`{"message":"fault injection: %s"}`, http.StatusText(status))
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+			StatusCode:    status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": []string{"application/json"}},
+			Body:          ioutil.NopCloser(bytes.NewBufferString(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+	return t.base.RoundTrip(req)
+}
+
+// FaultConn is a websocket connection with injected read faults.
+type FaultConn struct {
+	*websocket.Conn
+	f      *FaultInjector
+	opened time.Time
+}
+
+// NextReader returns a reader for the next frame, dropping, delaying or failing it according to
+// the fault configuration.
+func (c *FaultConn) NextReader() (int, io.Reader, error) {
+	for {
+		if every := c.f.cfg.ReconnectEvery; every > 0 && time.Since(c.opened) >= every {
+			c.f.mu.Lock()
+			c.f.stats.Disconnects++
+			c.f.mu.Unlock()
+			c.Conn.Close()
+			return 0, nil, ErrInjectedDisconnect
+		}
+
+		messageType, r, err := c.Conn.NextReader()
+		if err != nil {
+			return messageType, r, err
+		}
+		if c.f.chance(c.f.cfg.FrameDropRate, func(s *FaultStats) { s.FramesDropped++ }) {
+			if _, err := io.Copy(ioutil.Discard, r); err != nil {
+				return messageType, nil, err
+			}
+			continue
+		}
+		if c.f.cfg.FrameDelay > 0 {
+			c.f.mu.Lock()
+			c.f.stats.FramesDelayed++
+			c.f.mu.Unlock()
+			time.Sleep(c.f.cfg.FrameDelay)
+		}
+		return messageType, r, nil
+	}
+}
+
+// ReadMessage reads the next frame, dropping, delaying or failing it according to the fault configuration.
+func (c *FaultConn) ReadMessage() (int, []byte, error) {
+	messageType, r, err := c.NextReader()
+	if err != nil {
+		return messageType, nil, err
+	}
+	data, err := ioutil.ReadAll(r)
+	return messageType, data, err
+}
@@ -0,0 +1,102 @@
+package asterisk_ari_go
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// The experimental behaviors of the library, disabled unless enabled with EnableExperimental.
+const (
+	// ExperimentalRESTOverWebsocket enables WebsocketRESTTransport, REST requests over the event
+	// websocket, including the ones sent over the connections of an OutboundServer.
+	ExperimentalRESTOverWebsocket = "rest_over_ws"
+	// ExperimentalOutboundWebsocket enables OutboundServer, the websockets opened by Asterisk.
+	ExperimentalOutboundWebsocket = "outbound_ws"
+)
+
+// ErrExperimentalDisabled is returned by an experimental behavior whose flag is off.
+var ErrExperimentalDisabled = errors.New("experimental behavior is disabled")
+
+// Flags is a set of named feature flags gating experimental behaviors, the Experimental ones of
+// the library and any of the application. Components check their flag when they start an
+// operation, so flags can be flipped at runtime, through APIClient.Flags, to roll a behavior out
+// or back without a restart. It is safe for concurrent use; the zero value has every flag
+// disabled.
+type Flags struct {
+	mu      sync.RWMutex
+	enabled map[string]bool
+}
+
+// Enable turns flags on.
+func (f *Flags) Enable(names ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.enabled == nil {
+		f.enabled = make(map[string]bool)
+	}
+	for _, name := range names {
+		f.enabled[name] = true
+	}
+}
+
+// Disable turns flags off.
+func (f *Flags) Disable(names ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, name := range names {
+		delete(f.enabled, name)
+	}
+}
+
+// Enabled reports whether a flag is on. A nil Flags has every flag off.
+func (f *Flags) Enabled(name string) bool {
+	if f == nil {
+		return false
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.enabled[name]
+}
+
+// List returns the flags that are on, sorted.
+func (f *Flags) List() []string {
+	if f == nil {
+		return nil
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	names := make([]string, 0, len(f.enabled))
+	for name := range f.enabled {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EnableExperimental turns experimental behaviors on, see Flags.
+func (c *Configuration) EnableExperimental(names ...string) {
+	if c.Flags == nil {
+		c.Flags = &Flags{}
+	}
+	c.Flags.Enable(names...)
+}
+
+// Flags returns the feature flags of the client, to query or change them at runtime.
+func (c *APIClient) Flags() *Flags {
+	return c.cfg.Flags
+}
+
+// Experimental reports whether an experimental behavior is enabled.
+func (c *APIClient) Experimental(name string) bool {
+	return c.cfg.Flags.Enabled(name)
+}
+
+// requireExperimental returns an error wrapping ErrExperimentalDisabled if the flag is off.
+func (c *APIClient) requireExperimental(name string) error {
+	if c.Experimental(name) {
+		return nil
+	}
+	return fmt.Errorf("%w: enable %q with Configuration.EnableExperimental", ErrExperimentalDisabled, name)
+}
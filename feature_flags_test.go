@@ -0,0 +1,35 @@
+package asterisk_ari_go
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExperimentalDisabled(t *testing.T) {
+	cfg := NewConfiguration("/")
+	client := NewAPIClient(cfg, NewStdLogger(ioutil.Discard))
+
+	transport := client.WebsocketApi.NewRESTTransport(nil)
+	req, _ := http.NewRequest(http.MethodGet, "http://asterisk.invalid/ari/channels", nil)
+	if _, err := transport.RoundTrip(req); !errors.Is(err, ErrExperimentalDisabled) {
+		t.Errorf("REST over websocket err = %v, want ErrExperimentalDisabled", err)
+	}
+
+	server := client.WebsocketApi.NewOutboundServer(nil)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ari/events", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("outbound websocket status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	// Flags are checked on every connection, so enabling one at runtime takes effect.
+	client.Flags().Enable(ExperimentalOutboundWebsocket)
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ari/events", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("outbound websocket status = %d, want %d without Authenticate", rec.Code, http.StatusUnauthorized)
+	}
+}
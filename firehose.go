@@ -0,0 +1,63 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// DefaultFirehoseBuffer is the number of events a Firehose queues for a slow consumer by default.
+const DefaultFirehoseBuffer = 4096
+
+// Firehose mirrors every event to a secondary consumer, e.g. an analytics sidecar, on a goroutine
+// of its own, so that the consumer never delays the call-control handlers. Feed it every event
+// with HandleEvent after the primary handlers, and run Run. A consumer that falls behind by more
+// than the buffer loses events, counted in ari_firehose_dropped_total; HandleEvent never blocks.
+type Firehose struct {
+	client   *APIClient
+	consumer func(StasisEvent)
+	queue    chan StasisEvent
+	dropped  uint64
+}
+
+// NewFirehose creates a firehose delivering the events to consumer, buffering up to buffer
+// events; 0 uses DefaultFirehoseBuffer.
+func NewFirehose(client *APIClient, consumer func(StasisEvent), buffer int) *Firehose {
+	if buffer <= 0 {
+		buffer = DefaultFirehoseBuffer
+	}
+	return &Firehose{client: client, consumer: consumer, queue: make(chan StasisEvent, buffer)}
+}
+
+// HandleEvent queues an event for the consumer, dropping it if the buffer is full.
+func (f *Firehose) HandleEvent(ev StasisEvent) {
+	select {
+	case f.queue <- ev:
+	default:
+		atomic.AddUint64(&f.dropped, 1)
+		f.client.metrics().IncCounter("ari_firehose_dropped_total", map[string]string{"type": ev.Type}, 1)
+	}
+}
+
+// Run delivers the queued events to the consumer until ctx is done. A panic of the consumer is
+// logged and the delivery goes on with the next event.
+func (f *Firehose) Run(ctx context.Context) error {
+	deliver := NewCrashGuard(f.client).Wrap("firehose", f.consumer)
+	for {
+		select {
+		case ev := <-f.queue:
+			deliver(ev)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Dropped returns the number of events lost because the consumer fell behind.
+func (f *Firehose) Dropped() uint64 {
+	return atomic.LoadUint64(&f.dropped)
+}
+
+// Backlog returns the number of events queued for the consumer.
+func (f *Firehose) Backlog() int {
+	return len(f.queue)
+}
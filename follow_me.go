@@ -0,0 +1,195 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/antihax/optional"
+)
+
+// DefaultFollowMeConfirmTimeout is how long the answering party has to accept by default.
+const DefaultFollowMeConfirmTimeout = 10 * time.Second
+
+// ErrNobodyAccepted is returned by FollowMe.Find when no destination accepted the call.
+var ErrNobodyAccepted = errors.New("follow-me: no destination accepted the call")
+
+// FollowMeStage rings Endpoints simultaneously for Timeout.
+type FollowMeStage struct {
+	Endpoints []string      `json:"endpoints"`
+	Timeout   time.Duration `json:"timeout"`
+}
+
+// FollowMe finds a person at a list of destinations rung in stages, e.g. the desk phone, then
+// the mobile and the home phone. The answering party must press ConfirmDigit to accept the call,
+// so that a voicemail answering a mobile does not capture it; the caller, hearing ringback
+// meanwhile, is bridged with whoever accepts first. Every event must be fed to HandleEvent.
+type FollowMe struct {
+	client *APIClient
+	waits  *Waits
+
+	// App is the Stasis application the destinations are originated into.
+	App    string
+	Stages []FollowMeStage
+	// ConfirmPrompt is played to the answering party, e.g. "you have a call, press 1 to accept".
+	ConfirmPrompt []string
+	// ConfirmDigit accepts the call. Defaults to "1".
+	ConfirmDigit string
+	// ConfirmTimeout is how long the answering party has to accept. Defaults to
+	// DefaultFollowMeConfirmTimeout.
+	ConfirmTimeout time.Duration
+}
+
+// NewFollowMe creates a follow-me of stages originating into app.
+func NewFollowMe(client *APIClient, app string, stages ...FollowMeStage) *FollowMe {
+	waits := NewWaits(client)
+	waits.Prefix = "ari-followme"
+	return &FollowMe{
+		client:         client,
+		waits:          waits,
+		App:            app,
+		Stages:         stages,
+		ConfirmPrompt:  []string{"sound:followme/call-from", "sound:followme/options"},
+		ConfirmDigit:   "1",
+		ConfirmTimeout: DefaultFollowMeConfirmTimeout,
+	}
+}
+
+// HandleEvent feeds an event received from Asterisk into the follow-me.
+func (f *FollowMe) HandleEvent(ev StasisEvent) {
+	f.waits.HandleEvent(ev)
+}
+
+// Find rings the stages in turn for callerId, a channel of the application, and bridges it with
+// the first destination accepting the call. It returns the ID of the bridge and the endpoint that
+// accepted.
+func (f *FollowMe) Find(ctx context.Context, callerId string) (string, string, error) {
+	callerEvents, done := f.waits.subscribe("channel:" + callerId)
+	defer done()
+	search, cancel := context.WithCancel(ctx)
+	defer cancel()
+	f.client.goTracked("followme", func() {
+		for {
+			select {
+			case ev := <-callerEvents:
+				if ev.Type == "StasisEnd" || ev.Type == "ChannelDestroyed" {
+					cancel()
+					return
+				}
+			case <-search.Done():
+				return
+			}
+		}
+	})
+
+	f.client.ChannelsApi.Ring(search, callerId)
+	for i, stage := range f.Stages {
+		winner, endpoint, ok := f.ring(search, callerId, stage)
+		if ok {
+			bridgeId, err := f.connect(search, callerId, winner)
+			if err == nil {
+				f.client.logger.Infof("follow-me: %s accepted %s at stage %d", endpoint, callerId, i+1)
+			}
+			return bridgeId, endpoint, err
+		}
+		if search.Err() != nil {
+			break
+		}
+	}
+	f.client.ChannelsApi.RingStop(context.Background(), callerId)
+	if err := search.Err(); err != nil {
+		if ctx.Err() == nil {
+			return "", "", ErrChannelGone
+		}
+		return "", "", err
+	}
+	return "", "", ErrNobodyAccepted
+}
+
+// ring rings the endpoints of a stage and returns the channel of the first party accepting.
+// Parties answering after it, or not accepting, are hung up.
+func (f *FollowMe) ring(ctx context.Context, callerId string, stage FollowMeStage) (string, string, bool) {
+	// ringing bounds the ringing to the stage timeout; confirming lets answered parties accept
+	// past it.
+	ringing, stopRinging := context.WithTimeout(ctx, stage.Timeout)
+	defer stopRinging()
+	confirming, stopConfirming := context.WithCancel(ctx)
+	defer stopConfirming()
+
+	type accepted struct{ channelId, endpoint string }
+	winner := make(chan accepted, 1)
+	var wg sync.WaitGroup
+	for _, endpoint := range stage.Endpoints {
+		endpoint := endpoint
+		wg.Add(1)
+		f.client.goTracked("followme", func() {
+			defer wg.Done()
+			channel, err := f.waits.OriginateAndWait(ringing, endpoint, &ChannelsApiOriginateWithIdOpts{
+				App:     optional.NewString(f.App),
+				AppArgs: optional.NewString("followme," + callerId),
+				Timeout: optional.NewInt32(int32(stage.Timeout / time.Second)),
+			})
+			if err != nil {
+				return
+			}
+			if f.confirm(confirming, channel.Id) {
+				select {
+				case winner <- accepted{channel.Id, endpoint}:
+					stopRinging()
+					stopConfirming()
+					return
+				default:
+				}
+			}
+			f.client.ChannelsApi.Hangup(context.Background(), channel.Id, &ChannelsApiHangupOpts{Reason: optional.NewString("normal")})
+		})
+	}
+	wg.Wait()
+	select {
+	case w := <-winner:
+		return w.channelId, w.endpoint, true
+	default:
+		return "", "", false
+	}
+}
+
+// confirm asks the answering party to accept the call.
+func (f *FollowMe) confirm(ctx context.Context, channelId string) bool {
+	timeout := f.ConfirmTimeout
+	if timeout <= 0 {
+		timeout = DefaultFollowMeConfirmTimeout
+	}
+	digit := f.ConfirmDigit
+	if digit == "" {
+		digit = "1"
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	pressed, err := f.waits.CollectDigits(ctx, channelId, &CollectOpts{Max: 1, Prompt: f.ConfirmPrompt, FirstTimeout: timeout})
+	return err == nil && pressed == digit
+}
+
+// connect bridges the caller with the party that accepted.
+func (f *FollowMe) connect(ctx context.Context, callerId string, channelId string) (string, error) {
+	hangup := func() {
+		f.client.ChannelsApi.Hangup(context.Background(), channelId, &ChannelsApiHangupOpts{Reason: optional.NewString("normal")})
+	}
+	if ctx.Err() != nil {
+		hangup()
+		return "", ErrChannelGone
+	}
+	f.client.ChannelsApi.RingStop(ctx, callerId)
+	f.client.ChannelsApi.Answer(ctx, callerId)
+	bridge, _, err := f.client.BridgesApi.Create(ctx, &BridgesApiCreateOpts{Type_: optional.NewString("mixing")})
+	if err == nil {
+		if _, err = f.client.BridgesApi.AddChannel(ctx, bridge.Id, []string{callerId, channelId}, nil); err != nil {
+			f.client.BridgesApi.Destroy(context.Background(), bridge.Id)
+		}
+	}
+	if err != nil {
+		hangup()
+		return "", err
+	}
+	return bridge.Id, nil
+}
@@ -0,0 +1,75 @@
+package asterisk_ari_go
+
+import (
+	"context"
+)
+
+// Future is the pending result of an asynchronous operation. Done is closed once the operation
+// completes; Result then returns its outcome. With errgroup:
+//
+//	g.Go(func() error { _, err := f.Wait(ctx); return err })
+type Future[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+}
+
+// newFuture runs f in an accounted goroutine of client and returns its pending result.
+func newFuture[T any](client *APIClient, owner string, f func() (T, error)) *Future[T] {
+	fut := &Future[T]{done: make(chan struct{})}
+	client.goTracked(owner, func() {
+		defer close(fut.done)
+		fut.value, fut.err = f()
+	})
+	return fut
+}
+
+// Done is closed when the operation completes.
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Result returns the outcome of the operation. It must only be called once Done is closed; before
+// that it returns the zero value and ErrFuturePending.
+func (f *Future[T]) Result() (T, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	default:
+		var zero T
+		return zero, ErrFuturePending
+	}
+}
+
+// Wait blocks until the operation completes or ctx is done. A done ctx does not cancel the
+// operation, only the wait; the operation is cancelled through the context it was started with.
+func (f *Future[T]) Wait(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// PlayAsync is PlayAndWait returning a Future.
+func (w *Waits) PlayAsync(ctx context.Context, channelId string, media []string, opts *ChannelsApiPlaySoundWithIdOpts) *Future[Playback] {
+	return newFuture(w.client, "waits", func() (Playback, error) {
+		return w.PlayAndWait(ctx, channelId, media, opts)
+	})
+}
+
+// RecordAsync is RecordAndWait returning a Future.
+func (w *Waits) RecordAsync(ctx context.Context, channelId string, name string, format string, opts *ChannelsApiRecordchannelOpts) *Future[LiveRecording] {
+	return newFuture(w.client, "waits", func() (LiveRecording, error) {
+		return w.RecordAndWait(ctx, channelId, name, format, opts)
+	})
+}
+
+// OriginateAsync is OriginateAndWait returning a Future.
+func (w *Waits) OriginateAsync(ctx context.Context, endpoint string, opts *ChannelsApiOriginateWithIdOpts) *Future[Channel] {
+	return newFuture(w.client, "waits", func() (Channel, error) {
+		return w.OriginateAndWait(ctx, endpoint, opts)
+	})
+}
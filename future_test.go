@@ -0,0 +1,42 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestFuture(t *testing.T) {
+	client := NewAPIClient(NewConfiguration("/"), NewStdLogger(ioutil.Discard))
+	boom := errors.New("boom")
+	release := make(chan struct{})
+	f := newFuture(client, "test", func() (int, error) {
+		<-release
+		return 42, boom
+	})
+
+	if _, err := f.Result(); err != ErrFuturePending {
+		t.Errorf("Result before Done: err = %v, want ErrFuturePending", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := f.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Wait with a done ctx: err = %v, want context.DeadlineExceeded", err)
+	}
+
+	// The wait gave up, the operation did not.
+	close(release)
+	if v, err := f.Wait(context.Background()); v != 42 || err != boom {
+		t.Errorf("Wait = %d, %v, want 42, boom", v, err)
+	}
+	select {
+	case <-f.Done():
+	default:
+		t.Fatal("Done not closed after Wait returned")
+	}
+	if v, err := f.Result(); v != 42 || err != boom {
+		t.Errorf("Result = %d, %v, want 42, boom", v, err)
+	}
+}
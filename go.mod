@@ -1,11 +1,17 @@
 module github.com/olegromanchuk/asterisk-ari-go
 
-go 1.16
+go 1.18
 
 require (
 	github.com/antihax/optional v1.0.0
 	github.com/gorilla/websocket v1.5.3
-	github.com/joho/godotenv v1.5.1
-	github.com/sirupsen/logrus v1.9.3
 	golang.org/x/oauth2 v0.0.0-20220722155238-128564f6959c
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/golang/protobuf v1.5.2 // indirect
+	golang.org/x/net v0.0.0-20220624214902-1bab6f366d9e // indirect
+	google.golang.org/appengine v1.6.7 // indirect
+	google.golang.org/protobuf v1.28.0 // indirect
 )
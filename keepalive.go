@@ -0,0 +1,49 @@
+package asterisk_ari_go
+
+import (
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// DefaultPongTimeout is how long a pong is awaited when Configuration.PongTimeout is not set.
+const DefaultPongTimeout = 10 * time.Second
+
+// keepaliveWriteTimeout bounds the write of a ping, so that a full send buffer does not block the
+// keepalive.
+const keepaliveWriteTimeout = 5 * time.Second
+
+// keepalive pings conn every interval and expects a pong within timeout. Reads fail with a
+// timeout once no pong was received for interval+timeout, so that a dead TCP link, e.g. dropped
+// by a NAT or firewall without a reset, is detected instead of blocking the reader forever. The
+// pinging stops once conn is closed. Pongs are only processed while the connection is read: an
+// event handler blocking the reader longer than the deadline disconnects it as well. The pings
+// follow the Clock of the client; the deadlines of the socket are against the real time of the
+// OS, whatever the Clock.
+func (a *WebsocketApiService) keepalive(conn *websocket.Conn, interval time.Duration, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultPongTimeout
+	}
+	extend := func() error {
+		return conn.SetReadDeadline(time.Now().Add(interval + timeout))
+	}
+	extend()
+	conn.SetPongHandler(func(string) error {
+		a.client.metrics().IncCounter("ari_websocket_pongs_total", nil, 1)
+		return extend()
+	})
+
+	a.client.goTracked("keepalive", func() {
+		timer := a.client.clock().NewTimer(interval)
+		defer timer.Stop()
+		for range timer.C() {
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(keepaliveWriteTimeout)); err != nil {
+				if err != websocket.ErrCloseSent {
+					a.client.logger.Debugf("websocket keepalive: ping failed, stopping: %v", err)
+				}
+				return
+			}
+			timer.Reset(interval)
+		}
+	})
+}
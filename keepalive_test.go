@@ -0,0 +1,72 @@
+package asterisk_ari_go
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// TestKeepaliveFakeClock covers a client Clock far behind real time: the pings follow it, and the
+// socket deadlines do not expire at once.
+func TestKeepaliveFakeClock(t *testing.T) {
+	pings := make(chan struct{}, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetPingHandler(func(data string) error {
+			pings <- struct{}{}
+			return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	cfg := NewConfiguration("/")
+	clock := NewFakeClock(time.Unix(0, 0))
+	cfg.Clock = clock
+	client := NewAPIClient(cfg, NewStdLogger(ioutil.Discard))
+	client.WebsocketApi.keepalive(conn, time.Second, time.Second)
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < 2; i++ {
+		waitTimers(t, clock, 1)
+		clock.Advance(time.Second)
+		select {
+		case <-pings:
+		case err := <-readErr:
+			t.Fatalf("read failed: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("ping %d not sent", i+1)
+		}
+	}
+	select {
+	case err := <-readErr:
+		t.Fatalf("read failed: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,168 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/antihax/optional"
+)
+
+// LatencyProbeEvent is the name of the user events sent by LatencyProber.
+const LatencyProbeEvent = "AriLatencyProbe"
+
+// ErrProbeTimeout is returned when a probe user event was not received back in time.
+var ErrProbeTimeout = errors.New("latency probe event not received")
+
+// LatencySample is the result of one probe.
+type LatencySample struct {
+	At time.Time
+	// RestRTT is the round-trip time of GET /asterisk/ping.
+	RestRTT time.Duration
+	// EventLatency is the time between sending a user event and receiving it on the websocket.
+	// Zero if the event was not received.
+	EventLatency time.Duration
+}
+
+// LatencyProber periodically measures the control plane latency towards Asterisk: the REST
+// round trip of /asterisk/ping and the propagation delay of a user event echoed through the
+// websocket of App. Every event received on that websocket must be fed to HandleEvent.
+//
+// Samples are exported as the "ari_rest_rtt_seconds" and "ari_event_latency_seconds"
+// histograms, labelled with Connection. Lost probe events increment "ari_event_probe_lost_total".
+type LatencyProber struct {
+	client *APIClient
+	app    string
+
+	// Connection labels the exported metrics. Defaults to the configured host.
+	Connection string
+	// Interval between probes. Defaults to 10 seconds.
+	Interval time.Duration
+	// Timeout for the user event to come back. Defaults to 5 seconds.
+	Timeout time.Duration
+
+	mu      sync.Mutex
+	pending map[string]chan time.Time
+	last    LatencySample
+}
+
+// NewLatencyProber creates a prober sending its user events to app.
+func NewLatencyProber(client *APIClient, app string) *LatencyProber {
+	return &LatencyProber{
+		client:     client,
+		app:        app,
+		Connection: client.cfg.Host,
+		Interval:   10 * time.Second,
+		Timeout:    5 * time.Second,
+		pending:    make(map[string]chan time.Time),
+	}
+}
+
+// HandleEvent feeds an event received from Asterisk into the prober.
+func (p *LatencyProber) HandleEvent(ev StasisEvent) {
+	if ev.Type != "ChannelUserevent" || ev.Eventname != LatencyProbeEvent {
+		return
+	}
+	id, _ := ev.Userevent["probe_id"].(string)
+	p.mu.Lock()
+	ch, ok := p.pending[id]
+	p.mu.Unlock()
+	if ok {
+		select {
+		case ch <- p.client.clock().Now():
+		default:
+		}
+	}
+}
+
+// Probe measures the REST round trip and the event propagation latency once.
+// The REST measurement is returned even if the user event is lost.
+func (p *LatencyProber) Probe(ctx context.Context) (LatencySample, error) {
+	labels := map[string]string{"connection": p.Connection}
+	clock := p.client.clock()
+	sample := LatencySample{At: clock.Now()}
+
+	start := clock.Now()
+	if _, _, err := p.client.AsteriskApi.Ping(ctx); err != nil {
+		return sample, err
+	}
+	sample.RestRTT = clock.Now().Sub(start)
+	p.client.metrics().Observe("ari_rest_rtt_seconds", labels, sample.RestRTT.Seconds())
+
+	id := newResourceId("probe")
+	received := make(chan time.Time, 1)
+	p.mu.Lock()
+	p.pending[id] = received
+	p.mu.Unlock()
+	p.client.TrackResource(ResourceSubscription, "latency_prober", 1)
+	defer func() {
+		p.mu.Lock()
+		delete(p.pending, id)
+		p.mu.Unlock()
+		p.client.TrackResource(ResourceSubscription, "latency_prober", -1)
+	}()
+
+	sent := clock.Now()
+	opts := &EventsApiUserEventOpts{Variables: optional.NewInterface(Containers{Variables: map[string]string{"probe_id": id}})}
+	if _, err := p.client.EventsApi.UserEvent(ctx, LatencyProbeEvent, p.app, opts); err != nil {
+		p.store(sample)
+		return sample, err
+	}
+
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	timer := clock.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case at := <-received:
+		sample.EventLatency = at.Sub(sent)
+		p.client.metrics().Observe("ari_event_latency_seconds", labels, sample.EventLatency.Seconds())
+		p.store(sample)
+		return sample, nil
+	case <-timer.C():
+		p.client.metrics().IncCounter("ari_event_probe_lost_total", labels, 1)
+		p.store(sample)
+		return sample, ErrProbeTimeout
+	case <-ctx.Done():
+		return sample, ctx.Err()
+	}
+}
+
+// Run probes every Interval until ctx is done.
+func (p *LatencyProber) Run(ctx context.Context) error {
+	interval := p.Interval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	timer := p.client.clock().NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		if _, err := p.Probe(ctx); err != nil && ctx.Err() == nil {
+			p.client.logger.Warnf("latency prober: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C():
+			timer.Reset(interval)
+		}
+	}
+}
+
+// Last returns the most recent sample.
+func (p *LatencyProber) Last() LatencySample {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.last
+}
+
+func (p *LatencyProber) store(sample LatencySample) {
+	p.mu.Lock()
+	p.last = sample
+	p.mu.Unlock()
+}
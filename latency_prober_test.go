@@ -0,0 +1,33 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestLatencyProbeLost covers a probe event that never comes back, timed out on the client clock.
+func TestLatencyProbeLost(t *testing.T) {
+	w, clock, _ := waitsClient(t)
+	prober := NewLatencyProber(w.client, "ivr")
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := prober.Probe(context.Background())
+		result <- err
+	}()
+	waitTimers(t, clock, 1)
+	clock.Advance(prober.Timeout)
+	select {
+	case err := <-result:
+		if !errors.Is(err, ErrProbeTimeout) {
+			t.Fatalf("err = %v, want ErrProbeTimeout", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Probe did not time out")
+	}
+	if at := prober.Last().At; !at.Equal(time.Unix(0, 0)) {
+		t.Errorf("sample at %v, want the time of the clock", at)
+	}
+}
@@ -0,0 +1,188 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+// Iterator is the iteration protocol of the list endpoints, implemented by ListIterator.
+type Iterator[T any] interface {
+	Next() bool
+	Item() T
+	Err() error
+	Close() error
+}
+
+// ForEach calls fn on every item of it until the end of the list, an error of fn, or ctx being
+// done, and closes it.
+func ForEach[T any](ctx context.Context, it Iterator[T], fn func(item T) error) error {
+	defer it.Close()
+	for it.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(it.Item()); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
+
+// ListOpts configures a list iteration.
+type ListOpts struct {
+	// Fields, if set, projects every item on the given JSON fields, e.g. "id", "name" and "state"
+	// for channels; the other fields are left zero. It keeps big nested fields such as the
+	// channel variables out of memory.
+	Fields []string
+}
+
+// ListIterator streams the items of an ARI list response. ARI returns whole lists at once; the
+// iterator decodes the response one item at a time instead of building a slice, so memory stays
+// bounded by the size of one item. Cancelling the context of the request aborts the iteration.
+// It is not safe for concurrent use.
+//
+//	it, err := client.ChannelsApi.Iterate(ctx, nil)
+//	if err != nil {
+//		return err
+//	}
+//	defer it.Close()
+//	for it.Next() {
+//		ch := it.Item()
+//	}
+//	return it.Err()
+type ListIterator[T any] struct {
+	body   io.ReadCloser
+	dec    *json.Decoder
+	fields map[string]bool
+	item   T
+	err    error
+	done   bool
+}
+
+// openList requests the list at path and positions an iterator on its first item.
+func openList[T any](ctx context.Context, client *APIClient, path string, opts *ListOpts) (*ListIterator[T], error) {
+	r, err := client.prepareRequest(ctx, client.cfg.BasePath+path, http.MethodGet, nil, map[string]string{}, url.Values{}, url.Values{}, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.callAPI(r)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, GenericSwaggerError{body: body, error: resp.Status}
+	}
+
+	it := &ListIterator[T]{body: resp.Body, dec: json.NewDecoder(resp.Body)}
+	if opts != nil && len(opts.Fields) > 0 {
+		it.fields = make(map[string]bool, len(opts.Fields))
+		for _, f := range opts.Fields {
+			it.fields[f] = true
+		}
+	}
+	if tok, err := it.dec.Token(); err != nil {
+		it.Close()
+		return nil, err
+	} else if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		it.Close()
+		return nil, fmt.Errorf("list %s: expected a JSON array, got %v", path, tok)
+	}
+	return it, nil
+}
+
+// Next decodes the next item, reporting false at the end of the list or on error.
+func (it *ListIterator[T]) Next() bool {
+	if it.done {
+		return false
+	}
+	if !it.dec.More() {
+		it.finish(nil)
+		return false
+	}
+	var zero T
+	it.item = zero
+	var err error
+	if it.fields == nil {
+		err = it.dec.Decode(&it.item)
+	} else {
+		err = it.decodeProjected()
+	}
+	if err != nil {
+		it.finish(err)
+		return false
+	}
+	return true
+}
+
+// decodeProjected decodes the next item keeping only the projected fields.
+func (it *ListIterator[T]) decodeProjected() error {
+	var raw map[string]json.RawMessage
+	if err := it.dec.Decode(&raw); err != nil {
+		return err
+	}
+	for k := range raw {
+		if !it.fields[k] {
+			delete(raw, k)
+		}
+	}
+	projected, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(projected, &it.item)
+}
+
+// finish ends the iteration with err.
+func (it *ListIterator[T]) finish(err error) {
+	it.done = true
+	it.err = err
+	it.Close()
+}
+
+// Item returns the item decoded by the last call to Next.
+func (it *ListIterator[T]) Item() T {
+	return it.item
+}
+
+// Err returns the error that ended the iteration, nil at the end of the list.
+func (it *ListIterator[T]) Err() error {
+	return it.err
+}
+
+// Close releases the response. It is safe to call several times.
+func (it *ListIterator[T]) Close() error {
+	it.done = true
+	return it.body.Close()
+}
+
+// Iterate streams the active channels.
+func (a *ChannelsApiService) Iterate(ctx context.Context, opts *ListOpts) (*ListIterator[Channel], error) {
+	return openList[Channel](ctx, a.client, "/channels", opts)
+}
+
+// Iterate streams the bridges.
+func (a *BridgesApiService) Iterate(ctx context.Context, opts *ListOpts) (*ListIterator[Bridge], error) {
+	return openList[Bridge](ctx, a.client, "/bridges", opts)
+}
+
+// Iterate streams the endpoints.
+func (a *EndpointsApiService) Iterate(ctx context.Context, opts *ListOpts) (*ListIterator[Endpoint], error) {
+	return openList[Endpoint](ctx, a.client, "/endpoints", opts)
+}
+
+// IterateStored streams the stored recordings.
+func (a *RecordingsApiService) IterateStored(ctx context.Context, opts *ListOpts) (*ListIterator[StoredRecording], error) {
+	return openList[StoredRecording](ctx, a.client, "/recordings/stored", opts)
+}
+
+// Iterate streams the sounds.
+func (a *SoundsApiService) Iterate(ctx context.Context, opts *ListOpts) (*ListIterator[Sound], error) {
+	return openList[Sound](ctx, a.client, "/sounds", opts)
+}
@@ -0,0 +1,73 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func listClient(t *testing.T, body string) *APIClient {
+	t.Helper()
+	client, _ := goneClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	})
+	return client
+}
+
+func TestListIterator(t *testing.T) {
+	client := listClient(t, `[
+		{"id":"c1","name":"PJSIP/a-1","state":"Up","channelvars":{"X":"1"}},
+		{"id":"c2","name":"PJSIP/b-2","state":"Ring"}
+	]`)
+	ctx := context.Background()
+
+	it, err := client.ChannelsApi.Iterate(ctx, &ListOpts{Fields: []string{"id", "state"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []Channel
+	if err := ForEach[Channel](ctx, it, func(ch Channel) error { got = append(got, ch); return nil }); err != nil {
+		t.Fatal(err)
+	}
+	want := []Channel{{Id: "c1", State: "Up"}, {Id: "c2", State: "Ring"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("channels = %+v, want %+v", got, want)
+	}
+	if it.Next() {
+		t.Error("Next after the end reported an item")
+	}
+
+	// An error of fn stops the iteration.
+	stop := errors.New("stop")
+	it, err = client.ChannelsApi.Iterate(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	err = ForEach[Channel](ctx, it, func(ch Channel) error { names = append(names, ch.Name); return stop })
+	if err != stop || len(names) != 1 || names[0] != "PJSIP/a-1" {
+		t.Errorf("names = %q, err = %v, want the first channel and stop", names, err)
+	}
+}
+
+func TestListIteratorErrors(t *testing.T) {
+	ctx := context.Background()
+	if _, err := listClient(t, `{"message":"nope"}`).BridgesApi.Iterate(ctx, nil); err == nil {
+		t.Error("a JSON object was iterated as a list")
+	}
+
+	it, err := listClient(t, `[{"id":"b1"}, {"id":`).BridgesApi.Iterate(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer it.Close()
+	if !it.Next() || it.Item().Id != "b1" {
+		t.Fatalf("first item = %+v, want b1", it.Item())
+	}
+	if it.Next() || it.Err() == nil {
+		t.Errorf("truncated item: err = %v, want a decoding error", it.Err())
+	}
+}
@@ -0,0 +1,292 @@
+// Package loadtest originates synthetic calls into a Stasis application to measure how it behaves
+// under load before going to production.
+//
+// Calls are Local channels: the ";2" half runs the dialplan location given in Config.Endpoint,
+// which must hand the call to the application under test, while the ";1" half is placed in
+// Config.DriverApp where the runner plays the script (DTMF digits, media, hangup). Every event
+// received on the DriverApp websocket must be fed to Runner.HandleEvent.
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/antihax/optional"
+	ari "github.com/olegromanchuk/asterisk-ari-go"
+)
+
+// Step is one interaction of the call script.
+type Step struct {
+	// Wait is slept before the step is executed.
+	Wait time.Duration
+	// DTMF digits to send to the application under test.
+	DTMF string
+	// Media to play to the application under test, e.g. "sound:hello-world".
+	Media string
+	// Hangup ends the call.
+	Hangup bool
+}
+
+// Config describes a load test.
+type Config struct {
+	// Endpoint is the Local channel that leads into the application under test, e.g. "Local/s@loadtest".
+	Endpoint string
+	// DriverApp is the Stasis application of the runner.
+	DriverApp string
+	// Rate is the number of calls started per second.
+	Rate float64
+	// Calls is the total number of calls to place.
+	Calls int
+	// Concurrency caps the number of simultaneous calls. Zero means unlimited.
+	Concurrency int
+	// AnswerTimeout is how long to wait for the application to answer. Defaults to 10 seconds.
+	AnswerTimeout time.Duration
+	// Script is played on every answered call. The call is hung up after the last step.
+	Script []Step
+}
+
+// Distribution summarizes a set of durations.
+type Distribution struct {
+	Count int
+	Min   time.Duration
+	Max   time.Duration
+	Mean  time.Duration
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+}
+
+// Report is the outcome of a load test.
+type Report struct {
+	Started  int
+	Answered int
+	Failed   int
+	// Errors counts failures by reason.
+	Errors map[string]int
+	// Answer is the distribution of the time from originate to the application answering.
+	Answer Distribution
+	// Step is the distribution of the REST latency of script steps.
+	Step Distribution
+	// Call is the distribution of the full call duration.
+	Call Distribution
+	// Duration of the whole test.
+	Duration time.Duration
+}
+
+// Runner executes a load test.
+type Runner struct {
+	client *ari.APIClient
+	cfg    Config
+
+	mu      sync.Mutex
+	waiters map[string]chan ari.StasisEvent
+}
+
+// New creates a runner.
+func New(client *ari.APIClient, cfg Config) *Runner {
+	if cfg.AnswerTimeout <= 0 {
+		cfg.AnswerTimeout = 10 * time.Second
+	}
+	return &Runner{client: client, cfg: cfg, waiters: make(map[string]chan ari.StasisEvent)}
+}
+
+// HandleEvent feeds an event received on the DriverApp websocket into the runner.
+func (r *Runner) HandleEvent(ev ari.StasisEvent) {
+	r.mu.Lock()
+	ch, ok := r.waiters[ev.Channel.Id]
+	r.mu.Unlock()
+	if ok {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// callResult is the outcome of one synthetic call.
+type callResult struct {
+	err      string
+	answer   time.Duration
+	steps    []time.Duration
+	duration time.Duration
+}
+
+// Run places the configured calls and waits for all of them to finish or ctx to be done.
+func (r *Runner) Run(ctx context.Context) (Report, error) {
+	if r.cfg.Endpoint == "" || r.cfg.DriverApp == "" {
+		return Report{}, errors.New("loadtest: Endpoint and DriverApp are required")
+	}
+
+	pacer := ari.NewOriginatePacer(r.client, r.cfg.Rate, 1)
+	var sem chan struct{}
+	if r.cfg.Concurrency > 0 {
+		sem = make(chan struct{}, r.cfg.Concurrency)
+	}
+
+	start := time.Now()
+	prefix := fmt.Sprintf("loadtest-%d", start.UnixNano())
+	results := make(chan callResult, r.cfg.Calls)
+	var wg sync.WaitGroup
+	started := 0
+
+loop:
+	for i := 0; i < r.cfg.Calls; i++ {
+		if sem != nil {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				break loop
+			}
+		}
+		if err := pacer.Wait(ctx, "loadtest"); err != nil {
+			break
+		}
+		started++
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			results <- r.call(ctx, id)
+			if sem != nil {
+				<-sem
+			}
+		}(fmt.Sprintf("%s-%d", prefix, i))
+	}
+	wg.Wait()
+	close(results)
+
+	report := Report{Started: started, Errors: make(map[string]int), Duration: time.Since(start)}
+	var answers, steps, calls []time.Duration
+	for res := range results {
+		if res.err != "" {
+			report.Failed++
+			report.Errors[res.err]++
+		}
+		if res.answer > 0 {
+			report.Answered++
+			answers = append(answers, res.answer)
+			calls = append(calls, res.duration)
+		}
+		steps = append(steps, res.steps...)
+	}
+	report.Answer = distribution(answers)
+	report.Step = distribution(steps)
+	report.Call = distribution(calls)
+	return report, ctx.Err()
+}
+
+// call places one synthetic call and plays the script.
+func (r *Runner) call(ctx context.Context, id string) callResult {
+	var res callResult
+	events := make(chan ari.StasisEvent, 16)
+	r.mu.Lock()
+	r.waiters[id] = events
+	r.mu.Unlock()
+	r.client.TrackResource(ari.ResourceSubscription, "loadtest", 1)
+	defer func() {
+		r.mu.Lock()
+		delete(r.waiters, id)
+		r.mu.Unlock()
+		r.client.TrackResource(ari.ResourceSubscription, "loadtest", -1)
+	}()
+
+	start := time.Now()
+	opts := &ari.ChannelsApiOriginateWithIdOpts{
+		App:            optional.NewString(r.cfg.DriverApp),
+		OtherChannelId: optional.NewString(id + "-2"),
+		Timeout:        optional.NewInt32(int32(r.cfg.AnswerTimeout / time.Second)),
+	}
+	if _, _, err := r.client.ChannelsApi.OriginateWithId(ctx, id, r.cfg.Endpoint, opts); err != nil {
+		res.err = "originate: " + err.Error()
+		return res
+	}
+	defer r.client.ChannelsApi.Hangup(context.Background(), id, nil)
+
+	timer := time.NewTimer(r.cfg.AnswerTimeout)
+	defer timer.Stop()
+	for answered := false; !answered; {
+		select {
+		case ev := <-events:
+			switch ev.Type {
+			case "StasisStart":
+				answered = true
+			case "ChannelDestroyed":
+				res.err = "not answered: " + ev.CauseTxt
+				return res
+			}
+		case <-timer.C:
+			res.err = "answer timeout"
+			return res
+		case <-ctx.Done():
+			res.err = "cancelled"
+			return res
+		}
+	}
+	res.answer = time.Since(start)
+
+	for _, step := range r.cfg.Script {
+		wait := time.After(step.Wait)
+		for waiting := step.Wait > 0; waiting; {
+			select {
+			case ev := <-events:
+				if ev.Type == "ChannelDestroyed" || ev.Type == "StasisEnd" {
+					res.err = "hung up by application"
+					res.duration = time.Since(start)
+					return res
+				}
+			case <-wait:
+				waiting = false
+			case <-ctx.Done():
+				res.err = "cancelled"
+				return res
+			}
+		}
+
+		stepStart := time.Now()
+		var err error
+		switch {
+		case step.Hangup:
+			_, err = r.client.ChannelsApi.Hangup(ctx, id, nil)
+		case step.DTMF != "":
+			_, err = r.client.ChannelsApi.SendDTMF(ctx, id, &ari.ChannelsApiSendDTMFOpts{Dtmf: optional.NewString(step.DTMF)})
+		case step.Media != "":
+			_, _, err = r.client.ChannelsApi.Playsound(ctx, id, []string{step.Media}, nil)
+		}
+		res.steps = append(res.steps, time.Since(stepStart))
+		if err != nil {
+			res.err = "step: " + err.Error()
+			break
+		}
+		if step.Hangup {
+			break
+		}
+	}
+	res.duration = time.Since(start)
+	return res
+}
+
+// distribution computes the summary of samples.
+func distribution(samples []time.Duration) Distribution {
+	d := Distribution{Count: len(samples)}
+	if len(samples) == 0 {
+		return d
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	var total time.Duration
+	for _, s := range samples {
+		total += s
+	}
+	percentile := func(p float64) time.Duration {
+		return samples[int(p*float64(len(samples)-1))]
+	}
+	d.Min = samples[0]
+	d.Max = samples[len(samples)-1]
+	d.Mean = total / time.Duration(len(samples))
+	d.P50 = percentile(0.50)
+	d.P90 = percentile(0.90)
+	d.P99 = percentile(0.99)
+	return d
+}
@@ -0,0 +1,69 @@
+package asterisk_ari_go
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"reflect"
+)
+
+// Logger receives the logs of the client and its helpers. *logrus.Logger implements it, as do
+// the leveled loggers of most logging libraries, so that the core client does not depend on any
+// of them. Implementations must be safe for concurrent use.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// StdLogger is a Logger writing to a standard library logger, the default of NewAPIClient. The
+// zero value writes to log.Default().
+type StdLogger struct {
+	l *log.Logger
+	// Debug enables the debug logs.
+	Debug bool
+}
+
+// NewStdLogger creates a logger writing to w without the debug logs.
+func NewStdLogger(w io.Writer) *StdLogger {
+	return &StdLogger{l: log.New(w, "", log.LstdFlags)}
+}
+
+// defaultLogger is the logger of clients created without one.
+func defaultLogger() Logger {
+	return NewStdLogger(os.Stdout)
+}
+
+// isNilLogger reports whether l is nil, including a nil pointer such as a nil *logrus.Logger
+// wrapped in the interface.
+func isNilLogger(l Logger) bool {
+	if l == nil {
+		return true
+	}
+	v := reflect.ValueOf(l)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Func, reflect.Interface, reflect.Slice, reflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
+
+func (s *StdLogger) output(level string, format string, args []interface{}) {
+	l := s.l
+	if l == nil {
+		l = log.Default()
+	}
+	l.Output(3, level+" "+fmt.Sprintf(format, args...))
+}
+
+func (s *StdLogger) Debugf(format string, args ...interface{}) {
+	if s.Debug {
+		s.output("DEBUG", format, args)
+	}
+}
+
+func (s *StdLogger) Infof(format string, args ...interface{})  { s.output("INFO", format, args) }
+func (s *StdLogger) Warnf(format string, args ...interface{})  { s.output("WARN", format, args) }
+func (s *StdLogger) Errorf(format string, args ...interface{}) { s.output("ERROR", format, args) }
@@ -0,0 +1,37 @@
+package asterisk_ari_go
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestStdLoggerZero(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	var l StdLogger
+	l.Infof("hello %s", "world")
+	if !strings.Contains(buf.String(), "INFO hello world") {
+		t.Errorf("logged %q, want it on the default logger", buf.String())
+	}
+}
+
+func TestIsNilLogger(t *testing.T) {
+	var typed *StdLogger
+	for _, tc := range []struct {
+		l   Logger
+		nil bool
+	}{
+		{nil, true},
+		{typed, true},
+		{&StdLogger{}, false},
+	} {
+		if got := isNilLogger(tc.l); got != tc.nil {
+			t.Errorf("isNilLogger(%#v) = %v, want %v", tc.l, got, tc.nil)
+		}
+	}
+}
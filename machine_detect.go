@@ -0,0 +1,143 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// AnsweredBy is who answered a call, as guessed by DetectMachine.
+type AnsweredBy string
+
+const (
+	AnsweredByHuman   AnsweredBy = "human"
+	AnsweredByMachine AnsweredBy = "machine"
+	// AnsweredByUnknown is a call that stayed silent.
+	AnsweredByUnknown AnsweredBy = "unknown"
+)
+
+// MachineDetectOpts tunes DetectMachine. The defaults suit typical voicemail greetings.
+type MachineDetectOpts struct {
+	// InitialSilence is how long the callee may stay silent after answering. Defaults to 2.5
+	// seconds.
+	InitialSilence time.Duration
+	// Greeting is the talk duration above which the callee is a machine: people answer with a
+	// short "hello", voicemail with a long greeting. Defaults to 1.5 seconds.
+	Greeting time.Duration
+	// Silence ends a burst of talk. Defaults to 800 milliseconds.
+	Silence time.Duration
+	// Threshold is the energy level above which audio is talk; 0 uses the default of Asterisk.
+	Threshold int
+}
+
+func (o *MachineDetectOpts) withDefaults() MachineDetectOpts {
+	opts := MachineDetectOpts{}
+	if o != nil {
+		opts = *o
+	}
+	if opts.InitialSilence <= 0 {
+		opts.InitialSilence = 2500 * time.Millisecond
+	}
+	if opts.Greeting <= 0 {
+		opts.Greeting = 1500 * time.Millisecond
+	}
+	if opts.Silence <= 0 {
+		opts.Silence = 800 * time.Millisecond
+	}
+	return opts
+}
+
+// enableTalkDetect enables the TALK_DETECT events of channelId.
+func (w *Waits) enableTalkDetect(ctx context.Context, channelId string, opts MachineDetectOpts) error {
+	value := fmt.Sprint(opts.Silence.Milliseconds())
+	if opts.Threshold > 0 {
+		value = fmt.Sprintf("%d,%d", opts.Silence.Milliseconds(), opts.Threshold)
+	}
+	return w.client.ChannelsApi.SetVars(ctx, channelId, map[string]string{"TALK_DETECT(set)": value}, nil)
+}
+
+// DetectMachine guesses whether the answered channelId is a person or an answering machine from
+// the length of its greeting, measured with TALK_DETECT since ARI has no AMD. It returns as soon
+// as the greeting is longer than opts.Greeting, so that the result of a machine comes while its
+// greeting is still playing; see WaitGreetingEnd.
+func (w *Waits) DetectMachine(ctx context.Context, channelId string, opts *MachineDetectOpts) (AnsweredBy, error) {
+	o := opts.withDefaults()
+	events, done := w.subscribe("channel:" + channelId)
+	defer done()
+	if err := w.enableTalkDetect(ctx, channelId, o); err != nil {
+		return AnsweredByUnknown, err
+	}
+
+	timer := w.client.clock().NewTimer(o.InitialSilence)
+	defer timer.Stop()
+	talking := false
+	for {
+		select {
+		case ev := <-events:
+			switch ev.Type {
+			case "ChannelTalkingStarted":
+				if !talking {
+					talking = true
+					timer.Reset(o.Greeting)
+				}
+			case "ChannelTalkingFinished":
+				if time.Duration(ev.Duration)*time.Millisecond >= o.Greeting {
+					return AnsweredByMachine, nil
+				}
+				return AnsweredByHuman, nil
+			case "ChannelDestroyed", "StasisEnd":
+				return AnsweredByUnknown, ErrChannelGone
+			}
+		case <-timer.C():
+			if talking {
+				return AnsweredByMachine, nil
+			}
+			return AnsweredByUnknown, nil
+		case <-ctx.Done():
+			return AnsweredByUnknown, ctx.Err()
+		}
+	}
+}
+
+// WaitGreetingEnd waits for the greeting of an answering machine detected by DetectMachine to
+// end, followed by the beep if any, for up to maxWait, so that a message played next is
+// recorded. ARI does not detect beeps: the beep is the short burst of sound following the
+// greeting.
+func (w *Waits) WaitGreetingEnd(ctx context.Context, channelId string, maxWait time.Duration) error {
+	events, done := w.subscribe("channel:" + channelId)
+	defer done()
+
+	deadline := w.client.clock().NewTimer(maxWait)
+	defer deadline.Stop()
+	// After the greeting, the beep is given one second to come.
+	beepWait := w.client.clock().NewTimer(time.Second)
+	beepWait.Stop()
+	defer beepWait.Stop()
+	greetingDone, beeping := false, false
+	for {
+		select {
+		case ev := <-events:
+			switch ev.Type {
+			case "ChannelTalkingStarted":
+				if greetingDone {
+					beeping = true
+					beepWait.Stop()
+				}
+			case "ChannelTalkingFinished":
+				if beeping {
+					return nil
+				}
+				greetingDone = true
+				beepWait.Reset(time.Second)
+			case "ChannelDestroyed", "StasisEnd":
+				return ErrChannelGone
+			}
+		case <-beepWait.C():
+			return nil
+		case <-deadline.C():
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// defaultReconnect is the backoff of a ManagedConnection without one.
+var defaultReconnect = ReconnectConfig{InitialDelay: time.Second, MaxDelay: time.Minute, Multiplier: 2}
+
+// ManagedConnection owns the event websocket of applications: it connects, reads and decodes the
+// events, passes them to Handler, and reconnects with exponential backoff when the connection
+// drops, so that consumers keep receiving events across Asterisk restarts and network failures.
+// Events sent by Asterisk while disconnected are lost.
+type ManagedConnection struct {
+	client *APIClient
+	apps   []string
+	auth   []string
+
+	// Handler receives every event. Defaults to the HandleEvent of the client, which feeds WaitFor
+	// and the handlers registered with On.
+	Handler func(ev StasisEvent)
+	// Firehose, if set, mirrors every event once Handler returns.
+	Firehose *Firehose
+	// Reconnect is the backoff between connection attempts. The zero value retries forever from 1
+	// second up to 1 minute, doubling.
+	Reconnect ReconnectConfig
+	// StableAfter is how long a connection must last for the backoff to start over once it drops;
+	// a connection dropped sooner counts as a failed attempt, so that a server accepting and
+	// dropping connections is not redialed in a tight loop. Defaults to 30 seconds.
+	StableAfter time.Duration
+	// PoolBuffers, see EventReader.
+	PoolBuffers bool
+	// QueueSize is the number of events read ahead of Handler, see EventReader.Dispatch.
+	QueueSize int
+	// OnConnect and OnDisconnect, if set, are called when a connection is established and when it
+	// drops with the read error.
+	OnConnect    func(connectionId string)
+	OnDisconnect func(connectionId string, err error)
+
+	mu    sync.Mutex
+	conn  *websocket.Conn
+	stats *ConnectionStats
+}
+
+// NewManagedConnection creates a connection receiving the events of apps, authenticated with
+// username and password. Run connects it.
+func (a *WebsocketApiService) NewManagedConnection(apps []string, username string, password string) *ManagedConnection {
+	return &ManagedConnection{
+		client:  a.client,
+		apps:    apps,
+		auth:    []string{username + ":" + password},
+		Handler: a.client.HandleEvent,
+	}
+}
+
+func (m *ManagedConnection) backoff() ReconnectConfig {
+	if m.Reconnect.InitialDelay <= 0 || m.Reconnect.Multiplier < 1 {
+		return defaultReconnect
+	}
+	return m.Reconnect
+}
+
+// Run connects and delivers the events until ctx is done, reconnecting whenever the connection
+// drops, always after a delay. It returns ctx.Err(), the last connection error once
+// Reconnect.MaxAttempts consecutive attempts failed, or an *ApplicationReplacedError when another
+// connection took the applications over, since reconnecting would take them back and start a tug
+// of war.
+func (m *ManagedConnection) Run(ctx context.Context) error {
+	backoff := m.backoff()
+	stableAfter := m.StableAfter
+	if stableAfter <= 0 {
+		stableAfter = 30 * time.Second
+	}
+	failures := 0
+	for {
+		var delay time.Duration
+		conn, _, err := m.client.WebsocketApi.WebsocketConnect(ctx, m.apps, m.auth)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			failures++
+			if backoff.MaxAttempts > 0 && failures >= backoff.MaxAttempts {
+				return err
+			}
+			delay = backoff.Delay(failures)
+			m.client.logger.Warnf("managed connection: connecting to %v failed (attempt %d): %v; retrying in %s", m.apps, failures, err, delay)
+		} else {
+			connected := m.client.clock().Now()
+			err = m.serve(ctx, conn)
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if errors.Is(err, ErrApplicationReplaced) {
+				return err
+			}
+			m.client.metrics().IncCounter("ari_websocket_reconnects_total", nil, 1)
+			if m.client.clock().Now().Sub(connected) >= stableAfter {
+				failures = 0
+			}
+			failures++
+			delay = backoff.Delay(failures)
+			m.client.logger.Infof("managed connection: reconnecting to %v in %s", m.apps, delay)
+		}
+		select {
+		case <-m.client.clock().After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// serve delivers the events of conn until it fails or ctx is done, and closes it.
+func (m *ManagedConnection) serve(ctx context.Context, conn *websocket.Conn) error {
+	reader := m.client.WebsocketApi.NewEventReader(conn)
+	reader.PoolBuffers = m.PoolBuffers
+	m.mu.Lock()
+	m.conn, m.stats = conn, reader.Stats
+	m.mu.Unlock()
+	m.client.metrics().SetGauge("ari_websocket_connected", nil, 1)
+
+	// Closing the connection unblocks the read when ctx is done.
+	stop := make(chan struct{})
+	m.client.goTracked("managed_connection", func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-stop:
+		}
+	})
+	defer func() {
+		close(stop)
+		conn.Close()
+		reader.Stats.Close()
+		m.mu.Lock()
+		m.conn, m.stats = nil, nil
+		m.mu.Unlock()
+		m.client.metrics().SetGauge("ari_websocket_connected", nil, 0)
+	}()
+
+	m.client.logger.Infof("managed connection: connected to %v as connection %s", m.apps, reader.ConnectionId)
+	if m.OnConnect != nil {
+		m.OnConnect(reader.ConnectionId)
+	}
+	err := reader.Dispatch(m.QueueSize, func(ev StasisEvent) {
+		if m.Handler != nil {
+			reader.Stats.HandlerStart(ev)
+			m.Handler(ev)
+		}
+		if m.Firehose != nil {
+			m.Firehose.HandleEvent(ev)
+		}
+	}, func(err error) {
+		m.client.logger.Warnf("managed connection: dropping undecodable event on connection %s: %v", reader.ConnectionId, err)
+	})
+	if ctx.Err() == nil {
+		m.client.logger.Warnf("managed connection: connection %s dropped: %v", reader.ConnectionId, err)
+	}
+	if m.OnDisconnect != nil {
+		m.OnDisconnect(reader.ConnectionId, err)
+	}
+	return err
+}
+
+// Connected reports whether the connection is currently established.
+func (m *ManagedConnection) Connected() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.conn != nil
+}
+
+// Stats returns the stats of the current connection, nil while disconnected.
+func (m *ManagedConnection) Stats() *ConnectionStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats
+}
@@ -0,0 +1,28 @@
+package asterisk_ari_go
+
+// Metrics receives measurements from the client and its helpers, so they can be exported
+// to Prometheus, StatsD or any other monitoring system. Implementations must be safe for
+// concurrent use. Labels may be nil.
+type Metrics interface {
+	// IncCounter adds delta to a monotonically increasing counter.
+	IncCounter(name string, labels map[string]string, delta float64)
+	// SetGauge sets the current value of a gauge.
+	SetGauge(name string, labels map[string]string, value float64)
+	// Observe records a sample in a histogram or summary.
+	Observe(name string, labels map[string]string, value float64)
+}
+
+// noopMetrics discards all measurements.
+type noopMetrics struct{}
+
+func (noopMetrics) IncCounter(string, map[string]string, float64) {}
+func (noopMetrics) SetGauge(string, map[string]string, float64)   {}
+func (noopMetrics) Observe(string, map[string]string, float64)    {}
+
+// metrics returns the configured metrics sink or a no-op one.
+func (c *APIClient) metrics() Metrics {
+	if c.cfg.Metrics != nil {
+		return c.cfg.Metrics
+	}
+	return noopMetrics{}
+}
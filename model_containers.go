@@ -11,4 +11,8 @@ package asterisk_ari_go
 
 // Container
 type Containers struct {
+	// Variable key/value pairs to set on the created resource.
+	Variables map[string]string `json:"variables,omitempty"`
+	// Configuration object fields to create or update.
+	Fields []ConfigTuple `json:"fields,omitempty"`
 }
@@ -0,0 +1,152 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCriticalModules are the modules a Stasis application cannot work without.
+var DefaultCriticalModules = []string{"res_ari.so", "res_pjsip.so", "app_stasis.so", "res_stasis.so"}
+
+// ModuleHealth is the state of a watched module.
+type ModuleHealth struct {
+	// Name of the module, e.g. "res_pjsip.so".
+	Name string
+	// Present is false when the module is not loaded at all.
+	Present bool
+	// Status is the running status reported by Asterisk, e.g. "Running".
+	Status string
+	// Healthy is true when the module is loaded and running.
+	Healthy bool
+	// CheckedAt is the time of the check.
+	CheckedAt time.Time
+}
+
+// ModuleWatcher periodically checks that critical Asterisk modules are loaded and running,
+// which commonly silently breaks after Asterisk upgrades.
+//
+// Every check sets the "ari_module_up" gauge per module to 1 or 0. Failed checks increment
+// the "ari_module_check_errors_total" counter and leave the known state unchanged.
+type ModuleWatcher struct {
+	client  *APIClient
+	modules []string
+
+	// Interval between checks. Defaults to one minute.
+	Interval time.Duration
+	// OnUnhealthy is called when a module is found missing or not running,
+	// on the first check or after it was healthy.
+	OnUnhealthy func(ModuleHealth)
+	// OnRecovered is called when a previously unhealthy module is running again.
+	OnRecovered func(ModuleHealth)
+
+	mu    sync.Mutex
+	state map[string]ModuleHealth
+}
+
+// NewModuleWatcher creates a watcher for the given modules, or DefaultCriticalModules if none are given.
+// The ".so" suffix may be omitted.
+func NewModuleWatcher(client *APIClient, modules ...string) *ModuleWatcher {
+	if len(modules) == 0 {
+		modules = DefaultCriticalModules
+	}
+	names := make([]string, 0, len(modules))
+	for _, m := range modules {
+		if !strings.HasSuffix(m, ".so") {
+			m += ".so"
+		}
+		names = append(names, m)
+	}
+	return &ModuleWatcher{
+		client:   client,
+		modules:  names,
+		Interval: time.Minute,
+		state:    make(map[string]ModuleHealth),
+	}
+}
+
+// Check lists the loaded modules once, updates the state of the watched ones and fires the callbacks.
+func (w *ModuleWatcher) Check(ctx context.Context) ([]ModuleHealth, error) {
+	loaded, _, err := w.client.AsteriskApi.ListModules(ctx)
+	if err != nil {
+		w.client.metrics().IncCounter("ari_module_check_errors_total", nil, 1)
+		w.client.logger.Warnf("module watcher: failed to list modules: %v", err)
+		return nil, err
+	}
+
+	byName := make(map[string]Module, len(loaded))
+	for _, m := range loaded {
+		byName[m.Name] = m
+	}
+
+	now := w.client.clock().Now()
+	result := make([]ModuleHealth, 0, len(w.modules))
+	for _, name := range w.modules {
+		h := ModuleHealth{Name: name, CheckedAt: now}
+		if m, ok := byName[name]; ok {
+			h.Present = true
+			h.Status = m.Status
+			h.Healthy = strings.EqualFold(m.Status, "Running")
+		}
+		result = append(result, h)
+
+		up := 0.0
+		if h.Healthy {
+			up = 1
+		}
+		w.client.metrics().SetGauge("ari_module_up", map[string]string{"module": name}, up)
+
+		w.mu.Lock()
+		prev, seen := w.state[name]
+		w.state[name] = h
+		w.mu.Unlock()
+
+		switch {
+		case !h.Healthy && (!seen || prev.Healthy):
+			w.client.logger.Errorf("module watcher: module %s is not running (present=%v, status=%q)", name, h.Present, h.Status)
+			if w.OnUnhealthy != nil {
+				w.OnUnhealthy(h)
+			}
+		case h.Healthy && seen && !prev.Healthy:
+			w.client.logger.Infof("module watcher: module %s is running again", name)
+			if w.OnRecovered != nil {
+				w.OnRecovered(h)
+			}
+		}
+	}
+	return result, nil
+}
+
+// Run checks the modules immediately and then every Interval until ctx is done.
+func (w *ModuleWatcher) Run(ctx context.Context) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	timer := w.client.clock().NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		w.Check(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C():
+			timer.Reset(interval)
+		}
+	}
+}
+
+// Status returns the last known state of the watched modules.
+func (w *ModuleWatcher) Status() []ModuleHealth {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	result := make([]ModuleHealth, 0, len(w.modules))
+	for _, name := range w.modules {
+		if h, ok := w.state[name]; ok {
+			result = append(result, h)
+		}
+	}
+	return result
+}
@@ -0,0 +1,234 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/antihax/optional"
+)
+
+// NotifyStatus is the outcome of the notification of one destination.
+type NotifyStatus string
+
+const (
+	// NotifyDelivered is a message played to a person.
+	NotifyDelivered NotifyStatus = "delivered"
+	// NotifyAcknowledged is a message played to a person who pressed an acknowledgment digit.
+	NotifyAcknowledged NotifyStatus = "acknowledged"
+	// NotifyVoicemail is a message left on an answering machine.
+	NotifyVoicemail NotifyStatus = "voicemail"
+	// NotifyMachine is a call answered by a machine, hung up without message.
+	NotifyMachine NotifyStatus = "machine"
+	// NotifyHungUp is a call hung up by the callee before the end of the message.
+	NotifyHungUp NotifyStatus = "hung_up"
+	// NotifyBusy, NotifyNoAnswer and NotifyFailed are calls that were not answered.
+	NotifyBusy     NotifyStatus = "busy"
+	NotifyNoAnswer NotifyStatus = "no_answer"
+	NotifyFailed   NotifyStatus = "failed"
+)
+
+// TextToSpeech synthesizes text and returns the media URIs playing it, e.g. "sound:tts/1234"
+// for a file written in the sounds directory of Asterisk.
+type TextToSpeech func(ctx context.Context, text string) ([]string, error)
+
+// NotifyMessage is the message of a notification: prerecorded media, or text spoken with the
+// TextToSpeech of NotifyOpts.
+type NotifyMessage struct {
+	// Media are media URIs, e.g. "sound:outage-notice" or "recording:notices/outage".
+	Media []string
+	// Text is synthesized when Media is empty.
+	Text string
+}
+
+// NotifyAck asks the callee to acknowledge the message by pressing a digit.
+type NotifyAck struct {
+	// Prompt is played after the message, e.g. "press 1 to confirm".
+	Prompt []string
+	// Digits acknowledge the message, e.g. "1". Any other digit is recorded in the result
+	// without acknowledging.
+	Digits string
+	// Timeout is the wait for a digit after the prompt. Defaults to 5 seconds.
+	Timeout time.Duration
+	// Repeats is how many times the message and the prompt are played again when no digit is
+	// pressed.
+	Repeats int
+}
+
+// NotifyOpts configures Notify.
+type NotifyOpts struct {
+	// App is the Stasis application the calls are originated into; its events must be fed to
+	// the Waits. Required.
+	App      string
+	CallerId string
+	// Timeouts bound the setup and the ringing of every call.
+	Timeouts OriginateTimeouts
+	// FanOut bounds the calls in flight and paces them, see FanOut.
+	FanOut *FanOutOpts
+	// TextToSpeech synthesizes the Text of messages, once per Notify.
+	TextToSpeech TextToSpeech
+	// MachineDetection, if set, detects answering machines with DetectMachine. Without it, every
+	// callee is taken for a person.
+	MachineDetection *MachineDetectOpts
+	// LeaveVoicemail plays the message to answering machines once their greeting ends; otherwise
+	// they are hung up on.
+	LeaveVoicemail bool
+	// Ack, if set, asks the people answering to acknowledge the message.
+	Ack *NotifyAck
+}
+
+// NotifyResult is the outcome of the notification of one destination.
+type NotifyResult struct {
+	Destination string       `json:"destination"`
+	ChannelId   string       `json:"channel_id,omitempty"`
+	Status      NotifyStatus `json:"status"`
+	AnsweredBy  AnsweredBy   `json:"answered_by,omitempty"`
+	// Digits is the digit pressed after the message, if any.
+	Digits string        `json:"digits,omitempty"`
+	Answer time.Time     `json:"answer,omitempty"`
+	Talk   time.Duration `json:"talk,omitempty"`
+	// Err is the error that ended the call early, if any.
+	Err error `json:"-"`
+}
+
+// Notify calls every destination, an endpoint such as "PJSIP/+15551234567@trunk", concurrently
+// and plays them message: it detects answering machines and leaves them the message after their
+// greeting when configured, and collects an acknowledgment digit from people. It returns the
+// result of every destination, in order, once all the calls are over. The error is only set
+// when the notification could not start or ctx is done.
+func (w *Waits) Notify(ctx context.Context, destinations []string, message NotifyMessage, opts *NotifyOpts) ([]NotifyResult, error) {
+	if opts == nil || opts.App == "" {
+		return nil, errors.New("notify: App is required")
+	}
+	media := message.Media
+	if len(media) == 0 {
+		if message.Text == "" || opts.TextToSpeech == nil {
+			return nil, errors.New("notify: the message needs media, or text and a TextToSpeech")
+		}
+		var err error
+		if media, err = opts.TextToSpeech(ctx, message.Text); err != nil {
+			return nil, err
+		}
+	}
+
+	results, err := FanOut(ctx, w.client, destinations, opts.FanOut, func(ctx context.Context, destination string) (NotifyResult, error) {
+		r := w.notifyCall(ctx, destination, media, opts, func(ctx context.Context, r *NotifyResult) error {
+			return w.deliverWithAck(ctx, r, media, opts.Ack)
+		})
+		w.client.metrics().IncCounter("ari_notify_results_total", map[string]string{"status": string(r.Status)}, 1)
+		return r, nil
+	})
+	out := make([]NotifyResult, len(results))
+	for i, r := range results {
+		out[i] = r.Value
+		if r.Err != nil {
+			// Not called, ctx was done.
+			out[i] = NotifyResult{Destination: destinations[i], Status: NotifyFailed, Err: r.Err}
+		}
+	}
+	if err != nil {
+		return out, ctx.Err()
+	}
+	return out, nil
+}
+
+// notifyCall calls destination, leaves media to answering machines as configured by opts and
+// passes the calls answered by people to deliver, which sets the status of the result or returns
+// the error that interrupted the call. The call is hung up once over.
+func (w *Waits) notifyCall(ctx context.Context, destination string, media []string, opts *NotifyOpts, deliver func(ctx context.Context, r *NotifyResult) error) (r NotifyResult) {
+	r.Destination = destination
+	originateOpts := &ChannelsApiOriginateWithIdOpts{App: optional.NewString(opts.App)}
+	if opts.CallerId != "" {
+		originateOpts.CallerId = optional.NewString(opts.CallerId)
+	}
+	channel, err := w.OriginateWithTimeouts(ctx, destination, originateOpts, opts.Timeouts)
+	if err != nil {
+		r.Status, r.Err = notifyFailure(err), err
+		return r
+	}
+	r.ChannelId = channel.Id
+	r.Answer = w.client.clock().Now()
+	defer func() {
+		r.Talk = w.client.clock().Now().Sub(r.Answer)
+		w.cleanup("channel "+channel.Id, func(c context.Context) error {
+			_, err := w.client.ChannelsApi.Hangup(c, channel.Id, &ChannelsApiHangupOpts{Reason: optional.NewString("normal")})
+			return err
+		})
+	}()
+
+	r.AnsweredBy = AnsweredByHuman
+	if opts.MachineDetection != nil {
+		if r.AnsweredBy, err = w.DetectMachine(ctx, channel.Id, opts.MachineDetection); err != nil {
+			return r.hungUp(err)
+		}
+	}
+	if r.AnsweredBy == AnsweredByMachine {
+		if !opts.LeaveVoicemail {
+			r.Status = NotifyMachine
+			return r
+		}
+		if err := w.WaitGreetingEnd(ctx, channel.Id, 30*time.Second); err != nil {
+			return r.hungUp(err)
+		}
+		if _, err := w.PlayAndWait(ctx, channel.Id, media, nil); err != nil {
+			return r.hungUp(err)
+		}
+		r.Status = NotifyVoicemail
+		return r
+	}
+	if err := deliver(ctx, &r); err != nil {
+		return r.hungUp(err)
+	}
+	return r
+}
+
+// deliverWithAck plays media to a person and collects the acknowledgment digit, if any.
+func (w *Waits) deliverWithAck(ctx context.Context, r *NotifyResult, media []string, ack *NotifyAck) error {
+	for attempt := 0; ; attempt++ {
+		if _, err := w.PlayAndWait(ctx, r.ChannelId, media, nil); err != nil {
+			return err
+		}
+		if ack == nil {
+			r.Status = NotifyDelivered
+			return nil
+		}
+		digits, err := w.CollectDigits(ctx, r.ChannelId, &CollectOpts{Max: 1, Prompt: ack.Prompt, FirstTimeout: ack.Timeout})
+		switch {
+		case err == nil:
+			r.Digits, r.Status = digits, NotifyDelivered
+			if strings.Contains(ack.Digits, digits) {
+				r.Status = NotifyAcknowledged
+			}
+			return nil
+		case !errors.Is(err, ErrNoInput):
+			return err
+		case attempt >= ack.Repeats:
+			r.Status = NotifyDelivered
+			return nil
+		}
+	}
+}
+
+// hungUp ends the result of a call interrupted by err.
+func (r NotifyResult) hungUp(err error) NotifyResult {
+	r.Status, r.Err = NotifyHungUp, err
+	if !errors.Is(err, ErrChannelGone) {
+		r.Status = NotifyFailed
+	}
+	return r
+}
+
+// notifyFailure classifies the error of a call that was not answered.
+func notifyFailure(err error) NotifyStatus {
+	var hangup *HangupError
+	switch {
+	case errors.Is(err, ErrRingTimeout):
+		return NotifyNoAnswer
+	case errors.As(err, &hangup) && hangup.Cause == 17:
+		return NotifyBusy
+	case errors.As(err, &hangup) && (hangup.Cause == 18 || hangup.Cause == 19):
+		return NotifyNoAnswer
+	}
+	return NotifyFailed
+}
@@ -0,0 +1,43 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestNotifyAckSilentCallee covers a person who never presses a digit: the message and the
+// prompt are repeated, then the message counts as delivered.
+func TestNotifyAckSilentCallee(t *testing.T) {
+	w, clock, plays := waitsClient(t)
+	ack := &NotifyAck{Prompt: []string{"sound:press-1"}, Digits: "1", Timeout: 5 * time.Second, Repeats: 1}
+	result := make(chan error, 1)
+	r := &NotifyResult{ChannelId: "c1"}
+	go func() { result <- w.deliverWithAck(context.Background(), r, []string{"sound:notice"}, ack) }()
+
+	// The message and the prompt, twice.
+	for i := 0; i < 4; i++ {
+		select {
+		case id := <-plays:
+			w.HandleEvent(StasisEvent{Type: "PlaybackFinished", Playback: &Playback{Id: id}})
+			if i%2 == 1 {
+				waitTimers(t, clock, 1)
+				clock.Advance(ack.Timeout)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("play %d not requested", i+1)
+		}
+	}
+
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Fatalf("err = %v", err)
+		}
+		if r.Status != NotifyDelivered || r.Digits != "" {
+			t.Errorf("status = %s, digits = %q, want delivered without digits", r.Status, r.Digits)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("deliverWithAck did not return")
+	}
+}
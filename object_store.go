@@ -0,0 +1,220 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ObjectStore stores uploaded files, e.g. recordings, in a bucket.
+type ObjectStore interface {
+	// Put stores size bytes of body under key and returns the URL of the object.
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error)
+}
+
+// S3Credentials are AWS-style access keys. SessionToken is set for temporary credentials.
+type S3Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// S3Store is an ObjectStore for Amazon S3 and S3-compatible services such as MinIO, Ceph or
+// Google Cloud Storage with HMAC keys. Requests are signed with AWS Signature Version 4 and the
+// payload is streamed unsigned.
+type S3Store struct {
+	// Endpoint is the base URL of the service, https://s3.<Region>.amazonaws.com if empty.
+	Endpoint string
+	Region   string
+	Bucket   string
+	// PathStyle addresses the bucket as <Endpoint>/<Bucket> rather than <Bucket>.<Endpoint host>,
+	// as most S3-compatible services require.
+	PathStyle bool
+	// Credentials returns the keys to sign a request with, so that they can be rotated or fetched
+	// from instance metadata.
+	Credentials func(ctx context.Context) (S3Credentials, error)
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// StaticS3Credentials returns fixed credentials for S3Store.Credentials.
+func StaticS3Credentials(accessKeyID, secretAccessKey string) func(context.Context) (S3Credentials, error) {
+	return func(context.Context) (S3Credentials, error) {
+		return S3Credentials{AccessKeyID: accessKeyID, SecretAccessKey: secretAccessKey}, nil
+	}
+}
+
+// objectURL returns the URL of key.
+func (s *S3Store) objectURL(key string) (*url.URL, error) {
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + s.Region + ".amazonaws.com"
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if s.PathStyle {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.Bucket + "/" + key
+	} else {
+		u.Host = s.Bucket + "." + u.Host
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + key
+	}
+	u.RawPath = s3EscapePath(u.Path)
+	return u, nil
+}
+
+// Put uploads body to key.
+func (s *S3Store) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return "", err
+	}
+	creds, err := s.Credentials(ctx)
+	if err != nil {
+		return "", fmt.Errorf("s3 credentials: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPut, u.String(), ioutil.NopCloser(body))
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+	signS3(req, creds, s.Region, time.Now())
+
+	if err := doUpload(s.Client, req); err != nil {
+		return "", fmt.Errorf("s3 upload of %s: %w", key, err)
+	}
+	return u.String(), nil
+}
+
+// signS3 signs req with AWS Signature Version 4 for an unsigned payload.
+func signS3(req *http.Request, creds S3Credentials, region string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		if lk := strings.ToLower(k); lk == "content-type" || strings.HasPrefix(lk, "x-amz-") {
+			headers[lk] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		strings.Replace(req.URL.Query().Encode(), "+", "%20", -1),
+		canonicalHeaders.String(),
+		signedHeaders,
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+	scope := date + "/" + region + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+creds.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3EscapePath escapes every byte of path but the unreserved characters and slashes, as
+// Signature Version 4 requires.
+func s3EscapePath(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-._~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// GCSStore is an ObjectStore for Google Cloud Storage using the JSON API with OAuth 2.0 tokens.
+// With HMAC keys, S3Store on https://storage.googleapis.com works too.
+type GCSStore struct {
+	Bucket string
+	// Token returns an OAuth 2.0 access token with a storage write scope, e.g. from
+	// golang.org/x/oauth2/google.
+	Token func(ctx context.Context) (string, error)
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Put uploads body to key.
+func (s *GCSStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error) {
+	token, err := s.Token(ctx)
+	if err != nil {
+		return "", fmt.Errorf("gcs token: %w", err)
+	}
+	u := "https://storage.googleapis.com/upload/storage/v1/b/" + url.PathEscape(s.Bucket) +
+		"/o?uploadType=media&name=" + url.QueryEscape(key)
+	req, err := http.NewRequest(http.MethodPost, u, ioutil.NopCloser(body))
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	if err := doUpload(s.Client, req); err != nil {
+		return "", fmt.Errorf("gcs upload of %s: %w", key, err)
+	}
+	return "gs://" + s.Bucket + "/" + key, nil
+}
+
+// doUpload sends an upload request. Non-2xx responses are errors.
+func doUpload(client *http.Client, req *http.Request) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected response %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// PaceStats is a snapshot of the pacing state of one key.
+type PaceStats struct {
+	// Rate is the configured calls per second. Zero means unlimited.
+	Rate float64
+	// Burst is the number of calls that may be sent back to back.
+	Burst int
+	// CurrentCPS is the number of calls released during the last second.
+	CurrentCPS int
+	// QueueDepth is the number of calls waiting for their turn.
+	QueueDepth int
+}
+
+// paceBucket is a token bucket for a single key.
+type paceBucket struct {
+	rate    float64
+	burst   float64
+	tokens  float64
+	last    time.Time
+	waiting int
+	sent    []time.Time
+}
+
+// refill adds the tokens accumulated since the last update.
+func (b *paceBucket) refill(now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+}
+
+// trim forgets released calls older than one second.
+func (b *paceBucket) trim(now time.Time) {
+	cutoff := now.Add(-time.Second)
+	i := 0
+	for i < len(b.sent) && !b.sent[i].After(cutoff) {
+		i++
+	}
+	b.sent = b.sent[i:]
+}
+
+// record remembers a released call for the CurrentCPS computation.
+func (b *paceBucket) record(now time.Time) {
+	b.trim(now)
+	b.sent = append(b.sent, now)
+}
+
+// OriginatePacer throttles call origination to a configurable number of calls per second
+// per key (a trunk, a carrier host or any other grouping) with a burst allowance.
+// Calls over the rate are queued in arrival order.
+//
+// The current pace and queue depth of every key are reported to the client metrics as
+// the "ari_originate_pace_cps" and "ari_originate_queue_depth" gauges.
+type OriginatePacer struct {
+	client *APIClient
+
+	mu           sync.Mutex
+	defaultRate  float64
+	defaultBurst int
+	rates        map[string]paceBucket
+	buckets      map[string]*paceBucket
+}
+
+// NewOriginatePacer creates a pacer applying cps with the given burst to every key
+// that has no rate of its own. A cps of zero disables pacing for those keys.
+func NewOriginatePacer(client *APIClient, cps float64, burst int) *OriginatePacer {
+	return &OriginatePacer{
+		client:       client,
+		defaultRate:  cps,
+		defaultBurst: burst,
+		rates:        make(map[string]paceBucket),
+		buckets:      make(map[string]*paceBucket),
+	}
+}
+
+// SetRate configures the calls per second and burst of key.
+func (p *OriginatePacer) SetRate(key string, cps float64, burst int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if burst < 1 {
+		burst = 1
+	}
+	p.rates[key] = paceBucket{rate: cps, burst: float64(burst)}
+	if b, ok := p.buckets[key]; ok {
+		b.refill(p.client.clock().Now())
+		b.rate = cps
+		b.burst = float64(burst)
+	}
+}
+
+// Wait blocks until a call for key may be sent, or returns ctx.Err() if ctx is done first.
+func (p *OriginatePacer) Wait(ctx context.Context, key string) error {
+	p.mu.Lock()
+	now := p.client.clock().Now()
+	b := p.bucket(key, now)
+	if b.rate <= 0 {
+		b.record(now)
+		p.mu.Unlock()
+		return nil
+	}
+
+	b.refill(now)
+	b.tokens--
+	var delay time.Duration
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	if delay == 0 {
+		b.record(now)
+		p.report(key, b)
+		p.mu.Unlock()
+		return nil
+	}
+	b.waiting++
+	p.report(key, b)
+	p.mu.Unlock()
+
+	timer := p.client.clock().NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		p.mu.Lock()
+		b.waiting--
+		// Return the reserved token so that later calls are not delayed by it.
+		b.tokens++
+		p.report(key, b)
+		p.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C():
+		p.mu.Lock()
+		b.waiting--
+		b.record(p.client.clock().Now())
+		p.report(key, b)
+		p.mu.Unlock()
+		return nil
+	}
+}
+
+// Originate waits for the pace of key and then creates a new channel.
+func (p *OriginatePacer) Originate(ctx context.Context, key string, endpoint string, opts *ChannelsApiOriginateOpts) (Channel, *http.Response, error) {
+	if err := p.Wait(ctx, key); err != nil {
+		return Channel{}, nil, err
+	}
+	return p.client.ChannelsApi.Originate(ctx, endpoint, opts)
+}
+
+// Stats returns the pacing state of key.
+func (p *OriginatePacer) Stats(key string) PaceStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.client.clock().Now()
+	b := p.bucket(key, now)
+	b.trim(now)
+	return PaceStats{
+		Rate:       b.rate,
+		Burst:      int(b.burst),
+		CurrentCPS: len(b.sent),
+		QueueDepth: b.waiting,
+	}
+}
+
+// bucket returns the bucket of key, creating it full.
+func (p *OriginatePacer) bucket(key string, now time.Time) *paceBucket {
+	if b, ok := p.buckets[key]; ok {
+		return b
+	}
+	b, ok := p.rates[key]
+	if !ok {
+		b = paceBucket{rate: p.defaultRate, burst: float64(p.defaultBurst)}
+		if b.burst < 1 {
+			b.burst = 1
+		}
+	}
+	b.tokens = b.burst
+	b.last = now
+	p.buckets[key] = &b
+	return &b
+}
+
+// report publishes the pace and queue depth of key. Must be called with p.mu held.
+func (p *OriginatePacer) report(key string, b *paceBucket) {
+	labels := map[string]string{"key": key}
+	m := p.client.metrics()
+	m.SetGauge("ari_originate_pace_cps", labels, float64(len(b.sent)))
+	m.SetGauge("ari_originate_queue_depth", labels, float64(b.waiting))
+}
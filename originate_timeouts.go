@@ -0,0 +1,172 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/antihax/optional"
+)
+
+var (
+	// ErrSetupTimeout matches the *OriginateTimeoutError of a call that showed no progress in
+	// time: the far end never answered the signalling, usually a network or trunk problem.
+	ErrSetupTimeout = errors.New("call setup timed out")
+	// ErrRingTimeout matches the *OriginateTimeoutError of a call that rang but was not answered
+	// in time.
+	ErrRingTimeout = errors.New("call not answered")
+)
+
+// Originate phases, see OriginateTimeoutError.
+const (
+	PhaseSetup   = "setup"
+	PhaseRinging = "ringing"
+)
+
+// OriginateTimeouts bounds the phases of an originated call. Zero leaves a phase unbounded,
+// apart from the timeout of the originate itself.
+type OriginateTimeouts struct {
+	// Setup is the wait for the first sign of progress of the call: ringing, early media or
+	// answer.
+	Setup time.Duration
+	// Ringing is the wait for the answer once the call progresses.
+	Ringing time.Duration
+}
+
+// originateTimeout is the originate timeout in seconds covering both phases, 0 if either phase is
+// unbounded.
+func (t OriginateTimeouts) originateTimeout() int32 {
+	if t.Setup <= 0 || t.Ringing <= 0 {
+		return 0
+	}
+	return int32((t.Setup+t.Ringing+time.Second-1)/time.Second) + 1
+}
+
+// OriginateTimeoutError is returned when a phase of an originated call timed out. It matches
+// ErrSetupTimeout or ErrRingTimeout according to Phase, so that retry logic can tell network
+// problems from unanswered phones.
+type OriginateTimeoutError struct {
+	Phase     string
+	ChannelId string
+	Timeout   time.Duration
+}
+
+func (e *OriginateTimeoutError) Error() string {
+	return fmt.Sprintf("channel %s: %s timed out after %s", e.ChannelId, e.Phase, e.Timeout)
+}
+
+// Is makes errors.Is(err, ErrSetupTimeout) and errors.Is(err, ErrRingTimeout) match by phase.
+func (e *OriginateTimeoutError) Is(target error) bool {
+	return (target == ErrSetupTimeout && e.Phase == PhaseSetup) || (target == ErrRingTimeout && e.Phase == PhaseRinging)
+}
+
+// callProgressed reports whether ev shows that an originated call left the setup phase.
+func callProgressed(ev StasisEvent) bool {
+	switch ev.Type {
+	case "StasisStart", "ChannelConnectedLine":
+		return true
+	case "ChannelStateChange":
+		return ev.Channel.State != "" && ev.Channel.State != "Down"
+	case "Dial":
+		return ev.Dialstatus == "RINGING" || ev.Dialstatus == "PROGRESS" || ev.Dialstatus == "ANSWER"
+	}
+	return false
+}
+
+// phaseTimer runs the timer of the current phase of an originated call.
+type phaseTimer struct {
+	clock   Clock
+	timeout OriginateTimeouts
+	phase   string
+	timer   Timer
+}
+
+func newPhaseTimer(clock Clock, timeout OriginateTimeouts) *phaseTimer {
+	t := &phaseTimer{clock: clock, timeout: timeout}
+	t.enter(PhaseSetup)
+	return t
+}
+
+// enter starts the timer of phase.
+func (t *phaseTimer) enter(phase string) {
+	t.stop()
+	t.phase = phase
+	if d := t.current(); d > 0 {
+		t.timer = t.clock.NewTimer(d)
+	}
+}
+
+func (t *phaseTimer) current() time.Duration {
+	if t.phase == PhaseSetup {
+		return t.timeout.Setup
+	}
+	return t.timeout.Ringing
+}
+
+// C fires when the current phase times out; nil, never firing, for an unbounded phase.
+func (t *phaseTimer) C() <-chan time.Time {
+	if t.timer == nil {
+		return nil
+	}
+	return t.timer.C()
+}
+
+func (t *phaseTimer) stop() {
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+}
+
+// OriginateWithTimeouts is OriginateAndWait bounding the setup and the ringing of the call
+// separately. A phase timing out hangs the call up and yields an *OriginateTimeoutError. When
+// both phases are bounded and opts has no Timeout, the originate timeout is set to cover them.
+func (w *Waits) OriginateWithTimeouts(ctx context.Context, endpoint string, opts *ChannelsApiOriginateWithIdOpts, timeouts OriginateTimeouts) (Channel, error) {
+	if opts == nil || !opts.App.IsSet() {
+		return Channel{}, errors.New("waits: App is required to wait for an originated channel")
+	}
+	if timeout := timeouts.originateTimeout(); timeout > 0 && !opts.Timeout.IsSet() {
+		withTimeout := *opts
+		withTimeout.Timeout = optional.NewInt32(timeout)
+		opts = &withTimeout
+	}
+	channelId := newResourceId(w.Prefix)
+	events, done := w.subscribe("channel:" + channelId)
+	defer done()
+
+	hangup := func() {
+		w.cleanup("channel "+channelId, func(c context.Context) error {
+			_, err := w.client.ChannelsApi.Hangup(c, channelId, &ChannelsApiHangupOpts{Reason: optional.NewString("normal")})
+			return err
+		})
+	}
+	timer := newPhaseTimer(w.client.clock(), timeouts)
+	defer timer.stop()
+	w.client.markOriginated(channelId)
+	channel, resp, err := w.client.ChannelsApi.OriginateWithId(ctx, channelId, endpoint, opts)
+	if err != nil {
+		w.client.originateFailed(channelId, resp)
+		return channel, err
+	}
+	for {
+		select {
+		case ev := <-events:
+			switch {
+			case ev.Type == "StasisStart":
+				return ev.Channel, nil
+			case ev.Type == "ChannelDestroyed":
+				return channel, &HangupError{Cause: ev.Cause, CauseTxt: ev.CauseTxt}
+			case timer.phase == PhaseSetup && callProgressed(ev):
+				timer.enter(PhaseRinging)
+			}
+		case <-timer.C():
+			hangup()
+			w.client.metrics().IncCounter("ari_originate_timeouts_total", map[string]string{"phase": timer.phase}, 1)
+			return channel, &OriginateTimeoutError{Phase: timer.phase, ChannelId: channelId, Timeout: timer.current()}
+		case <-ctx.Done():
+			hangup()
+			return channel, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package asterisk_ari_go
+
+import (
+	"net/http"
+	"sync"
+)
+
+// originatedChannels holds the IDs of the channels originated by the helpers of a client, until
+// they are destroyed.
+type originatedChannels struct {
+	mu  sync.Mutex
+	ids map[string]struct{}
+}
+
+// markOriginated records that a helper is originating channelId, before the request is sent so
+// that a StasisStart arriving before the response is recognized.
+func (c *APIClient) markOriginated(channelId string) {
+	o := &c.originated
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.ids == nil {
+		o.ids = make(map[string]struct{})
+	}
+	o.ids[channelId] = struct{}{}
+}
+
+// originateFailed forgets channelId after a failed origination that Asterisk answered, which
+// created no channel. Without an answer the channel may exist: it is forgotten once destroyed.
+func (c *APIClient) originateFailed(channelId string, resp *http.Response) {
+	if resp != nil {
+		c.forgetOriginated(channelId)
+	}
+}
+
+func (c *APIClient) forgetOriginated(channelId string) {
+	o := &c.originated
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.ids, channelId)
+}
+
+// OriginatedByHelpers reports whether channelId was originated by a helper of this package on
+// the client, e.g. a queue agent leg, a follow-me attempt or a trunk call, and is not destroyed
+// yet. Channels are forgotten on the ChannelDestroyed events passed to HandleEvent.
+func (c *APIClient) OriginatedByHelpers(channelId string) bool {
+	o := &c.originated
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	_, ok := o.ids[channelId]
+	return ok
+}
@@ -0,0 +1,161 @@
+package asterisk_ari_go
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// OutboundConnection is a websocket opened by Asterisk towards the application.
+type OutboundConnection struct {
+	// Id identifies the connection in logs and in the events received on it.
+	Id         string
+	Conn       *websocket.Conn
+	RemoteAddr string
+	// Transport executes REST requests over this connection.
+	Transport *WebsocketRESTTransport
+	// Stats measures the event throughput and lag of this connection.
+	Stats *ConnectionStats
+}
+
+// OutboundServer accepts the websocket connections that Asterisk 20+ opens towards applications
+// configured with type=outbound_websocket in ari.conf, so that no inbound port has to be opened
+// on Asterisk. It is an http.Handler: mount it on the path configured in ari.conf.
+//
+// Events received on every connection are passed to Handler. REST requests can be sent back over
+// the same connection through OutboundConnection.Transport. Connections are refused with a 503
+// unless ExperimentalOutboundWebsocket is enabled.
+type OutboundServer struct {
+	client  *APIClient
+	handler func(StasisEvent)
+
+	// Authenticate validates an incoming connection. Connections are rejected when it is nil.
+	Authenticate func(r *http.Request) bool
+	// OnConnect, if set, is called when Asterisk connects, before any event is handled.
+	OnConnect func(conn *OutboundConnection)
+	// OnDisconnect, if set, is called when a connection ends with the read error.
+	OnDisconnect func(conn *OutboundConnection, err error)
+	// Upgrader negotiates the websocket. The zero value accepts any origin header,
+	// which is what Asterisk sends.
+	Upgrader websocket.Upgrader
+	// Firehose, if set, mirrors every event once the handler returns.
+	Firehose *Firehose
+	// QueueSize is the number of events read ahead of the handler, see EventReader.Dispatch.
+	QueueSize int
+
+	mu     sync.Mutex
+	conns  map[*OutboundConnection]struct{}
+	closed bool
+}
+
+// NewOutboundServer creates a server passing the received events to handler.
+func (a *WebsocketApiService) NewOutboundServer(handler func(StasisEvent)) *OutboundServer {
+	return &OutboundServer{
+		client:   a.client,
+		handler:  handler,
+		Upgrader: websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }},
+		conns:    make(map[*OutboundConnection]struct{}),
+	}
+}
+
+// BasicAuthenticator accepts connections presenting the given HTTP basic credentials,
+// as configured with username and password in the ari.conf outbound websocket.
+func BasicAuthenticator(username, password string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		u, p, ok := r.BasicAuth()
+		if !ok {
+			return false
+		}
+		userOk := subtle.ConstantTimeCompare([]byte(u), []byte(username)) == 1
+		passOk := subtle.ConstantTimeCompare([]byte(p), []byte(password)) == 1
+		return userOk && passOk
+	}
+}
+
+// ServeHTTP authenticates and upgrades the request, then reads events until the connection ends.
+func (s *OutboundServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := s.client.requireExperimental(ExperimentalOutboundWebsocket); err != nil {
+		s.client.logger.Warnf("outbound server: refused connection from %s: %v", r.RemoteAddr, err)
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if s.Authenticate == nil || !s.Authenticate(r) {
+		s.client.logger.Warnf("outbound server: rejected connection from %s", r.RemoteAddr)
+		w.Header().Set("WWW-Authenticate", `Basic realm="ari"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	ws, err := s.Upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.client.logger.Warnf("outbound server: upgrade from %s failed: %v", r.RemoteAddr, err)
+		return
+	}
+	reader := s.client.WebsocketApi.NewEventReader(ws)
+	conn := &OutboundConnection{Id: reader.ConnectionId, Conn: ws, RemoteAddr: r.RemoteAddr, Stats: reader.Stats}
+	conn.Transport = s.client.WebsocketApi.NewRESTTransport(ws)
+	reader.Transport = conn.Transport
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		ws.Close()
+		return
+	}
+	s.conns[conn] = struct{}{}
+	s.mu.Unlock()
+	s.client.TrackResource(ResourceGoroutine, "outbound_server", 1)
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Transport.Close()
+		reader.Stats.Close()
+		ws.Close()
+		s.client.TrackResource(ResourceGoroutine, "outbound_server", -1)
+	}()
+
+	s.client.logger.Infof("outbound server: Asterisk connected from %s as connection %s", r.RemoteAddr, conn.Id)
+	if s.OnConnect != nil {
+		s.OnConnect(conn)
+	}
+
+	err = reader.Dispatch(s.QueueSize, func(ev StasisEvent) {
+		if s.handler != nil {
+			reader.Stats.HandlerStart(ev)
+			s.handler(ev)
+		}
+		if s.Firehose != nil {
+			s.Firehose.HandleEvent(ev)
+		}
+	}, func(err error) {
+		s.client.logger.Warnf("outbound server: dropping undecodable event on connection %s: %v", conn.Id, err)
+	})
+	if s.OnDisconnect != nil {
+		s.OnDisconnect(conn, err)
+	}
+}
+
+// Connections returns the currently open connections.
+func (s *OutboundServer) Connections() []*OutboundConnection {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conns := make([]*OutboundConnection, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	return conns
+}
+
+// Close closes every open connection and rejects new ones.
+func (s *OutboundServer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for c := range s.conns {
+		c.Conn.Close()
+	}
+	return nil
+}
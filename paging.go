@@ -0,0 +1,189 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/antihax/optional"
+)
+
+// DefaultPageRingTimeout is how long a page rings the endpoints by default.
+const DefaultPageRingTimeout = 10 * time.Second
+
+// DefaultAutoAnswerHeaders are the SIP headers making common phones answer a page on speaker.
+var DefaultAutoAnswerHeaders = map[string]string{
+	"Alert-Info": "<http://localhost>;info=alert-autoanswer;delay=0",
+	"Call-Info":  "<sip:localhost>;answer-after=0",
+}
+
+// ErrNoPageAnswered is returned by Page when no endpoint answered.
+var ErrNoPageAnswered = errors.New("no paged endpoint answered")
+
+// PageOpts holds the parameters of Page. Exactly one of Media and Talker must be set.
+type PageOpts struct {
+	// Media is the announcement played to the endpoints, e.g. "sound:fire-drill".
+	Media []string
+	// Talker is a channel of the application relayed live to the endpoints, e.g. the
+	// receptionist. The page lasts until it leaves the application.
+	Talker string
+	// Headers are added to the SIP INVITE of PJSIP endpoints. Defaults to
+	// DefaultAutoAnswerHeaders.
+	Headers map[string]string
+	// RingTimeout is how long endpoints ring before being left out. Defaults to
+	// DefaultPageRingTimeout.
+	RingTimeout time.Duration
+	// CallerId shown on the phones, e.g. "Paging <100>".
+	CallerId string
+}
+
+// PageResult reports which endpoints a page reached.
+type PageResult struct {
+	Answered []string         `json:"answered"`
+	Failed   map[string]error `json:"-"`
+}
+
+// Pager broadcasts pages: it originates to many endpoints with auto-answer headers, joins them
+// muted to a bridge, plays an announcement or relays a live talker, and tears everything down
+// afterwards. Every event must be fed to HandleEvent.
+type Pager struct {
+	client *APIClient
+	waits  *Waits
+
+	// App is the Stasis application the paged endpoints are originated into.
+	App string
+}
+
+// NewPager creates a pager originating into app.
+func NewPager(client *APIClient, app string) *Pager {
+	waits := NewWaits(client)
+	waits.Prefix = "ari-page"
+	return &Pager{client: client, waits: waits, App: app}
+}
+
+// HandleEvent feeds an event received from Asterisk into the pager.
+func (p *Pager) HandleEvent(ev StasisEvent) {
+	p.waits.HandleEvent(ev)
+}
+
+// Page pages endpoints and returns once the page is over: the announcement was played, or the
+// talker left. Cancelling ctx ends the page early; the talker is then released, not hung up.
+func (p *Pager) Page(ctx context.Context, endpoints []string, opts PageOpts) (PageResult, error) {
+	if (len(opts.Media) == 0) == (opts.Talker == "") {
+		return PageResult{}, errors.New("page: exactly one of Media and Talker is required")
+	}
+	headers := opts.Headers
+	if headers == nil {
+		headers = DefaultAutoAnswerHeaders
+	}
+	variables := make(map[string]string, len(headers))
+	for name, value := range headers {
+		variables["PJSIP_HEADER(add,"+name+")"] = value
+	}
+	ringTimeout := opts.RingTimeout
+	if ringTimeout <= 0 {
+		ringTimeout = DefaultPageRingTimeout
+	}
+
+	bridge, _, err := p.client.BridgesApi.Create(ctx, &BridgesApiCreateOpts{
+		Type_: optional.NewString("mixing"),
+		Name:  optional.NewString("page"),
+	})
+	if err != nil {
+		return PageResult{}, err
+	}
+	var talkerEvents <-chan StasisEvent
+	if opts.Talker != "" {
+		var done func()
+		talkerEvents, done = p.waits.subscribe("channel:" + opts.Talker)
+		defer done()
+		if _, err := p.client.BridgesApi.AddChannel(ctx, bridge.Id, []string{opts.Talker}, nil); err != nil {
+			p.client.BridgesApi.Destroy(context.Background(), bridge.Id)
+			return PageResult{}, err
+		}
+	}
+
+	// ringing is cancelled when the page ends, hanging up the endpoints still ringing.
+	ringing, stopRinging := context.WithCancel(ctx)
+	defer stopRinging()
+	result := PageResult{Failed: make(map[string]error)}
+	var mu sync.Mutex
+	var paged []string
+	var wg sync.WaitGroup
+	for _, endpoint := range endpoints {
+		endpoint := endpoint
+		wg.Add(1)
+		p.client.goTracked("pager", func() {
+			defer wg.Done()
+			originate := &ChannelsApiOriginateWithIdOpts{
+				App:       optional.NewString(p.App),
+				AppArgs:   optional.NewString("page"),
+				Timeout:   optional.NewInt32(int32(ringTimeout / time.Second)),
+				Variables: optional.NewInterface(Containers{Variables: variables}),
+			}
+			if opts.CallerId != "" {
+				originate.CallerId = optional.NewString(opts.CallerId)
+			}
+			ringCtx, cancel := context.WithTimeout(ringing, ringTimeout+5*time.Second)
+			defer cancel()
+			channel, err := p.waits.OriginateAndWait(ringCtx, endpoint, originate)
+			if err == nil {
+				_, err = p.client.BridgesApi.AddChannel(ctx, bridge.Id, []string{channel.Id}, &BridgesApiAddChannelOpts{Mute: optional.NewBool(true)})
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if channel.Id != "" {
+				paged = append(paged, channel.Id)
+			}
+			if err != nil {
+				result.Failed[endpoint] = err
+				return
+			}
+			result.Answered = append(result.Answered, endpoint)
+		})
+	}
+
+	if opts.Talker != "" {
+		// The talker is live while the endpoints answer.
+		for talking := true; talking; {
+			select {
+			case ev := <-talkerEvents:
+				talking = ev.Type != "StasisEnd" && ev.Type != "ChannelDestroyed"
+			case <-ctx.Done():
+				talking = false
+			}
+		}
+		stopRinging()
+		wg.Wait()
+	} else {
+		wg.Wait()
+		if len(result.Answered) > 0 && ctx.Err() == nil {
+			_, err = p.waits.PlayBridgeAndWait(ctx, bridge.Id, opts.Media, nil)
+		}
+	}
+
+	p.teardown(bridge.Id, opts.Talker, paged)
+	p.client.metrics().IncCounter("ari_pages_total", nil, 1)
+	p.client.metrics().Observe("ari_page_endpoints_answered", nil, float64(len(result.Answered)))
+	if err == nil && len(result.Answered) == 0 {
+		err = ErrNoPageAnswered
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	return result, err
+}
+
+// teardown hangs up the paged channels, releases the talker and destroys the bridge.
+func (p *Pager) teardown(bridgeId string, talker string, paged []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for _, id := range paged {
+		p.client.ChannelsApi.Hangup(ctx, id, &ChannelsApiHangupOpts{Reason: optional.NewString("normal")})
+	}
+	if talker != "" {
+		p.client.BridgesApi.RemoveChannel(ctx, bridgeId, []string{talker})
+	}
+	p.client.BridgesApi.Destroy(ctx, bridgeId)
+}
@@ -0,0 +1,338 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/antihax/optional"
+)
+
+// DefaultParkingTimeout is how long a call stays parked by default.
+const DefaultParkingTimeout = 45 * time.Second
+
+var (
+	// ErrParkingFull is returned by Park when every slot of the lot is taken.
+	ErrParkingFull = errors.New("parking lot is full")
+	// ErrSlotEmpty is returned by Retrieve for a slot without a parked call.
+	ErrSlotEmpty = errors.New("no call parked in slot")
+)
+
+// ParkedCall is a call held in a slot of a parking lot.
+type ParkedCall struct {
+	Slot      string    `json:"slot"`
+	ChannelId string    `json:"channel_id"`
+	Parked    time.Time `json:"parked"`
+	// ReturnTo is the endpoint rung when the call times out, usually the one of whoever parked
+	// it; empty hangs the call up on timeout.
+	ReturnTo string `json:"return_to,omitempty"`
+}
+
+type parkingSlot struct {
+	call ParkedCall
+	// done is closed when the call leaves the slot, stopping its timeout.
+	done chan struct{}
+}
+
+// ParkingLot parks calls in numbered slots without res_parking: parked channels wait in a
+// holding bridge of the lot, on music on hold, until they are retrieved, hang up or time out.
+// Every event must be fed to HandleEvent.
+type ParkingLot struct {
+	client *APIClient
+	waits  *Waits
+
+	Name string
+	// First and Last are the slot numbers, e.g. 701 to 720.
+	First, Last int
+	// App is the Stasis application timed out calls are returned into.
+	App string
+	// Timeout after which a parked call is returned, or hung up. Defaults to
+	// DefaultParkingTimeout.
+	Timeout time.Duration
+	// MohClass is the music on hold class of the lot; empty uses the default class.
+	MohClass string
+	// OnTimeout, if set, is called with the calls that timed out, before they are returned.
+	OnTimeout func(c ParkedCall)
+
+	mu       sync.Mutex
+	bridgeId string
+	slots    map[string]*parkingSlot
+}
+
+// NewParkingLot creates a lot of the slots first to last.
+func NewParkingLot(client *APIClient, name string, app string, first int, last int) *ParkingLot {
+	waits := NewWaits(client)
+	waits.Prefix = "ari-park-" + name
+	return &ParkingLot{
+		client:  client,
+		waits:   waits,
+		Name:    name,
+		First:   first,
+		Last:    last,
+		App:     app,
+		Timeout: DefaultParkingTimeout,
+		slots:   make(map[string]*parkingSlot),
+	}
+}
+
+// bridge returns the holding bridge of the lot, creating it on first use.
+func (p *ParkingLot) bridge(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.bridgeId != "" {
+		return p.bridgeId, nil
+	}
+	bridgeId := newResourceId("ari-park-" + p.Name)
+	if _, _, err := p.client.BridgesApi.CreateWithId(ctx, bridgeId, &BridgesApiCreateWithIdOpts{
+		Type_: optional.NewString("holding"),
+		Name:  optional.NewString("parking-" + p.Name),
+	}); err != nil {
+		return "", err
+	}
+	opts := &BridgesApiStartMohOpts{}
+	if p.MohClass != "" {
+		opts.MohClass = optional.NewString(p.MohClass)
+	}
+	if _, err := p.client.BridgesApi.StartMoh(ctx, bridgeId, opts); err != nil {
+		p.client.BridgesApi.Destroy(ctx, bridgeId)
+		return "", err
+	}
+	p.bridgeId = bridgeId
+	return bridgeId, nil
+}
+
+// Park moves a channel to the first free slot and returns the slot. The channel must not be in a
+// bridge. returnTo is rung if the call times out; empty hangs it up instead.
+func (p *ParkingLot) Park(ctx context.Context, channelId string, returnTo string) (string, error) {
+	bridgeId, err := p.bridge(ctx)
+	if err != nil {
+		return "", err
+	}
+	p.mu.Lock()
+	slot := p.freeSlotLocked()
+	if slot == "" {
+		p.mu.Unlock()
+		return "", ErrParkingFull
+	}
+	ps := &parkingSlot{
+		call: ParkedCall{Slot: slot, ChannelId: channelId, Parked: p.client.clock().Now(), ReturnTo: returnTo},
+		done: make(chan struct{}),
+	}
+	p.slots[slot] = ps
+	p.mu.Unlock()
+
+	if _, err := p.client.BridgesApi.AddChannel(ctx, bridgeId, []string{channelId}, &BridgesApiAddChannelOpts{Role: optional.NewString("participant")}); err != nil {
+		p.release(slot, ps)
+		return "", err
+	}
+	p.client.logger.Infof("parking %s: %s parked in slot %s", p.Name, channelId, slot)
+	p.client.metrics().IncCounter("ari_parking_parked_total", map[string]string{"lot": p.Name}, 1)
+	p.report()
+	p.watch(slot, ps, p.timeoutOrDefault())
+	return slot, nil
+}
+
+// freeSlotLocked returns the first free slot, or "" if the lot is full.
+func (p *ParkingLot) freeSlotLocked() string {
+	for n := p.First; n <= p.Last; n++ {
+		if _, taken := p.slots[strconv.Itoa(n)]; !taken {
+			return strconv.Itoa(n)
+		}
+	}
+	return ""
+}
+
+func (p *ParkingLot) timeoutOrDefault() time.Duration {
+	if p.Timeout <= 0 {
+		return DefaultParkingTimeout
+	}
+	return p.Timeout
+}
+
+// watch times out the call parked in slot after d, unless it leaves the slot first.
+func (p *ParkingLot) watch(slot string, ps *parkingSlot, d time.Duration) {
+	p.client.goTracked("parking", func() {
+		select {
+		case <-p.client.clock().After(d):
+			p.timeout(slot, ps)
+		case <-ps.done:
+		}
+	})
+}
+
+// Retrieve bridges the call parked in slot with the retriever, a channel in the application.
+// It returns the ID of the bridge of the call. If that fails, the call is parked again, keeping
+// its slot and its timeout.
+func (p *ParkingLot) Retrieve(ctx context.Context, slot string, retrieverId string) (string, error) {
+	ps, ok := p.take(slot)
+	if !ok {
+		return "", ErrSlotEmpty
+	}
+	bridgeId, err := p.unpark(ctx, ps.call, retrieverId)
+	if err != nil {
+		p.repark(ps.call)
+		return "", err
+	}
+	p.client.logger.Infof("parking %s: slot %s retrieved by %s", p.Name, slot, retrieverId)
+	p.client.metrics().IncCounter("ari_parking_retrieved_total", map[string]string{"lot": p.Name}, 1)
+	return bridgeId, nil
+}
+
+// unpark moves a parked call from the holding bridge to a new mixing bridge with peer.
+func (p *ParkingLot) unpark(ctx context.Context, call ParkedCall, peerId string) (string, error) {
+	p.mu.Lock()
+	holding := p.bridgeId
+	p.mu.Unlock()
+	p.client.BridgesApi.RemoveChannel(ctx, holding, []string{call.ChannelId})
+	bridge, _, err := p.client.BridgesApi.Create(ctx, &BridgesApiCreateOpts{Type_: optional.NewString("mixing")})
+	if err != nil {
+		return "", err
+	}
+	if _, err := p.client.BridgesApi.AddChannel(ctx, bridge.Id, []string{call.ChannelId, peerId}, nil); err != nil {
+		p.client.BridgesApi.Destroy(ctx, bridge.Id)
+		return "", err
+	}
+	return bridge.Id, nil
+}
+
+// repark puts a call whose retrieval failed back in the lot: in its slot, or the first free one
+// if its slot was taken in the meantime, with what is left of its timeout. A call that cannot be
+// parked again is hung up rather than left outside of any bridge.
+func (p *ParkingLot) repark(call ParkedCall) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	p.mu.Lock()
+	holding := p.bridgeId
+	if _, taken := p.slots[call.Slot]; taken {
+		call.Slot = p.freeSlotLocked()
+	}
+	fail := func(err error) {
+		p.client.logger.Warnf("parking %s: %s cannot be parked again: %v", p.Name, call.ChannelId, err)
+		p.client.ChannelsApi.Hangup(ctx, call.ChannelId, &ChannelsApiHangupOpts{Reason: optional.NewString("normal")})
+	}
+	if call.Slot == "" {
+		p.mu.Unlock()
+		fail(ErrParkingFull)
+		return
+	}
+	ps := &parkingSlot{call: call, done: make(chan struct{})}
+	p.slots[call.Slot] = ps
+	p.mu.Unlock()
+
+	if _, err := p.client.BridgesApi.AddChannel(ctx, holding, []string{call.ChannelId}, &BridgesApiAddChannelOpts{Role: optional.NewString("participant")}); err != nil {
+		p.release(call.Slot, ps)
+		fail(err)
+		return
+	}
+	p.client.logger.Infof("parking %s: %s parked again in slot %s", p.Name, call.ChannelId, call.Slot)
+	p.report()
+	p.watch(call.Slot, ps, p.timeoutOrDefault()-p.client.clock().Now().Sub(call.Parked))
+}
+
+// timeout returns a call that stayed parked too long to ReturnTo, or hangs it up.
+func (p *ParkingLot) timeout(slot string, ps *parkingSlot) {
+	if _, ok := p.take(slot); !ok {
+		return
+	}
+	call := ps.call
+	p.client.logger.Infof("parking %s: slot %s timed out", p.Name, slot)
+	p.client.metrics().IncCounter("ari_parking_timeouts_total", map[string]string{"lot": p.Name}, 1)
+	if p.OnTimeout != nil {
+		p.OnTimeout(call)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	hangup := func(id string) {
+		p.client.ChannelsApi.Hangup(ctx, id, &ChannelsApiHangupOpts{Reason: optional.NewString("normal")})
+	}
+	if call.ReturnTo == "" {
+		hangup(call.ChannelId)
+		return
+	}
+	peer, err := p.waits.OriginateAndWait(ctx, call.ReturnTo, &ChannelsApiOriginateWithIdOpts{
+		App:     optional.NewString(p.App),
+		AppArgs: optional.NewString("parking-return," + p.Name + "," + slot),
+	})
+	if err != nil {
+		p.client.logger.Warnf("parking %s: returning slot %s to %s failed: %v", p.Name, slot, call.ReturnTo, err)
+		hangup(call.ChannelId)
+		return
+	}
+	if _, err := p.unpark(ctx, call, peer.Id); err != nil {
+		hangup(peer.Id)
+		hangup(call.ChannelId)
+	}
+}
+
+// take frees a slot and returns what was parked in it.
+func (p *ParkingLot) take(slot string) (*parkingSlot, bool) {
+	p.mu.Lock()
+	ps, ok := p.slots[slot]
+	p.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	return ps, p.release(slot, ps)
+}
+
+// release frees a slot if it still holds ps; it reports whether it did.
+func (p *ParkingLot) release(slot string, ps *parkingSlot) bool {
+	p.mu.Lock()
+	if p.slots[slot] != ps {
+		p.mu.Unlock()
+		return false
+	}
+	delete(p.slots, slot)
+	close(ps.done)
+	p.mu.Unlock()
+	p.report()
+	return true
+}
+
+// Parked returns the parked calls, by slot.
+func (p *ParkingLot) Parked() []ParkedCall {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	calls := make([]ParkedCall, 0, len(p.slots))
+	for _, ps := range p.slots {
+		calls = append(calls, ps.call)
+	}
+	sort.Slice(calls, func(i, j int) bool {
+		a, _ := strconv.Atoi(calls[i].Slot)
+		b, _ := strconv.Atoi(calls[j].Slot)
+		return a < b
+	})
+	return calls
+}
+
+// HandleEvent frees the slots of parked calls that hang up.
+func (p *ParkingLot) HandleEvent(ev StasisEvent) {
+	p.waits.HandleEvent(ev)
+	if ev.Type != "StasisEnd" && ev.Type != "ChannelDestroyed" {
+		return
+	}
+	p.mu.Lock()
+	var slot string
+	var ps *parkingSlot
+	for s, candidate := range p.slots {
+		if candidate.call.ChannelId == ev.Channel.Id {
+			slot, ps = s, candidate
+			break
+		}
+	}
+	p.mu.Unlock()
+	if ps != nil && p.release(slot, ps) {
+		p.client.logger.Infof("parking %s: call in slot %s hung up", p.Name, slot)
+		p.client.metrics().IncCounter("ari_parking_abandoned_total", map[string]string{"lot": p.Name}, 1)
+	}
+}
+
+func (p *ParkingLot) report() {
+	p.mu.Lock()
+	n := len(p.slots)
+	p.mu.Unlock()
+	p.client.metrics().SetGauge("ari_parking_occupied", map[string]string{"lot": p.Name}, float64(n))
+}
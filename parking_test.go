@@ -0,0 +1,59 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestParkingRetrieveFailed covers a retrieval failing once the call left the holding bridge:
+// the call is parked again in its slot.
+func TestParkingRetrieveFailed(t *testing.T) {
+	var mu sync.Mutex
+	var added []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/bridges"):
+			// The mixing bridge of the retrieval.
+			w.WriteHeader(http.StatusInternalServerError)
+		case strings.HasSuffix(r.URL.Path, "/addChannel"):
+			mu.Lock()
+			added = append(added, r.URL.Query().Get("channel"))
+			mu.Unlock()
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	cfg := NewConfiguration("/")
+	cfg.Host, cfg.Scheme = u.Host, u.Scheme
+	clock := NewFakeClock(time.Unix(0, 0))
+	cfg.Clock = clock
+	lot := NewParkingLot(NewAPIClient(cfg, NewStdLogger(ioutil.Discard)), "main", "ivr", 701, 702)
+
+	slot, err := lot.Park(context.Background(), "c1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(10 * time.Second)
+	if _, err := lot.Retrieve(context.Background(), slot, "agent"); err == nil {
+		t.Fatal("retrieve succeeded, want the failure of the bridge")
+	}
+
+	parked := lot.Parked()
+	if len(parked) != 1 || parked[0].Slot != slot || parked[0].ChannelId != "c1" || !parked[0].Parked.Equal(time.Unix(0, 0)) {
+		t.Fatalf("parked = %+v, want c1 in slot %s since the start", parked, slot)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(added) != 2 || added[1] != "c1" {
+		t.Errorf("channels added = %v, want c1 back in the holding bridge", added)
+	}
+}
@@ -0,0 +1,116 @@
+package asterisk_ari_go
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// DefaultPartitionReplicas is the number of points of every member on the hash ring.
+const DefaultPartitionReplicas = 128
+
+// Partitioner assigns channels to the instances of a horizontally scaled application with
+// consistent hashing, so that instances sharing a subscribeAll event stream each handle a disjoint
+// subset of the calls. When membership changes only the calls of the joining or leaving instance
+// move.
+type Partitioner struct {
+	self     string
+	replicas int
+
+	// KeyFunc returns the partition key of an event. Defaults to the channel ID; return a key shared
+	// by related channels (e.g. a bridge ID or a channel variable) to keep them on one instance.
+	// Events with an empty key are handled by every instance.
+	KeyFunc func(ev StasisEvent) string
+	// OnRebalance is called after membership changed, with the new members and a function telling
+	// whether this instance owns a key, so that calls which moved away can be released.
+	OnRebalance func(members []string, owns func(key string) bool)
+
+	mu      sync.RWMutex
+	members []string
+	points  []uint32
+	owners  map[uint32]string
+}
+
+// NewPartitioner creates the partitioner of the instance self among members.
+func NewPartitioner(self string, members ...string) *Partitioner {
+	p := &Partitioner{self: self, replicas: DefaultPartitionReplicas}
+	p.build(members)
+	return p
+}
+
+// SetMembers replaces the members, e.g. from service discovery, and calls OnRebalance.
+func (p *Partitioner) SetMembers(members ...string) {
+	p.mu.Lock()
+	p.build(members)
+	current := append([]string(nil), p.members...)
+	p.mu.Unlock()
+	if p.OnRebalance != nil {
+		p.OnRebalance(current, p.Owns)
+	}
+}
+
+// build computes the ring. p.mu must be held by the caller when p is shared.
+func (p *Partitioner) build(members []string) {
+	p.members = append([]string(nil), members...)
+	sort.Strings(p.members)
+	p.points = p.points[:0]
+	p.owners = make(map[uint32]string, len(members)*p.replicas)
+	for _, m := range p.members {
+		for i := 0; i < p.replicas; i++ {
+			point := hashKey(m + "#" + strconv.Itoa(i))
+			if _, taken := p.owners[point]; taken {
+				continue
+			}
+			p.owners[point] = m
+			p.points = append(p.points, point)
+		}
+	}
+	sort.Slice(p.points, func(i, j int) bool { return p.points[i] < p.points[j] })
+}
+
+// Members returns the current members.
+func (p *Partitioner) Members() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]string(nil), p.members...)
+}
+
+// Owner returns the member owning key, or "" when there are no members.
+func (p *Partitioner) Owner(key string) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if len(p.points) == 0 {
+		return ""
+	}
+	h := hashKey(key)
+	i := sort.Search(len(p.points), func(i int) bool { return p.points[i] >= h })
+	if i == len(p.points) {
+		i = 0
+	}
+	return p.owners[p.points[i]]
+}
+
+// Owns reports whether this instance owns key.
+func (p *Partitioner) Owns(key string) bool {
+	return p.Owner(key) == p.self
+}
+
+// Wrap returns a handler passing to next only the events owned by this instance.
+func (p *Partitioner) Wrap(next func(StasisEvent)) func(StasisEvent) {
+	return func(ev StasisEvent) {
+		key := ev.Channel.Id
+		if p.KeyFunc != nil {
+			key = p.KeyFunc(ev)
+		}
+		if key == "" || p.Owns(key) {
+			next(ev)
+		}
+	}
+}
+
+func hashKey(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return h.Sum32()
+}
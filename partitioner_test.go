@@ -0,0 +1,84 @@
+package asterisk_ari_go
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestPartitioner(t *testing.T) {
+	a := NewPartitioner("a", "a", "b", "c")
+	b := NewPartitioner("b", "c", "b", "a")
+	keys := make([]string, 300)
+	for i := range keys {
+		keys[i] = "chan-" + strconv.Itoa(i)
+	}
+
+	// Every instance agrees on the owner, whatever the order of the members.
+	owned := map[string]int{}
+	for _, key := range keys {
+		owner := a.Owner(key)
+		if owner != b.Owner(key) {
+			t.Fatalf("owner of %s = %s on a, %s on b", key, owner, b.Owner(key))
+		}
+		if a.Owns(key) != (owner == "a") || b.Owns(key) != (owner == "b") {
+			t.Fatalf("Owns(%s) disagrees with owner %s", key, owner)
+		}
+		owned[owner]++
+	}
+	for _, m := range []string{"a", "b", "c"} {
+		if owned[m] == 0 {
+			t.Errorf("%s owns no key: %v", m, owned)
+		}
+	}
+
+	// Removing c only moves the keys of c.
+	var rebalanced []string
+	a.OnRebalance = func(members []string, owns func(string) bool) { rebalanced = members }
+	before := map[string]string{}
+	for _, key := range keys {
+		before[key] = a.Owner(key)
+	}
+	a.SetMembers("b", "a")
+	if len(rebalanced) != 2 || rebalanced[0] != "a" || rebalanced[1] != "b" {
+		t.Errorf("rebalanced with %v, want [a b]", rebalanced)
+	}
+	for _, key := range keys {
+		if owner := a.Owner(key); before[key] != "c" && owner != before[key] {
+			t.Errorf("%s moved from %s to %s", key, before[key], owner)
+		}
+	}
+
+	if owner := NewPartitioner("a").Owner("x"); owner != "" {
+		t.Errorf("owner without members = %q, want empty", owner)
+	}
+}
+
+func TestPartitionerWrap(t *testing.T) {
+	p := NewPartitioner("a", "a", "b")
+	var mine, theirs string
+	for i := 0; mine == "" || theirs == ""; i++ {
+		key := "chan-" + strconv.Itoa(i)
+		if p.Owns(key) {
+			mine = key
+		} else {
+			theirs = key
+		}
+	}
+
+	var handled []string
+	handler := p.Wrap(func(ev StasisEvent) { handled = append(handled, ev.Channel.Id) })
+	handler(StasisEvent{Channel: Channel{Id: mine}})
+	handler(StasisEvent{Channel: Channel{Id: theirs}})
+	handler(StasisEvent{Type: "BridgeCreated"})
+	if len(handled) != 2 || handled[0] != mine || handled[1] != "" {
+		t.Errorf("handled %q, want [%s \"\"]", handled, mine)
+	}
+
+	// A custom key keeps related channels together.
+	handled = nil
+	p.KeyFunc = func(ev StasisEvent) string { return theirs }
+	handler(StasisEvent{Channel: Channel{Id: mine}})
+	if len(handled) != 0 {
+		t.Errorf("handled %q under a key owned by b", handled)
+	}
+}
@@ -0,0 +1,219 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/antihax/optional"
+)
+
+// DefaultPickupRingTimeout is how long the phone of the picker rings by default.
+const DefaultPickupRingTimeout = 15 * time.Second
+
+var (
+	// ErrNothingToPickUp is returned by PickUp when no call rings for the target.
+	ErrNothingToPickUp = errors.New("no ringing call to pick up")
+	// ErrPickupTooLate is returned by PickUp when the call was answered, or hung up, while the
+	// picker was ringing.
+	ErrPickupTooLate = errors.New("call was answered or hung up before the pickup")
+)
+
+// RingingCall is a call of the application ringing one or more legs.
+type RingingCall struct {
+	// CallerId is the channel ID of the ringing call, in the application.
+	CallerId string `json:"caller_id"`
+	// Legs are the ringing outbound channels, by channel ID, with their endpoint.
+	Legs map[string]string `json:"legs"`
+}
+
+// Pickup answers calls ringing someone else: the picker is called, the ringing legs are hung
+// up as answered elsewhere, and the call is bridged with the picker. Ringing legs are learnt from
+// the Dial events of channels dialled with ChannelsApi.Dial; calls ringing originated channels
+// must be registered with Ring. Every event must be fed to HandleEvent.
+type Pickup struct {
+	client *APIClient
+	waits  *Waits
+
+	// App is the Stasis application the picker is originated into.
+	App string
+	// RingTimeout is how long the phone of the picker rings. Defaults to
+	// DefaultPickupRingTimeout.
+	RingTimeout time.Duration
+
+	mu      sync.Mutex
+	ringing map[string]*RingingCall // by caller channel ID
+	// picking are the calls being picked up, set once they are answered or hung up meanwhile.
+	picking map[string]bool
+}
+
+// NewPickup creates a pickup helper originating into app.
+func NewPickup(client *APIClient, app string) *Pickup {
+	waits := NewWaits(client)
+	waits.Prefix = "ari-pickup"
+	return &Pickup{client: client, waits: waits, App: app, RingTimeout: DefaultPickupRingTimeout, ringing: make(map[string]*RingingCall), picking: make(map[string]bool)}
+}
+
+// Ring registers legChannel, dialled on endpoint, as ringing for callerId.
+func (p *Pickup) Ring(callerId string, legChannel string, endpoint string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	call, ok := p.ringing[callerId]
+	if !ok {
+		call = &RingingCall{CallerId: callerId, Legs: make(map[string]string)}
+		p.ringing[callerId] = call
+	}
+	call.Legs[legChannel] = endpoint
+}
+
+// stopRingingLocked removes a leg, and the call once no leg rings.
+func (p *Pickup) stopRingingLocked(callerId string, legChannel string) {
+	call, ok := p.ringing[callerId]
+	if !ok {
+		return
+	}
+	delete(call.Legs, legChannel)
+	if len(call.Legs) == 0 {
+		delete(p.ringing, callerId)
+	}
+}
+
+// HandleEvent tracks the ringing legs.
+func (p *Pickup) HandleEvent(ev StasisEvent) {
+	p.waits.HandleEvent(ev)
+	switch ev.Type {
+	case "Dial":
+		if ev.Caller == nil || ev.Peer == nil {
+			return
+		}
+		switch ev.Dialstatus {
+		case "", "RINGING", "PROGRESS", "PROCEEDING":
+			p.Ring(ev.Caller.Id, ev.Peer.Id, ChannelEndpoint(ev.Peer.Name))
+		case "ANSWER":
+			p.mu.Lock()
+			delete(p.ringing, ev.Caller.Id)
+			if _, ok := p.picking[ev.Caller.Id]; ok {
+				p.picking[ev.Caller.Id] = true
+			}
+			p.mu.Unlock()
+		default:
+			p.mu.Lock()
+			p.stopRingingLocked(ev.Caller.Id, ev.Peer.Id)
+			p.mu.Unlock()
+		}
+	case "ChannelDestroyed", "StasisEnd":
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		delete(p.ringing, ev.Channel.Id)
+		if _, ok := p.picking[ev.Channel.Id]; ok {
+			p.picking[ev.Channel.Id] = true
+		}
+		for callerId, call := range p.ringing {
+			if _, ok := call.Legs[ev.Channel.Id]; ok {
+				p.stopRingingLocked(callerId, ev.Channel.Id)
+			}
+		}
+	}
+}
+
+// Ringing returns the calls currently ringing.
+func (p *Pickup) Ringing() []RingingCall {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	calls := make([]RingingCall, 0, len(p.ringing))
+	for _, call := range p.ringing {
+		legs := make(map[string]string, len(call.Legs))
+		for id, endpoint := range call.Legs {
+			legs[id] = endpoint
+		}
+		calls = append(calls, RingingCall{CallerId: call.CallerId, Legs: legs})
+	}
+	sort.Slice(calls, func(i, j int) bool { return calls[i].CallerId < calls[j].CallerId })
+	return calls
+}
+
+// take removes the call target designates: its caller channel ID, the channel ID of one of its
+// legs, or the endpoint it rings, e.g. "PJSIP/alice".
+func (p *Pickup) take(target string) (*RingingCall, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for callerId, call := range p.ringing {
+		match := callerId == target
+		for id, endpoint := range call.Legs {
+			match = match || id == target || endpoint == target
+		}
+		if match {
+			delete(p.ringing, callerId)
+			p.picking[callerId] = false
+			return call, true
+		}
+	}
+	return nil, false
+}
+
+// PickUp calls pickerEndpoint and, once it answers, stops the ringing of the call designated by
+// target (see Ringing) and bridges it with the picker. It returns the ID of the bridge. If the
+// picker does not answer, the call keeps ringing.
+func (p *Pickup) PickUp(ctx context.Context, target string, pickerEndpoint string) (string, error) {
+	call, ok := p.take(target)
+	if !ok {
+		return "", ErrNothingToPickUp
+	}
+	defer func() {
+		p.mu.Lock()
+		delete(p.picking, call.CallerId)
+		p.mu.Unlock()
+	}()
+	restore := func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if _, ok := p.ringing[call.CallerId]; !ok && !p.picking[call.CallerId] {
+			p.ringing[call.CallerId] = call
+		}
+	}
+
+	ringTimeout := p.RingTimeout
+	if ringTimeout <= 0 {
+		ringTimeout = DefaultPickupRingTimeout
+	}
+	ringCtx, cancel := context.WithTimeout(ctx, ringTimeout+5*time.Second)
+	defer cancel()
+	picker, err := p.waits.OriginateAndWait(ringCtx, pickerEndpoint, &ChannelsApiOriginateWithIdOpts{
+		App:     optional.NewString(p.App),
+		AppArgs: optional.NewString("pickup," + call.CallerId),
+		Timeout: optional.NewInt32(int32(ringTimeout / time.Second)),
+	})
+	if err != nil {
+		restore()
+		return "", err
+	}
+	p.mu.Lock()
+	tooLate := p.picking[call.CallerId]
+	p.mu.Unlock()
+	if tooLate {
+		p.client.ChannelsApi.Hangup(context.Background(), picker.Id, &ChannelsApiHangupOpts{Reason: optional.NewString("normal")})
+		return "", ErrPickupTooLate
+	}
+
+	for leg := range call.Legs {
+		p.client.ChannelsApi.Hangup(ctx, leg, &ChannelsApiHangupOpts{Reason: optional.NewString("answered_elsewhere")})
+	}
+	// The call may have been answered by the application already.
+	p.client.ChannelsApi.Answer(ctx, call.CallerId)
+	bridge, _, err := p.client.BridgesApi.Create(ctx, &BridgesApiCreateOpts{Type_: optional.NewString("mixing")})
+	if err == nil {
+		_, err = p.client.BridgesApi.AddChannel(ctx, bridge.Id, []string{call.CallerId, picker.Id}, nil)
+		if err != nil {
+			p.client.BridgesApi.Destroy(context.Background(), bridge.Id)
+		}
+	}
+	if err != nil {
+		p.client.ChannelsApi.Hangup(context.Background(), picker.Id, &ChannelsApiHangupOpts{Reason: optional.NewString("normal")})
+		return "", err
+	}
+	p.client.logger.Infof("pickup: %s picked up %s", pickerEndpoint, call.CallerId)
+	p.client.metrics().IncCounter("ari_pickups_total", nil, 1)
+	return bridge.Id, nil
+}
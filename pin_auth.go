@@ -0,0 +1,197 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrPINRejected is returned by AuthenticatePIN when every attempt failed.
+	ErrPINRejected = errors.New("PIN rejected")
+	// ErrPINLockedOut is returned by AuthenticatePIN while the lockout key is locked.
+	ErrPINLockedOut = errors.New("PIN entry locked out")
+)
+
+// PINValidator checks an entered PIN.
+type PINValidator func(ctx context.Context, pin string) (bool, error)
+
+// PINOpts holds the optional parameters of AuthenticatePIN.
+type PINOpts struct {
+	// Prompt asks for the PIN. Defaults to "sound:agent-pass".
+	Prompt []string
+	// Invalid is played after a wrong PIN. Defaults to "sound:auth-incorrect".
+	Invalid []string
+	// LockedOut is played when the caller is locked out. Defaults to "sound:auth-thankyou".
+	LockedOut []string
+	// MaxAttempts per call. Defaults to 3.
+	MaxAttempts int
+	// Length of the PIN; entry also ends with Terminator. Zero means terminator or timeout only.
+	Length     int
+	Terminator string
+	// Lockout, shared between calls, locks LockoutKey (e.g. the mailbox or conference) after
+	// repeated failures. Optional.
+	Lockout    *PINLockout
+	LockoutKey string
+	// OnSuccess and OnFailure are called with the number of attempts made.
+	OnSuccess func(channelId string, attempts int)
+	OnFailure func(channelId string, attempts int)
+}
+
+// PINLockout locks a key after Threshold failures within Window, for Duration.
+// It is safe for concurrent use and meant to be shared by all calls. The zero value never locks;
+// set Threshold, or use NewPINLockout.
+type PINLockout struct {
+	Threshold int
+	Window    time.Duration
+	Duration  time.Duration
+	// Clock defaults to RealClock.
+	Clock Clock
+
+	mu       sync.Mutex
+	failures map[string][]time.Time
+	until    map[string]time.Time
+}
+
+// NewPINLockout creates a lockout tracker.
+func NewPINLockout(threshold int, window time.Duration, duration time.Duration) *PINLockout {
+	return &PINLockout{
+		Threshold: threshold,
+		Window:    window,
+		Duration:  duration,
+		Clock:     RealClock,
+		failures:  make(map[string][]time.Time),
+		until:     make(map[string]time.Time),
+	}
+}
+
+// clock returns the configured clock.
+func (l *PINLockout) clock() Clock {
+	if l.Clock != nil {
+		return l.Clock
+	}
+	return RealClock
+}
+
+// Locked reports whether key is locked.
+func (l *PINLockout) Locked(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	until, ok := l.until[key]
+	if ok && l.clock().Now().After(until) {
+		delete(l.until, key)
+		return false
+	}
+	return ok
+}
+
+// Fail records a failure of key and reports whether it is now locked.
+func (l *PINLockout) Fail(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.clock().Now()
+	recent := l.failures[key][:0]
+	for _, t := range l.failures[key] {
+		if now.Sub(t) < l.Window {
+			recent = append(recent, t)
+		}
+	}
+	recent = append(recent, now)
+	if l.failures == nil {
+		l.failures = make(map[string][]time.Time)
+		l.until = make(map[string]time.Time)
+	}
+	if l.Threshold > 0 && len(recent) >= l.Threshold {
+		l.until[key] = now.Add(l.Duration)
+		delete(l.failures, key)
+		return true
+	}
+	l.failures[key] = recent
+	return false
+}
+
+// Reset clears the failures of key after a success.
+func (l *PINLockout) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.failures, key)
+	delete(l.until, key)
+}
+
+// AuthenticatePIN prompts channelId for a PIN and validates it, allowing MaxAttempts tries.
+// Entered digits are never logged. It returns nil once a PIN is accepted, ErrPINRejected after
+// the last failed attempt and ErrPINLockedOut if the lockout key is or becomes locked.
+func (w *Waits) AuthenticatePIN(ctx context.Context, channelId string, validate PINValidator, opts *PINOpts) error {
+	if opts == nil {
+		opts = &PINOpts{}
+	}
+	prompt, invalid, lockedOut := opts.Prompt, opts.Invalid, opts.LockedOut
+	if prompt == nil {
+		prompt = []string{"sound:agent-pass"}
+	}
+	if invalid == nil {
+		invalid = []string{"sound:auth-incorrect"}
+	}
+	if lockedOut == nil {
+		lockedOut = []string{"sound:auth-thankyou"}
+	}
+	maxAttempts := opts.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+	terminator := opts.Terminator
+	if terminator == "" {
+		terminator = "#"
+	}
+
+	locked := func() error {
+		if _, err := w.PlayAndWait(ctx, channelId, lockedOut, nil); err != nil {
+			return err
+		}
+		return ErrPINLockedOut
+	}
+	if opts.Lockout != nil && opts.Lockout.Locked(opts.LockoutKey) {
+		w.client.logger.Infof("pin: %s is locked out, refusing channel %s", opts.LockoutKey, channelId)
+		return locked()
+	}
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		pin, err := w.CollectDigits(ctx, channelId, &CollectOpts{Max: opts.Length, Terminator: terminator, Prompt: prompt})
+		if err != nil && !errors.Is(err, ErrNoInput) {
+			return err
+		}
+
+		ok := false
+		if pin != "" {
+			if ok, err = validate(ctx, pin); err != nil {
+				return err
+			}
+		}
+		if ok {
+			w.client.logger.Debugf("pin: channel %s authenticated after %d attempts", channelId, attempt)
+			if opts.Lockout != nil {
+				opts.Lockout.Reset(opts.LockoutKey)
+			}
+			if opts.OnSuccess != nil {
+				opts.OnSuccess(channelId, attempt)
+			}
+			return nil
+		}
+
+		w.client.logger.Debugf("pin: channel %s entered a wrong PIN (%d digits), attempt %d/%d", channelId, len(pin), attempt, maxAttempts)
+		if opts.OnFailure != nil {
+			opts.OnFailure(channelId, attempt)
+		}
+		if opts.Lockout != nil && opts.Lockout.Fail(opts.LockoutKey) {
+			w.client.logger.Warnf("pin: locking out %s after repeated failures", opts.LockoutKey)
+			return locked()
+		}
+		if attempt < maxAttempts {
+			if _, err := w.PlayAndWait(ctx, channelId, invalid, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return ErrPINRejected
+}
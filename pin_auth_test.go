@@ -0,0 +1,50 @@
+package asterisk_ari_go
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPINLockout(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	l := NewPINLockout(3, 10*time.Second, time.Minute)
+	l.Clock = clock
+
+	l.Fail("mailbox-1")
+	clock.Advance(5 * time.Second)
+	l.Fail("mailbox-1")
+	// The first failure leaves the window.
+	clock.Advance(6 * time.Second)
+	if l.Fail("mailbox-1") || l.Locked("mailbox-1") {
+		t.Fatal("locked with 2 failures within the window")
+	}
+	if !l.Fail("mailbox-1") || !l.Locked("mailbox-1") {
+		t.Fatal("not locked after 3 failures within the window")
+	}
+	if l.Locked("mailbox-2") {
+		t.Error("another key is locked")
+	}
+	clock.Advance(time.Minute + time.Second)
+	if l.Locked("mailbox-1") {
+		t.Error("still locked after the lockout")
+	}
+
+	l.Fail("mailbox-1")
+	l.Fail("mailbox-1")
+	l.Reset("mailbox-1")
+	if l.Fail("mailbox-1") {
+		t.Error("failures kept after a reset")
+	}
+}
+
+func TestPINLockoutZero(t *testing.T) {
+	var l PINLockout
+	for i := 0; i < 5; i++ {
+		if l.Fail("k") {
+			t.Fatal("the zero lockout locked")
+		}
+	}
+	if l.Locked("k") {
+		t.Error("the zero lockout is locked")
+	}
+}
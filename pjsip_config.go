@@ -0,0 +1,239 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/antihax/optional"
+)
+
+// PjsipConfigClass is the dynamic configuration class of res_pjsip objects.
+const PjsipConfigClass = "res_pjsip"
+
+// PjsipEndpoint is a res_pjsip endpoint object. Empty fields are left untouched on update.
+// Dynamic objects require a sorcery wizard that supports writes (e.g. astdb) for the
+// object type in sorcery.conf.
+type PjsipEndpoint struct {
+	Id             string `config:"-"`
+	Transport      string `config:"transport"`
+	Context        string `config:"context"`
+	Disallow       string `config:"disallow"`
+	Allow          string `config:"allow"`
+	Auth           string `config:"auth"`
+	OutboundAuth   string `config:"outbound_auth"`
+	Aors           string `config:"aors"`
+	CallerId       string `config:"callerid"`
+	FromUser       string `config:"from_user"`
+	FromDomain     string `config:"from_domain"`
+	DirectMedia    string `config:"direct_media"`
+	DtmfMode       string `config:"dtmf_mode"`
+	RtpSymmetric   string `config:"rtp_symmetric"`
+	ForceRport     string `config:"force_rport"`
+	RewriteContact string `config:"rewrite_contact"`
+	IceSupport     string `config:"ice_support"`
+	MediaEncrypt   string `config:"media_encryption"`
+	Webrtc         string `config:"webrtc"`
+	// Extra holds any other attribute.
+	Extra map[string]string `config:"-"`
+}
+
+// PjsipAuth is a res_pjsip auth object.
+type PjsipAuth struct {
+	Id       string `config:"-"`
+	AuthType string `config:"auth_type"`
+	Username string `config:"username"`
+	Password string `config:"password"`
+	Realm    string `config:"realm"`
+	// Extra holds any other attribute.
+	Extra map[string]string `config:"-"`
+}
+
+// PjsipAor is a res_pjsip address of record object.
+type PjsipAor struct {
+	Id                string `config:"-"`
+	MaxContacts       string `config:"max_contacts"`
+	RemoveExisting    string `config:"remove_existing"`
+	Contact           string `config:"contact"`
+	QualifyFrequency  string `config:"qualify_frequency"`
+	DefaultExpiration string `config:"default_expiration"`
+	// Extra holds any other attribute.
+	Extra map[string]string `config:"-"`
+}
+
+// PjsipUser describes a typical registering phone: an endpoint with an auth and an aor of the same id.
+type PjsipUser struct {
+	Id       string
+	Password string
+	Context  string
+	// Codecs is the allow list, e.g. "ulaw,alaw". Defaults to "ulaw".
+	Codecs string
+	// Transport is the PJSIP transport name. Optional.
+	Transport string
+	// MaxContacts defaults to 1.
+	MaxContacts string
+}
+
+// UpsertPjsipEndpoint creates or updates a PJSIP endpoint.
+func (a *AsteriskApiService) UpsertPjsipEndpoint(ctx context.Context, endpoint PjsipEndpoint) error {
+	return a.upsertConfigObject(ctx, "endpoint", endpoint.Id, configTuples(endpoint, endpoint.Extra))
+}
+
+// UpsertPjsipAuth creates or updates a PJSIP auth.
+func (a *AsteriskApiService) UpsertPjsipAuth(ctx context.Context, auth PjsipAuth) error {
+	if auth.AuthType == "" {
+		auth.AuthType = "userpass"
+	}
+	return a.upsertConfigObject(ctx, "auth", auth.Id, configTuples(auth, auth.Extra))
+}
+
+// UpsertPjsipAor creates or updates a PJSIP aor.
+func (a *AsteriskApiService) UpsertPjsipAor(ctx context.Context, aor PjsipAor) error {
+	return a.upsertConfigObject(ctx, "aor", aor.Id, configTuples(aor, aor.Extra))
+}
+
+// GetPjsipEndpoint retrieves a PJSIP endpoint.
+func (a *AsteriskApiService) GetPjsipEndpoint(ctx context.Context, id string) (PjsipEndpoint, error) {
+	endpoint := PjsipEndpoint{Id: id, Extra: make(map[string]string)}
+	tuples, _, err := a.GetObject(ctx, PjsipConfigClass, "endpoint", id)
+	if err == nil {
+		fromConfigTuples(tuples, &endpoint, endpoint.Extra)
+	}
+	return endpoint, err
+}
+
+// GetPjsipAuth retrieves a PJSIP auth.
+func (a *AsteriskApiService) GetPjsipAuth(ctx context.Context, id string) (PjsipAuth, error) {
+	auth := PjsipAuth{Id: id, Extra: make(map[string]string)}
+	tuples, _, err := a.GetObject(ctx, PjsipConfigClass, "auth", id)
+	if err == nil {
+		fromConfigTuples(tuples, &auth, auth.Extra)
+	}
+	return auth, err
+}
+
+// GetPjsipAor retrieves a PJSIP aor.
+func (a *AsteriskApiService) GetPjsipAor(ctx context.Context, id string) (PjsipAor, error) {
+	aor := PjsipAor{Id: id, Extra: make(map[string]string)}
+	tuples, _, err := a.GetObject(ctx, PjsipConfigClass, "aor", id)
+	if err == nil {
+		fromConfigTuples(tuples, &aor, aor.Extra)
+	}
+	return aor, err
+}
+
+// DeletePjsipEndpoint deletes a PJSIP endpoint.
+func (a *AsteriskApiService) DeletePjsipEndpoint(ctx context.Context, id string) error {
+	_, err := a.DeleteObject(ctx, PjsipConfigClass, "endpoint", id)
+	return err
+}
+
+// DeletePjsipAuth deletes a PJSIP auth.
+func (a *AsteriskApiService) DeletePjsipAuth(ctx context.Context, id string) error {
+	_, err := a.DeleteObject(ctx, PjsipConfigClass, "auth", id)
+	return err
+}
+
+// DeletePjsipAor deletes a PJSIP aor.
+func (a *AsteriskApiService) DeletePjsipAor(ctx context.Context, id string) error {
+	_, err := a.DeleteObject(ctx, PjsipConfigClass, "aor", id)
+	return err
+}
+
+// ProvisionPjsipUser creates the auth, aor and endpoint of a registering phone. If one of the
+// objects cannot be created, the ones already created are removed again.
+func (a *AsteriskApiService) ProvisionPjsipUser(ctx context.Context, user PjsipUser) error {
+	if user.Id == "" {
+		return errors.New("pjsip user id is required")
+	}
+	if user.Codecs == "" {
+		user.Codecs = "ulaw"
+	}
+	if user.MaxContacts == "" {
+		user.MaxContacts = "1"
+	}
+
+	if err := a.UpsertPjsipAuth(ctx, PjsipAuth{Id: user.Id, Username: user.Id, Password: user.Password}); err != nil {
+		return err
+	}
+	if err := a.UpsertPjsipAor(ctx, PjsipAor{Id: user.Id, MaxContacts: user.MaxContacts, RemoveExisting: "yes"}); err != nil {
+		a.DeletePjsipAuth(ctx, user.Id)
+		return err
+	}
+	err := a.UpsertPjsipEndpoint(ctx, PjsipEndpoint{
+		Id:        user.Id,
+		Transport: user.Transport,
+		Context:   user.Context,
+		Disallow:  "all",
+		Allow:     user.Codecs,
+		Auth:      user.Id,
+		Aors:      user.Id,
+	})
+	if err != nil {
+		a.DeletePjsipAor(ctx, user.Id)
+		a.DeletePjsipAuth(ctx, user.Id)
+	}
+	return err
+}
+
+// DeprovisionPjsipUser removes the endpoint, aor and auth created by ProvisionPjsipUser.
+// It attempts every deletion and returns the first error.
+func (a *AsteriskApiService) DeprovisionPjsipUser(ctx context.Context, id string) error {
+	var first error
+	for _, del := range []func(context.Context, string) error{a.DeletePjsipEndpoint, a.DeletePjsipAor, a.DeletePjsipAuth} {
+		if err := del(ctx, id); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (a *AsteriskApiService) upsertConfigObject(ctx context.Context, objectType string, id string, fields []ConfigTuple) error {
+	if id == "" {
+		return errors.New("pjsip " + objectType + " id is required")
+	}
+	opts := &AsteriskApiUpdateObjectOpts{Fields: optional.NewInterface(Containers{Fields: fields})}
+	_, _, err := a.UpdateObject(ctx, PjsipConfigClass, objectType, id, opts)
+	return err
+}
+
+// configTuples converts the non-empty string fields tagged with `config` plus extra into tuples.
+func configTuples(obj interface{}, extra map[string]string) []ConfigTuple {
+	var tuples []ConfigTuple
+	v := reflect.ValueOf(obj)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Tag.Get("config")
+		if name == "" || name == "-" || t.Field(i).Type.Kind() != reflect.String {
+			continue
+		}
+		if value := v.Field(i).String(); value != "" {
+			tuples = append(tuples, ConfigTuple{Attribute: name, Value: value})
+		}
+	}
+	for k, val := range extra {
+		tuples = append(tuples, ConfigTuple{Attribute: k, Value: val})
+	}
+	return tuples
+}
+
+// fromConfigTuples sets the fields of the struct pointed to by obj from tuples.
+// Attributes without a matching field are stored in extra.
+func fromConfigTuples(tuples []ConfigTuple, obj interface{}, extra map[string]string) {
+	v := reflect.ValueOf(obj).Elem()
+	t := v.Type()
+	fields := make(map[string]int)
+	for i := 0; i < t.NumField(); i++ {
+		if name := t.Field(i).Tag.Get("config"); name != "" && name != "-" {
+			fields[name] = i
+		}
+	}
+	for _, tuple := range tuples {
+		if i, ok := fields[strings.ToLower(tuple.Attribute)]; ok {
+			v.Field(i).SetString(tuple.Value)
+		} else {
+			extra[tuple.Attribute] = tuple.Value
+		}
+	}
+}
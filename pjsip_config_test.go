@@ -0,0 +1,54 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConfigTuples(t *testing.T) {
+	endpoint := PjsipEndpoint{Id: "1001", Context: "phones", Allow: "ulaw", Extra: map[string]string{"language": "fr"}}
+	tuples := configTuples(endpoint, endpoint.Extra)
+	want := []ConfigTuple{{"context", "phones"}, {"allow", "ulaw"}, {"language", "fr"}}
+	if !reflect.DeepEqual(tuples, want) {
+		t.Errorf("tuples = %v, want %v", tuples, want)
+	}
+
+	got := PjsipEndpoint{Id: "1001", Extra: map[string]string{}}
+	fromConfigTuples(append(tuples, ConfigTuple{"DTMF_MODE", "rfc4733"}), &got, got.Extra)
+	endpoint.DtmfMode = "rfc4733"
+	if !reflect.DeepEqual(got, endpoint) {
+		t.Errorf("endpoint = %+v, want %+v", got, endpoint)
+	}
+}
+
+func TestProvisionPjsipUserRollback(t *testing.T) {
+	var requests []string
+	node := clusterNode(t, "a", nil, func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/ari/asterisk/config/dynamic/res_pjsip/")
+		requests = append(requests, r.Method+" "+path)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		case strings.HasPrefix(path, "endpoint/"):
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message":"Cannot update endpoint"}`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	})
+
+	if err := node.Client.AsteriskApi.ProvisionPjsipUser(context.Background(), PjsipUser{Id: "1001", Password: "s3cret"}); err == nil {
+		t.Fatal("provisioning succeeded although the endpoint was refused")
+	}
+	want := []string{"PUT auth/1001", "PUT aor/1001", "PUT endpoint/1001", "DELETE aor/1001", "DELETE auth/1001"}
+	if !reflect.DeepEqual(requests, want) {
+		t.Errorf("requests = %q, want %q", requests, want)
+	}
+	if err := node.Client.AsteriskApi.ProvisionPjsipUser(context.Background(), PjsipUser{}); err == nil {
+		t.Error("provisioned a user without id")
+	}
+}
@@ -0,0 +1,80 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Backoff between the attempts of a play answered with 409 Conflict.
+const (
+	playRetryInitialDelay = 50 * time.Millisecond
+	playRetryMaxDelay     = 500 * time.Millisecond
+)
+
+type playConflictWaitKey struct{}
+
+// WithPlayConflictWait returns a context retrying the plays made with it for up to wait when
+// Asterisk answers 409 Conflict, overriding Configuration.PlayConflictWait. 0 disables the
+// retries.
+func WithPlayConflictWait(ctx context.Context, wait time.Duration) context.Context {
+	return context.WithValue(ctx, playConflictWaitKey{}, wait)
+}
+
+// playConflictWait returns how long a play may be retried, 0 if req is not a play on a channel.
+func (c *APIClient) playConflictWait(req *http.Request) time.Duration {
+	if req.Method != http.MethodPost || c.channelOperationId(req) == "" {
+		return 0
+	}
+	parts := strings.Split(strings.Trim(c.apiPath(req), "/"), "/")
+	if len(parts) < 3 || parts[2] != "play" {
+		return 0
+	}
+	if wait, ok := req.Context().Value(playConflictWaitKey{}).(time.Duration); ok {
+		return wait
+	}
+	return c.cfg.PlayConflictWait
+}
+
+// send sends a request. A play on a channel answered with 409 Conflict, because the channel is
+// not answered yet or another operation is in progress on it, is retried with backoff within the
+// wait ceiling, if enabled; the last response is returned when it expires.
+func (c *APIClient) send(req *http.Request) (*http.Response, error) {
+	resp, err := c.cfg.HTTPClient.Do(req)
+	if err != nil || resp.StatusCode != http.StatusConflict {
+		return resp, err
+	}
+	wait := c.playConflictWait(req)
+	if wait <= 0 {
+		return resp, err
+	}
+	deadline := c.clock().Now().Add(wait)
+	delay := playRetryInitialDelay
+	for attempt := 1; ; attempt++ {
+		remaining := deadline.Sub(c.clock().Now())
+		if remaining <= 0 {
+			return resp, nil
+		}
+		if delay > remaining {
+			delay = remaining
+		}
+		select {
+		case <-req.Context().Done():
+			return resp, nil
+		case <-c.clock().After(delay):
+		}
+		c.logger.Debugf("play on channel %s conflicted, retry %d", c.channelOperationId(req), attempt)
+		c.metrics().IncCounter("ari_play_conflict_retries_total", nil, 1)
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		if resp, err = c.cfg.HTTPClient.Do(req.Clone(req.Context())); err != nil || resp.StatusCode != http.StatusConflict {
+			return resp, err
+		}
+		if delay *= 2; delay > playRetryMaxDelay {
+			delay = playRetryMaxDelay
+		}
+	}
+}
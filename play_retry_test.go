@@ -0,0 +1,58 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPlayConflictRetry(t *testing.T) {
+	var plays, hangups int32
+	client, _ := goneClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodDelete {
+			atomic.AddInt32(&hangups, 1)
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		if atomic.AddInt32(&plays, 1) <= 2 {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"message":"Channel not in Stasis application"}`))
+			return
+		}
+		w.Write([]byte(`{"id":"pb1"}`))
+	})
+	ctx := WithPlayConflictWait(context.Background(), 5*time.Second)
+
+	playback, resp, err := client.ChannelsApi.Playsound(ctx, "c1", []string{"sound:hello"}, nil)
+	if err != nil || resp.StatusCode != http.StatusOK || playback.Id != "pb1" {
+		t.Fatalf("play = %+v, %v, want pb1 after the conflicts", playback, err)
+	}
+	if n := atomic.LoadInt32(&plays); n != 3 {
+		t.Errorf("%d attempts, want 3", n)
+	}
+
+	// Other requests answered 409 are not retried.
+	client.ChannelsApi.Hangup(ctx, "c1", nil)
+	if n := atomic.LoadInt32(&hangups); n != 1 {
+		t.Errorf("%d hangup attempts, want 1", n)
+	}
+}
+
+func TestPlayConflictNoRetry(t *testing.T) {
+	var plays int32
+	client, _ := goneClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&plays, 1)
+		w.WriteHeader(http.StatusConflict)
+	})
+
+	_, resp, err := client.ChannelsApi.Playsound(WithPlayConflictWait(context.Background(), 0), "c1", []string{"sound:hello"}, nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusConflict {
+		t.Errorf("play = %v, %v, want the 409", resp, err)
+	}
+	if n := atomic.LoadInt32(&plays); n != 1 {
+		t.Errorf("%d attempts, want 1", n)
+	}
+}
@@ -0,0 +1,64 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"net/http"
+)
+
+// PlaybackOperation is an operation of PlaybacksApiService.Control.
+type PlaybackOperation string
+
+const (
+	PlaybackPause   PlaybackOperation = "pause"
+	PlaybackUnpause PlaybackOperation = "unpause"
+	// PlaybackReverse and PlaybackForward skip back and forth by the skipms of the play request,
+	// 3 seconds by default.
+	PlaybackReverse PlaybackOperation = "reverse"
+	PlaybackForward PlaybackOperation = "forward"
+	PlaybackRestart PlaybackOperation = "restart"
+)
+
+// ControlOp controls a playback with a typed operation. A playback that already finished is
+// answered with a 404, and one that cannot take the operation in its state with a 409.
+func (a *PlaybacksApiService) ControlOp(ctx context.Context, playbackId string, op PlaybackOperation) (*http.Response, error) {
+	return a.Control(ctx, playbackId, string(op))
+}
+
+// Pause pauses a playback.
+func (a *PlaybacksApiService) Pause(ctx context.Context, playbackId string) error {
+	_, err := a.ControlOp(ctx, playbackId, PlaybackPause)
+	return err
+}
+
+// Unpause resumes a paused playback.
+func (a *PlaybacksApiService) Unpause(ctx context.Context, playbackId string) error {
+	_, err := a.ControlOp(ctx, playbackId, PlaybackUnpause)
+	return err
+}
+
+// Reverse skips a playback back.
+func (a *PlaybacksApiService) Reverse(ctx context.Context, playbackId string) error {
+	_, err := a.ControlOp(ctx, playbackId, PlaybackReverse)
+	return err
+}
+
+// Forward skips a playback forward.
+func (a *PlaybacksApiService) Forward(ctx context.Context, playbackId string) error {
+	_, err := a.ControlOp(ctx, playbackId, PlaybackForward)
+	return err
+}
+
+// Restart restarts a playback from the beginning.
+func (a *PlaybacksApiService) Restart(ctx context.Context, playbackId string) error {
+	_, err := a.ControlOp(ctx, playbackId, PlaybackRestart)
+	return err
+}
+
+// StopCtx stops a playback. A playback that already finished is not an error.
+func (a *PlaybacksApiService) StopCtx(ctx context.Context, playbackId string) error {
+	resp, err := a.Stop(ctx, playbackId)
+	if hasStatus(resp, http.StatusNotFound) {
+		return nil
+	}
+	return err
+}
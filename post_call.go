@@ -0,0 +1,199 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// PostCallRecordingsKey is the ChannelHandle data key listing the names of the stored recordings of
+// a call, as a []string, for the recording stages of PostCallPipeline.
+const PostCallRecordingsKey = "recordings"
+
+// PostCallJob is the post-call work of one call. Stages pass results to the following ones with
+// Set, e.g. the URL of an uploaded recording to the webhook.
+type PostCallJob struct {
+	Record     CallRecord             `json:"record"`
+	Recordings []string               `json:"recordings,omitempty"`
+	Results    map[string]interface{} `json:"results,omitempty"`
+
+	mu sync.Mutex
+}
+
+// Set records a result for the following stages.
+func (j *PostCallJob) Set(key string, value interface{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.Results[key] = value
+}
+
+// Get returns a result recorded by a previous stage.
+func (j *PostCallJob) Get(key string) (interface{}, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	v, ok := j.Results[key]
+	return v, ok
+}
+
+// PostCallStage is a step of the post-call pipeline.
+type PostCallStage struct {
+	Name string
+	Run  func(ctx context.Context, job *PostCallJob) error
+	// Retries is the number of attempts after the first failure.
+	Retries int
+	// Backoff is the delay before the first retry, doubled on every retry. Defaults to a second.
+	Backoff time.Duration
+	// Timeout bounds every attempt. Zero means no timeout.
+	Timeout time.Duration
+}
+
+// RecordingStage returns a stage running fn on every recording of the call, e.g. to upload it or
+// submit it for transcription.
+func RecordingStage(name string, fn func(ctx context.Context, job *PostCallJob, recording string) error) PostCallStage {
+	return PostCallStage{Name: name, Run: func(ctx context.Context, job *PostCallJob) error {
+		for _, recording := range job.Recordings {
+			if err := fn(ctx, job, recording); err != nil {
+				return fmt.Errorf("recording %s: %w", recording, err)
+			}
+		}
+		return nil
+	}}
+}
+
+// CDRStage returns a stage delivering the CallRecord of the call, e.g. to a billing database.
+func CDRStage(deliver func(ctx context.Context, r CallRecord) error) PostCallStage {
+	return PostCallStage{Name: "cdr", Run: func(ctx context.Context, job *PostCallJob) error {
+		return deliver(ctx, job.Record)
+	}}
+}
+
+// WebhookStage returns a stage posting the job as JSON to url. A nil client uses
+// http.DefaultClient.
+func WebhookStage(url string, header http.Header, client *http.Client) PostCallStage {
+	return PostCallStage{Name: "webhook", Run: func(ctx context.Context, job *PostCallJob) error {
+		// The job is encoded under its lock, which is not held while the webhook answers.
+		job.mu.Lock()
+		body, err := json.Marshal(job)
+		job.mu.Unlock()
+		if err != nil {
+			return err
+		}
+		return postJSON(ctx, client, url, header, json.RawMessage(body))
+	}}
+}
+
+// PostCallPipeline runs stages on every finished call, in order. A stage failing after its retries
+// stops the job, which is handed to OnDeadLetter so that it can be retried later from the failing
+// stage. Set CallRegistry.OnEnd to Submit to run it on every call.
+type PostCallPipeline struct {
+	client *APIClient
+	stages []PostCallStage
+
+	// OnDeadLetter receives the jobs a stage failed.
+	OnDeadLetter func(job *PostCallJob, stage string, err error)
+
+	slots chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewPostCallPipeline creates a pipeline running up to workers jobs at a time.
+func NewPostCallPipeline(client *APIClient, workers int, stages ...PostCallStage) *PostCallPipeline {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &PostCallPipeline{client: client, stages: stages, slots: make(chan struct{}, workers)}
+}
+
+// Submit queues the post-call work of a finished call. Recordings are taken from the
+// PostCallRecordingsKey data of the call.
+func (p *PostCallPipeline) Submit(r CallRecord) {
+	job := &PostCallJob{Record: r, Results: make(map[string]interface{})}
+	switch recordings := r.Data[PostCallRecordingsKey].(type) {
+	case []string:
+		job.Recordings = recordings
+	case []interface{}:
+		// Restored from a StateStore.
+		for _, name := range recordings {
+			if s, ok := name.(string); ok {
+				job.Recordings = append(job.Recordings, s)
+			}
+		}
+	}
+	p.Resume(job, "")
+}
+
+// Resume runs job from stage on, e.g. a dead-lettered job once the failure is fixed. An empty
+// stage runs every stage.
+func (p *PostCallPipeline) Resume(job *PostCallJob, stage string) {
+	p.wg.Add(1)
+	p.client.goTracked("post_call", func() {
+		defer p.wg.Done()
+		p.slots <- struct{}{}
+		defer func() { <-p.slots }()
+		p.run(job, stage)
+	})
+}
+
+// Wait blocks until the submitted jobs are done or ctx is done, e.g. on shutdown.
+func (p *PostCallPipeline) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *PostCallPipeline) run(job *PostCallJob, from string) {
+	started := from == ""
+	for _, stage := range p.stages {
+		if !started {
+			if stage.Name != from {
+				continue
+			}
+			started = true
+		}
+		if err := p.runStage(stage, job); err != nil {
+			p.client.logger.Warnf("post-call: stage %s failed for channel %s: %v", stage.Name, job.Record.ChannelId, err)
+			p.client.metrics().IncCounter("ari_postcall_failures_total", map[string]string{"stage": stage.Name}, 1)
+			if p.OnDeadLetter != nil {
+				p.OnDeadLetter(job, stage.Name, err)
+			}
+			return
+		}
+	}
+}
+
+// runStage runs a stage with its retries.
+func (p *PostCallPipeline) runStage(stage PostCallStage, job *PostCallJob) error {
+	backoff := stage.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	var err error
+	for attempt := 0; attempt <= stage.Retries; attempt++ {
+		if attempt > 0 {
+			<-p.client.clock().After(backoff)
+			backoff *= 2
+		}
+		ctx, cancel := context.Background(), context.CancelFunc(func() {})
+		if stage.Timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, stage.Timeout)
+		}
+		err = stage.Run(ctx, job)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		p.client.logger.Debugf("post-call: stage %s attempt %d for channel %s: %v", stage.Name, attempt+1, job.Record.ChannelId, err)
+	}
+	return err
+}
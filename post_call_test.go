@@ -0,0 +1,56 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestWebhookStageUnlocked covers a slow webhook: the job stays readable while it answers.
+func TestWebhookStageUnlocked(t *testing.T) {
+	job := &PostCallJob{Record: CallRecord{ChannelId: "c1"}, Results: map[string]interface{}{"url": "https://example.com/r1"}}
+	type postedJob struct {
+		Record  CallRecord             `json:"record"`
+		Results map[string]interface{} `json:"results"`
+	}
+	posted := make(chan postedJob, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var got postedJob
+		json.NewDecoder(r.Body).Decode(&got)
+		posted <- got
+		<-release
+	}))
+	defer server.Close()
+	// Unblocks the webhook before the server closes, also when the test fails.
+	defer close(release)
+
+	result := make(chan error, 1)
+	go func() { result <- WebhookStage(server.URL, nil, nil).Run(context.Background(), job) }()
+	select {
+	case got := <-posted:
+		if got.Record.ChannelId != "c1" || got.Results["url"] != "https://example.com/r1" {
+			t.Errorf("posted = %+v, want the job", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not posted")
+	}
+
+	read := make(chan struct{})
+	go func() {
+		job.Get("url")
+		close(read)
+	}()
+	select {
+	case <-read:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job locked while the webhook answers")
+	}
+	release <- struct{}{}
+	if err := <-result; err != nil {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package asterisk_ari_go
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SayNumber returns the media URI saying n as a number, e.g. "forty two".
+func SayNumber(n int) string {
+	return "number:" + strconv.Itoa(n)
+}
+
+// SayDigits returns the media URI saying digits one by one, e.g. "four two".
+func SayDigits(digits string) string {
+	return "digits:" + digits
+}
+
+// ExpandPrompt composes a prompt from a template of media URIs. "{name}" placeholders are
+// replaced with the value of name, so that "number:{position}" says the position. Items whose
+// placeholders have no value are dropped.
+func ExpandPrompt(template []string, values map[string]int) []string {
+	media := make([]string, 0, len(template))
+	for _, item := range template {
+		expanded, ok := expandPromptItem(item, values)
+		if ok {
+			media = append(media, expanded)
+		}
+	}
+	return media
+}
+
+func expandPromptItem(item string, values map[string]int) (string, bool) {
+	var b strings.Builder
+	for {
+		open := strings.IndexByte(item, '{')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(item[open:], '}')
+		if end < 0 {
+			break
+		}
+		v, ok := values[item[open+1:open+end]]
+		if !ok {
+			return "", false
+		}
+		b.WriteString(item[:open])
+		b.WriteString(strconv.Itoa(v))
+		item = item[open+end+1:]
+	}
+	b.WriteString(item)
+	return b.String(), true
+}
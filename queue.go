@@ -0,0 +1,462 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/antihax/optional"
+)
+
+// DefaultQueueRingTimeout is how long a queue rings an agent by default.
+const DefaultQueueRingTimeout = 20 * time.Second
+
+// DefaultQueueRetryDelay is how long an agent who did not answer is skipped by default.
+const DefaultQueueRetryDelay = 5 * time.Second
+
+// DefaultQueueRecheckInterval is how often calls no free agent suits are retried by default.
+const DefaultQueueRecheckInterval = 5 * time.Second
+
+// Agent is a member of a queue, reached by dialling Endpoint.
+type Agent struct {
+	Id       string `json:"id"`
+	Endpoint string `json:"endpoint"`
+	// Weight of the agent for WeightedStrategy; 0 counts as 1.
+	Weight int `json:"weight,omitempty"`
+	// Skills of the agent with their proficiency, e.g. {"spanish": 3, "billing": 5}.
+	Skills map[string]int `json:"skills,omitempty"`
+}
+
+// AgentStatus is an available agent, as offered to a Strategy.
+type AgentStatus struct {
+	Agent
+	// Calls is the number of queue calls the agent answered.
+	Calls int `json:"calls"`
+	// LastCall is when the agent was last offered a call; zero if never.
+	LastCall time.Time `json:"last_call,omitempty"`
+	// IdleSince is when the agent last became free.
+	IdleSince time.Time `json:"idle_since"`
+}
+
+// QueuedCall is a caller waiting in a queue.
+type QueuedCall struct {
+	ChannelId    string    `json:"channel_id"`
+	CallerNumber string    `json:"caller_number,omitempty"`
+	Enqueued     time.Time `json:"enqueued"`
+	// Callback is the number the caller asked to be called back on; the caller hung up and
+	// keeps its place in line.
+	Callback string `json:"callback,omitempty"`
+	// Skills are the skills the call requires, with their minimum proficiency, see
+	// SkillStrategy.
+	Skills map[string]int `json:"skills,omitempty"`
+}
+
+type queueAgent struct {
+	status      AgentStatus
+	busy        bool
+	availableAt time.Time
+	// blocked is set by the AgentManager while the agent is not routable.
+	blocked bool
+	joined  time.Time
+}
+
+// queueSession is a caller connected, or being connected, to an agent.
+type queueSession struct {
+	call         QueuedCall
+	agentId      string
+	agentChannel string
+	bridgeId     string
+	answered     time.Time
+	gone         bool
+}
+
+// Queue is an ACD queue: callers wait on music on hold and are connected, first come first
+// served, to the agent picked by Strategy. The agent is originated into App and bridged with the
+// caller; the agent is free again once its channel is destroyed. Every event must be fed to
+// HandleEvent.
+type Queue struct {
+	client *APIClient
+	waits  *Waits
+
+	Name     string
+	Strategy Strategy
+	// App is the Stasis application agents are originated into; usually the one of the callers.
+	App string
+	// MohClass is the music on hold class of waiting callers; empty uses the default class.
+	MohClass string
+	// RingTimeout is how long an agent rings. Defaults to DefaultQueueRingTimeout.
+	RingTimeout time.Duration
+	// RetryDelay is how long an agent who did not answer is skipped. Defaults to
+	// DefaultQueueRetryDelay.
+	RetryDelay time.Duration
+	// ServiceLevelTarget is the wait within which answered calls meet the service level.
+	// Defaults to DefaultServiceLevelTarget.
+	ServiceLevelTarget time.Duration
+	// RecheckInterval is how often calls no free agent suits are retried. Defaults to
+	// DefaultQueueRecheckInterval.
+	RecheckInterval time.Duration
+	// Announcements, if set, are played periodically to waiting callers.
+	Announcements *QueueAnnouncements
+	// Callback, if set, offers waiting callers to be called back instead of waiting.
+	Callback *QueueCallback
+
+	mu       sync.Mutex
+	agents   map[string]*queueAgent
+	waiting  []QueuedCall
+	sessions map[string]*queueSession // by caller and agent channel ID
+	// prompting are the callers a prompt is being played to.
+	prompting map[string]bool
+	// handleTime is the moving average of the duration of answered calls.
+	handleTime time.Duration
+	// agentHook, set by AgentManager.Login, is told when agents start and end calls, outside
+	// of the lock. answered is set at the end of calls the agent took.
+	agentHook func(agentId string, busy bool, answered bool)
+	// recheckPending is set while a dispatch of unmatched calls is scheduled.
+	recheckPending bool
+	stats          queueCounters
+}
+
+// NewQueue creates a queue without agents. A nil strategy uses LongestIdleStrategy.
+func NewQueue(client *APIClient, name string, app string, strategy Strategy) *Queue {
+	if strategy == nil {
+		strategy = LongestIdleStrategy{}
+	}
+	waits := NewWaits(client)
+	waits.Prefix = "ari-queue-" + name
+	q := &Queue{
+		client:             client,
+		waits:              waits,
+		Name:               name,
+		Strategy:           strategy,
+		App:                app,
+		RingTimeout:        DefaultQueueRingTimeout,
+		RetryDelay:         DefaultQueueRetryDelay,
+		RecheckInterval:    DefaultQueueRecheckInterval,
+		ServiceLevelTarget: DefaultServiceLevelTarget,
+		agents:             make(map[string]*queueAgent),
+		sessions:           make(map[string]*queueSession),
+		prompting:          make(map[string]bool),
+	}
+	q.stats.since = client.clock().Now()
+	return q
+}
+
+// AddAgent adds an agent, or updates its endpoint and weight, and offers it the waiting callers.
+func (q *Queue) AddAgent(a Agent) {
+	q.mu.Lock()
+	q.putAgentLocked(a)
+	q.mu.Unlock()
+	q.dispatch()
+}
+
+// addAgentBlocked is AddAgent with the agent excluded from routing or not, set before any caller
+// is offered to it.
+func (q *Queue) addAgentBlocked(a Agent, blocked bool) {
+	q.mu.Lock()
+	q.putAgentLocked(a).blocked = blocked
+	q.mu.Unlock()
+	if !blocked {
+		q.dispatch()
+	}
+}
+
+func (q *Queue) putAgentLocked(a Agent) *queueAgent {
+	if qa, ok := q.agents[a.Id]; ok {
+		qa.status.Agent = a
+		return qa
+	}
+	now := q.client.clock().Now()
+	qa := &queueAgent{status: AgentStatus{Agent: a, IdleSince: now}, joined: now}
+	q.agents[a.Id] = qa
+	return qa
+}
+
+// RemoveAgent removes an agent. A call in progress with the agent is not affected.
+func (q *Queue) RemoveAgent(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if qa, ok := q.agents[id]; ok {
+		q.stats.presence += q.client.clock().Now().Sub(q.statsJoined(qa))
+	}
+	delete(q.agents, id)
+}
+
+// Agents returns the status of the agents of the queue.
+func (q *Queue) Agents() []AgentStatus {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	agents := make([]AgentStatus, 0, len(q.agents))
+	for _, qa := range q.agents {
+		agents = append(agents, qa.status)
+	}
+	return agents
+}
+
+// Waiting returns the waiting callers, first in line first.
+func (q *Queue) Waiting() []QueuedCall {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]QueuedCall(nil), q.waiting...)
+}
+
+// Enqueue puts an answered channel in the queue, on music on hold.
+func (q *Queue) Enqueue(ctx context.Context, channelId string, callerNumber string) error {
+	return q.EnqueueCall(ctx, QueuedCall{ChannelId: channelId, CallerNumber: callerNumber})
+}
+
+// EnqueueCall puts an answered channel in the queue, on music on hold, with the requirements of
+// the call such as its skills.
+func (q *Queue) EnqueueCall(ctx context.Context, call QueuedCall) error {
+	channelId, callerNumber := call.ChannelId, call.CallerNumber
+	opts := &ChannelsApiAddMohOpts{}
+	if q.MohClass != "" {
+		opts.MohClass = optional.NewString(q.MohClass)
+	}
+	if _, err := q.client.ChannelsApi.AddMoh(ctx, channelId, opts); err != nil {
+		return err
+	}
+	q.mu.Lock()
+	call.Enqueued = q.client.clock().Now()
+	q.waiting = append(q.waiting, call)
+	q.stats.offered++
+	q.reportLocked()
+	q.mu.Unlock()
+	if q.Announcements != nil {
+		q.client.goTracked("queue", func() { q.announce(channelId) })
+	}
+	if q.Callback != nil && q.Callback.Endpoint != nil {
+		q.client.goTracked("queue", func() { q.offerCallback(channelId, callerNumber) })
+	}
+	q.dispatch()
+	return nil
+}
+
+// HandleEvent removes callers who hang up and frees agents whose call ended.
+func (q *Queue) HandleEvent(ev StasisEvent) {
+	q.waits.HandleEvent(ev)
+	if ev.Type != "StasisEnd" && ev.Type != "ChannelDestroyed" {
+		return
+	}
+	channelId := ev.Channel.Id
+	q.mu.Lock()
+	for i, call := range q.waiting {
+		if call.ChannelId == channelId && call.Callback == "" {
+			q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+			q.abandonedLocked(call)
+			q.reportLocked()
+			break
+		}
+	}
+	s, ok := q.sessions[channelId]
+	if !ok {
+		q.mu.Unlock()
+		return
+	}
+	s.gone = true
+	var other string
+	agentFreed := channelId != s.call.ChannelId
+	if !agentFreed {
+		other = s.agentChannel
+		if s.answered.IsZero() {
+			// Hung up while the agent was ringing.
+			q.abandonedLocked(s.call)
+		}
+	} else {
+		other = s.call.ChannelId
+		q.freeLocked(s.agentId)
+		if !s.answered.IsZero() {
+			q.addHandleTimeLocked(q.client.clock().Now().Sub(s.answered))
+		}
+	}
+	delete(q.sessions, channelId)
+	q.mu.Unlock()
+
+	if agentFreed {
+		q.notifyAgent(s.agentId, false, !s.answered.IsZero())
+	}
+
+	if other != "" {
+		q.client.ChannelsApi.Hangup(context.Background(), other, &ChannelsApiHangupOpts{Reason: optional.NewString("normal")})
+	}
+	if s.bridgeId != "" && channelId != s.call.ChannelId {
+		q.client.BridgesApi.Destroy(context.Background(), s.bridgeId)
+	}
+	q.dispatch()
+}
+
+// freeLocked makes an agent available again after a call.
+func (q *Queue) freeLocked(agentId string) {
+	if qa, ok := q.agents[agentId]; ok {
+		qa.busy = false
+		qa.status.IdleSince = q.client.clock().Now()
+	}
+}
+
+// addHandleTimeLocked folds the duration of an answered call into the average handle time, with
+// the weighting Asterisk uses for its holdtime: 3/4 of the average, 1/4 of the new sample.
+func (q *Queue) addHandleTimeLocked(d time.Duration) {
+	q.stats.handled++
+	q.stats.handleTime += d
+	if q.handleTime == 0 {
+		q.handleTime = d
+		return
+	}
+	q.handleTime = (3*q.handleTime + d) / 4
+}
+
+// availableLocked returns the agents able to take a call at now.
+func (q *Queue) availableLocked(now time.Time) []AgentStatus {
+	var agents []AgentStatus
+	for _, qa := range q.agents {
+		if !qa.busy && !qa.blocked && !now.Before(qa.availableAt) {
+			agents = append(agents, qa.status)
+		}
+	}
+	return agents
+}
+
+// dispatch connects waiting callers to available agents.
+func (q *Queue) dispatch() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := q.client.clock().Now()
+	unmatched := false
+	for i := 0; i < len(q.waiting); {
+		candidates := q.availableLocked(now)
+		if len(candidates) == 0 {
+			break
+		}
+		call := q.waiting[i]
+		agent, ok := q.Strategy.Select(call, candidates)
+		qa, known := q.agents[agent.Id]
+		if !ok || !known {
+			// No free agent suits this call, e.g. for lack of a skill; the next ones may
+			// find one.
+			unmatched = true
+			i++
+			continue
+		}
+		qa.busy = true
+		qa.status.LastCall = now
+		q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+		s := &queueSession{call: call, agentId: agent.Id}
+		if call.Callback == "" {
+			q.sessions[call.ChannelId] = s
+		}
+		q.reportLocked()
+		q.client.goTracked("queue", func() { q.connect(s, agent) })
+	}
+	if unmatched && !q.recheckPending {
+		// What suits a call may change with its wait, see SkillOverflow.
+		q.recheckPending = true
+		q.client.goTracked("queue", func() {
+			<-q.client.clock().After(q.RecheckInterval)
+			q.mu.Lock()
+			q.recheckPending = false
+			q.mu.Unlock()
+			q.dispatch()
+		})
+	}
+}
+
+// connect rings the agent of s and bridges it with the caller. If the agent does not answer the
+// caller goes back to the head of the line.
+func (q *Queue) connect(s *queueSession, agent AgentStatus) {
+	q.notifyAgent(agent.Id, true, false)
+	ctx, cancel := context.WithTimeout(context.Background(), q.RingTimeout+5*time.Second)
+	defer cancel()
+	opts := &ChannelsApiOriginateWithIdOpts{
+		App:     optional.NewString(q.App),
+		AppArgs: optional.NewString("queue," + q.Name),
+		Timeout: optional.NewInt32(int32(q.RingTimeout / time.Second)),
+	}
+	if s.call.CallerNumber != "" {
+		opts.CallerId = optional.NewString(s.call.CallerNumber)
+	}
+	channel, err := q.waits.OriginateAndWait(ctx, agent.Endpoint, opts)
+	if err != nil {
+		q.client.logger.Infof("queue %s: agent %s did not answer: %v", q.Name, agent.Id, err)
+		q.mu.Lock()
+		delete(q.sessions, s.call.ChannelId)
+		if qa, ok := q.agents[agent.Id]; ok {
+			qa.busy = false
+			qa.availableAt = q.client.clock().Now().Add(q.RetryDelay)
+		}
+		if !s.gone {
+			q.waiting = append([]QueuedCall{s.call}, q.waiting...)
+			q.reportLocked()
+		}
+		q.mu.Unlock()
+		q.notifyAgent(agent.Id, false, false)
+		q.client.goTracked("queue", func() {
+			<-q.client.clock().After(q.RetryDelay)
+			q.dispatch()
+		})
+		q.dispatch()
+		return
+	}
+
+	q.mu.Lock()
+	s.agentChannel = channel.Id
+	q.sessions[channel.Id] = s
+	gone := s.gone
+	if !gone {
+		s.answered = q.client.clock().Now()
+		if qa, ok := q.agents[agent.Id]; ok {
+			qa.status.Calls++
+		}
+		q.answeredLocked(s.call, s.answered)
+	}
+	q.mu.Unlock()
+	if gone {
+		q.client.ChannelsApi.Hangup(ctx, channel.Id, &ChannelsApiHangupOpts{Reason: optional.NewString("normal")})
+		return
+	}
+	if s.call.Callback != "" {
+		if !q.callBack(s, channel.Id) {
+			return
+		}
+		// The callback rang for a while: bridge with a fresh deadline.
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+	}
+
+	bridge, _, err := q.client.BridgesApi.Create(ctx, &BridgesApiCreateOpts{Type_: optional.NewString("mixing")})
+	if err == nil {
+		q.mu.Lock()
+		s.bridgeId = bridge.Id
+		q.mu.Unlock()
+		q.client.ChannelsApi.Deletemoh(ctx, s.call.ChannelId)
+		_, err = q.client.BridgesApi.AddChannel(ctx, bridge.Id, []string{s.call.ChannelId, channel.Id}, nil)
+	}
+	if err != nil {
+		q.client.logger.Errorf("queue %s: failed to connect %s to agent %s: %v", q.Name, s.call.ChannelId, agent.Id, err)
+		q.client.ChannelsApi.Hangup(ctx, channel.Id, &ChannelsApiHangupOpts{Reason: optional.NewString("normal")})
+	}
+}
+
+// setAgentBlocked excludes an agent from routing, or includes it again.
+func (q *Queue) setAgentBlocked(agentId string, blocked bool) {
+	q.mu.Lock()
+	qa, ok := q.agents[agentId]
+	if ok {
+		qa.blocked = blocked
+	}
+	q.mu.Unlock()
+	if ok && !blocked {
+		q.dispatch()
+	}
+}
+
+func (q *Queue) notifyAgent(agentId string, busy bool, answered bool) {
+	q.mu.Lock()
+	hook := q.agentHook
+	q.mu.Unlock()
+	if hook != nil {
+		hook(agentId, busy, answered)
+	}
+}
+
+func (q *Queue) reportLocked() {
+	q.client.metrics().SetGauge("ari_queue_waiting", map[string]string{"queue": q.Name}, float64(len(q.waiting)))
+}
@@ -0,0 +1,158 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"time"
+
+	"github.com/antihax/optional"
+)
+
+// DefaultQueueAnnounceInterval is how often waiting callers hear their position by default.
+const DefaultQueueAnnounceInterval = 45 * time.Second
+
+// QueueAnnouncements configures the periodic announcements of a queue. Templates are composed
+// with ExpandPrompt; the placeholders are {position}, 1 for the next caller, and {minutes}, the
+// estimated wait rounded up.
+type QueueAnnouncements struct {
+	// Interval between announcements; the first is played one interval after enqueuing.
+	Interval time.Duration
+	// Next is played to the caller next in line instead of Position.
+	Next []string
+	// Position announces the position in the queue.
+	Position []string
+	// Wait announces the estimated wait. It is skipped until the queue has answered a call,
+	// since there is no estimate before.
+	Wait []string
+}
+
+// DefaultQueueAnnouncements returns announcements using the sounds shipped with Asterisk.
+func DefaultQueueAnnouncements() *QueueAnnouncements {
+	return &QueueAnnouncements{
+		Interval: DefaultQueueAnnounceInterval,
+		Next:     []string{"sound:queue-youarenext"},
+		Position: []string{"sound:queue-thereare", "number:{position}", "sound:queue-callswaiting"},
+		Wait:     []string{"sound:queue-holdtime", "number:{minutes}", "sound:queue-minutes"},
+	}
+}
+
+// Position returns the position of a waiting caller, 1 for the next in line, or 0 if the caller
+// is not waiting.
+func (q *Queue) Position(channelId string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.positionLocked(channelId)
+}
+
+// holdingLocked reports whether a caller is on the line in the queue, i.e. waiting and not
+// registered for a callback.
+func (q *Queue) holdingLocked(channelId string) bool {
+	for _, call := range q.waiting {
+		if call.ChannelId == channelId {
+			return call.Callback == ""
+		}
+	}
+	return false
+}
+
+func (q *Queue) positionLocked(channelId string) int {
+	for i, call := range q.waiting {
+		if call.ChannelId == channelId {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// EstimatedWait returns the expected wait of the caller at position: the calls ahead of it, and
+// its own, are answered as agents free up, each agent taking the average handle time per call.
+// It is 0 while the queue has no average yet.
+func (q *Queue) EstimatedWait(position int) time.Duration {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.estimatedWaitLocked(position)
+}
+
+func (q *Queue) estimatedWaitLocked(position int) time.Duration {
+	agents := len(q.agents)
+	if agents == 0 {
+		agents = 1
+	}
+	return time.Duration(position) * q.handleTime / time.Duration(agents)
+}
+
+// announce plays the announcements to a caller for as long as it waits.
+func (q *Queue) announce(channelId string) {
+	a := q.Announcements
+	interval := a.Interval
+	if interval <= 0 {
+		interval = DefaultQueueAnnounceInterval
+	}
+	for {
+		<-q.client.clock().After(interval)
+		q.mu.Lock()
+		position := q.positionLocked(channelId)
+		wait := q.estimatedWaitLocked(position)
+		holding := q.holdingLocked(channelId)
+		q.mu.Unlock()
+		if !holding {
+			return
+		}
+
+		values := map[string]int{"position": position}
+		var media []string
+		if position == 1 && len(a.Next) > 0 {
+			media = ExpandPrompt(a.Next, values)
+		} else {
+			media = ExpandPrompt(a.Position, values)
+		}
+		if wait > 0 {
+			values["minutes"] = int((wait + time.Minute - 1) / time.Minute)
+			media = append(media, ExpandPrompt(a.Wait, values)...)
+		}
+		if len(media) > 0 {
+			q.playToWaiting(channelId, media)
+		}
+	}
+}
+
+// playToWaiting interrupts the music on hold of a waiting caller to play media.
+func (q *Queue) playToWaiting(channelId string, media []string) {
+	q.withPrompt(channelId, func(ctx context.Context) {
+		if _, err := q.waits.PlayAndWait(ctx, channelId, media, nil); err != nil {
+			q.client.logger.Debugf("queue %s: announcement to %s failed: %v", q.Name, channelId, err)
+		}
+	})
+}
+
+// withPrompt runs f with the music on hold of a waiting caller stopped, and restarts it after
+// unless the caller left the line meanwhile. Prompts to a caller do not overlap.
+func (q *Queue) withPrompt(channelId string, f func(ctx context.Context)) {
+	q.mu.Lock()
+	if q.prompting[channelId] {
+		q.mu.Unlock()
+		return
+	}
+	q.prompting[channelId] = true
+	q.mu.Unlock()
+	defer func() {
+		q.mu.Lock()
+		delete(q.prompting, channelId)
+		q.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	q.client.ChannelsApi.Deletemoh(ctx, channelId)
+	f(ctx)
+	q.mu.Lock()
+	holding := q.holdingLocked(channelId)
+	q.mu.Unlock()
+	if !holding {
+		return
+	}
+	opts := &ChannelsApiAddMohOpts{}
+	if q.MohClass != "" {
+		opts.MohClass = optional.NewString(q.MohClass)
+	}
+	q.client.ChannelsApi.AddMoh(ctx, channelId, opts)
+}
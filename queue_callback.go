@@ -0,0 +1,203 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/antihax/optional"
+)
+
+// DefaultQueueCallbackAfter is how long a caller waits before being offered a callback by
+// default.
+const DefaultQueueCallbackAfter = 2 * time.Minute
+
+// QueueCallback configures the callback offer of a queue: past a wait threshold, callers can
+// press Digit to be called back instead of waiting. Their number is read back for confirmation,
+// or entered, the call is released and the caller keeps its place in line. When its turn comes,
+// the agent is rung first and the caller is called back once the agent answers.
+type QueueCallback struct {
+	// After is the wait before the offer, and between offers. Defaults to
+	// DefaultQueueCallbackAfter.
+	After time.Duration
+	// Digit accepts the offer. Defaults to "1".
+	Digit string
+	// Offer invites the caller to press Digit.
+	Offer []string
+	// ConfirmNumber precedes the caller number, read back digit by digit, and ConfirmKeys follows
+	// it, inviting to press 1 to confirm or any other key to enter another number.
+	ConfirmNumber []string
+	ConfirmKeys   []string
+	// EnterNumber asks for the number, followed by #.
+	EnterNumber []string
+	// Invalid is played after an invalid number.
+	Invalid []string
+	// Registered is played before the call is released.
+	Registered []string
+	// Validate reports whether a number can be called back. Defaults to ValidCallbackNumber.
+	Validate func(number string) bool
+	// Endpoint returns the endpoint calling a number, e.g. "PJSIP/" + number + "@trunk". It is
+	// required: without it callbacks are not offered.
+	Endpoint func(number string) string
+}
+
+// DefaultQueueCallback returns a callback offer using the sounds shipped with Asterisk; only
+// Endpoint is left to set.
+func DefaultQueueCallback() *QueueCallback {
+	return &QueueCallback{
+		After:         DefaultQueueCallbackAfter,
+		Digit:         "1",
+		Offer:         []string{"sound:queue-callback-offer"},
+		ConfirmNumber: []string{"sound:queue-callback-number"},
+		ConfirmKeys:   []string{"sound:queue-callback-confirm"},
+		EnterNumber:   []string{"sound:queue-callback-enter"},
+		Invalid:       []string{"sound:invalid"},
+		Registered:    []string{"sound:queue-callback-registered"},
+	}
+}
+
+// ValidCallbackNumber accepts numbers of 7 to 15 digits, with an optional leading +.
+func ValidCallbackNumber(number string) bool {
+	digits := number
+	if len(digits) > 0 && digits[0] == '+' {
+		digits = digits[1:]
+	}
+	if len(digits) < 7 || len(digits) > 15 {
+		return false
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// offerCallback offers a callback to a caller for as long as it waits.
+func (q *Queue) offerCallback(channelId string, callerNumber string) {
+	cb := q.Callback
+	after := cb.After
+	if after <= 0 {
+		after = DefaultQueueCallbackAfter
+	}
+	digit := cb.Digit
+	if digit == "" {
+		digit = "1"
+	}
+	for {
+		<-q.client.clock().After(after)
+		q.mu.Lock()
+		holding := q.holdingLocked(channelId)
+		q.mu.Unlock()
+		if !holding {
+			return
+		}
+		registered := false
+		q.withPrompt(channelId, func(ctx context.Context) {
+			// A caller staying silent gets ErrNoInput once the offer is over, and goes back to
+			// the music on hold.
+			pressed, err := q.waits.CollectDigits(ctx, channelId, &CollectOpts{Max: 1, Prompt: cb.Offer})
+			if err != nil || pressed != digit {
+				return
+			}
+			number, ok := q.captureNumber(ctx, channelId, callerNumber)
+			if !ok {
+				return
+			}
+			registered = q.registerCallback(channelId, number)
+			if registered && len(cb.Registered) > 0 {
+				q.waits.PlayAndWait(ctx, channelId, cb.Registered, nil)
+			}
+		})
+		if registered {
+			q.client.ChannelsApi.Hangup(context.Background(), channelId, &ChannelsApiHangupOpts{Reason: optional.NewString("normal")})
+			return
+		}
+	}
+}
+
+// captureNumber confirms the caller number, or asks for another one.
+func (q *Queue) captureNumber(ctx context.Context, channelId string, callerNumber string) (string, bool) {
+	cb := q.Callback
+	validate := cb.Validate
+	if validate == nil {
+		validate = ValidCallbackNumber
+	}
+	if validate(callerNumber) {
+		prompt := append(append(append([]string(nil), cb.ConfirmNumber...), SayDigits(callerNumber)), cb.ConfirmKeys...)
+		pressed, err := q.waits.CollectDigits(ctx, channelId, &CollectOpts{Max: 1, Prompt: prompt})
+		if errors.Is(err, ErrChannelGone) || ctx.Err() != nil {
+			return "", false
+		}
+		if pressed == "1" {
+			return callerNumber, true
+		}
+	}
+	for attempt := 0; attempt < 3; attempt++ {
+		number, err := q.waits.CollectDigits(ctx, channelId, &CollectOpts{Max: 15, Terminator: "#", Prompt: cb.EnterNumber})
+		if errors.Is(err, ErrChannelGone) || ctx.Err() != nil {
+			return "", false
+		}
+		if validate(number) {
+			return number, true
+		}
+		if len(cb.Invalid) > 0 {
+			q.waits.PlayAndWait(ctx, channelId, cb.Invalid, nil)
+		}
+	}
+	return "", false
+}
+
+// registerCallback turns a waiting caller into a callback, keeping its place in line.
+func (q *Queue) registerCallback(channelId string, number string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i := range q.waiting {
+		if q.waiting[i].ChannelId == channelId {
+			q.waiting[i].Callback = number
+			q.stats.callbacks++
+			q.client.logger.Infof("queue %s: %s registered a callback to %s at position %d", q.Name, channelId, number, i+1)
+			q.client.metrics().IncCounter("ari_queue_callbacks_total", map[string]string{"queue": q.Name, "result": "registered"}, 1)
+			return true
+		}
+	}
+	// Connected to an agent meanwhile.
+	return false
+}
+
+// callBack calls the caller of s back once its agent answered on agentChannel, and makes the
+// new channel the caller of s. It reports whether the caller answered; if not, the agent is hung
+// up.
+func (q *Queue) callBack(s *queueSession, agentChannel string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), q.RingTimeout+5*time.Second)
+	defer cancel()
+	q.client.ChannelsApi.Ring(ctx, agentChannel)
+	opts := &ChannelsApiOriginateWithIdOpts{
+		App:     optional.NewString(q.App),
+		AppArgs: optional.NewString("queue-callback," + q.Name),
+		Timeout: optional.NewInt32(int32(q.RingTimeout / time.Second)),
+	}
+	customer, err := q.waits.OriginateAndWait(ctx, q.Callback.Endpoint(s.call.Callback), opts)
+	result := "answered"
+	if err != nil {
+		result = "failed"
+	}
+	q.client.metrics().IncCounter("ari_queue_callbacks_total", map[string]string{"queue": q.Name, "result": result}, 1)
+	if err != nil {
+		q.client.logger.Warnf("queue %s: callback to %s failed: %v", q.Name, s.call.Callback, err)
+		q.client.ChannelsApi.Hangup(context.Background(), agentChannel, &ChannelsApiHangupOpts{Reason: optional.NewString("normal")})
+		return false
+	}
+	q.client.ChannelsApi.RingStop(ctx, agentChannel)
+
+	q.mu.Lock()
+	s.call.ChannelId = customer.Id
+	q.sessions[customer.Id] = s
+	gone := s.gone
+	q.mu.Unlock()
+	if gone {
+		q.client.ChannelsApi.Hangup(context.Background(), customer.Id, &ChannelsApiHangupOpts{Reason: optional.NewString("normal")})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+package asterisk_ari_go
+
+import "time"
+
+// SkillOverflow relaxes the skill requirements of calls that waited long enough, so that they
+// are not stuck when no skilled agent is free. Rules apply cumulatively once their After is
+// reached.
+type SkillOverflow struct {
+	// After is the wait from which the rule applies.
+	After time.Duration
+	// LowerBy lowers the minimum proficiency of every required skill.
+	LowerBy int
+	// Drop removes skills from the requirements, e.g. a preferred language.
+	Drop []string
+	// AnyAgent removes all requirements.
+	AnyAgent bool
+}
+
+// SkillStrategy routes calls tagged with required skills (QueuedCall.Skills) to agents having
+// each of them at the required proficiency or above. Among the qualified agents, the most
+// proficient are preferred when PreferProficient is set, and Next picks one.
+type SkillStrategy struct {
+	// Next picks among the qualified agents. Defaults to LongestIdleStrategy.
+	Next Strategy
+	// PreferProficient restricts the choice to the agents with the highest total proficiency
+	// in the required skills.
+	PreferProficient bool
+	// Overflow rules, by increasing After.
+	Overflow []SkillOverflow
+	Clock    Clock
+}
+
+// NewSkillStrategy creates a skill matcher. A nil next uses LongestIdleStrategy.
+func NewSkillStrategy(next Strategy, overflow ...SkillOverflow) *SkillStrategy {
+	if next == nil {
+		next = LongestIdleStrategy{}
+	}
+	return &SkillStrategy{Next: next, Overflow: overflow, Clock: RealClock}
+}
+
+// Select picks a qualified agent; none if no candidate qualifies yet.
+func (s *SkillStrategy) Select(call QueuedCall, candidates []AgentStatus) (AgentStatus, bool) {
+	required := s.Requirements(call)
+	var qualified []AgentStatus
+	best := -1
+	for _, a := range candidates {
+		score, ok := skillScore(a.Skills, required)
+		if !ok {
+			continue
+		}
+		if s.PreferProficient {
+			if score < best {
+				continue
+			}
+			if score > best {
+				best, qualified = score, qualified[:0]
+			}
+		}
+		qualified = append(qualified, a)
+	}
+	if len(qualified) == 0 {
+		return AgentStatus{}, false
+	}
+	return s.Next.Select(call, qualified)
+}
+
+// Requirements returns the skills required from the agent of a call, after overflow.
+func (s *SkillStrategy) Requirements(call QueuedCall) map[string]int {
+	waited := s.Clock.Now().Sub(call.Enqueued)
+	required := make(map[string]int, len(call.Skills))
+	for skill, level := range call.Skills {
+		required[skill] = level
+	}
+	for _, rule := range s.Overflow {
+		if waited < rule.After {
+			continue
+		}
+		if rule.AnyAgent {
+			return map[string]int{}
+		}
+		for skill := range required {
+			required[skill] -= rule.LowerBy
+		}
+		for _, skill := range rule.Drop {
+			delete(required, skill)
+		}
+	}
+	return required
+}
+
+// skillScore reports whether skills meet required, and their total proficiency in the required
+// skills.
+func skillScore(skills map[string]int, required map[string]int) (int, bool) {
+	score := 0
+	for skill, level := range required {
+		have, ok := skills[skill]
+		if !ok || have < level {
+			return 0, false
+		}
+		score += have
+	}
+	return score, true
+}
@@ -0,0 +1,61 @@
+package asterisk_ari_go
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func skilledAgents() []AgentStatus {
+	return []AgentStatus{
+		{Agent: Agent{Id: "bob", Skills: map[string]int{"billing": 2}}, IdleSince: strategyEpoch},
+		{Agent: Agent{Id: "alice", Skills: map[string]int{"billing": 4, "french": 3}}, IdleSince: strategyEpoch.Add(time.Minute)},
+		{Agent: Agent{Id: "carol", Skills: map[string]int{"billing": 5, "french": 5}}, IdleSince: strategyEpoch.Add(2 * time.Minute)},
+	}
+}
+
+func TestSkillStrategy(t *testing.T) {
+	s := NewSkillStrategy(nil)
+	s.Clock = NewFakeClock(strategyEpoch)
+	call := QueuedCall{Enqueued: strategyEpoch, Skills: map[string]int{"billing": 3, "french": 1}}
+
+	// Bob lacks french; alice has been idle the longest of the others.
+	if a, ok := s.Select(call, skilledAgents()); !ok || a.Id != "alice" {
+		t.Errorf("pick = %s, %v, want alice", a.Id, ok)
+	}
+	s.PreferProficient = true
+	if a, ok := s.Select(call, skilledAgents()); !ok || a.Id != "carol" {
+		t.Errorf("proficient pick = %s, %v, want carol", a.Id, ok)
+	}
+	if a, ok := s.Select(QueuedCall{Skills: map[string]int{"spanish": 1}}, skilledAgents()); ok {
+		t.Errorf("pick = %s, want none for a missing skill", a.Id)
+	}
+}
+
+func TestSkillOverflow(t *testing.T) {
+	clock := NewFakeClock(strategyEpoch)
+	s := NewSkillStrategy(nil,
+		SkillOverflow{After: time.Minute, LowerBy: 1, Drop: []string{"french"}},
+		SkillOverflow{After: 5 * time.Minute, AnyAgent: true},
+	)
+	s.Clock = clock
+	call := QueuedCall{Enqueued: strategyEpoch, Skills: map[string]int{"billing": 3, "french": 4}}
+	step := func(d time.Duration, want map[string]int) {
+		t.Helper()
+		clock.Advance(d)
+		if got := s.Requirements(call); !reflect.DeepEqual(got, want) {
+			t.Errorf("after %v: requirements = %v, want %v", clock.Now().Sub(strategyEpoch), got, want)
+		}
+	}
+
+	step(0, map[string]int{"billing": 3, "french": 4})
+	step(time.Minute, map[string]int{"billing": 2})
+	if a, ok := s.Select(call, skilledAgents()); !ok || a.Id != "bob" {
+		t.Errorf("pick after overflow = %s, %v, want bob", a.Id, ok)
+	}
+	step(4*time.Minute, map[string]int{})
+	// The requirements of the call itself are left untouched.
+	if call.Skills["french"] != 4 {
+		t.Errorf("call skills changed to %v", call.Skills)
+	}
+}
@@ -0,0 +1,147 @@
+package asterisk_ari_go
+
+import "time"
+
+// DefaultServiceLevelTarget is the wait within which answered calls meet the service level by
+// default.
+const DefaultServiceLevelTarget = 20 * time.Second
+
+// QueueStats are the service level statistics of a queue, since its creation or the last
+// ResetStats.
+type QueueStats struct {
+	Queue string    `json:"queue"`
+	Since time.Time `json:"since"`
+
+	// Waiting is the number of callers in line, LongestWait the wait of the first.
+	Waiting     int           `json:"waiting"`
+	LongestWait time.Duration `json:"longest_wait"`
+	Agents      int           `json:"agents"`
+	AgentsBusy  int           `json:"agents_busy"`
+
+	// Offered calls entered the queue; Answered reached an agent; Abandoned hung up before;
+	// Callbacks were registered instead of waiting.
+	Offered   int `json:"offered"`
+	Answered  int `json:"answered"`
+	Abandoned int `json:"abandoned"`
+	Callbacks int `json:"callbacks"`
+	// AnsweredWithinTarget calls were answered within ServiceLevelTarget.
+	AnsweredWithinTarget int           `json:"answered_within_target"`
+	ServiceLevelTarget   time.Duration `json:"service_level_target"`
+
+	// ServiceLevel is the share of the answered and abandoned calls answered within the target.
+	ServiceLevel float64 `json:"service_level"`
+	// AbandonRate is the share of the answered and abandoned calls that were abandoned.
+	AbandonRate float64 `json:"abandon_rate"`
+	// AverageSpeedOfAnswer is the average wait of the answered calls.
+	AverageSpeedOfAnswer time.Duration `json:"average_speed_of_answer"`
+	// AverageHandleTime is the average duration of the finished answered calls.
+	AverageHandleTime time.Duration `json:"average_handle_time"`
+	// Occupancy is the share of the time the agents were in the queue they spent on its calls.
+	Occupancy float64 `json:"occupancy"`
+}
+
+type queueCounters struct {
+	since        time.Time
+	offered      int
+	answered     int
+	abandoned    int
+	callbacks    int
+	withinTarget int
+	answerWait   time.Duration
+	handled      int
+	handleTime   time.Duration
+	presence     time.Duration // agent time in the queue of the agents removed
+}
+
+// Stats returns the statistics of the queue.
+func (q *Queue) Stats() QueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := q.client.clock().Now()
+	c := q.stats
+	st := QueueStats{
+		Queue:                q.Name,
+		Since:                c.since,
+		Waiting:              len(q.waiting),
+		Agents:               len(q.agents),
+		Offered:              c.offered,
+		Answered:             c.answered,
+		Abandoned:            c.abandoned,
+		Callbacks:            c.callbacks,
+		AnsweredWithinTarget: c.withinTarget,
+		ServiceLevelTarget:   q.ServiceLevelTarget,
+	}
+	if len(q.waiting) > 0 {
+		st.LongestWait = now.Sub(q.waiting[0].Enqueued)
+	}
+	presence := c.presence
+	for _, qa := range q.agents {
+		if qa.busy {
+			st.AgentsBusy++
+		}
+		presence += now.Sub(q.statsJoined(qa))
+	}
+	if n := c.answered + c.abandoned; n > 0 {
+		st.ServiceLevel = float64(c.withinTarget) / float64(n)
+		st.AbandonRate = float64(c.abandoned) / float64(n)
+	}
+	if c.answered > 0 {
+		st.AverageSpeedOfAnswer = c.answerWait / time.Duration(c.answered)
+	}
+	if c.handled > 0 {
+		st.AverageHandleTime = c.handleTime / time.Duration(c.handled)
+	}
+	if presence > 0 {
+		st.Occupancy = float64(c.handleTime) / float64(presence)
+		if st.Occupancy > 1 {
+			st.Occupancy = 1
+		}
+	}
+	return st
+}
+
+// ResetStats starts a new statistics period, e.g. every day or every interval of a report.
+func (q *Queue) ResetStats() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.stats = queueCounters{since: q.client.clock().Now()}
+}
+
+// statsJoined returns when an agent joined the queue within the statistics period.
+func (q *Queue) statsJoined(qa *queueAgent) time.Time {
+	if qa.joined.Before(q.stats.since) {
+		return q.stats.since
+	}
+	return qa.joined
+}
+
+func (q *Queue) answeredLocked(call QueuedCall, at time.Time) {
+	wait := at.Sub(call.Enqueued)
+	q.stats.answered++
+	q.stats.answerWait += wait
+	if wait <= q.ServiceLevelTarget {
+		q.stats.withinTarget++
+	}
+	labels := map[string]string{"queue": q.Name}
+	q.client.metrics().Observe("ari_queue_answer_seconds", labels, wait.Seconds())
+	q.client.metrics().IncCounter("ari_queue_calls_total", map[string]string{"queue": q.Name, "result": "answered"}, 1)
+	q.reportServiceLevelLocked()
+}
+
+func (q *Queue) abandonedLocked(call QueuedCall) {
+	q.stats.abandoned++
+	wait := q.client.clock().Now().Sub(call.Enqueued)
+	q.client.metrics().Observe("ari_queue_abandon_seconds", map[string]string{"queue": q.Name}, wait.Seconds())
+	q.client.metrics().IncCounter("ari_queue_calls_total", map[string]string{"queue": q.Name, "result": "abandoned"}, 1)
+	q.reportServiceLevelLocked()
+}
+
+func (q *Queue) reportServiceLevelLocked() {
+	n := q.stats.answered + q.stats.abandoned
+	if n == 0 {
+		return
+	}
+	labels := map[string]string{"queue": q.Name}
+	q.client.metrics().SetGauge("ari_queue_service_level", labels, float64(q.stats.withinTarget)/float64(n))
+	q.client.metrics().SetGauge("ari_queue_abandon_rate", labels, float64(q.stats.abandoned)/float64(n))
+}
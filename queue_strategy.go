@@ -0,0 +1,170 @@
+package asterisk_ari_go
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Strategy picks the agent a queued call is offered to among the available ones. candidates is
+// never empty; implementations must be safe for concurrent use.
+type Strategy interface {
+	Select(call QueuedCall, candidates []AgentStatus) (AgentStatus, bool)
+}
+
+// StrategyFunc adapts a function to Strategy.
+type StrategyFunc func(call QueuedCall, candidates []AgentStatus) (AgentStatus, bool)
+
+// Select calls f.
+func (f StrategyFunc) Select(call QueuedCall, candidates []AgentStatus) (AgentStatus, bool) {
+	return f(call, candidates)
+}
+
+// RoundRobinStrategy offers calls to the agents in turn, in the order of their IDs.
+type RoundRobinStrategy struct {
+	mu   sync.Mutex
+	last string
+}
+
+// Select picks the agent following the last one picked.
+func (s *RoundRobinStrategy) Select(call QueuedCall, candidates []AgentStatus) (AgentStatus, bool) {
+	sorted := append([]AgentStatus(nil), candidates...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Id < sorted[j].Id })
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pick := sorted[0]
+	for _, a := range sorted {
+		if a.Id > s.last {
+			pick = a
+			break
+		}
+	}
+	s.last = pick.Id
+	return pick, true
+}
+
+// LeastRecentStrategy offers calls to the agent who was offered one the longest ago.
+type LeastRecentStrategy struct{}
+
+// Select picks the agent with the oldest LastCall; agents never called first.
+func (LeastRecentStrategy) Select(call QueuedCall, candidates []AgentStatus) (AgentStatus, bool) {
+	return minAgent(candidates, func(a AgentStatus) time.Time { return a.LastCall }), true
+}
+
+// LongestIdleStrategy offers calls to the agent who has been free the longest.
+type LongestIdleStrategy struct{}
+
+// Select picks the agent with the oldest IdleSince.
+func (LongestIdleStrategy) Select(call QueuedCall, candidates []AgentStatus) (AgentStatus, bool) {
+	return minAgent(candidates, func(a AgentStatus) time.Time { return a.IdleSince }), true
+}
+
+// minAgent returns the candidate with the earliest key, the lowest ID among ties.
+func minAgent(candidates []AgentStatus, key func(AgentStatus) time.Time) AgentStatus {
+	pick := candidates[0]
+	for _, a := range candidates[1:] {
+		if k, p := key(a), key(pick); k.Before(p) || (k.Equal(p) && a.Id < pick.Id) {
+			pick = a
+		}
+	}
+	return pick
+}
+
+// WeightedStrategy offers calls to agents at random, in proportion to their Weight.
+type WeightedStrategy struct {
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewWeightedStrategy creates a weighted strategy drawing from r; nil uses a time-seeded source.
+func NewWeightedStrategy(r *rand.Rand) *WeightedStrategy {
+	if r == nil {
+		r = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return &WeightedStrategy{rand: r}
+}
+
+// Select draws an agent.
+func (s *WeightedStrategy) Select(call QueuedCall, candidates []AgentStatus) (AgentStatus, bool) {
+	weight := func(a AgentStatus) int {
+		if a.Weight <= 0 {
+			return 1
+		}
+		return a.Weight
+	}
+	total := 0
+	for _, a := range candidates {
+		total += weight(a)
+	}
+	s.mu.Lock()
+	n := s.rand.Intn(total)
+	s.mu.Unlock()
+	for _, a := range candidates {
+		if n -= weight(a); n < 0 {
+			return a, true
+		}
+	}
+	return candidates[len(candidates)-1], true
+}
+
+// StickyStrategy offers a returning caller to the agent who took their previous call, if that
+// agent is available, so that callers keep dealing with the same person. Otherwise, and for
+// callers without a number, it defers to Fallback.
+type StickyStrategy struct {
+	Fallback Strategy
+	// TTL is how long a caller sticks to an agent after their last call.
+	TTL   time.Duration
+	Clock Clock
+
+	mu     sync.Mutex
+	recent map[string]stickyAgent
+}
+
+type stickyAgent struct {
+	agentId string
+	at      time.Time
+}
+
+// NewStickyStrategy creates a sticky strategy. A nil fallback uses LongestIdleStrategy.
+func NewStickyStrategy(fallback Strategy, ttl time.Duration) *StickyStrategy {
+	if fallback == nil {
+		fallback = LongestIdleStrategy{}
+	}
+	return &StickyStrategy{Fallback: fallback, TTL: ttl, Clock: RealClock, recent: make(map[string]stickyAgent)}
+}
+
+// Select picks the agent the caller last had, or the choice of Fallback.
+func (s *StickyStrategy) Select(call QueuedCall, candidates []AgentStatus) (AgentStatus, bool) {
+	if call.CallerNumber == "" {
+		return s.Fallback.Select(call, candidates)
+	}
+	now := s.Clock.Now()
+	s.mu.Lock()
+	prev, ok := s.recent[call.CallerNumber]
+	s.mu.Unlock()
+	if ok && now.Sub(prev.at) < s.TTL {
+		for _, a := range candidates {
+			if a.Id == prev.agentId {
+				s.remember(call.CallerNumber, a.Id, now)
+				return a, true
+			}
+		}
+	}
+	pick, ok := s.Fallback.Select(call, candidates)
+	if ok {
+		s.remember(call.CallerNumber, pick.Id, now)
+	}
+	return pick, ok
+}
+
+func (s *StickyStrategy) remember(caller string, agentId string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, v := range s.recent {
+		if now.Sub(v.at) >= s.TTL {
+			delete(s.recent, k)
+		}
+	}
+	s.recent[caller] = stickyAgent{agentId: agentId, at: now}
+}
@@ -0,0 +1,111 @@
+package asterisk_ari_go
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+var strategyEpoch = time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+
+func strategyAgents() []AgentStatus {
+	return []AgentStatus{
+		{Agent: Agent{Id: "bob", Weight: 1}, LastCall: strategyEpoch.Add(2 * time.Minute), IdleSince: strategyEpoch.Add(3 * time.Minute)},
+		{Agent: Agent{Id: "alice", Weight: 3}, LastCall: strategyEpoch.Add(time.Minute), IdleSince: strategyEpoch.Add(4 * time.Minute)},
+		{Agent: Agent{Id: "carol"}, IdleSince: strategyEpoch.Add(5 * time.Minute)},
+	}
+}
+
+func TestRoundRobinStrategy(t *testing.T) {
+	s := &RoundRobinStrategy{}
+	var got []string
+	for i := 0; i < 4; i++ {
+		a, ok := s.Select(QueuedCall{}, strategyAgents())
+		if !ok {
+			t.Fatal("no agent selected")
+		}
+		got = append(got, a.Id)
+	}
+	want := []string{"alice", "bob", "carol", "alice"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("picks = %v, want %v", got, want)
+		}
+	}
+	// An agent leaving does not reset the rotation.
+	a, _ := s.Select(QueuedCall{}, strategyAgents()[:1])
+	if a.Id != "bob" {
+		t.Fatalf("pick = %s, want bob", a.Id)
+	}
+}
+
+func TestLeastRecentStrategy(t *testing.T) {
+	a, _ := LeastRecentStrategy{}.Select(QueuedCall{}, strategyAgents())
+	if a.Id != "carol" {
+		t.Fatalf("pick = %s, want carol who was never called", a.Id)
+	}
+	a, _ = LeastRecentStrategy{}.Select(QueuedCall{}, strategyAgents()[:2])
+	if a.Id != "alice" {
+		t.Fatalf("pick = %s, want alice", a.Id)
+	}
+}
+
+func TestLongestIdleStrategy(t *testing.T) {
+	a, _ := LongestIdleStrategy{}.Select(QueuedCall{}, strategyAgents())
+	if a.Id != "bob" {
+		t.Fatalf("pick = %s, want bob", a.Id)
+	}
+}
+
+func TestWeightedStrategy(t *testing.T) {
+	s := NewWeightedStrategy(rand.New(rand.NewSource(1)))
+	counts := map[string]int{}
+	const draws = 5000
+	for i := 0; i < draws; i++ {
+		a, _ := s.Select(QueuedCall{}, strategyAgents())
+		counts[a.Id]++
+	}
+	// Weights 1, 3 and 1 (the default): alice gets 60% of the calls.
+	if share := float64(counts["alice"]) / draws; share < 0.55 || share > 0.65 {
+		t.Fatalf("alice got %.2f of the calls, want 0.60: %v", share, counts)
+	}
+	if counts["bob"] == 0 || counts["carol"] == 0 {
+		t.Fatalf("an agent was never picked: %v", counts)
+	}
+}
+
+func TestStickyStrategy(t *testing.T) {
+	clock := NewFakeClock(strategyEpoch)
+	s := NewStickyStrategy(&RoundRobinStrategy{}, time.Hour)
+	s.Clock = clock
+	caller := QueuedCall{CallerNumber: "+15551234"}
+
+	first, _ := s.Select(caller, strategyAgents())
+	if first.Id != "alice" {
+		t.Fatalf("first pick = %s, want alice from the fallback", first.Id)
+	}
+	again, _ := s.Select(caller, strategyAgents())
+	if again.Id != first.Id {
+		t.Fatalf("returning caller got %s, want %s", again.Id, first.Id)
+	}
+
+	// The agent is busy: the fallback picks someone else, who becomes the sticky agent.
+	other, _ := s.Select(caller, strategyAgents()[:1])
+	if other.Id != "bob" {
+		t.Fatalf("pick = %s, want bob", other.Id)
+	}
+	if a, _ := s.Select(caller, strategyAgents()); a.Id != "bob" {
+		t.Fatalf("pick = %s, want bob", a.Id)
+	}
+
+	// Past the TTL the caller is routed afresh.
+	clock.Advance(2 * time.Hour)
+	if a, _ := s.Select(caller, strategyAgents()); a.Id != "carol" {
+		t.Fatalf("pick after TTL = %s, want carol", a.Id)
+	}
+
+	// Anonymous callers do not stick.
+	if a, _ := s.Select(QueuedCall{}, strategyAgents()); a.Id != "alice" {
+		t.Fatalf("anonymous pick = %s, want alice", a.Id)
+	}
+}
@@ -0,0 +1,77 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultQuiesceWindow is the settle window of Quiesce when Configuration.QuiesceWindow is 0.
+const DefaultQuiesceWindow = 500 * time.Millisecond
+
+// clientActivity tracks the REST calls in flight and the time of the last activity of a client.
+type clientActivity struct {
+	mu       sync.Mutex
+	inflight int
+	last     time.Time
+}
+
+func (a *clientActivity) begin(now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.inflight++
+	a.last = now
+}
+
+func (a *clientActivity) end(now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.inflight--
+	a.last = now
+}
+
+func (a *clientActivity) touch(now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.last = now
+}
+
+// idleFor returns how long the client has been idle at now, 0 while REST calls are in flight.
+func (a *clientActivity) idleFor(now time.Time) time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.inflight > 0 {
+		return 0
+	}
+	return now.Sub(a.last)
+}
+
+// Quiesce blocks until no REST call has been in flight and no event has been passed to
+// HandleEvent for the settle window of Configuration.QuiesceWindow, or ctx is done. Meant for
+// the teardown of integration tests against a real Asterisk, so that the events of one test do
+// not leak into the next.
+func (c *APIClient) Quiesce(ctx context.Context) error {
+	window := c.cfg.QuiesceWindow
+	if window <= 0 {
+		window = DefaultQuiesceWindow
+	}
+	timer := c.clock().NewTimer(window)
+	defer timer.Stop()
+	for {
+		idle := c.activity.idleFor(c.clock().Now())
+		if idle >= window {
+			return nil
+		}
+		wait := window - idle
+		if idle == 0 {
+			// Calls in flight: poll until they are done.
+			wait = window / 10
+		}
+		timer.Reset(wait)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestQuiesceFakeClock(t *testing.T) {
+	cfg := NewConfiguration("/")
+	clock := NewFakeClock(time.Unix(1000, 0))
+	cfg.Clock = clock
+	cfg.QuiesceWindow = time.Second
+	client := NewAPIClient(cfg, NewStdLogger(ioutil.Discard))
+	client.HandleEvent(StasisEvent{Type: "ChannelDtmfReceived"})
+
+	result := make(chan error, 1)
+	go func() { result <- client.Quiesce(context.Background()) }()
+	waitTimers(t, clock, 1)
+	select {
+	case err := <-result:
+		t.Fatalf("Quiesce returned %v within the window", err)
+	default:
+	}
+	clock.Advance(time.Second)
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Fatalf("err = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Quiesce did not return after the window")
+	}
+}
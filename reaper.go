@@ -0,0 +1,113 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"strings"
+)
+
+// Reaper tears down the resources left behind by a crashed instance of the application. Resources
+// are recognized by the prefix of their client-chosen ID, so every instance must create its
+// channels, bridges and recordings with IDs starting with a prefix of its own.
+//
+// Run it on startup and after every websocket reconnect; on reconnect, set InUse so that the calls
+// still handled by this instance are kept.
+type Reaper struct {
+	client *APIClient
+
+	// Channels, Bridges and Recordings are the ID prefixes reaped for each resource type.
+	// An empty list disables reaping of that type. Recordings are stored recordings, deleted
+	// by name; live recordings stop with their channel or bridge.
+	Channels   []string
+	Bridges    []string
+	Recordings []string
+	// InUse, if set, protects the resources it reports true for.
+	InUse func(kind string, id string) bool
+}
+
+// ReapReport lists the resources torn down by Reap.
+type ReapReport struct {
+	Channels   []string
+	Bridges    []string
+	Recordings []string
+	// Errors maps the IDs that could not be torn down to the reason.
+	Errors map[string]error
+}
+
+// NewReaper creates a reaper for channels, bridges and recordings whose ID starts with one of prefixes.
+func NewReaper(client *APIClient, prefixes ...string) *Reaper {
+	return &Reaper{client: client, Channels: prefixes, Bridges: prefixes, Recordings: prefixes}
+}
+
+// Reap tears down the orphaned resources. Bridges are destroyed after channels so that their
+// members are gone first. An error is returned only if a listing fails.
+func (r *Reaper) Reap(ctx context.Context) (ReapReport, error) {
+	report := ReapReport{Errors: make(map[string]error)}
+
+	if len(r.Channels) > 0 {
+		channels, _, err := r.client.ChannelsApi.Listchannels(ctx)
+		if err != nil {
+			return report, err
+		}
+		for _, channel := range channels {
+			if !r.orphaned("channel", channel.Id, r.Channels) {
+				continue
+			}
+			if _, err := r.client.ChannelsApi.Hangup(ctx, channel.Id, nil); err != nil {
+				report.Errors[channel.Id] = err
+				continue
+			}
+			report.Channels = append(report.Channels, channel.Id)
+		}
+	}
+
+	if len(r.Bridges) > 0 {
+		bridges, _, err := r.client.BridgesApi.Listbridges(ctx)
+		if err != nil {
+			return report, err
+		}
+		for _, bridge := range bridges {
+			if !r.orphaned("bridge", bridge.Id, r.Bridges) {
+				continue
+			}
+			if _, err := r.client.BridgesApi.Destroy(ctx, bridge.Id); err != nil {
+				report.Errors[bridge.Id] = err
+				continue
+			}
+			report.Bridges = append(report.Bridges, bridge.Id)
+		}
+	}
+
+	if len(r.Recordings) > 0 {
+		recordings, _, err := r.client.RecordingsApi.ListStored(ctx)
+		if err != nil {
+			return report, err
+		}
+		for _, recording := range recordings {
+			if !r.orphaned("recording", recording.Name, r.Recordings) {
+				continue
+			}
+			if _, err := r.client.RecordingsApi.DeleteStored(ctx, recording.Name); err != nil {
+				report.Errors[recording.Name] = err
+				continue
+			}
+			report.Recordings = append(report.Recordings, recording.Name)
+		}
+	}
+
+	for id, err := range report.Errors {
+		r.client.logger.Warnf("reaper: tearing down %s: %v", id, err)
+	}
+	r.client.logger.Infof("reaper: reaped %d channels, %d bridges, %d recordings",
+		len(report.Channels), len(report.Bridges), len(report.Recordings))
+	return report, nil
+}
+
+// orphaned reports whether id matches one of prefixes and is not in use.
+func (r *Reaper) orphaned(kind string, id string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(id, prefix) {
+			return r.InUse == nil || !r.InUse(kind, id)
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestReaper(t *testing.T) {
+	var teardowns []string
+	node := clusterNode(t, "a", nil, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			switch r.URL.Path {
+			case "/ari/channels":
+				w.Write([]byte(`[{"id":"app1-c1"},{"id":"app1-c2"},{"id":"other-c3"},{"id":"app1-c4"}]`))
+			case "/ari/bridges":
+				w.Write([]byte(`[{"id":"app1-b1"},{"id":"other-b2"}]`))
+			case "/ari/recordings/stored":
+				w.Write([]byte(`[{"name":"app1-r1"},{"name":"other-r2"}]`))
+			}
+			return
+		}
+		teardowns = append(teardowns, r.Method+" "+r.URL.Path)
+		if r.URL.Path == "/ari/channels/app1-c4" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"message":"busy"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	reaper := NewReaper(node.Client, "app1-")
+	reaper.InUse = func(kind string, id string) bool { return kind == "channel" && id == "app1-c2" }
+
+	report, err := reaper.Reap(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(report.Channels, []string{"app1-c1"}) ||
+		!reflect.DeepEqual(report.Bridges, []string{"app1-b1"}) ||
+		!reflect.DeepEqual(report.Recordings, []string{"app1-r1"}) {
+		t.Errorf("report = %+v, want app1-c1, app1-b1 and app1-r1", report)
+	}
+	if len(report.Errors) != 1 || report.Errors["app1-c4"] == nil {
+		t.Errorf("errors = %v, want app1-c4", report.Errors)
+	}
+	want := []string{
+		"DELETE /ari/channels/app1-c1",
+		"DELETE /ari/channels/app1-c4",
+		"DELETE /ari/bridges/app1-b1",
+		"DELETE /ari/recordings/stored/app1-r1",
+	}
+	if !reflect.DeepEqual(teardowns, want) {
+		t.Errorf("teardowns = %q, want %q", teardowns, want)
+	}
+}
@@ -0,0 +1,230 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultRecordingFormat is the format of recordings made by RecordingManager when none is set.
+const DefaultRecordingFormat = "wav"
+
+// recordingByteRates estimates the bytes written per second of audio by format, to rotate on size.
+var recordingByteRates = map[string]int64{
+	"wav":   16000, // 8 kHz signed linear
+	"wav16": 32000,
+	"sln":   16000,
+	"ulaw":  8000,
+	"alaw":  8000,
+	"gsm":   1650,
+	"g722":  8000,
+}
+
+// RotateOpts splits a recording into chunks. The next chunk is started before the previous one is
+// stopped, so no audio is lost between chunks.
+type RotateOpts struct {
+	// Every starts a new chunk after this duration.
+	Every time.Duration `json:"every,omitempty" yaml:"every,omitempty"`
+	// MaxBytes starts a new chunk once the current one is estimated to reach this size from the
+	// byte rate of the format. Formats of unknown rate ignore it.
+	MaxBytes int64 `json:"max_bytes,omitempty" yaml:"max_bytes,omitempty"`
+}
+
+// interval returns the chunk duration implied by the options, 0 for no rotation.
+func (o *RotateOpts) interval(format string) time.Duration {
+	if o == nil {
+		return 0
+	}
+	d := o.Every
+	if rate := recordingByteRates[format]; o.MaxBytes > 0 && rate > 0 {
+		if bySize := time.Duration(o.MaxBytes/rate) * time.Second; d == 0 || bySize < d {
+			d = bySize
+		}
+	}
+	return d
+}
+
+// RecordingChunk is one stored recording of a rotated recording.
+type RecordingChunk struct {
+	Seq   int       `json:"seq"`
+	Name  string    `json:"name"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end,omitempty"`
+}
+
+// RecordingManifest lists the chunks of a bridge recording in order, to reassemble or process them.
+type RecordingManifest struct {
+	BridgeId string           `json:"bridge_id"`
+	Name     string           `json:"name"`
+	Format   string           `json:"format"`
+	Chunks   []RecordingChunk `json:"chunks"`
+}
+
+// JSON returns the manifest as indented JSON.
+func (m RecordingManifest) JSON() ([]byte, error) {
+	return json.MarshalIndent(m, "", "  ")
+}
+
+// RecordingManager records bridges, optionally rotating the recording into sequential chunks so
+// that multi-hour conferences do not produce a single unmanageable file.
+type RecordingManager struct {
+	client *APIClient
+
+	// Format of the recordings, DefaultRecordingFormat if empty.
+	Format string
+	// IfExists is the policy for a chunk name already taken. Defaults to IfExistsFail, so that
+	// a recording never clobbers another one.
+	IfExists IfExists
+	// OnChunk is called with every chunk once it is stopped, e.g. to upload it.
+	OnChunk func(m RecordingManifest, chunk RecordingChunk)
+}
+
+// NewRecordingManager creates a manager of recordings.
+func NewRecordingManager(client *APIClient) *RecordingManager {
+	return &RecordingManager{client: client, Format: DefaultRecordingFormat, IfExists: IfExistsFail}
+}
+
+func (m *RecordingManager) format() string {
+	if m.Format == "" {
+		return DefaultRecordingFormat
+	}
+	return m.Format
+}
+
+// BridgeRecording is a recording of a bridge in progress.
+type BridgeRecording struct {
+	m        *RecordingManager
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+	mu       sync.Mutex
+	record   RecordingManifest
+	err      error
+}
+
+// RecordBridge starts recording bridgeId. Chunks are named "<name>-0001", "<name>-0002", ...;
+// without rotation the single chunk is "<name>-0001" too, so that consumers handle one layout.
+// The name is namespaced with the namespace of ctx, see WithRecordingNamespace.
+func (m *RecordingManager) RecordBridge(ctx context.Context, bridgeId string, name string, rotate *RotateOpts) (*BridgeRecording, error) {
+	if !m.IfExists.Valid() {
+		return nil, fmt.Errorf("invalid IfExists policy %q", m.IfExists)
+	}
+	name = recordingName(ctx, name)
+	r := &BridgeRecording{
+		m:      m,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+		record: RecordingManifest{BridgeId: bridgeId, Name: name, Format: m.format()},
+	}
+	if err := r.startChunk(ctx); err != nil {
+		return nil, err
+	}
+	interval := rotate.interval(m.format())
+	m.client.goTracked("recording_manager", func() {
+		defer close(r.done)
+		if interval <= 0 {
+			<-r.stop
+			return
+		}
+		timer := m.client.clock().NewTimer(interval)
+		defer timer.Stop()
+		for {
+			select {
+			case <-timer.C():
+				if err := r.rotate(); err != nil {
+					m.client.logger.Warnf("recording manager: rotating recording of bridge %s: %v", bridgeId, err)
+					r.mu.Lock()
+					r.err = err
+					r.mu.Unlock()
+					return
+				}
+				timer.Reset(interval)
+			case <-r.stop:
+				return
+			}
+		}
+	})
+	return r, nil
+}
+
+// startChunk starts the next chunk.
+func (r *BridgeRecording) startChunk(ctx context.Context) error {
+	r.mu.Lock()
+	chunk := RecordingChunk{Seq: len(r.record.Chunks) + 1}
+	chunk.Name = fmt.Sprintf("%s-%04d", r.record.Name, chunk.Seq)
+	bridgeId, format := r.record.BridgeId, r.record.Format
+	r.mu.Unlock()
+
+	_, _, err := r.m.client.BridgesApi.Record(ctx, bridgeId, chunk.Name, format, &BridgesApiRecordOpts{IfExists: r.m.IfExists.option()})
+	if err != nil {
+		return fmt.Errorf("starting recording %s: %w", chunk.Name, err)
+	}
+	chunk.Start = r.m.client.clock().Now()
+	r.mu.Lock()
+	r.record.Chunks = append(r.record.Chunks, chunk)
+	r.mu.Unlock()
+	return nil
+}
+
+// stopChunk stops chunk seq and reports it to OnChunk.
+func (r *BridgeRecording) stopChunk(ctx context.Context, seq int) error {
+	r.mu.Lock()
+	name := r.record.Chunks[seq-1].Name
+	r.mu.Unlock()
+	_, err := r.m.client.RecordingsApi.Stoprecording(ctx, name)
+	r.mu.Lock()
+	r.record.Chunks[seq-1].End = r.m.client.clock().Now()
+	chunk, manifest := r.record.Chunks[seq-1], r.manifestLocked()
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("stopping recording %s: %w", name, err)
+	}
+	if r.m.OnChunk != nil {
+		r.m.OnChunk(manifest, chunk)
+	}
+	return nil
+}
+
+// rotate starts the next chunk, then stops the previous one.
+func (r *BridgeRecording) rotate() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	r.mu.Lock()
+	previous := len(r.record.Chunks)
+	r.mu.Unlock()
+	if err := r.startChunk(ctx); err != nil {
+		return err
+	}
+	return r.stopChunk(ctx, previous)
+}
+
+// Stop ends the recording and returns its manifest. It fails if the last chunk cannot be stopped,
+// e.g. because the bridge was destroyed, which also ends the recording.
+func (r *BridgeRecording) Stop(ctx context.Context) (RecordingManifest, error) {
+	r.stopOnce.Do(func() { close(r.stop) })
+	<-r.done
+	r.mu.Lock()
+	last := len(r.record.Chunks)
+	rotateErr := r.err
+	r.mu.Unlock()
+	err := r.stopChunk(ctx, last)
+	if rotateErr != nil {
+		err = rotateErr
+	}
+	return r.Manifest(), err
+}
+
+// Manifest returns the chunks recorded so far.
+func (r *BridgeRecording) Manifest() RecordingManifest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.manifestLocked()
+}
+
+func (r *BridgeRecording) manifestLocked() RecordingManifest {
+	m := r.record
+	m.Chunks = append([]RecordingChunk(nil), r.record.Chunks...)
+	return m
+}
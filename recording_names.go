@@ -0,0 +1,181 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/antihax/optional"
+)
+
+// IfExists is what Asterisk does when a recording is started with the name of an existing one.
+type IfExists string
+
+const (
+	// IfExistsDefault leaves the choice to Asterisk, which fails.
+	IfExistsDefault IfExists = ""
+	// IfExistsFail fails the recording with 409 Conflict.
+	IfExistsFail IfExists = "fail"
+	// IfExistsOverwrite replaces the existing recording.
+	IfExistsOverwrite IfExists = "overwrite"
+	// IfExistsAppend appends the audio to the existing recording.
+	IfExistsAppend IfExists = "append"
+)
+
+// Valid reports whether p is a policy known to Asterisk.
+func (p IfExists) Valid() bool {
+	switch p {
+	case IfExistsDefault, IfExistsFail, IfExistsOverwrite, IfExistsAppend:
+		return true
+	}
+	return false
+}
+
+func (p IfExists) option() optional.String {
+	if p == IfExistsDefault {
+		return optional.EmptyString()
+	}
+	return optional.NewString(string(p))
+}
+
+// RecordingNamespace scopes recording names to an application, a tenant and a call, so that the
+// recordings of different tenants or calls never collide and can be listed by prefix. Names are
+// prefixed with "<app>~<tenant>~<call>~"; empty parts are left out. The separator is not "/" since
+// the REST API takes recording names as a single path segment.
+type RecordingNamespace struct {
+	App    string
+	Tenant string
+	CallId string
+}
+
+// namespaceSeparator separates the parts of a namespaced recording name.
+const namespaceSeparator = "~"
+
+// namespacePart makes s usable as a part of a namespaced name, without "/" nor the separator.
+func namespacePart(s string) string {
+	return strings.NewReplacer("/", "-", namespaceSeparator, "-").Replace(s)
+}
+
+// Prefix returns the prefix of the namespace, ending with the separator, or "" for an empty
+// namespace.
+func (ns RecordingNamespace) Prefix() string {
+	var b strings.Builder
+	for _, part := range []string{ns.App, ns.Tenant, ns.CallId} {
+		if part != "" {
+			b.WriteString(namespacePart(part))
+			b.WriteString(namespaceSeparator)
+		}
+	}
+	return b.String()
+}
+
+// Name returns the namespaced name of the recording name. A name already in the namespace is
+// returned as is.
+func (ns RecordingNamespace) Name(name string) string {
+	prefix := ns.Prefix()
+	if strings.HasPrefix(name, prefix) {
+		return name
+	}
+	return prefix + name
+}
+
+// Contains reports whether the stored recording name belongs to the namespace.
+func (ns RecordingNamespace) Contains(name string) bool {
+	return strings.HasPrefix(name, ns.Prefix())
+}
+
+type recordingNamespaceKey struct{}
+
+// WithRecordingNamespace returns a context namespacing the recordings started with it by the
+// record helpers: RecordNamed of ChannelsApi and BridgesApi, and RecordingManager.
+func WithRecordingNamespace(ctx context.Context, ns RecordingNamespace) context.Context {
+	return context.WithValue(ctx, recordingNamespaceKey{}, ns)
+}
+
+// RecordingNamespaceFrom returns the namespace set on ctx with WithRecordingNamespace.
+func RecordingNamespaceFrom(ctx context.Context) (RecordingNamespace, bool) {
+	ns, ok := ctx.Value(recordingNamespaceKey{}).(RecordingNamespace)
+	return ns, ok
+}
+
+// recordingName namespaces name with the namespace of ctx, if any.
+func recordingName(ctx context.Context, name string) string {
+	if ns, ok := RecordingNamespaceFrom(ctx); ok {
+		return ns.Name(name)
+	}
+	return name
+}
+
+// RecordOpts are the typed options of a recording.
+type RecordOpts struct {
+	// IfExists is the policy for a name already taken.
+	IfExists IfExists
+	// MaxDuration and MaxSilence end the recording; 0 means no limit. They are rounded down to
+	// the second.
+	MaxDuration time.Duration
+	MaxSilence  time.Duration
+	// Beep plays a beep before recording.
+	Beep bool
+	// TerminateOn is the DTMF key ending the recording: "none", "any", "*" or "#".
+	TerminateOn string
+}
+
+func (o *RecordOpts) validate() error {
+	if o != nil && !o.IfExists.Valid() {
+		return fmt.Errorf("invalid IfExists policy %q", o.IfExists)
+	}
+	return nil
+}
+
+func (o *RecordOpts) channelOpts() *ChannelsApiRecordchannelOpts {
+	opts := &ChannelsApiRecordchannelOpts{}
+	if o == nil {
+		return opts
+	}
+	opts.IfExists = o.IfExists.option()
+	if o.MaxDuration > 0 {
+		opts.MaxDurationSeconds = optional.NewInt32(int32(o.MaxDuration / time.Second))
+	}
+	if o.MaxSilence > 0 {
+		opts.MaxSilenceSeconds = optional.NewInt32(int32(o.MaxSilence / time.Second))
+	}
+	if o.Beep {
+		opts.Beep = optional.NewBool(true)
+	}
+	if o.TerminateOn != "" {
+		opts.TerminateOn = optional.NewString(o.TerminateOn)
+	}
+	return opts
+}
+
+func (o *RecordOpts) bridgeOpts() *BridgesApiRecordOpts {
+	c := o.channelOpts()
+	return &BridgesApiRecordOpts{
+		MaxDurationSeconds: c.MaxDurationSeconds,
+		MaxSilenceSeconds:  c.MaxSilenceSeconds,
+		IfExists:           c.IfExists,
+		Beep:               c.Beep,
+		TerminateOn:        c.TerminateOn,
+	}
+}
+
+// RecordNamed starts recording channelId under name, namespaced with the namespace of ctx, see
+// WithRecordingNamespace. The returned recording carries the namespaced name.
+func (a *ChannelsApiService) RecordNamed(ctx context.Context, channelId string, name string, format string, opts *RecordOpts) (LiveRecording, error) {
+	if err := opts.validate(); err != nil {
+		return LiveRecording{}, err
+	}
+	recording, _, err := a.Recordchannel(ctx, channelId, recordingName(ctx, name), format, opts.channelOpts())
+	return recording, err
+}
+
+// RecordNamed starts recording bridgeId under name, namespaced with the namespace of ctx, see
+// WithRecordingNamespace. The returned recording carries the namespaced name.
+func (a *BridgesApiService) RecordNamed(ctx context.Context, bridgeId string, name string, format string, opts *RecordOpts) (LiveRecording, error) {
+	if err := opts.validate(); err != nil {
+		return LiveRecording{}, err
+	}
+	recording, _, err := a.Record(ctx, bridgeId, recordingName(ctx, name), format, opts.bridgeOpts())
+	return recording, err
+}
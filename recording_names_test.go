@@ -0,0 +1,50 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// TestRouteTenantRecordingNames covers the recordings of a tenant-routed call: their namespaced
+// name must address them on the REST API, whose routes take the name as one path segment.
+func TestRouteTenantRecordingNames(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(LiveRecording{Name: r.URL.Query().Get("name")})
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	cfg := NewConfiguration("/")
+	cfg.Host, cfg.Scheme = u.Host, u.Scheme
+	client := NewAPIClient(cfg, NewStdLogger(ioutil.Discard))
+
+	var name string
+	handler := RouteTenant(client, func(ctx context.Context, ev StasisStartEvent) (string, error) {
+		return "acme/eu", nil
+	})(func(ctx context.Context, ev StasisStartEvent) {
+		recording, err := client.ChannelsApi.RecordNamed(ctx, ev.Channel.Id, "greeting", "wav", nil)
+		if err != nil {
+			t.Fatalf("record: %v", err)
+		}
+		name = recording.Name
+		if _, err := client.RecordingsApi.Stoprecording(ctx, recording.Name); err != nil {
+			t.Fatalf("stop: %v", err)
+		}
+	})
+	handler(context.Background(), StasisStartEvent{StasisEvent{Application: "ivr", Channel: Channel{Id: "c1"}}})
+
+	if want := "ivr~acme-eu~c1~greeting"; name != want {
+		t.Errorf("recording name = %q, want %q", name, want)
+	}
+	if want := "/recordings/live/" + name + "/stop"; len(paths) != 2 || !strings.HasSuffix(paths[1], want) {
+		t.Errorf("requests = %q, want the stop at %q", paths, want)
+	}
+}
@@ -0,0 +1,104 @@
+package asterisk_ari_go
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// OpenStoredFile streams the file of a stored recording. Unlike GetStoredFile it does not load the
+// file in memory nor decode it as text. The caller must close the returned body.
+func (a *RecordingsApiService) OpenStoredFile(ctx context.Context, recordingName string) (io.ReadCloser, *http.Response, error) {
+	path := a.client.cfg.BasePath + "/recordings/stored/" + url.PathEscape(recordingName) + "/file"
+	r, err := a.client.prepareRequest(ctx, path, http.MethodGet, nil, map[string]string{}, url.Values{}, url.Values{}, "", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := a.client.callAPI(r)
+	if err != nil || resp == nil {
+		return nil, resp, err
+	}
+	if resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, resp, GenericSwaggerError{body: body, error: resp.Status}
+	}
+	return resp.Body, resp, nil
+}
+
+// UploadOpts configures UploadStage.
+type UploadOpts struct {
+	// Store returns the bucket of the call, e.g. by tenant from the data of the call.
+	Store func(job *PostCallJob) (ObjectStore, error)
+	// Key returns the object key of a recording, "<channel id>/<recording>.<format>" if nil.
+	Key func(job *PostCallJob, recording StoredRecording) string
+	// DeleteAfterUpload deletes the stored recording from Asterisk once uploaded.
+	DeleteAfterUpload bool
+}
+
+// UploadStage returns a stage streaming the recordings of the call to an object store. The URL
+// of every upload is recorded in the job under "upload:<recording>".
+func UploadStage(client *APIClient, opts UploadOpts) PostCallStage {
+	return RecordingStage("upload", func(ctx context.Context, job *PostCallJob, recording string) error {
+		key := "upload:" + recording
+		if _, done := job.Get(key); done {
+			// Uploaded by a previous attempt, which failed on a later recording.
+			return nil
+		}
+		store, err := opts.Store(job)
+		if err != nil {
+			return err
+		}
+		stored, _, err := client.RecordingsApi.GetStored(ctx, recording)
+		if err != nil {
+			return err
+		}
+		objectKey := defaultUploadKey(job, stored)
+		if opts.Key != nil {
+			objectKey = opts.Key(job, stored)
+		}
+
+		body, resp, err := client.RecordingsApi.OpenStoredFile(ctx, recording)
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+		var reader io.Reader = body
+		size := resp.ContentLength
+		if size < 0 {
+			// Object stores need the length up front.
+			data, err := ioutil.ReadAll(body)
+			if err != nil {
+				return err
+			}
+			reader, size = bytes.NewReader(data), int64(len(data))
+		}
+		contentType := resp.Header.Get("Content-Type")
+		if contentType == "" {
+			contentType = mime.TypeByExtension("." + stored.Format)
+		}
+		location, err := store.Put(ctx, objectKey, reader, size, contentType)
+		if err != nil {
+			return err
+		}
+		job.Set(key, location)
+
+		if opts.DeleteAfterUpload {
+			if _, err := client.RecordingsApi.DeleteStored(ctx, recording); err != nil {
+				client.logger.Warnf("post-call: deleting uploaded recording %s: %v", recording, err)
+			}
+		}
+		return nil
+	})
+}
+
+// defaultUploadKey returns the default object key of a recording of a call.
+func defaultUploadKey(job *PostCallJob, r StoredRecording) string {
+	return fmt.Sprintf("%s/%s.%s", job.Record.ChannelId, strings.Replace(r.Name, "/", "_", -1), r.Format)
+}
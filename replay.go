@@ -0,0 +1,200 @@
+package asterisk_ari_go
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// LoadReplay reads recorded events, either as a JSON array, e.g. DiagnosticBundle.Events, or as a
+// stream of JSON objects such as one event per line.
+func LoadReplay(r io.Reader) ([]StasisEvent, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(1)
+	for err == nil && len(bytes.TrimSpace(head)) == 0 {
+		br.ReadByte()
+		head, err = br.Peek(1)
+	}
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(br)
+	if head[0] == '[' {
+		var events []StasisEvent
+		if err := dec.Decode(&events); err != nil {
+			return nil, err
+		}
+		return events, nil
+	}
+	var events []StasisEvent
+	for {
+		var ev StasisEvent
+		if err := dec.Decode(&ev); err == io.EOF {
+			return events, nil
+		} else if err != nil {
+			return events, err
+		}
+		events = append(events, ev)
+	}
+}
+
+// Replayer feeds recorded events to a handler, e.g. to reproduce a production incident against
+// handler logic in a test. Events are spaced by the difference of their timestamps divided by the
+// speed; events without a timestamp are delivered immediately.
+type Replayer struct {
+	events []StasisEvent
+
+	mu           sync.Mutex
+	speed        float64
+	speedChanged chan struct{}
+}
+
+// NewReplayer creates a replayer of events at real-time speed.
+func NewReplayer(events []StasisEvent) *Replayer {
+	return &Replayer{events: events, speed: 1, speedChanged: make(chan struct{}, 1)}
+}
+
+// SetSpeed changes the speed of the replay, also while it is running: 2 replays twice as fast as
+// recorded, 0 replays without delays.
+func (r *Replayer) SetSpeed(speed float64) {
+	r.mu.Lock()
+	r.speed = speed
+	r.mu.Unlock()
+	select {
+	case r.speedChanged <- struct{}{}:
+	default:
+	}
+}
+
+// Speed returns the speed of the replay.
+func (r *Replayer) Speed() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.speed
+}
+
+// Run feeds the events to handler in order until they are exhausted or ctx is done.
+func (r *Replayer) Run(ctx context.Context, handler func(StasisEvent)) error {
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	for i, ev := range r.events {
+		if i > 0 {
+			if err := r.pause(ctx, &timer, ev.Timestamp.Timestamp.Sub(r.events[i-1].Timestamp.Timestamp)); err != nil {
+				return err
+			}
+		} else if err := ctx.Err(); err != nil {
+			return err
+		}
+		handler(ev)
+	}
+	return nil
+}
+
+// pause waits for the recorded gap between two events at the current speed, starting over when
+// the speed changes.
+func (r *Replayer) pause(ctx context.Context, timer **time.Timer, gap time.Duration) error {
+	start := time.Now()
+	for {
+		speed := r.Speed()
+		if gap <= 0 || speed <= 0 {
+			return ctx.Err()
+		}
+		remaining := time.Duration(float64(gap)/speed) - time.Since(start)
+		if remaining <= 0 {
+			return ctx.Err()
+		}
+		if *timer == nil {
+			*timer = time.NewTimer(remaining)
+		} else {
+			resetTimer(*timer, remaining)
+		}
+		select {
+		case <-(*timer).C:
+			return nil
+		case <-r.speedChanged:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// TestingT is the subset of testing.TB used by the assertion helpers.
+type TestingT interface {
+	Helper()
+	Fatalf(format string, args ...interface{})
+}
+
+// DefaultExpectTimeout is how long ExpectNext waits for an event when EventProbe.Timeout is 0.
+const DefaultExpectTimeout = time.Second
+
+// EventProbe records the events it is fed for assertions in tests, typically the events replayed
+// to the handler under test, or the events the handler emits.
+type EventProbe struct {
+	// Timeout of ExpectNext, DefaultExpectTimeout if 0.
+	Timeout time.Duration
+
+	events chan StasisEvent
+}
+
+// NewEventProbe creates a probe holding up to 1024 unread events; HandleEvent blocks beyond that.
+func NewEventProbe() *EventProbe {
+	return &EventProbe{events: make(chan StasisEvent, 1024)}
+}
+
+// HandleEvent records an event.
+func (p *EventProbe) HandleEvent(ev StasisEvent) {
+	p.events <- ev
+}
+
+// ExpectNext consumes the next event recorded by p and fails the test unless it has type T.
+func ExpectNext[T TypedEvent](t TestingT, p *EventProbe) T {
+	t.Helper()
+	timeout := p.Timeout
+	if timeout == 0 {
+		timeout = DefaultExpectTimeout
+	}
+	var zero T
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case ev := <-p.events:
+		if want := zero.EventType(); want != "" && ev.Type != want {
+			t.Fatalf("expected %s event, got %s", want, ev.Type)
+			return zero
+		}
+		return zero.fromStasis(ev).(T)
+	case <-timer.C:
+		t.Fatalf("expected %T event, got none within %s", zero, timeout)
+		return zero
+	}
+}
+
+// ExpectWithin consumes events recorded by p until one matches, failing the test if none does
+// within d. MatchEvent builds common predicates.
+func (p *EventProbe) ExpectWithin(t TestingT, d time.Duration, match func(StasisEvent) bool) StasisEvent {
+	t.Helper()
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	for {
+		select {
+		case ev := <-p.events:
+			if match(ev) {
+				return ev
+			}
+		case <-timer.C:
+			t.Fatalf("expected matching event within %s, got none", d)
+			return StasisEvent{}
+		}
+	}
+}
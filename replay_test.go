@@ -0,0 +1,140 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+const replayIncident = `
+{"type":"StasisStart","timestamp":"2021-03-01T10:00:00.000+0000","channel":{"id":"c1","state":"Up"}}
+{"type":"ChannelDtmfReceived","timestamp":"2021-03-01T10:00:00.100+0000","channel":{"id":"c1"},"digit":"5","duration_ms":120}
+{"type":"ChannelDestroyed","timestamp":"2021-03-01T10:00:00.200+0000","channel":{"id":"c1"},"cause":16}
+`
+
+func loadIncident(t *testing.T) []StasisEvent {
+	t.Helper()
+	events, err := LoadReplay(strings.NewReader(replayIncident))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("loaded %d events, want 3", len(events))
+	}
+	return events
+}
+
+func TestLoadReplayArray(t *testing.T) {
+	events, err := LoadReplay(strings.NewReader(`  [{"type":"StasisStart"},{"type":"StasisEnd"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[1].Type != "StasisEnd" {
+		t.Fatalf("events = %+v", events)
+	}
+}
+
+func TestReplayerSpeed(t *testing.T) {
+	events := loadIncident(t)
+	for _, tc := range []struct {
+		speed    float64
+		min, max time.Duration
+	}{
+		{speed: 1, min: 180 * time.Millisecond, max: time.Second},
+		{speed: 0, min: 0, max: 50 * time.Millisecond},
+	} {
+		t.Run(fmt.Sprint(tc.speed), func(t *testing.T) {
+			r := NewReplayer(events)
+			r.SetSpeed(tc.speed)
+			start := time.Now()
+			n := 0
+			if err := r.Run(context.Background(), func(StasisEvent) { n++ }); err != nil {
+				t.Fatal(err)
+			}
+			if elapsed := time.Since(start); elapsed < tc.min || elapsed > tc.max {
+				t.Errorf("replay took %s, want between %s and %s", elapsed, tc.min, tc.max)
+			}
+			if n != len(events) {
+				t.Errorf("replayed %d events, want %d", n, len(events))
+			}
+		})
+	}
+}
+
+func TestReplayerCancel(t *testing.T) {
+	r := NewReplayer(loadIncident(t))
+	ctx, cancel := context.WithCancel(context.Background())
+	err := r.Run(ctx, func(ev StasisEvent) {
+		if ev.Type == "StasisStart" {
+			cancel()
+		}
+	})
+	if err != context.Canceled {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestExpectNext(t *testing.T) {
+	p := NewEventProbe()
+	r := NewReplayer(loadIncident(t))
+	r.SetSpeed(0)
+	if err := r.Run(context.Background(), p.HandleEvent); err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectNext[StasisStartEvent](t, p)
+	dtmf := ExpectNext[ChannelDtmfReceivedEvent](t, p)
+	if dtmf.Digit != "5" || dtmf.Channel.Id != "c1" {
+		t.Errorf("dtmf = %+v", dtmf.StasisEvent)
+	}
+	ExpectNext[StasisEvent](t, p)
+}
+
+// fakeT records the failures of assertions expected to fail.
+type fakeT struct {
+	failed string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Fatalf(format string, args ...interface{}) {
+	f.failed = fmt.Sprintf(format, args...)
+}
+
+func TestExpectNextFailures(t *testing.T) {
+	p := NewEventProbe()
+	p.Timeout = 10 * time.Millisecond
+
+	ft := &fakeT{}
+	ExpectNext[StasisStartEvent](ft, p)
+	if !strings.Contains(ft.failed, "got none") {
+		t.Errorf("empty probe: failure = %q", ft.failed)
+	}
+
+	p.HandleEvent(StasisEvent{Type: "StasisEnd"})
+	ft = &fakeT{}
+	ExpectNext[StasisStartEvent](ft, p)
+	if ft.failed != "expected StasisStart event, got StasisEnd" {
+		t.Errorf("wrong type: failure = %q", ft.failed)
+	}
+}
+
+func TestExpectWithin(t *testing.T) {
+	p := NewEventProbe()
+	r := NewReplayer(loadIncident(t))
+	r.SetSpeed(10)
+	go r.Run(context.Background(), p.HandleEvent)
+
+	ev := p.ExpectWithin(t, time.Second, MatchEvent().Type("ChannelDestroyed").Channel("c1").Match)
+	if ev.Cause != 16 {
+		t.Errorf("cause = %d, want 16", ev.Cause)
+	}
+
+	ft := &fakeT{}
+	p.ExpectWithin(ft, 10*time.Millisecond, MatchEvent().Type("StasisEnd").Match)
+	if ft.failed == "" {
+		t.Error("ExpectWithin did not fail without a matching event")
+	}
+}
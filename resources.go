@@ -0,0 +1,145 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResourceKind classifies the resources accounted by the client.
+type ResourceKind string
+
+const (
+	// ResourceGoroutine is a goroutine started by the client or one of its components.
+	ResourceGoroutine ResourceKind = "goroutine"
+	// ResourceChannel is a channel tracked in memory, e.g. by a CallLimiter.
+	ResourceChannel ResourceKind = "channel"
+	// ResourceSubscription is a registered event waiter or handler.
+	ResourceSubscription ResourceKind = "subscription"
+	// ResourceBuffer is a buffer held for reuse or in flight.
+	ResourceBuffer ResourceKind = "buffer"
+)
+
+// ResourceStats is a snapshot of the resources held by a client.
+type ResourceStats struct {
+	Goroutines    int64
+	Channels      int64
+	Subscriptions int64
+	Buffers       int64
+	// ByOwner breaks the counts down by "kind/owner", e.g. "subscription/trunk_router".
+	ByOwner map[string]int64
+	// ProcessGoroutines is runtime.NumGoroutine at the time of the snapshot, for reference.
+	ProcessGoroutines int
+}
+
+// ResourceLeakError lists the resources still held above a baseline.
+type ResourceLeakError struct {
+	// Leaked maps "kind/owner" to the number of resources above the baseline.
+	Leaked map[string]int64
+}
+
+func (e *ResourceLeakError) Error() string {
+	keys := make([]string, 0, len(e.Leaked))
+	for k := range e.Leaked {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=+%d", k, e.Leaked[k])
+	}
+	return "resource leak: " + strings.Join(parts, ", ")
+}
+
+// Leaks returns the resources held in s above baseline, or nil if there are none.
+func (s ResourceStats) Leaks(baseline ResourceStats) map[string]int64 {
+	var leaked map[string]int64
+	for k, n := range s.ByOwner {
+		if d := n - baseline.ByOwner[k]; d > 0 {
+			if leaked == nil {
+				leaked = make(map[string]int64)
+			}
+			leaked[k] = d
+		}
+	}
+	return leaked
+}
+
+// resourceAccounting counts the resources held per kind and owner.
+type resourceAccounting struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func (r *resourceAccounting) add(kind ResourceKind, owner string, delta int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.counts == nil {
+		r.counts = make(map[string]int64)
+	}
+	key := string(kind) + "/" + owner
+	if r.counts[key] += delta; r.counts[key] == 0 {
+		delete(r.counts, key)
+	}
+}
+
+// TrackResource adjusts the number of resources of kind held by owner by delta.
+// Components built on top of the client use it so that their resources show up in Stats.
+func (c *APIClient) TrackResource(kind ResourceKind, owner string, delta int) {
+	c.resources.add(kind, owner, int64(delta))
+}
+
+// goTracked runs f in a goroutine accounted to owner.
+func (c *APIClient) goTracked(owner string, f func()) {
+	c.TrackResource(ResourceGoroutine, owner, 1)
+	go func() {
+		defer c.TrackResource(ResourceGoroutine, owner, -1)
+		f()
+	}()
+}
+
+// Stats returns the resources currently held by the client and its components.
+// Comparing snapshots taken weeks apart on an idle system reveals per-call leaks.
+func (c *APIClient) Stats() ResourceStats {
+	c.resources.mu.Lock()
+	defer c.resources.mu.Unlock()
+
+	stats := ResourceStats{ByOwner: make(map[string]int64, len(c.resources.counts)), ProcessGoroutines: runtime.NumGoroutine()}
+	for key, n := range c.resources.counts {
+		stats.ByOwner[key] = n
+		switch ResourceKind(key[:strings.IndexByte(key, '/')]) {
+		case ResourceGoroutine:
+			stats.Goroutines += n
+		case ResourceChannel:
+			stats.Channels += n
+		case ResourceSubscription:
+			stats.Subscriptions += n
+		case ResourceBuffer:
+			stats.Buffers += n
+		}
+	}
+	return stats
+}
+
+// CheckLeaks waits until the resources held by the client are back to baseline and returns a
+// *ResourceLeakError listing what is still held if that does not happen before ctx is done.
+// It is meant for tests: take a baseline with Stats, run calls, then check for leaks.
+func (c *APIClient) CheckLeaks(ctx context.Context, baseline ResourceStats) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		leaked := c.Stats().Leaks(baseline)
+		if leaked == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return &ResourceLeakError{Leaked: leaked}
+		case <-ticker.C:
+		}
+	}
+}
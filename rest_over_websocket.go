@@ -0,0 +1,181 @@
+package asterisk_ari_go
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// ErrWebsocketTransportClosed is returned for requests pending when the transport is closed.
+var ErrWebsocketTransportClosed = errors.New("websocket REST transport closed")
+
+// restQueryString is a query parameter of a RESTRequest.
+type restQueryString struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// restRequest is the RESTRequest message sent over the websocket.
+type restRequest struct {
+	Type          string            `json:"type"`
+	TransactionId string            `json:"transaction_id"`
+	RequestId     string            `json:"request_id"`
+	Method        string            `json:"method"`
+	Uri           string            `json:"uri"`
+	ContentType   string            `json:"content_type,omitempty"`
+	MessageBody   string            `json:"message_body,omitempty"`
+	QueryStrings  []restQueryString `json:"query_strings,omitempty"`
+}
+
+// restResponse is the RESTResponse message received over the websocket.
+type restResponse struct {
+	Type          string `json:"type"`
+	TransactionId string `json:"transaction_id"`
+	RequestId     string `json:"request_id"`
+	StatusCode    int    `json:"status_code"`
+	ReasonPhrase  string `json:"reason_phrase"`
+	ContentType   string `json:"content_type"`
+	MessageBody   string `json:"message_body"`
+}
+
+// WebsocketRESTTransport executes REST requests as RESTRequest messages over the event websocket
+// (Asterisk 20.13, 21.8, 22.3 and later), for deployments where the HTTP port of Asterisk is not
+// reachable. It is an http.RoundTripper, so every API service works unchanged once the client is
+// configured with its HTTPClient. Responses must be routed back with HandleMessage, which
+// EventReader does when its Transport is set. Requests fail with ErrExperimentalDisabled unless
+// ExperimentalRESTOverWebsocket is enabled.
+type WebsocketRESTTransport struct {
+	client *APIClient
+	conn   *websocket.Conn
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	pending map[string]chan restResponse
+	closed  bool
+}
+
+// NewRESTTransport creates a transport sending requests over conn.
+func (a *WebsocketApiService) NewRESTTransport(conn *websocket.Conn) *WebsocketRESTTransport {
+	return &WebsocketRESTTransport{client: a.client, conn: conn, pending: make(map[string]chan restResponse)}
+}
+
+// HTTPClient returns a client to use as Configuration.HTTPClient.
+func (t *WebsocketRESTTransport) HTTPClient() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// RoundTrip sends req over the websocket and waits for the correlated response.
+func (t *WebsocketRESTTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.client.requireExperimental(ExperimentalRESTOverWebsocket); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	msg := restRequest{
+		Type:          "RESTRequest",
+		TransactionId: newResourceId("tx"),
+		RequestId:     newResourceId("req"),
+		Method:        req.Method,
+		Uri:           strings.TrimPrefix(strings.TrimPrefix(req.URL.Path, t.client.cfg.BasePath), "/"),
+		ContentType:   req.Header.Get("Content-Type"),
+	}
+	for name, values := range req.URL.Query() {
+		for _, v := range values {
+			msg.QueryStrings = append(msg.QueryStrings, restQueryString{Name: name, Value: v})
+		}
+	}
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		msg.MessageBody = string(body)
+	}
+
+	responses := make(chan restResponse, 1)
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil, ErrWebsocketTransportClosed
+	}
+	t.pending[msg.RequestId] = responses
+	t.mu.Unlock()
+	t.client.TrackResource(ResourceSubscription, "websocket_rest", 1)
+	defer func() {
+		t.mu.Lock()
+		delete(t.pending, msg.RequestId)
+		t.mu.Unlock()
+		t.client.TrackResource(ResourceSubscription, "websocket_rest", -1)
+	}()
+
+	t.writeMu.Lock()
+	err := t.conn.WriteJSON(msg)
+	t.writeMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case resp, ok := <-responses:
+		if !ok {
+			return nil, ErrWebsocketTransportClosed
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", resp.StatusCode, resp.ReasonPhrase),
+			StatusCode:    resp.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": []string{resp.ContentType}},
+			Body:          ioutil.NopCloser(bytes.NewBufferString(resp.MessageBody)),
+			ContentLength: int64(len(resp.MessageBody)),
+			Request:       req,
+		}, nil
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+}
+
+// HandleMessage routes a RESTResponse frame to the pending request. It reports whether data was
+// a RESTResponse, in which case it must not be processed as an event.
+func (t *WebsocketRESTTransport) HandleMessage(data []byte) bool {
+	var resp restResponse
+	if err := json.Unmarshal(data, &resp); err != nil || resp.Type != "RESTResponse" {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ch, ok := t.pending[resp.RequestId]
+	if !ok {
+		t.client.logger.Warnf("websocket REST transport: response for unknown request %s", resp.RequestId)
+		return true
+	}
+	select {
+	case ch <- resp:
+	default:
+	}
+	return true
+}
+
+// Close fails all pending requests. It does not close the websocket.
+func (t *WebsocketRESTTransport) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+	t.closed = true
+	for id, ch := range t.pending {
+		close(ch)
+		delete(t.pending, id)
+	}
+}
@@ -0,0 +1,138 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"time"
+
+	"github.com/antihax/optional"
+)
+
+// RouteMiddleware wraps a RouteHandler, e.g. to log, authorize or limit the calls before they
+// reach it. A middleware that rejects a call must hang it up and not call next.
+type RouteMiddleware func(next RouteHandler) RouteHandler
+
+// Use appends middlewares to the router. They wrap every route, NotFound included, the first one
+// being the outermost, and apply to the calls dispatched from then on.
+func (r *Router) Use(middlewares ...RouteMiddleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+// chain wraps handler with the middlewares of the router.
+func (r *Router) chain(handler RouteHandler) RouteHandler {
+	r.mu.RLock()
+	middlewares := r.middlewares
+	r.mu.RUnlock()
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// rejectCall hangs up a call refused by a middleware with reason.
+func rejectCall(ctx context.Context, client *APIClient, ev StasisStartEvent, middleware string, reason string) {
+	match, _ := RouteMatchFrom(ctx)
+	client.metrics().IncCounter("ari_router_rejected_total", map[string]string{"middleware": middleware}, 1)
+	_, err := client.ChannelsApi.Hangup(ctx, ev.Channel.Id, &ChannelsApiHangupOpts{Reason: optional.NewString(reason)})
+	if err != nil && ctx.Err() == nil {
+		client.logger.Warnf("router: %s: hanging up channel %s to %q: %v", middleware, ev.Channel.Id, match.Destination, err)
+	}
+}
+
+// RouteLogging logs every routed call when it enters and when its handler returns.
+func RouteLogging(client *APIClient) RouteMiddleware {
+	return func(next RouteHandler) RouteHandler {
+		return func(ctx context.Context, ev StasisStartEvent) {
+			match, _ := RouteMatchFrom(ctx)
+			start := client.clock().Now()
+			client.logger.Infof("router: channel %s from %q to %q routed to %q", ev.Channel.Id, callerNumber(ev.StasisEvent), match.Destination, match.Pattern)
+			next(ctx, ev)
+			client.logger.Infof("router: channel %s to %q handled in %s", ev.Channel.Id, match.Destination, client.clock().Now().Sub(start))
+		}
+	}
+}
+
+// RouteScreening runs the screens of s, e.g. a BlacklistScreen, before the handler: rejected and
+// diverted calls never reach it.
+func RouteScreening(s *Screener) RouteMiddleware {
+	return func(next RouteHandler) RouteHandler {
+		return func(ctx context.Context, ev StasisStartEvent) {
+			if _, ok := s.Screen(ctx, ev.StasisEvent); ok {
+				next(ctx, ev)
+			}
+		}
+	}
+}
+
+// RouteCallLimit bounds the concurrent calls of every dialed destination with the limits of l
+// under DIDLimitKey; the calls over the limit are hung up with the "congestion" cause. l must be
+// fed the events to release the calls.
+func RouteCallLimit(l *CallLimiter) RouteMiddleware {
+	return func(next RouteHandler) RouteHandler {
+		return func(ctx context.Context, ev StasisStartEvent) {
+			match, _ := RouteMatchFrom(ctx)
+			if err := l.TryAcquire(ev.Channel.Id, DIDLimitKey(match.Destination)); err != nil {
+				l.client.logger.Infof("router: rejecting channel %s: %v", ev.Channel.Id, err)
+				rejectCall(ctx, l.client, ev, "call_limit", "congestion")
+				return
+			}
+			next(ctx, ev)
+		}
+	}
+}
+
+// RouteRateLimit paces the calls of every dialed destination with the rates of p under
+// DIDLimitKey. A call waits up to maxWait for its turn, then is hung up with the "congestion"
+// cause; a maxWait of 0 rejects the calls over the rate at once.
+func RouteRateLimit(p *OriginatePacer, maxWait time.Duration) RouteMiddleware {
+	return func(next RouteHandler) RouteHandler {
+		return func(ctx context.Context, ev StasisStartEvent) {
+			match, _ := RouteMatchFrom(ctx)
+			waitCtx, cancel := context.WithTimeout(ctx, maxWait)
+			err := p.Wait(waitCtx, DIDLimitKey(match.Destination))
+			cancel()
+			if err != nil {
+				if ctx.Err() == nil {
+					p.client.logger.Infof("router: rejecting channel %s: rate of %q exceeded", ev.Channel.Id, match.Destination)
+					rejectCall(ctx, p.client, ev, "rate_limit", "congestion")
+				}
+				return
+			}
+			next(ctx, ev)
+		}
+	}
+}
+
+type tenantKey struct{}
+
+// WithTenant returns a context carrying the tenant of a call.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFrom returns the tenant set on ctx with WithTenant, e.g. by RouteTenant.
+func TenantFrom(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	return tenant, ok
+}
+
+// RouteTenant resolves the tenant of every call with resolve, e.g. from the dialed number, and
+// passes it to the handler on the context, see TenantFrom. The recordings started with the
+// context are namespaced by application, tenant and call, see WithRecordingNamespace. The calls
+// resolve fails for are hung up with the "unallocated" cause.
+func RouteTenant(client *APIClient, resolve func(ctx context.Context, ev StasisStartEvent) (string, error)) RouteMiddleware {
+	return func(next RouteHandler) RouteHandler {
+		return func(ctx context.Context, ev StasisStartEvent) {
+			tenant, err := resolve(ctx, ev)
+			if err != nil {
+				client.logger.Infof("router: rejecting channel %s: resolving tenant: %v", ev.Channel.Id, err)
+				rejectCall(ctx, client, ev, "tenant", "unallocated")
+				return
+			}
+			ctx = WithTenant(ctx, tenant)
+			ctx = WithRecordingNamespace(ctx, RecordingNamespace{App: ev.Application, Tenant: tenant, CallId: ev.Channel.Id})
+			next(ctx, ev)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRouterMiddlewares(t *testing.T) {
+	hangups := make(chan string, 4)
+	client, _ := goneClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			hangups <- r.URL.Path + "?reason=" + r.URL.Query().Get("reason")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	router := NewRouter(client)
+	var mu sync.Mutex
+	var order []string
+	trace := func(name string) RouteMiddleware {
+		return func(next RouteHandler) RouteHandler {
+			return func(ctx context.Context, ev StasisStartEvent) {
+				mu.Lock()
+				order = append(order, name)
+				mu.Unlock()
+				next(ctx, ev)
+			}
+		}
+	}
+	limiter := NewCallLimiter(client, map[string]int{DIDLimitKey("100"): 1})
+	router.Use(trace("outer"), trace("inner"), RouteCallLimit(limiter))
+
+	handled := make(chan string, 4)
+	release := make(chan struct{})
+	router.Handle("100", func(ctx context.Context, ev StasisStartEvent) {
+		handled <- ev.Channel.Id
+		<-release
+	})
+	call := func(id string) {
+		router.HandleEvent(StasisEvent{Type: "StasisStart", Channel: Channel{Id: id, Dialplan: &DialplanCep{Exten: "100"}}})
+	}
+
+	call("c1")
+	select {
+	case id := <-handled:
+		if id != "c1" {
+			t.Fatalf("handled %s, want c1", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("c1 not handled")
+	}
+	// The DID is at its limit: c2 is hung up without reaching the handler.
+	call("c2")
+	select {
+	case hangup := <-hangups:
+		if want := "/ari/channels/c2?reason=congestion"; hangup != want {
+			t.Errorf("hangup %s, want %s", hangup, want)
+		}
+	case id := <-handled:
+		t.Errorf("handled %s over the limit", id)
+	case <-time.After(5 * time.Second):
+		t.Fatal("c2 not hung up")
+	}
+	close(release)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"outer", "inner", "outer", "inner"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("middlewares ran in order %q, want %q", order, want)
+	}
+}
+
+func TestRouteTenantRejects(t *testing.T) {
+	hangups := make(chan string, 1)
+	client, _ := goneClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hangups <- r.Method + " " + r.URL.Query().Get("reason")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	var tenant string
+	handler := RouteTenant(client, func(ctx context.Context, ev StasisStartEvent) (string, error) {
+		if ev.Channel.Id == "unknown" {
+			return "", errors.New("no tenant")
+		}
+		return "acme", nil
+	})(func(ctx context.Context, ev StasisStartEvent) {
+		tenant, _ = TenantFrom(ctx)
+	})
+
+	handler(context.Background(), StasisStartEvent{StasisEvent{Channel: Channel{Id: "c1"}}})
+	if tenant != "acme" {
+		t.Errorf("tenant = %q, want acme", tenant)
+	}
+	tenant = ""
+	handler(context.Background(), StasisStartEvent{StasisEvent{Channel: Channel{Id: "unknown"}}})
+	if tenant != "" {
+		t.Error("handler reached without a tenant")
+	}
+	if got := <-hangups; got != "DELETE unallocated" {
+		t.Errorf("request = %q, want a hangup with the unallocated reason", got)
+	}
+}
@@ -0,0 +1,188 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ScheduleDecision is the state of a schedule at a given time.
+type ScheduleDecision string
+
+const (
+	// ScheduleOpen is within business hours.
+	ScheduleOpen ScheduleDecision = "open"
+	// ScheduleAfterHours is a business day, outside business hours.
+	ScheduleAfterHours ScheduleDecision = "after_hours"
+	// ScheduleClosed is a day without business hours.
+	ScheduleClosed ScheduleDecision = "closed"
+	// ScheduleHoliday is a holiday.
+	ScheduleHoliday ScheduleDecision = "holiday"
+)
+
+// BusinessHours opens a schedule on Days between Open and Close, as "15:04" in the schedule time zone.
+// Close may be before Open for hours spanning midnight.
+type BusinessHours struct {
+	Days  []time.Weekday `json:"days" yaml:"days"`
+	Open  string         `json:"open" yaml:"open"`
+	Close string         `json:"close" yaml:"close"`
+}
+
+// Holiday closes a schedule on Date ("2006-01-02"), or every year on the same day when Recurring.
+type Holiday struct {
+	Name      string `json:"name" yaml:"name"`
+	Date      string `json:"date" yaml:"date"`
+	Recurring bool   `json:"recurring,omitempty" yaml:"recurring,omitempty"`
+}
+
+// Schedule describes when a queue or a number is open.
+type Schedule struct {
+	Name string `json:"name" yaml:"name"`
+	// TimeZone is an IANA name such as "Europe/Paris". Defaults to UTC.
+	TimeZone string          `json:"time_zone,omitempty" yaml:"time_zone,omitempty"`
+	Hours    []BusinessHours `json:"hours" yaml:"hours"`
+	Holidays []Holiday       `json:"holidays,omitempty" yaml:"holidays,omitempty"`
+}
+
+// ScheduleResult is the evaluation of a schedule.
+type ScheduleResult struct {
+	Decision ScheduleDecision
+	// Holiday is the name of the holiday when Decision is ScheduleHoliday.
+	Holiday string
+}
+
+// Evaluate returns the state of the schedule at t.
+func (s Schedule) Evaluate(t time.Time) (ScheduleResult, error) {
+	loc := time.UTC
+	if s.TimeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(s.TimeZone); err != nil {
+			return ScheduleResult{}, fmt.Errorf("schedule %s: %w", s.Name, err)
+		}
+	}
+	t = t.In(loc)
+
+	for _, h := range s.Holidays {
+		date, err := time.ParseInLocation("2006-01-02", h.Date, loc)
+		if err != nil {
+			return ScheduleResult{}, fmt.Errorf("schedule %s: holiday %s: %w", s.Name, h.Name, err)
+		}
+		if date.Month() == t.Month() && date.Day() == t.Day() && (h.Recurring || date.Year() == t.Year()) {
+			return ScheduleResult{Decision: ScheduleHoliday, Holiday: h.Name}, nil
+		}
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	yesterday := (t.Weekday() + 6) % 7
+	businessDay := false
+	for _, h := range s.Hours {
+		open, err := parseClock(h.Open)
+		if err != nil {
+			return ScheduleResult{}, fmt.Errorf("schedule %s: %w", s.Name, err)
+		}
+		close, err := parseClock(h.Close)
+		if err != nil {
+			return ScheduleResult{}, fmt.Errorf("schedule %s: %w", s.Name, err)
+		}
+		for _, day := range h.Days {
+			switch {
+			case day == t.Weekday() && open <= close:
+				businessDay = true
+				if minute >= open && minute < close {
+					return ScheduleResult{Decision: ScheduleOpen}, nil
+				}
+			case day == t.Weekday():
+				// Spans midnight: open from Open until the end of the day.
+				businessDay = true
+				if minute >= open {
+					return ScheduleResult{Decision: ScheduleOpen}, nil
+				}
+			case day == yesterday && open > close && minute < close:
+				return ScheduleResult{Decision: ScheduleOpen}, nil
+			}
+		}
+	}
+	if businessDay {
+		return ScheduleResult{Decision: ScheduleAfterHours}, nil
+	}
+	return ScheduleResult{Decision: ScheduleClosed}, nil
+}
+
+// parseClock parses "15:04" into minutes since midnight. "24:00" is accepted as the end of the day.
+func parseClock(s string) (int, error) {
+	if s == "24:00" {
+		return 24 * 60, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// ScheduleRouter evaluates the schedules of queues or numbers, loaded from configuration with Set
+// or on demand with Load, and plays the standard "we are closed" announcements.
+type ScheduleRouter struct {
+	// Load fetches the schedule of a name not set with Set. Optional.
+	Load func(ctx context.Context, name string) (Schedule, error)
+	// Announcements are played by Announce for each decision other than ScheduleOpen.
+	Announcements map[ScheduleDecision][]string
+
+	mu        sync.RWMutex
+	schedules map[string]Schedule
+}
+
+// NewScheduleRouter creates a router with the given schedules, keyed by their name.
+func NewScheduleRouter(schedules ...Schedule) *ScheduleRouter {
+	r := &ScheduleRouter{
+		schedules: make(map[string]Schedule),
+		Announcements: map[ScheduleDecision][]string{
+			ScheduleAfterHours: {"sound:office-closed"},
+			ScheduleClosed:     {"sound:office-closed"},
+			ScheduleHoliday:    {"sound:office-closed"},
+		},
+	}
+	for _, s := range schedules {
+		r.schedules[s.Name] = s
+	}
+	return r
+}
+
+// Set adds or replaces a schedule.
+func (r *ScheduleRouter) Set(s Schedule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.schedules[s.Name] = s
+}
+
+// Evaluate returns the state of the schedule called name at t.
+func (r *ScheduleRouter) Evaluate(ctx context.Context, name string, t time.Time) (ScheduleResult, error) {
+	r.mu.RLock()
+	s, ok := r.schedules[name]
+	r.mu.RUnlock()
+	if !ok {
+		if r.Load == nil {
+			return ScheduleResult{}, fmt.Errorf("schedule %s not found", name)
+		}
+		var err error
+		if s, err = r.Load(ctx, name); err != nil {
+			return ScheduleResult{}, err
+		}
+	}
+	return s.Evaluate(t)
+}
+
+// Announce evaluates the schedule called name now and, unless it is open, plays the matching
+// announcement on channelId. It returns the decision so the caller can route the call, e.g. to
+// voicemail when closed.
+func (r *ScheduleRouter) Announce(ctx context.Context, w *Waits, channelId string, name string) (ScheduleResult, error) {
+	result, err := r.Evaluate(ctx, name, w.client.clock().Now())
+	if err != nil || result.Decision == ScheduleOpen {
+		return result, err
+	}
+	if media := r.Announcements[result.Decision]; len(media) > 0 {
+		_, err = w.PlayAndWait(ctx, channelId, media, nil)
+	}
+	return result, err
+}
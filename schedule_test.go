@@ -0,0 +1,56 @@
+package asterisk_ari_go
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestScheduleEvaluate(t *testing.T) {
+	s := Schedule{
+		Name: "support",
+		Hours: []BusinessHours{
+			{Days: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}, Open: "09:00", Close: "17:00"},
+			// Spanning midnight.
+			{Days: []time.Weekday{time.Saturday}, Open: "22:00", Close: "02:00"},
+		},
+		Holidays: []Holiday{
+			{Name: "christmas", Date: "2021-12-25", Recurring: true},
+			{Name: "bridge day", Date: "2021-07-05"},
+		},
+	}
+	at := func(value string) time.Time {
+		t, _ := time.Parse("2006-01-02 15:04", value)
+		return t
+	}
+	for _, tc := range []struct {
+		at       string
+		decision ScheduleDecision
+		holiday  string
+	}{
+		{"2021-03-01 10:00", ScheduleOpen, ""},
+		{"2021-03-01 08:59", ScheduleAfterHours, ""},
+		{"2021-03-01 17:00", ScheduleAfterHours, ""},
+		{"2021-03-07 12:00", ScheduleClosed, ""},
+		{"2021-03-06 23:00", ScheduleOpen, ""},
+		{"2021-03-07 01:30", ScheduleOpen, ""},
+		{"2021-03-07 02:00", ScheduleClosed, ""},
+		{"2023-12-25 10:00", ScheduleHoliday, "christmas"},
+		{"2021-07-05 10:00", ScheduleHoliday, "bridge day"},
+		{"2022-07-05 10:00", ScheduleOpen, ""},
+	} {
+		r, err := s.Evaluate(at(tc.at))
+		if err != nil || r.Decision != tc.decision || r.Holiday != tc.holiday {
+			t.Errorf("%s = %s %q, %v, want %s %q", tc.at, r.Decision, r.Holiday, err, tc.decision, tc.holiday)
+		}
+	}
+
+	s.TimeZone = "Europe/Paris"
+	if r, _ := s.Evaluate(at("2021-03-01 08:30")); r.Decision != ScheduleOpen {
+		t.Errorf("08:30 UTC in Paris = %s, want open", r.Decision)
+	}
+	s.TimeZone = "Nowhere/Atlantis"
+	if _, err := s.Evaluate(at("2021-03-01 10:00")); err == nil {
+		t.Error("unknown time zone accepted")
+	}
+}
@@ -0,0 +1,286 @@
+package asterisk_ari_go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ScreenPop is the notification shown to an agent about the call they are about to receive.
+type ScreenPop struct {
+	ChannelId    string                 `json:"channel_id"`
+	CallerNumber string                 `json:"caller_number"`
+	CallerName   string                 `json:"caller_name,omitempty"`
+	Dialed       string                 `json:"dialed,omitempty"`
+	Agent        string                 `json:"agent,omitempty"`
+	Contact      map[string]interface{} `json:"contact,omitempty"`
+	At           time.Time              `json:"at"`
+}
+
+// ScreenPopLookup finds the CRM data of a new call, e.g. the contact matching the caller number.
+type ScreenPopLookup func(ctx context.Context, ev StasisEvent) (map[string]interface{}, error)
+
+// ScreenPopPublisher delivers screen pops to agents' desktops.
+type ScreenPopPublisher interface {
+	Publish(ctx context.Context, pop ScreenPop) error
+}
+
+// ScreenPopPublisherFunc adapts a function, e.g. publishing on a NATS subject, to ScreenPopPublisher.
+type ScreenPopPublisherFunc func(ctx context.Context, pop ScreenPop) error
+
+// Publish calls f.
+func (f ScreenPopPublisherFunc) Publish(ctx context.Context, pop ScreenPop) error {
+	return f(ctx, pop)
+}
+
+// ScreenPopper looks up the CRM data of every new call on StasisStart and publishes a screen pop
+// when an agent is about to receive it: either when the application dials the agent (detected from
+// the Dial event, the agent being the dialed endpoint) or when a queue or dialer calls Offer.
+// Every event must be fed to HandleEvent.
+type ScreenPopper struct {
+	client     *APIClient
+	lookup     ScreenPopLookup
+	publishers []ScreenPopPublisher
+
+	// PublishOnStart also publishes a screen pop without agent as soon as the lookup completes.
+	PublishOnStart bool
+	// Timeout bounds the lookup and every publication. Defaults to 3 seconds.
+	Timeout time.Duration
+
+	mu    sync.Mutex
+	calls map[string]*screenPopCall
+}
+
+type screenPopCall struct {
+	pop   ScreenPop
+	ready chan struct{}
+}
+
+// NewScreenPopper creates a screen popper. lookup may be nil to publish caller information only.
+func NewScreenPopper(client *APIClient, lookup ScreenPopLookup, publishers ...ScreenPopPublisher) *ScreenPopper {
+	return &ScreenPopper{
+		client:     client,
+		lookup:     lookup,
+		publishers: publishers,
+		Timeout:    3 * time.Second,
+		calls:      make(map[string]*screenPopCall),
+	}
+}
+
+// HandleEvent feeds an event received from Asterisk into the screen popper.
+func (p *ScreenPopper) HandleEvent(ev StasisEvent) {
+	switch ev.Type {
+	case "StasisStart":
+		p.start(ev)
+	case "Dial":
+		if ev.Caller != nil && ev.Peer != nil && ev.Dialstatus == "" {
+			channelId, agent := ev.Caller.Id, ChannelEndpoint(ev.Peer.Name)
+			p.client.goTracked("screen_pop", func() { p.Offer(context.Background(), channelId, agent) })
+		}
+	case "ChannelDestroyed":
+		p.mu.Lock()
+		if _, ok := p.calls[ev.Channel.Id]; ok {
+			delete(p.calls, ev.Channel.Id)
+			p.client.TrackResource(ResourceChannel, "screen_pop", -1)
+		}
+		p.mu.Unlock()
+	}
+}
+
+// Offer publishes the screen pop of channelId to agent. It waits for the lookup of the call to
+// complete. Channels the screen popper did not see entering the application are ignored.
+func (p *ScreenPopper) Offer(ctx context.Context, channelId string, agent string) error {
+	p.mu.Lock()
+	call, ok := p.calls[channelId]
+	p.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	select {
+	case <-call.ready:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	pop := call.pop
+	pop.Agent = agent
+	pop.At = p.client.clock().Now()
+	return p.publish(ctx, pop)
+}
+
+// start looks up a new call in the background.
+func (p *ScreenPopper) start(ev StasisEvent) {
+	call := &screenPopCall{ready: make(chan struct{})}
+	call.pop = ScreenPop{ChannelId: ev.Channel.Id, CallerNumber: callerNumber(ev), At: p.client.clock().Now()}
+	if ev.Channel.Caller != nil {
+		call.pop.CallerName = ev.Channel.Caller.Name
+	}
+	if ev.Channel.Dialplan != nil {
+		call.pop.Dialed = ev.Channel.Dialplan.Exten
+	}
+
+	p.mu.Lock()
+	if _, ok := p.calls[ev.Channel.Id]; ok {
+		p.mu.Unlock()
+		return
+	}
+	p.calls[ev.Channel.Id] = call
+	p.mu.Unlock()
+	p.client.TrackResource(ResourceChannel, "screen_pop", 1)
+
+	p.client.goTracked("screen_pop", func() {
+		if p.lookup != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), p.timeout())
+			contact, err := p.lookup(ctx, ev)
+			cancel()
+			if err != nil {
+				p.client.logger.Warnf("screen pop: lookup for channel %s: %v", ev.Channel.Id, err)
+			}
+			call.pop.Contact = contact
+		}
+		close(call.ready)
+		if p.PublishOnStart {
+			p.publish(context.Background(), call.pop)
+		}
+	})
+}
+
+// publish delivers pop through every publisher.
+func (p *ScreenPopper) publish(ctx context.Context, pop ScreenPop) error {
+	var firstErr error
+	for _, publisher := range p.publishers {
+		pctx, cancel := context.WithTimeout(ctx, p.timeout())
+		err := publisher.Publish(pctx, pop)
+		cancel()
+		if err != nil {
+			p.client.logger.Warnf("screen pop: publishing for channel %s: %v", pop.ChannelId, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+func (p *ScreenPopper) timeout() time.Duration {
+	if p.Timeout <= 0 {
+		return 3 * time.Second
+	}
+	return p.Timeout
+}
+
+// WebhookPublisher posts screen pops as JSON to URL.
+type WebhookPublisher struct {
+	URL    string
+	Header http.Header
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Publish posts pop to the webhook. Non-2xx responses are errors.
+func (w *WebhookPublisher) Publish(ctx context.Context, pop ScreenPop) error {
+	if err := postJSON(ctx, w.Client, w.URL, w.Header, pop); err != nil {
+		return fmt.Errorf("screen pop webhook: %w", err)
+	}
+	return nil
+}
+
+// postJSON posts v as JSON to url. Non-2xx responses are errors. A nil client uses
+// http.DefaultClient.
+func postJSON(ctx context.Context, client *http.Client, url string, header http.Header, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response %s", resp.Status)
+	}
+	return nil
+}
+
+// SSEPublisher streams screen pops to browsers as server-sent events. It is an http.Handler;
+// a desktop subscribes with ?agent=<endpoint> and receives the pops offered to that agent.
+// Subscribers without agent receive every pop.
+type SSEPublisher struct {
+	mu          sync.Mutex
+	subscribers map[chan ScreenPop]string
+}
+
+// NewSSEPublisher creates a publisher without subscribers.
+func NewSSEPublisher() *SSEPublisher {
+	return &SSEPublisher{subscribers: make(map[chan ScreenPop]string)}
+}
+
+// Publish sends pop to the matching subscribers. Slow subscribers miss pops rather than block.
+func (s *SSEPublisher) Publish(ctx context.Context, pop ScreenPop) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch, agent := range s.subscribers {
+		if agent != "" && agent != pop.Agent {
+			continue
+		}
+		select {
+		case ch <- pop:
+		default:
+		}
+	}
+	return nil
+}
+
+// ServeHTTP streams the pops until the client disconnects.
+func (s *SSEPublisher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	ch := make(chan ScreenPop, 16)
+	s.mu.Lock()
+	s.subscribers[ch] = r.URL.Query().Get("agent")
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.subscribers, ch)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case pop := <-ch:
+			data, err := json.Marshal(pop)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: screenpop\ndata: %s\n\n", data)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
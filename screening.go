@@ -0,0 +1,255 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/antihax/optional"
+)
+
+// ScreenAction is the verdict of a screening function.
+type ScreenAction int
+
+const (
+	// ScreenAllow lets the call through to the handlers.
+	ScreenAllow ScreenAction = iota
+	// ScreenReject hangs the call up.
+	ScreenReject
+	// ScreenDivert sends the call back to the dialplan.
+	ScreenDivert
+)
+
+// ScreenDecision describes what to do with an inbound call.
+type ScreenDecision struct {
+	Action ScreenAction
+	// Reason is the hangup reason used by ScreenReject, e.g. "busy" or "congestion".
+	// Defaults to "normal".
+	Reason string
+	// Context, Extension and Priority are the dialplan location used by ScreenDivert.
+	Context   string
+	Extension string
+	Priority  int32
+	// Tags are set as channel variables on the call, whatever the action.
+	Tags map[string]string
+}
+
+// ScreenFunc inspects a StasisStart event and decides the fate of the call.
+type ScreenFunc func(ctx context.Context, ev StasisEvent) ScreenDecision
+
+// Screener runs screening functions on inbound calls before any handler sees them.
+// Screens run in order; the first one that rejects or diverts the call wins, and tags of
+// all screens that ran are applied.
+type Screener struct {
+	client  *APIClient
+	screens []ScreenFunc
+}
+
+// NewScreener creates a screener running the given screens in order.
+func NewScreener(client *APIClient, screens ...ScreenFunc) *Screener {
+	return &Screener{client: client, screens: screens}
+}
+
+// Screen evaluates ev and applies the decision. It reports whether the call may continue
+// to the handlers. Events other than StasisStart are always allowed.
+func (s *Screener) Screen(ctx context.Context, ev StasisEvent) (ScreenDecision, bool) {
+	decision := ScreenDecision{Action: ScreenAllow}
+	if ev.Type != "StasisStart" {
+		return decision, true
+	}
+
+	tags := make(map[string]string)
+	for _, screen := range s.screens {
+		d := screen(ctx, ev)
+		for k, v := range d.Tags {
+			tags[k] = v
+		}
+		if d.Action != ScreenAllow {
+			decision = d
+			break
+		}
+	}
+	decision.Tags = tags
+
+	channelId := ev.Channel.Id
+	for k, v := range tags {
+		if _, err := s.client.ChannelsApi.SetChannelVar(ctx, channelId, k, &ChannelsApiSetChannelVarOpts{Value: optional.NewString(v)}); err != nil {
+			s.client.logger.Warnf("screening: failed to tag channel %s with %s: %v", channelId, k, err)
+		}
+	}
+
+	switch decision.Action {
+	case ScreenReject:
+		reason := decision.Reason
+		if reason == "" {
+			reason = "normal"
+		}
+		s.client.logger.Debugf("screening: rejecting channel %s from %s", channelId, callerNumber(ev))
+		if _, err := s.client.ChannelsApi.Hangup(ctx, channelId, &ChannelsApiHangupOpts{Reason: optional.NewString(reason)}); err != nil {
+			s.client.logger.Warnf("screening: failed to reject channel %s: %v", channelId, err)
+		}
+		return decision, false
+	case ScreenDivert:
+		opts := &ChannelsApiContinueInDialplanOpts{}
+		if decision.Context != "" {
+			opts.Context = optional.NewString(decision.Context)
+		}
+		if decision.Extension != "" {
+			opts.Extension = optional.NewString(decision.Extension)
+		}
+		if decision.Priority != 0 {
+			opts.Priority = optional.NewInt32(decision.Priority)
+		}
+		s.client.logger.Debugf("screening: diverting channel %s from %s", channelId, callerNumber(ev))
+		if _, err := s.client.ChannelsApi.ContinueInDialplan(ctx, channelId, opts); err != nil {
+			s.client.logger.Warnf("screening: failed to divert channel %s: %v", channelId, err)
+		}
+		return decision, false
+	}
+	return decision, true
+}
+
+// Wrap returns a handler that screens every StasisStart event before passing it to next.
+// Rejected and diverted calls never reach next.
+func (s *Screener) Wrap(next func(StasisEvent)) func(StasisEvent) {
+	return func(ev StasisEvent) {
+		if _, ok := s.Screen(context.Background(), ev); ok {
+			next(ev)
+		}
+	}
+}
+
+// ScreenList matches calls by caller number or by source address.
+//
+// Number patterns are exact numbers ("+15551234567") or prefixes ending with "*" ("+1900*").
+// Address patterns are IPs or CIDR blocks ("10.0.0.0/8"); they are checked against the
+// PJSIP remote address of the channel.
+type ScreenList struct {
+	mu       sync.RWMutex
+	numbers  map[string]bool
+	prefixes []string
+	networks []*net.IPNet
+}
+
+// NewScreenList creates a list from the given patterns. Invalid CIDR blocks are returned as an error.
+func NewScreenList(patterns ...string) (*ScreenList, error) {
+	l := &ScreenList{numbers: make(map[string]bool)}
+	for _, p := range patterns {
+		if err := l.Add(p); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// Add appends a pattern to the list.
+func (l *ScreenList) Add(pattern string) error {
+	pattern = strings.TrimSpace(pattern)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch {
+	case strings.Contains(pattern, "/"):
+		_, network, err := net.ParseCIDR(pattern)
+		if err != nil {
+			return err
+		}
+		l.networks = append(l.networks, network)
+	case net.ParseIP(pattern) != nil:
+		ip := net.ParseIP(pattern)
+		bits := 128
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 32
+		}
+		l.networks = append(l.networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+	case strings.HasSuffix(pattern, "*"):
+		l.prefixes = append(l.prefixes, strings.TrimSuffix(pattern, "*"))
+	default:
+		l.numbers[pattern] = true
+	}
+	return nil
+}
+
+// MatchNumber reports whether number matches one of the number patterns.
+func (l *ScreenList) MatchNumber(number string) bool {
+	if number == "" {
+		return false
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.numbers[number] {
+		return true
+	}
+	for _, p := range l.prefixes {
+		if strings.HasPrefix(number, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchAddress reports whether addr, an IP optionally followed by a port, matches one of the address patterns.
+func (l *ScreenList) MatchAddress(addr string) bool {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	ip := net.ParseIP(strings.Trim(addr, "[]"))
+	if ip == nil {
+		return false
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for _, n := range l.networks {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// match checks the caller of ev against the list, looking up the source address only when needed.
+func (l *ScreenList) match(ctx context.Context, client *APIClient, ev StasisEvent) bool {
+	if l.MatchNumber(callerNumber(ev)) {
+		return true
+	}
+	l.mu.RLock()
+	hasNetworks := len(l.networks) > 0
+	l.mu.RUnlock()
+	if !hasNetworks || !strings.HasPrefix(ev.Channel.Name, "PJSIP/") {
+		return false
+	}
+	v, _, err := client.ChannelsApi.GetChannelVar(ctx, ev.Channel.Id, "CHANNEL(pjsip,remote_addr)")
+	if err != nil {
+		return false
+	}
+	return l.MatchAddress(v.Value)
+}
+
+// BlacklistScreen returns a screen applying decision to calls matching list.
+func BlacklistScreen(client *APIClient, list *ScreenList, decision ScreenDecision) ScreenFunc {
+	return func(ctx context.Context, ev StasisEvent) ScreenDecision {
+		if list.match(ctx, client, ev) {
+			return decision
+		}
+		return ScreenDecision{Action: ScreenAllow}
+	}
+}
+
+// WhitelistScreen returns a screen applying decision to calls that do not match list.
+func WhitelistScreen(client *APIClient, list *ScreenList, decision ScreenDecision) ScreenFunc {
+	return func(ctx context.Context, ev StasisEvent) ScreenDecision {
+		if !list.match(ctx, client, ev) {
+			return decision
+		}
+		return ScreenDecision{Action: ScreenAllow}
+	}
+}
+
+// callerNumber returns the caller ID number of the channel in ev.
+func callerNumber(ev StasisEvent) string {
+	if ev.Channel.Caller == nil {
+		return ""
+	}
+	return ev.Channel.Caller.Number
+}
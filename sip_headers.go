@@ -0,0 +1,67 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ErrNotPJSIP is returned when reading SIP headers of a channel whose technology is not PJSIP.
+var ErrNotPJSIP = errors.New("channel is not a PJSIP channel")
+
+// SIPHeader returns a header of the INVITE that created the channel, or "" if the INVITE did not
+// carry it. Headers are read once through PJSIP_HEADER and cached for the life of the call.
+func (h *ChannelHandle) SIPHeader(ctx context.Context, name string) (string, error) {
+	key := strings.ToLower(name)
+	h.mu.RLock()
+	value, cached := h.headers[key]
+	channelName := h.channel.Name
+	h.mu.RUnlock()
+	if cached {
+		return value, nil
+	}
+	// The snapshot may not be known yet, e.g. for a handle restored from a StateStore; Asterisk
+	// then reports the error itself.
+	if channelName != "" && !strings.HasPrefix(channelName, "PJSIP/") {
+		return "", ErrNotPJSIP
+	}
+
+	value, err := h.GetVar(ctx, "PJSIP_HEADER(read,"+name+")")
+	if err != nil {
+		return "", err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.headers == nil {
+		h.headers = make(map[string]string)
+	}
+	h.headers[key] = value
+	return value, nil
+}
+
+// SIPCallID returns the Call-ID of the INVITE that created the channel.
+func (h *ChannelHandle) SIPCallID(ctx context.Context) (string, error) {
+	return h.SIPHeader(ctx, "Call-ID")
+}
+
+// SIPFrom returns the From header of the INVITE that created the channel.
+func (h *ChannelHandle) SIPFrom(ctx context.Context) (string, error) {
+	return h.SIPHeader(ctx, "From")
+}
+
+// SIPTo returns the To header of the INVITE that created the channel.
+func (h *ChannelHandle) SIPTo(ctx context.Context) (string, error) {
+	return h.SIPHeader(ctx, "To")
+}
+
+// SIPDiversion returns the Diversion header of the INVITE that created the channel, set when the
+// call was forwarded.
+func (h *ChannelHandle) SIPDiversion(ctx context.Context) (string, error) {
+	return h.SIPHeader(ctx, "Diversion")
+}
+
+// SIPAssertedIdentity returns the P-Asserted-Identity header of the INVITE that created the
+// channel.
+func (h *ChannelHandle) SIPAssertedIdentity(ctx context.Context) (string, error) {
+	return h.SIPHeader(ctx, "P-Asserted-Identity")
+}
@@ -0,0 +1,40 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSIPHeader(t *testing.T) {
+	var requests int32
+	client, _ := goneClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if got := r.URL.Query().Get("variable"); got != "PJSIP_HEADER(read,Call-ID)" {
+			t.Errorf("variable = %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"value":"abc@pbx"}`))
+	})
+	ctx := context.Background()
+
+	h := &ChannelHandle{client: client, id: "c1", channel: Channel{Id: "c1", Name: "PJSIP/alice-00000001"}}
+	for i := 0; i < 2; i++ {
+		if id, err := h.SIPCallID(ctx); err != nil || id != "abc@pbx" {
+			t.Errorf("Call-ID = %q, %v, want abc@pbx", id, err)
+		}
+	}
+	// Header names are case-insensitive and read once.
+	if id, err := h.SIPHeader(ctx, "call-id"); err != nil || id != "abc@pbx" {
+		t.Errorf("call-id = %q, %v, want abc@pbx", id, err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("%d requests, want 1", n)
+	}
+
+	sip := &ChannelHandle{client: client, id: "c2", channel: Channel{Id: "c2", Name: "SIP/bob-00000002"}}
+	if _, err := sip.SIPCallID(ctx); err != ErrNotPJSIP {
+		t.Errorf("err = %v, want ErrNotPJSIP", err)
+	}
+}
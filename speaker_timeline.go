@@ -0,0 +1,271 @@
+package asterisk_ari_go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpeakerSegment is a span of talk of one member of a recorded bridge. Start and End are offsets
+// from the start of the recording.
+type SpeakerSegment struct {
+	ChannelId string        `json:"channel_id"`
+	Start     time.Duration `json:"start"`
+	End       time.Duration `json:"end"`
+}
+
+// TimelineMember is a channel that was in a recorded bridge. Joined and Left are offsets from
+// the start of the recording; Joined is 0 for the members already in the bridge when it started.
+type TimelineMember struct {
+	ChannelId string        `json:"channel_id"`
+	Name      string        `json:"name,omitempty"`
+	Number    string        `json:"number,omitempty"`
+	Joined    time.Duration `json:"joined"`
+	Left      time.Duration `json:"left,omitempty"`
+}
+
+// SpeakerTimeline tells who talked when in a bridge recording, for playback UIs to show the
+// speakers along the recording.
+type SpeakerTimeline struct {
+	BridgeId  string           `json:"bridge_id"`
+	Recording string           `json:"recording"`
+	Started   time.Time        `json:"started"`
+	Duration  time.Duration    `json:"duration"`
+	Members   []TimelineMember `json:"members"`
+	Segments  []SpeakerSegment `json:"segments"`
+}
+
+type recordedBridge struct {
+	timeline SpeakerTimeline
+	// members indexes the members of the timeline, and talking the start of the current talk of
+	// every member, by channel ID.
+	members map[string]int
+	talking map[string]time.Time
+}
+
+// SpeakerTimelines builds the SpeakerTimeline of every bridge recording, e.g. of conferences, by
+// correlating the TALK_DETECT events of the channels with the members of the bridge. Talk
+// detection must be enabled on the members, see ChannelHandle.EnableTalkDetect. Every event must
+// be fed to HandleEvent.
+type SpeakerTimelines struct {
+	client *APIClient
+
+	// OnTimeline is called with the timeline of every bridge recording once it finishes.
+	OnTimeline func(t SpeakerTimeline)
+	// Store, if set, receives the timeline of every finished recording as JSON under the name of
+	// the recording followed by ".timeline.json", next to the recording uploaded by UploadStage.
+	Store ObjectStore
+
+	mu sync.Mutex
+	// bridges holds the members of every bridge by channel ID, recorded or not, so that the
+	// members present when a recording starts are known.
+	bridges    map[string]map[string]Channel
+	recordings map[string]*recordedBridge
+}
+
+// NewSpeakerTimelines creates a timeline builder.
+func NewSpeakerTimelines(client *APIClient) *SpeakerTimelines {
+	return &SpeakerTimelines{
+		client:     client,
+		bridges:    make(map[string]map[string]Channel),
+		recordings: make(map[string]*recordedBridge),
+	}
+}
+
+// eventTime returns when ev happened, by the clock of Asterisk.
+func (s *SpeakerTimelines) eventTime(ev StasisEvent) time.Time {
+	if !ev.Timestamp.Timestamp.IsZero() {
+		return ev.Timestamp.Timestamp
+	}
+	return s.client.clock().Now()
+}
+
+// HandleEvent feeds an event received from Asterisk into the timelines.
+func (s *SpeakerTimelines) HandleEvent(ev StasisEvent) {
+	at := s.eventTime(ev)
+	var finished *SpeakerTimeline
+	s.mu.Lock()
+	switch ev.Type {
+	case "ChannelEnteredBridge":
+		if ev.Bridge == nil {
+			break
+		}
+		members, ok := s.bridges[ev.Bridge.Id]
+		if !ok {
+			members = make(map[string]Channel)
+			s.bridges[ev.Bridge.Id] = members
+		}
+		members[ev.Channel.Id] = ev.Channel
+		for _, rec := range s.recordingsLocked(ev.Bridge.Id) {
+			rec.join(ev.Channel, at)
+		}
+	case "ChannelLeftBridge":
+		if ev.Bridge == nil {
+			break
+		}
+		delete(s.bridges[ev.Bridge.Id], ev.Channel.Id)
+		for _, rec := range s.recordingsLocked(ev.Bridge.Id) {
+			rec.leave(ev.Channel.Id, at)
+		}
+	case "BridgeDestroyed":
+		if ev.Bridge != nil {
+			delete(s.bridges, ev.Bridge.Id)
+		}
+	case "ChannelTalkingStarted", "ChannelTalkingFinished":
+		for _, rec := range s.recordings {
+			if _, ok := rec.members[ev.Channel.Id]; !ok {
+				continue
+			}
+			if ev.Type == "ChannelTalkingStarted" {
+				if _, talking := rec.talking[ev.Channel.Id]; !talking {
+					rec.talking[ev.Channel.Id] = at
+				}
+			} else {
+				rec.endTalk(ev.Channel.Id, at, at.Add(-time.Duration(ev.Duration)*time.Millisecond))
+			}
+		}
+	case "RecordingStarted":
+		bridgeId, ok := recordedBridgeId(ev)
+		if !ok {
+			break
+		}
+		rec := &recordedBridge{
+			timeline: SpeakerTimeline{BridgeId: bridgeId, Recording: ev.Recording.Name, Started: at},
+			members:  make(map[string]int),
+			talking:  make(map[string]time.Time),
+		}
+		for _, channel := range s.bridges[bridgeId] {
+			rec.join(channel, at)
+		}
+		s.recordings[ev.Recording.Name] = rec
+		s.client.TrackResource(ResourceSubscription, "speaker_timeline", 1)
+	case "RecordingFinished", "RecordingFailed":
+		if _, ok := recordedBridgeId(ev); !ok {
+			break
+		}
+		rec, ok := s.recordings[ev.Recording.Name]
+		if !ok {
+			break
+		}
+		delete(s.recordings, ev.Recording.Name)
+		s.client.TrackResource(ResourceSubscription, "speaker_timeline", -1)
+		for id := range rec.talking {
+			rec.endTalk(id, at, time.Time{})
+		}
+		rec.timeline.Duration = at.Sub(rec.timeline.Started)
+		finished = &rec.timeline
+	}
+	s.mu.Unlock()
+
+	if finished != nil {
+		t := *finished
+		s.client.goTracked("speaker_timeline", func() { s.finish(t) })
+	}
+}
+
+// Timeline returns the timeline of a bridge recording in progress, up to now.
+func (s *SpeakerTimelines) Timeline(recordingName string) (SpeakerTimeline, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.recordings[recordingName]
+	if !ok {
+		return SpeakerTimeline{}, false
+	}
+	t := rec.timeline
+	t.Members = append([]TimelineMember(nil), t.Members...)
+	t.Segments = append([]SpeakerSegment(nil), t.Segments...)
+	now := s.client.clock().Now()
+	t.Duration = now.Sub(t.Started)
+	for id, start := range rec.talking {
+		t.Segments = append(t.Segments, SpeakerSegment{ChannelId: id, Start: timelineOffset(t.Started, start), End: t.Duration})
+	}
+	return t, true
+}
+
+// finish delivers a finished timeline, off the event loop since storing it may be slow.
+func (s *SpeakerTimelines) finish(t SpeakerTimeline) {
+	if s.Store != nil {
+		body, err := json.Marshal(t)
+		if err == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			_, err = s.Store.Put(ctx, t.Recording+".timeline.json", bytes.NewReader(body), int64(len(body)), "application/json")
+			cancel()
+		}
+		if err != nil {
+			s.client.logger.Warnf("speaker timeline: storing the timeline of recording %s: %v", t.Recording, err)
+		}
+	}
+	if s.OnTimeline != nil {
+		s.OnTimeline(t)
+	}
+}
+
+// recordingsLocked returns the recordings in progress of bridgeId.
+func (s *SpeakerTimelines) recordingsLocked(bridgeId string) []*recordedBridge {
+	var recs []*recordedBridge
+	for _, rec := range s.recordings {
+		if rec.timeline.BridgeId == bridgeId {
+			recs = append(recs, rec)
+		}
+	}
+	return recs
+}
+
+// recordedBridgeId returns the bridge recorded by the recording of ev.
+func recordedBridgeId(ev StasisEvent) (string, bool) {
+	if ev.Recording == nil || !strings.HasPrefix(ev.Recording.TargetUri, "bridge:") {
+		return "", false
+	}
+	return strings.TrimPrefix(ev.Recording.TargetUri, "bridge:"), true
+}
+
+// timelineOffset returns the offset of at from start, not negative.
+func timelineOffset(start time.Time, at time.Time) time.Duration {
+	if at.Before(start) {
+		return 0
+	}
+	return at.Sub(start)
+}
+
+func (r *recordedBridge) join(channel Channel, at time.Time) {
+	if _, ok := r.members[channel.Id]; ok {
+		return
+	}
+	m := TimelineMember{ChannelId: channel.Id, Joined: timelineOffset(r.timeline.Started, at)}
+	if channel.Caller != nil {
+		m.Name, m.Number = channel.Caller.Name, channel.Caller.Number
+	}
+	r.members[channel.Id] = len(r.timeline.Members)
+	r.timeline.Members = append(r.timeline.Members, m)
+}
+
+func (r *recordedBridge) leave(channelId string, at time.Time) {
+	i, ok := r.members[channelId]
+	if !ok {
+		return
+	}
+	r.endTalk(channelId, at, time.Time{})
+	r.timeline.Members[i].Left = timelineOffset(r.timeline.Started, at)
+	// A channel re-entering the bridge is a new member.
+	delete(r.members, channelId)
+}
+
+// endTalk closes the current talk of channelId at end. fallback is the start of the talk when
+// its beginning was not seen, zero to drop it.
+func (r *recordedBridge) endTalk(channelId string, end time.Time, fallback time.Time) {
+	start, ok := r.talking[channelId]
+	delete(r.talking, channelId)
+	if !ok {
+		if fallback.IsZero() {
+			return
+		}
+		start = fallback
+	}
+	seg := SpeakerSegment{ChannelId: channelId, Start: timelineOffset(r.timeline.Started, start), End: timelineOffset(r.timeline.Started, end)}
+	if seg.End > seg.Start {
+		r.timeline.Segments = append(r.timeline.Segments, seg)
+	}
+}
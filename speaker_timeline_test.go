@@ -0,0 +1,87 @@
+package asterisk_ari_go
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSpeakerTimeline(t *testing.T) {
+	s := time.Second
+	start := time.Unix(1000, 0)
+	cfg := NewConfiguration("/")
+	cfg.Clock = NewFakeClock(start.Add(5 * s))
+	timelines := NewSpeakerTimelines(NewAPIClient(cfg, NewStdLogger(ioutil.Discard)))
+	finished := make(chan SpeakerTimeline, 1)
+	timelines.OnTimeline = func(t SpeakerTimeline) { finished <- t }
+
+	bridge := &Bridge{Id: "br"}
+	recording := &LiveRecording{Name: "conf-1", TargetUri: "bridge:br"}
+	at := func(d time.Duration) StasisTimestampEvent { return StasisTimestampEvent{start.Add(d)} }
+	feed := func(d time.Duration, typ string, channel string, duration time.Duration) {
+		ev := StasisEvent{Type: typ, Channel: Channel{Id: channel}, Timestamp: at(d), Duration: int32(duration.Milliseconds())}
+		switch typ {
+		case "ChannelEnteredBridge", "ChannelLeftBridge":
+			ev.Bridge = bridge
+		case "RecordingStarted", "RecordingFinished":
+			ev.Channel, ev.Recording = Channel{}, recording
+		}
+		timelines.HandleEvent(ev)
+	}
+
+	feed(0, "ChannelEnteredBridge", "a", 0)
+	// Talk before the recording is not part of the timeline.
+	feed(1*s, "ChannelTalkingStarted", "a", 0)
+	feed(1500*time.Millisecond, "ChannelTalkingFinished", "a", 500*time.Millisecond)
+	feed(2*s, "RecordingStarted", "", 0)
+	feed(3*s, "ChannelEnteredBridge", "b", 0)
+	feed(4*s, "ChannelTalkingStarted", "a", 0)
+
+	// In progress, the current talk ends now.
+	live, ok := timelines.Timeline("conf-1")
+	if !ok {
+		t.Fatal("no timeline in progress")
+	}
+	if want := []SpeakerSegment{{"a", 2 * s, 3 * s}}; live.Duration != 3*s || !reflect.DeepEqual(live.Segments, want) {
+		t.Errorf("in progress: duration = %v, segments = %v, want 3s and %v", live.Duration, live.Segments, want)
+	}
+
+	feed(6*s, "ChannelTalkingFinished", "a", 2*s)
+	// Leaving the bridge ends the talk; coming back is a new member.
+	feed(6500*time.Millisecond, "ChannelTalkingStarted", "b", 0)
+	feed(7*s, "ChannelLeftBridge", "b", 0)
+	feed(8*s, "ChannelEnteredBridge", "b", 0)
+	// The start of the talk was missed: it is derived from its duration.
+	feed(10*s, "ChannelTalkingFinished", "b", 1*s)
+	feed(12*s, "RecordingFinished", "", 0)
+
+	var timeline SpeakerTimeline
+	select {
+	case timeline = <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeline not delivered")
+	}
+	if _, ok := timelines.Timeline("conf-1"); ok {
+		t.Error("finished timeline still in progress")
+	}
+	if timeline.BridgeId != "br" || timeline.Recording != "conf-1" || !timeline.Started.Equal(start.Add(2*s)) || timeline.Duration != 10*s {
+		t.Errorf("timeline = %s %s %v %v, want br conf-1 at 2s for 10s", timeline.BridgeId, timeline.Recording, timeline.Started, timeline.Duration)
+	}
+	wantMembers := []TimelineMember{
+		{ChannelId: "a", Joined: 0},
+		{ChannelId: "b", Joined: 1 * s, Left: 5 * s},
+		{ChannelId: "b", Joined: 6 * s},
+	}
+	if !reflect.DeepEqual(timeline.Members, wantMembers) {
+		t.Errorf("members = %+v, want %+v", timeline.Members, wantMembers)
+	}
+	wantSegments := []SpeakerSegment{
+		{"a", 2 * s, 4 * s},
+		{"b", 4500 * time.Millisecond, 5 * s},
+		{"b", 7 * s, 8 * s},
+	}
+	if !reflect.DeepEqual(timeline.Segments, wantSegments) {
+		t.Errorf("segments = %v, want %v", timeline.Segments, wantSegments)
+	}
+}
@@ -0,0 +1,130 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// ErrStandby is returned for requests with side effects issued while the instance is on standby.
+var ErrStandby = errors.New("instance is on standby")
+
+// Standby runs an instance as a hot standby of the active one. The standby mirrors the event stream,
+// typically through an application subscribed to all events, to keep Cache warm, but its handlers
+// do not run and REST requests with side effects are refused with ErrStandby. Once promoted it
+// behaves as the active instance with an up-to-date view of the calls in progress.
+//
+// Every event must be fed to HandleEvent; handlers are wrapped with Wrap and the client is
+// configured with HTTPClient.
+type Standby struct {
+	client *APIClient
+
+	// Cache is kept up to date in both modes.
+	Cache *ChannelCache
+	// OnPromote is called on promotion, e.g. to register the application of the active instance.
+	OnPromote func()
+	// OnDemote is called when the instance goes back to standby.
+	OnDemote func()
+
+	mu     sync.RWMutex
+	active bool
+}
+
+// NewStandby creates the standby mode of an instance, active or not.
+func NewStandby(client *APIClient, active bool) *Standby {
+	cache := NewChannelCache()
+	cache.Clock = client.clock()
+	return &Standby{client: client, Cache: cache, active: active}
+}
+
+// Active reports whether the instance is active.
+func (s *Standby) Active() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.active
+}
+
+// Promote makes the instance active.
+func (s *Standby) Promote() {
+	if s.set(true) {
+		s.client.logger.Infof("standby: promoted to active with %d calls in progress", len(s.Cache.List()))
+		if s.OnPromote != nil {
+			s.OnPromote()
+		}
+	}
+}
+
+// Demote puts the instance back on standby.
+func (s *Standby) Demote() {
+	if s.set(false) {
+		s.client.logger.Infof("standby: demoted to standby")
+		if s.OnDemote != nil {
+			s.OnDemote()
+		}
+	}
+}
+
+// set changes the mode and reports whether it changed.
+func (s *Standby) set(active bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := s.active != active
+	s.active = active
+	return changed
+}
+
+// PromoteOnSignal promotes the instance when one of sigs is received, until ctx is done.
+func (s *Standby) PromoteOnSignal(ctx context.Context, sigs ...os.Signal) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	s.client.goTracked("standby", func() {
+		defer signal.Stop(ch)
+		select {
+		case <-ch:
+			s.Promote()
+		case <-ctx.Done():
+		}
+	})
+}
+
+// HandleEvent mirrors an event into Cache.
+func (s *Standby) HandleEvent(ev StasisEvent) {
+	s.Cache.HandleEvent(ev)
+}
+
+// Wrap returns a handler that passes events to next only while the instance is active.
+func (s *Standby) Wrap(next func(StasisEvent)) func(StasisEvent) {
+	return func(ev StasisEvent) {
+		if s.Active() {
+			next(ev)
+		}
+	}
+}
+
+// HTTPClient returns a client to use as Configuration.HTTPClient, refusing requests other than
+// GET and HEAD while on standby. A nil base uses http.DefaultTransport.
+func (s *Standby) HTTPClient(base http.RoundTripper) *http.Client {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &http.Client{Transport: &standbyTransport{s: s, base: base}}
+}
+
+// standbyTransport is the http.RoundTripper of Standby.HTTPClient.
+type standbyTransport struct {
+	s    *Standby
+	base http.RoundTripper
+}
+
+func (t *standbyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead && !t.s.Active() {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, ErrStandby
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,196 @@
+package asterisk_ari_go
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidStasisArgs is returned (wrapped in a *StasisArgError) when the arguments of a
+// StasisStart event do not match the schema they are parsed into.
+var ErrInvalidStasisArgs = errors.New("invalid stasis arguments")
+
+// StasisArgError reports the argument that could not be parsed.
+type StasisArgError struct {
+	// Arg is the position or the flag name of the argument.
+	Arg   string
+	Value string
+	Err   error
+}
+
+func (e *StasisArgError) Error() string {
+	if e.Value == "" {
+		return fmt.Sprintf("%v: argument %s: %v", ErrInvalidStasisArgs, e.Arg, e.Err)
+	}
+	return fmt.Sprintf("%v: argument %s=%q: %v", ErrInvalidStasisArgs, e.Arg, e.Value, e.Err)
+}
+
+// Is matches ErrInvalidStasisArgs.
+func (e *StasisArgError) Is(target error) bool { return target == ErrInvalidStasisArgs }
+
+func (e *StasisArgError) Unwrap() error { return e.Err }
+
+// StasisArgs are the arguments passed to a Stasis application by the dialplan, e.g.
+// Stasis(app,queue,sales,priority=2). Arguments of the form key=value are flags, the others are
+// positional.
+type StasisArgs []string
+
+// Positional returns the arguments that are not flags, in order.
+func (a StasisArgs) Positional() []string {
+	var positional []string
+	for _, arg := range a {
+		if _, _, ok := splitFlag(arg); !ok {
+			positional = append(positional, arg)
+		}
+	}
+	return positional
+}
+
+// Flags returns the key=value arguments by key; the last one wins when a key is repeated.
+func (a StasisArgs) Flags() map[string]string {
+	flags := make(map[string]string)
+	for _, arg := range a {
+		if k, v, ok := splitFlag(arg); ok {
+			flags[k] = v
+		}
+	}
+	return flags
+}
+
+// Arg returns the positional argument i, or "" if there are fewer.
+func (a StasisArgs) Arg(i int) string {
+	positional := a.Positional()
+	if i < 0 || i >= len(positional) {
+		return ""
+	}
+	return positional[i]
+}
+
+// Flag returns the value of the flag key and whether it was passed.
+func (a StasisArgs) Flag(key string) (string, bool) {
+	v, ok := a.Flags()[key]
+	return v, ok
+}
+
+// splitFlag splits a key=value argument. The key must be non-empty and contain no spaces, so that
+// positional values such as "a = b" or "=x" are not taken for flags.
+func splitFlag(arg string) (key string, value string, ok bool) {
+	key, value, ok = strings.Cut(arg, "=")
+	if !ok || key == "" || strings.ContainsAny(key, " \t") {
+		return "", "", false
+	}
+	return key, value, true
+}
+
+// Parse sets the fields of the struct pointed to by dst from the arguments, following the `arg`
+// tags of its fields: a number selects a positional argument, any other name a flag, and
+// ",required" fails when the argument is missing. Fields may be strings, booleans, integers,
+// floats or time.Durations; fields without a tag are left alone. E.g.
+//
+//	var args struct {
+//		Kind     string        `arg:"0,required"`
+//		Queue    string        `arg:"1"`
+//		Priority int           `arg:"priority"`
+//		Timeout  time.Duration `arg:"timeout"`
+//	}
+//	err := ev.StasisArgs().Parse(&args)
+func (a StasisArgs) Parse(dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("stasis args: Parse needs a pointer to a struct, got %T", dst)
+	}
+	v = v.Elem()
+	t := v.Type()
+	positional, flags := a.Positional(), a.Flags()
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("arg")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		value, present := "", false
+		if pos, err := strconv.Atoi(name); err == nil {
+			if pos >= 0 && pos < len(positional) {
+				value, present = positional[pos], true
+			}
+		} else {
+			value, present = flags[name]
+		}
+		if !present {
+			if options == "required" {
+				return &StasisArgError{Arg: name, Err: errors.New("missing")}
+			}
+			continue
+		}
+		if err := setArg(v.Field(i), value); err != nil {
+			return &StasisArgError{Arg: name, Value: value, Err: err}
+		}
+	}
+	return nil
+}
+
+// setArg converts value to the type of field and sets it.
+func setArg(field reflect.Value, value string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			// A bare number is taken for seconds, as dialplan timeouts usually are.
+			seconds, serr := strconv.ParseFloat(value, 64)
+			if serr != nil {
+				return err
+			}
+			d = time.Duration(seconds * float64(time.Second))
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		if value == "" {
+			// A flag without a value, e.g. "record=", is set.
+			field.SetBool(true)
+			return nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+// StasisArgs returns the arguments the channel entered the application with.
+func (ev StasisEvent) StasisArgs() StasisArgs {
+	return StasisArgs(ev.Args)
+}
+
+// ParseArgs parses the arguments of the event into dst, see StasisArgs.Parse.
+func (ev StasisStartEvent) ParseArgs(dst interface{}) error {
+	return ev.StasisArgs().Parse(dst)
+}
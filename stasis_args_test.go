@@ -0,0 +1,60 @@
+package asterisk_ari_go
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStasisArgs(t *testing.T) {
+	args := StasisArgs{"queue", "sales", "priority=2", "a = b", "=x", "priority=3"}
+	if want := []string{"queue", "sales", "a = b", "=x"}; !reflect.DeepEqual(args.Positional(), want) {
+		t.Errorf("positional = %q, want %q", args.Positional(), want)
+	}
+	if v, ok := args.Flag("priority"); !ok || v != "3" {
+		t.Errorf("priority = %q, %v, want the last one, 3", v, ok)
+	}
+	if args.Arg(1) != "sales" || args.Arg(9) != "" {
+		t.Errorf("args 1 and 9 = %q and %q", args.Arg(1), args.Arg(9))
+	}
+}
+
+func TestStasisArgsParse(t *testing.T) {
+	var dst struct {
+		Kind     string        `arg:"0,required"`
+		Queue    string        `arg:"1"`
+		Priority int           `arg:"priority"`
+		Timeout  time.Duration `arg:"timeout"`
+		Ring     time.Duration `arg:"ring"`
+		Record   bool          `arg:"record"`
+		Score    float64       `arg:"score"`
+		Untagged string
+	}
+	args := StasisArgs{"queue", "sales", "priority=2", "timeout=1m30s", "ring=2.5", "record=", "score=0.5"}
+	if err := args.Parse(&dst); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Kind != "queue" || dst.Queue != "sales" || dst.Priority != 2 || dst.Timeout != 90*time.Second ||
+		dst.Ring != 2500*time.Millisecond || !dst.Record || dst.Score != 0.5 || dst.Untagged != "" {
+		t.Errorf("parsed = %+v", dst)
+	}
+
+	for _, tc := range []struct {
+		args StasisArgs
+		arg  string
+	}{
+		{StasisArgs{}, "0"},
+		{StasisArgs{"queue", "priority=high"}, "priority"},
+		{StasisArgs{"queue", "timeout=soon"}, "timeout"},
+	} {
+		err := tc.args.Parse(&dst)
+		var argErr *StasisArgError
+		if !errors.Is(err, ErrInvalidStasisArgs) || !errors.As(err, &argErr) || argErr.Arg != tc.arg {
+			t.Errorf("%q: err = %v, want an error on argument %s", tc.args, err, tc.arg)
+		}
+	}
+	if err := (StasisArgs{}).Parse(dst); err == nil {
+		t.Error("parsing into a struct value succeeded")
+	}
+}
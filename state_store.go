@@ -0,0 +1,58 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrStateNotFound is returned by StateStore.Load for unknown keys.
+var ErrStateNotFound = errors.New("state not found")
+
+// StateStore persists state across restarts of the application, e.g. in Redis or a database.
+// Implementations must be safe for concurrent use.
+type StateStore interface {
+	Save(ctx context.Context, key string, data []byte) error
+	// Load returns ErrStateNotFound if key was never saved or was deleted.
+	Load(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// MemoryStateStore is a StateStore kept in memory, for tests and single-instance deployments
+// where state only has to survive the loss of the websocket.
+type MemoryStateStore struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+}
+
+// NewMemoryStateStore creates an empty store.
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{data: make(map[string][]byte)}
+}
+
+// Save stores a copy of data under key.
+func (s *MemoryStateStore) Save(ctx context.Context, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = append([]byte(nil), data...)
+	return nil
+}
+
+// Load returns the data stored under key.
+func (s *MemoryStateStore) Load(ctx context.Context, key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.data[key]
+	if !ok {
+		return nil, ErrStateNotFound
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// Delete removes key.
+func (s *MemoryStateStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+	return nil
+}
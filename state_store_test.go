@@ -0,0 +1,60 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+)
+
+func TestMemoryStateStore(t *testing.T) {
+	s := NewMemoryStateStore()
+	ctx := context.Background()
+	data := []byte("v1")
+	s.Save(ctx, "k", data)
+	// The store keeps a copy.
+	data[1] = '2'
+	if got, err := s.Load(ctx, "k"); err != nil || string(got) != "v1" {
+		t.Errorf("Load = %q, %v, want v1", got, err)
+	}
+	s.Delete(ctx, "k")
+	if _, err := s.Load(ctx, "k"); err != ErrStateNotFound {
+		t.Errorf("Load after Delete: err = %v, want ErrStateNotFound", err)
+	}
+}
+
+// TestCallContextRestore covers a call persisted by an instance and adopted by the next one after
+// a restart.
+func TestCallContextRestore(t *testing.T) {
+	store := NewMemoryStateStore()
+	client := NewAPIClient(NewConfiguration("/"), NewStdLogger(ioutil.Discard))
+	start := StasisEvent{Type: "StasisStart", Channel: Channel{Id: "c1"}}
+
+	before := NewCallRegistry(client)
+	before.Store = store
+	before.OnStart = func(h *ChannelHandle) {
+		h.SetState("menu")
+		h.Set("attempts", 2)
+		if err := h.Persist(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before.HandleEvent(start)
+
+	after := NewCallRegistry(client)
+	after.Store = store
+	var state string
+	var attempts interface{}
+	after.OnStart = func(h *ChannelHandle) {
+		state = h.State()
+		attempts, _ = h.Get("attempts")
+	}
+	after.HandleEvent(start)
+	if state != "menu" || attempts != float64(2) {
+		t.Errorf("restored state %q and attempts %v, want menu and 2", state, attempts)
+	}
+
+	after.HandleEvent(StasisEvent{Type: "ChannelDestroyed", Channel: Channel{Id: "c1"}})
+	if _, err := store.Load(context.Background(), callContextKey("c1")); err != ErrStateNotFound {
+		t.Errorf("context of a destroyed call: err = %v, want ErrStateNotFound", err)
+	}
+}
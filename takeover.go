@@ -0,0 +1,190 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/antihax/optional"
+)
+
+// User events of the takeover protocol.
+const (
+	TakeoverRequestEvent = "AriTakeoverRequest"
+	TakeoverCallEvent    = "AriTakeoverCall"
+	TakeoverDoneEvent    = "AriTakeoverDone"
+)
+
+// Takeover hands the in-flight calls of a running instance of a Stasis application over to a new
+// instance, for zero-downtime deploys. Each instance registers under its own application name
+// (e.g. "ivr-blue" and "ivr-green"); the dialplan keeps sending new calls to the old one until
+// the handover starts.
+//
+// The protocol runs over user events:
+//  1. the new instance calls Request, sending TakeoverRequestEvent to the old application;
+//  2. the old instance stops accepting calls: StasisStart events passed through Wrap are moved
+//     to the new application instead of being handled;
+//  3. for every call returned by Export it sends TakeoverCallEvent with the call metadata to the
+//     new application, then moves the channel there;
+//  4. the new instance calls Adopt when the moved channel enters its application;
+//  5. the old instance sends TakeoverDoneEvent, which makes Request return, and calls OnDrained.
+//
+// Every event received by either instance must be fed to HandleEvent.
+type Takeover struct {
+	client *APIClient
+	app    string
+
+	// Export returns the metadata of the calls handled by this instance, keyed by channel ID.
+	// Called on the old instance when the handover starts.
+	Export func() map[string]map[string]string
+	// Adopt is called on the new instance for every call handed over, once its channel entered
+	// the application.
+	Adopt func(channel Channel, metadata map[string]string)
+	// OnDrained is called on the old instance once every call was handed over.
+	OnDrained func()
+
+	mu       sync.Mutex
+	target   string
+	pending  map[string]map[string]string
+	finished chan struct{}
+}
+
+// NewTakeover creates the takeover protocol of the instance registered as app.
+func NewTakeover(client *APIClient, app string) *Takeover {
+	return &Takeover{client: client, app: app, pending: make(map[string]map[string]string), finished: make(chan struct{})}
+}
+
+// Accepting reports whether this instance still accepts new calls.
+func (t *Takeover) Accepting() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.target == ""
+}
+
+// Wrap returns a handler that passes events to next, except the StasisStart of new calls once a
+// handover started, which are moved to the new instance.
+func (t *Takeover) Wrap(next func(StasisEvent)) func(StasisEvent) {
+	return func(ev StasisEvent) {
+		t.mu.Lock()
+		target := t.target
+		t.mu.Unlock()
+		if ev.Type != "StasisStart" || target == "" || ev.Application != t.app {
+			next(ev)
+			return
+		}
+		if _, err := t.client.ChannelsApi.Move(context.Background(), ev.Channel.Id, target, t.moveOpts(ev)); err != nil {
+			t.client.logger.Warnf("takeover: moving new channel %s to %s: %v", ev.Channel.Id, target, err)
+		}
+	}
+}
+
+// HandleEvent feeds an event received from Asterisk into the protocol.
+func (t *Takeover) HandleEvent(ev StasisEvent) {
+	switch ev.Type {
+	case "ChannelUserevent":
+		switch ev.Eventname {
+		case TakeoverRequestEvent:
+			from, _ := ev.Userevent["from"].(string)
+			if from != "" && from != t.app {
+				t.client.goTracked("takeover", func() { t.handover(from) })
+			}
+		case TakeoverCallEvent:
+			id, _ := ev.Userevent["channel_id"].(string)
+			encoded, _ := ev.Userevent["metadata"].(string)
+			metadata := make(map[string]string)
+			if encoded != "" {
+				if err := json.Unmarshal([]byte(encoded), &metadata); err != nil {
+					t.client.logger.Warnf("takeover: metadata of channel %s: %v", id, err)
+				}
+			}
+			t.mu.Lock()
+			t.pending[id] = metadata
+			t.mu.Unlock()
+		case TakeoverDoneEvent:
+			t.mu.Lock()
+			select {
+			case <-t.finished:
+			default:
+				close(t.finished)
+			}
+			t.mu.Unlock()
+		}
+	case "StasisStart":
+		t.mu.Lock()
+		metadata, ok := t.pending[ev.Channel.Id]
+		delete(t.pending, ev.Channel.Id)
+		t.mu.Unlock()
+		if ok && t.Adopt != nil {
+			t.Adopt(ev.Channel, metadata)
+		}
+	}
+}
+
+// Request asks the instance registered as oldApp to hand its calls over and waits until it is done.
+func (t *Takeover) Request(ctx context.Context, oldApp string) error {
+	if err := t.userEvent(ctx, TakeoverRequestEvent, oldApp, map[string]string{"from": t.app}); err != nil {
+		return err
+	}
+	select {
+	case <-t.finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// handover runs the old side of the protocol towards the application target.
+func (t *Takeover) handover(target string) {
+	t.mu.Lock()
+	if t.target != "" {
+		t.mu.Unlock()
+		return
+	}
+	t.target = target
+	t.mu.Unlock()
+	t.client.logger.Infof("takeover: handing calls over to %s", target)
+
+	ctx := context.Background()
+	var calls map[string]map[string]string
+	if t.Export != nil {
+		calls = t.Export()
+	}
+	for channelId, metadata := range calls {
+		encoded, err := json.Marshal(metadata)
+		if err != nil {
+			t.client.logger.Warnf("takeover: metadata of channel %s: %v", channelId, err)
+			continue
+		}
+		variables := map[string]string{"channel_id": channelId, "metadata": string(encoded)}
+		if err := t.userEvent(ctx, TakeoverCallEvent, target, variables); err != nil {
+			t.client.logger.Warnf("takeover: announcing channel %s: %v", channelId, err)
+			continue
+		}
+		if _, err := t.client.ChannelsApi.Move(ctx, channelId, target, nil); err != nil {
+			t.client.logger.Warnf("takeover: moving channel %s: %v", channelId, err)
+		}
+	}
+
+	if err := t.userEvent(ctx, TakeoverDoneEvent, target, nil); err != nil {
+		t.client.logger.Warnf("takeover: completing handover: %v", err)
+	}
+	t.client.logger.Infof("takeover: %d calls handed over to %s", len(calls), target)
+	if t.OnDrained != nil {
+		t.OnDrained()
+	}
+}
+
+// moveOpts keeps the Stasis arguments of a new call when it is moved.
+func (t *Takeover) moveOpts(ev StasisEvent) *ChannelsApiMoveOpts {
+	if len(ev.Args) == 0 {
+		return nil
+	}
+	return &ChannelsApiMoveOpts{AppArgs: optional.NewString(strings.Join(ev.Args, ","))}
+}
+
+func (t *Takeover) userEvent(ctx context.Context, name string, app string, variables map[string]string) error {
+	opts := &EventsApiUserEventOpts{Variables: optional.NewInterface(Containers{Variables: variables})}
+	_, err := t.client.EventsApi.UserEvent(ctx, name, app, opts)
+	return err
+}
@@ -0,0 +1,274 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TalkStats are the talk-time analytics of a call: how long each party talked, talked over each
+// other, or nobody talked, from the answer of the channel to its end. They are computed from the
+// TALK_DETECT events of the channel and of the channels bridged with it, see EnableTalkDetect.
+type TalkStats struct {
+	// Window is the analysed time, from the answer of the channel to its end.
+	Window time.Duration `json:"window"`
+	// Talk is the time the channel talked.
+	Talk time.Duration `json:"talk"`
+	// Parties is the talk time of every party of the call by channel ID, the channel included.
+	Parties map[string]time.Duration `json:"parties,omitempty"`
+	// Overtalk is the time two or more parties talked at once.
+	Overtalk time.Duration `json:"overtalk"`
+	// Silence is the time nobody talked.
+	Silence time.Duration `json:"silence"`
+	// TalkRatio, OvertalkRatio and SilenceRatio are Talk, Overtalk and Silence over Window.
+	TalkRatio     float64 `json:"talk_ratio"`
+	OvertalkRatio float64 `json:"overtalk_ratio"`
+	SilenceRatio  float64 `json:"silence_ratio"`
+	// RecordedTalk and RecordedSilence are the talking and silence durations reported by the
+	// recordings of the channel started with a MaxSilenceSeconds, e.g. voicemail, which detect
+	// talk without TALK_DETECT.
+	RecordedTalk    time.Duration `json:"recorded_talk,omitempty"`
+	RecordedSilence time.Duration `json:"recorded_silence,omitempty"`
+}
+
+type talkInterval struct {
+	start, end time.Time
+}
+
+type talkChannel struct {
+	answered time.Time
+	// talking is the start of the current talk, zero while silent.
+	talking   time.Time
+	intervals []talkInterval
+	peers     map[string]bool
+	destroyed time.Time
+
+	recordedTalk, recordedSilence time.Duration
+}
+
+// TalkAnalytics computes the TalkStats of calls. Set it as CallRegistry.Talk to have them
+// attached to the CallRecord of every call, or feed every event to HandleEvent.
+type TalkAnalytics struct {
+	client *APIClient
+
+	mu       sync.Mutex
+	channels map[string]*talkChannel
+	bridges  map[string]map[string]bool
+}
+
+// NewTalkAnalytics creates analytics without calls.
+func NewTalkAnalytics(client *APIClient) *TalkAnalytics {
+	return &TalkAnalytics{client: client, channels: make(map[string]*talkChannel), bridges: make(map[string]map[string]bool)}
+}
+
+// EnableTalkDetect enables the TALK_DETECT events of the channel. silence is how long the
+// channel must be quiet to stop talking, and threshold the energy level above which audio is
+// talk; 0 uses the defaults of Asterisk, 2.5s and 256.
+func (h *ChannelHandle) EnableTalkDetect(ctx context.Context, silence time.Duration, threshold int) error {
+	value := ""
+	if silence > 0 || threshold > 0 {
+		value = fmt.Sprintf("%d,%d", silence.Milliseconds(), threshold)
+		if threshold <= 0 {
+			value = fmt.Sprint(silence.Milliseconds())
+		}
+	}
+	return h.SetVar(ctx, "TALK_DETECT(set)", value)
+}
+
+// eventTime returns when ev happened, by the clock of Asterisk, which is precise and free of
+// delivery delays.
+func (t *TalkAnalytics) eventTime(ev StasisEvent) time.Time {
+	if !ev.Timestamp.Timestamp.IsZero() {
+		return ev.Timestamp.Timestamp
+	}
+	return t.client.clock().Now()
+}
+
+func (t *TalkAnalytics) channelLocked(id string) *talkChannel {
+	ch, ok := t.channels[id]
+	if !ok {
+		ch = &talkChannel{peers: make(map[string]bool)}
+		t.channels[id] = ch
+	}
+	return ch
+}
+
+// HandleEvent tracks the talk of the channels.
+func (t *TalkAnalytics) HandleEvent(ev StasisEvent) {
+	at := t.eventTime(ev)
+	id := ev.Channel.Id
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch ev.Type {
+	case "ChannelTalkingStarted":
+		ch := t.channelLocked(id)
+		if ch.answered.IsZero() {
+			ch.answered = at
+		}
+		if ch.talking.IsZero() {
+			ch.talking = at
+		}
+		return
+	case "ChannelTalkingFinished":
+		ch := t.channelLocked(id)
+		start := ch.talking
+		if start.IsZero() {
+			start = at.Add(-time.Duration(ev.Duration) * time.Millisecond)
+		}
+		ch.intervals = append(ch.intervals, talkInterval{start, at})
+		ch.talking = time.Time{}
+		return
+	case "ChannelEnteredBridge":
+		if ev.Bridge == nil {
+			return
+		}
+		members, ok := t.bridges[ev.Bridge.Id]
+		if !ok {
+			members = make(map[string]bool)
+			t.bridges[ev.Bridge.Id] = members
+		}
+		ch := t.channelLocked(id)
+		for peer := range members {
+			ch.peers[peer] = true
+			t.channelLocked(peer).peers[id] = true
+		}
+		members[id] = true
+	case "ChannelLeftBridge":
+		if ev.Bridge != nil {
+			delete(t.bridges[ev.Bridge.Id], id)
+		}
+	case "BridgeDestroyed":
+		if ev.Bridge != nil {
+			delete(t.bridges, ev.Bridge.Id)
+		}
+		return
+	case "RecordingFinished":
+		if ev.Recording == nil || !strings.HasPrefix(ev.Recording.TargetUri, "channel:") {
+			return
+		}
+		ch := t.channelLocked(strings.TrimPrefix(ev.Recording.TargetUri, "channel:"))
+		ch.recordedTalk += time.Duration(ev.Recording.TalkingDuration) * time.Second
+		ch.recordedSilence += time.Duration(ev.Recording.SilenceDuration) * time.Second
+		return
+	case "ChannelDestroyed":
+		ch, ok := t.channels[id]
+		if !ok {
+			return
+		}
+		if !ch.talking.IsZero() {
+			ch.intervals = append(ch.intervals, talkInterval{ch.talking, at})
+			ch.talking = time.Time{}
+		}
+		ch.destroyed = at
+		t.pruneLocked(at)
+		return
+	}
+	if id != "" && ev.Channel.State == "Up" {
+		if ch := t.channelLocked(id); ch.answered.IsZero() {
+			ch.answered = at
+		}
+	}
+}
+
+// pruneLocked forgets the channels destroyed for a while whose peers are all destroyed too.
+func (t *TalkAnalytics) pruneLocked(now time.Time) {
+	for id, ch := range t.channels {
+		if ch.destroyed.IsZero() || now.Sub(ch.destroyed) < channelTombstoneTTL {
+			continue
+		}
+		done := true
+		for peer := range ch.peers {
+			if p, ok := t.channels[peer]; ok && p.destroyed.IsZero() {
+				done = false
+			}
+		}
+		if done {
+			delete(t.channels, id)
+		}
+	}
+}
+
+// Stats returns the talk analytics of a call up to its end, or up to now while it is in progress.
+// It reports false for a channel without talk or recording data.
+func (t *TalkAnalytics) Stats(channelId string) (TalkStats, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ch, ok := t.channels[channelId]
+	if !ok {
+		return TalkStats{}, false
+	}
+	end := ch.destroyed
+	if end.IsZero() {
+		end = t.client.clock().Now()
+	}
+	s := TalkStats{RecordedTalk: ch.recordedTalk, RecordedSilence: ch.recordedSilence}
+	if ch.answered.IsZero() || !end.After(ch.answered) {
+		return s, s.RecordedTalk > 0 || s.RecordedSilence > 0
+	}
+	start := ch.answered
+	s.Window = end.Sub(start)
+	s.Parties = make(map[string]time.Duration)
+
+	// Sweep the talk intervals of all the parties, counting how many talk at every instant.
+	type edge struct {
+		at    time.Time
+		delta int
+	}
+	var edges []edge
+	parties := []string{channelId}
+	for peer := range ch.peers {
+		parties = append(parties, peer)
+	}
+	for _, id := range parties {
+		party, ok := t.channels[id]
+		if !ok {
+			continue
+		}
+		intervals := party.intervals
+		if !party.talking.IsZero() {
+			intervals = append(intervals[:len(intervals):len(intervals)], talkInterval{party.talking, end})
+		}
+		for _, in := range intervals {
+			if in.start.Before(start) {
+				in.start = start
+			}
+			if in.end.After(end) {
+				in.end = end
+			}
+			if !in.end.After(in.start) {
+				continue
+			}
+			s.Parties[id] += in.end.Sub(in.start)
+			edges = append(edges, edge{in.start, 1}, edge{in.end, -1})
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].at.Equal(edges[j].at) {
+			return edges[i].delta < edges[j].delta
+		}
+		return edges[i].at.Before(edges[j].at)
+	})
+	talking, last := 0, start
+	for _, e := range edges {
+		span := e.at.Sub(last)
+		switch {
+		case talking == 0:
+			s.Silence += span
+		case talking >= 2:
+			s.Overtalk += span
+		}
+		talking += e.delta
+		last = e.at
+	}
+	s.Silence += end.Sub(last)
+
+	s.Talk = s.Parties[channelId]
+	s.TalkRatio = float64(s.Talk) / float64(s.Window)
+	s.OvertalkRatio = float64(s.Overtalk) / float64(s.Window)
+	s.SilenceRatio = float64(s.Silence) / float64(s.Window)
+	return s, true
+}
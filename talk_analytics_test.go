@@ -0,0 +1,113 @@
+package asterisk_ari_go
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+// talkStep is an event of a talk analytics test, at an offset from the start of the call.
+type talkStep struct {
+	at       time.Duration
+	typ      string
+	channel  string
+	duration time.Duration
+}
+
+func TestTalkAnalyticsStats(t *testing.T) {
+	s := time.Second
+	for _, tc := range []struct {
+		name  string
+		steps []talkStep
+		// at is when the stats of channel "a" are taken.
+		at                time.Duration
+		talk, peer        time.Duration
+		overtalk, silence time.Duration
+	}{
+		{
+			name: "overlapping",
+			steps: []talkStep{
+				{1 * s, "ChannelTalkingStarted", "a", 0},
+				{3 * s, "ChannelTalkingStarted", "b", 0},
+				{5 * s, "ChannelTalkingFinished", "a", 4 * s},
+				{7 * s, "ChannelTalkingFinished", "b", 4 * s},
+			},
+			at: 10 * s, talk: 4 * s, peer: 4 * s, overtalk: 2 * s, silence: 4 * s,
+		},
+		{
+			name: "adjacent",
+			steps: []talkStep{
+				{1 * s, "ChannelTalkingStarted", "a", 0},
+				{3 * s, "ChannelTalkingFinished", "a", 2 * s},
+				{3 * s, "ChannelTalkingStarted", "b", 0},
+				{5 * s, "ChannelTalkingFinished", "b", 2 * s},
+			},
+			at: 6 * s, talk: 2 * s, peer: 2 * s, overtalk: 0, silence: 2 * s,
+		},
+		{
+			name: "open at stats time",
+			steps: []talkStep{
+				{2 * s, "ChannelTalkingStarted", "a", 0},
+				{4 * s, "ChannelTalkingStarted", "b", 0},
+				{6 * s, "ChannelTalkingFinished", "b", 2 * s},
+			},
+			at: 8 * s, talk: 6 * s, peer: 2 * s, overtalk: 2 * s, silence: 2 * s,
+		},
+		{
+			name: "peer leaving mid-talk",
+			steps: []talkStep{
+				{2 * s, "ChannelTalkingStarted", "b", 0},
+				{3 * s, "ChannelTalkingStarted", "a", 0},
+				{4 * s, "ChannelTalkingFinished", "a", 1 * s},
+				{5 * s, "ChannelLeftBridge", "b", 0},
+				{5 * s, "ChannelDestroyed", "b", 0},
+			},
+			at: 8 * s, talk: 1 * s, peer: 3 * s, overtalk: 1 * s, silence: 5 * s,
+		},
+		{
+			name: "start not seen",
+			steps: []talkStep{
+				{4 * s, "ChannelTalkingFinished", "a", 2 * s},
+			},
+			at: 5 * s, talk: 2 * s, peer: 0, overtalk: 0, silence: 3 * s,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			start := time.Unix(1000, 0)
+			cfg := NewConfiguration("/")
+			cfg.Clock = NewFakeClock(start.Add(tc.at))
+			talk := NewTalkAnalytics(NewAPIClient(cfg, NewStdLogger(ioutil.Discard)))
+
+			bridge := &Bridge{Id: "br"}
+			for _, id := range []string{"a", "b"} {
+				talk.HandleEvent(StasisEvent{Type: "ChannelEnteredBridge", Channel: Channel{Id: id, State: "Up"}, Bridge: bridge, Timestamp: StasisTimestampEvent{start}})
+			}
+			for _, step := range tc.steps {
+				ev := StasisEvent{
+					Type:      step.typ,
+					Channel:   Channel{Id: step.channel, State: "Up"},
+					Duration:  int32(step.duration.Milliseconds()),
+					Timestamp: StasisTimestampEvent{start.Add(step.at)},
+				}
+				if step.typ == "ChannelLeftBridge" {
+					ev.Bridge = bridge
+				}
+				talk.HandleEvent(ev)
+			}
+
+			stats, ok := talk.Stats("a")
+			if !ok {
+				t.Fatal("no stats")
+			}
+			if stats.Window != tc.at {
+				t.Errorf("window = %v, want %v", stats.Window, tc.at)
+			}
+			if stats.Talk != tc.talk || stats.Parties["b"] != tc.peer {
+				t.Errorf("talk = %v, peer = %v, want %v and %v", stats.Talk, stats.Parties["b"], tc.talk, tc.peer)
+			}
+			if stats.Overtalk != tc.overtalk || stats.Silence != tc.silence {
+				t.Errorf("overtalk = %v, silence = %v, want %v and %v", stats.Overtalk, stats.Silence, tc.overtalk, tc.silence)
+			}
+		})
+	}
+}
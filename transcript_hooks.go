@@ -0,0 +1,191 @@
+package asterisk_ari_go
+
+import (
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Transcript is a piece of speech-to-text output for a channel, e.g. from a speech engine fed by
+// an external media channel. Interim transcripts are revised until a final one closes the
+// utterance.
+type Transcript struct {
+	ChannelId string    `json:"channel_id"`
+	Text      string    `json:"text"`
+	Final     bool      `json:"final"`
+	Time      time.Time `json:"time"`
+	// Speaker identifies the party, e.g. "caller" or "agent", if the engine separates them.
+	Speaker string `json:"speaker,omitempty"`
+}
+
+// TranscriptHook is invoked on every transcript, interim ones included, so it must return quickly.
+type TranscriptHook interface {
+	OnTranscript(t Transcript)
+}
+
+// TranscriptHookFunc adapts a function to TranscriptHook.
+type TranscriptHookFunc func(t Transcript)
+
+// OnTranscript calls f.
+func (f TranscriptHookFunc) OnTranscript(t Transcript) { f(t) }
+
+// Transcriber dispatches the transcripts of the speech engine to hooks, enabling real-time agent
+// assist triggers. The engine integration calls HandleTranscript.
+type Transcriber struct {
+	mu    sync.RWMutex
+	hooks []TranscriptHook
+}
+
+// NewTranscriber creates a transcriber invoking hooks.
+func NewTranscriber(hooks ...TranscriptHook) *Transcriber {
+	return &Transcriber{hooks: hooks}
+}
+
+// AddHook adds a hook.
+func (tr *Transcriber) AddHook(hook TranscriptHook) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.hooks = append(tr.hooks, hook)
+}
+
+// HandleTranscript passes a transcript to the hooks.
+func (tr *Transcriber) HandleTranscript(t Transcript) {
+	if t.Time.IsZero() {
+		t.Time = time.Now()
+	}
+	tr.mu.RLock()
+	hooks := tr.hooks
+	tr.mu.RUnlock()
+	for _, hook := range hooks {
+		hook.OnTranscript(t)
+	}
+}
+
+// KeywordSpotter is a TranscriptHook calling OnMatch when a phrase is spoken, e.g. to alert a
+// supervisor on "cancel my account". Matching ignores case and punctuation and respects word
+// boundaries. A phrase fires once per utterance, however many interim transcripts repeat it.
+type KeywordSpotter struct {
+	OnMatch func(t Transcript, phrase string)
+
+	phrases []string
+	mu      sync.Mutex
+	fired   map[string]map[string]bool // channel ID -> normalized phrases of the current utterance
+}
+
+// NewKeywordSpotter creates a spotter of phrases.
+func NewKeywordSpotter(onMatch func(t Transcript, phrase string), phrases ...string) *KeywordSpotter {
+	return &KeywordSpotter{OnMatch: onMatch, phrases: phrases, fired: make(map[string]map[string]bool)}
+}
+
+// OnTranscript looks for the phrases in t.
+func (k *KeywordSpotter) OnTranscript(t Transcript) {
+	text := " " + normalizeTranscript(t.Text) + " "
+	var matched []string
+	k.mu.Lock()
+	fired := k.fired[t.ChannelId]
+	for _, phrase := range k.phrases {
+		p := normalizeTranscript(phrase)
+		if p == "" || fired[p] || !strings.Contains(text, " "+p+" ") {
+			continue
+		}
+		if fired == nil {
+			fired = make(map[string]bool)
+			k.fired[t.ChannelId] = fired
+		}
+		fired[p] = true
+		matched = append(matched, phrase)
+	}
+	if t.Final {
+		delete(k.fired, t.ChannelId)
+	}
+	k.mu.Unlock()
+	for _, phrase := range matched {
+		k.OnMatch(t, phrase)
+	}
+}
+
+// Forget drops the state of a finished call whose last utterance was not final.
+func (k *KeywordSpotter) Forget(channelId string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	delete(k.fired, channelId)
+}
+
+// normalizeTranscript lowercases text and reduces punctuation and spacing to single spaces.
+func normalizeTranscript(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	}), " ")
+}
+
+// SentimentScorer rates text from -1, very negative, to 1, very positive.
+type SentimentScorer func(text string) float64
+
+// SentimentMonitor is a TranscriptHook tracking the sentiment of final transcripts per channel
+// as an exponential moving average, calling OnNegative when it drops below Threshold.
+type SentimentMonitor struct {
+	Score SentimentScorer
+	// Threshold below which OnNegative is called, once until the average recovers.
+	Threshold float64
+	// Smoothing is the weight of the latest utterance in the average, 0.3 if 0.
+	Smoothing  float64
+	OnNegative func(t Transcript, average float64)
+
+	mu      sync.Mutex
+	average map[string]float64
+	alerted map[string]bool
+}
+
+// NewSentimentMonitor creates a monitor alerting below threshold.
+func NewSentimentMonitor(score SentimentScorer, threshold float64, onNegative func(t Transcript, average float64)) *SentimentMonitor {
+	return &SentimentMonitor{
+		Score:      score,
+		Threshold:  threshold,
+		OnNegative: onNegative,
+		average:    make(map[string]float64),
+		alerted:    make(map[string]bool),
+	}
+}
+
+// OnTranscript scores final transcripts; interim ones are ignored.
+func (s *SentimentMonitor) OnTranscript(t Transcript) {
+	if !t.Final {
+		return
+	}
+	score := s.Score(t.Text)
+	alpha := s.Smoothing
+	if alpha == 0 {
+		alpha = 0.3
+	}
+	s.mu.Lock()
+	avg, seen := s.average[t.ChannelId]
+	if seen {
+		avg = alpha*score + (1-alpha)*avg
+	} else {
+		avg = score
+	}
+	s.average[t.ChannelId] = avg
+	alert := avg < s.Threshold && !s.alerted[t.ChannelId]
+	s.alerted[t.ChannelId] = avg < s.Threshold
+	s.mu.Unlock()
+	if alert {
+		s.OnNegative(t, avg)
+	}
+}
+
+// Sentiment returns the current average of channelId.
+func (s *SentimentMonitor) Sentiment(channelId string) (float64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	avg, ok := s.average[channelId]
+	return avg, ok
+}
+
+// Forget drops the state of a finished call.
+func (s *SentimentMonitor) Forget(channelId string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.average, channelId)
+	delete(s.alerted, channelId)
+}
@@ -0,0 +1,391 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	mathrand "math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antihax/optional"
+)
+
+// TrunkVariable is the channel variable set on originated channels with the name of the trunk used.
+const TrunkVariable = "ARI_TRUNK"
+
+// ErrAllTrunksFailed is returned by TrunkRouter.Originate when no trunk could complete the call.
+var ErrAllTrunksFailed = errors.New("all trunks failed")
+
+// ErrNoFailover is returned by TrunkRouter.Originate when an attempt failed for a reason
+// that another trunk would not fix (busy, no answer, rejected).
+var ErrNoFailover = errors.New("call failed without failover")
+
+// Trunk describes an outbound route to a carrier.
+type Trunk struct {
+	// Name identifies the trunk in results and logs.
+	Name string
+	// Endpoint is the dial string template. "%s" is replaced with the destination number,
+	// e.g. "PJSIP/%s@carrier-a". Without "%s" the number is appended after a slash.
+	Endpoint string
+	// Weight is the relative share of first attempts when TrunkSelectionWeighted is used.
+	Weight int
+	// Strip is removed from the start of the number before dialing, e.g. "+1" or "+".
+	Strip string
+	// Prefix is prepended to the number after Strip was applied, e.g. "011".
+	Prefix string
+}
+
+// dialString builds the endpoint for the given destination number.
+func (t Trunk) dialString(number string) string {
+	number = t.Prefix + strings.TrimPrefix(number, t.Strip)
+	if strings.Contains(t.Endpoint, "%s") {
+		return fmt.Sprintf(t.Endpoint, number)
+	}
+	return strings.TrimSuffix(t.Endpoint, "/") + "/" + number
+}
+
+// TrunkSelection controls the order in which trunks are attempted.
+type TrunkSelection int
+
+const (
+	// TrunkSelectionOrdered attempts trunks in the order they were configured.
+	TrunkSelectionOrdered TrunkSelection = iota
+	// TrunkSelectionWeighted shuffles trunks proportionally to their weight for every call.
+	TrunkSelectionWeighted
+)
+
+// TrunkRouteOpts holds the optional parameters of TrunkRouter.Originate.
+type TrunkRouteOpts struct {
+	// App is the Stasis application that receives the answered channel. Required.
+	App string
+	// AppArgs are passed to the Stasis application.
+	AppArgs string
+	// CallerId to present on the outbound call.
+	CallerId string
+	// Timeout is the ring timeout in seconds for each attempt.
+	Timeout int32
+	// SetupTimeout and RingTimeout bound the setup and the ringing of each attempt, see
+	// OriginateTimeouts. A setup timeout fails over to the next trunk, a ring timeout does not.
+	SetupTimeout time.Duration
+	RingTimeout  time.Duration
+	// Variables are set on every originated channel.
+	Variables map[string]string
+	// Selection overrides the router's trunk ordering for this call.
+	Selection TrunkSelection
+	// Trunks overrides the router's trunk list for this call.
+	Trunks []Trunk
+}
+
+// TrunkAttempt records the outcome of a single trunk attempt.
+type TrunkAttempt struct {
+	Trunk      string
+	ChannelId  string
+	Dialstatus string
+	Cause      int32
+	CauseTxt   string
+	// Err is set when the originate request itself failed, or with an *OriginateTimeoutError
+	// when a phase of the attempt timed out.
+	Err error
+}
+
+// TrunkRouteResult describes a routed call.
+type TrunkRouteResult struct {
+	// Trunk is the name of the trunk that completed the call. Empty if the call failed.
+	Trunk string
+	// Channel is the answered channel.
+	Channel Channel
+	// Attempts lists every attempt in order, including the successful one.
+	Attempts []TrunkAttempt
+}
+
+// trunkFailoverCauses are hangup causes that indicate a trunk or network problem
+// rather than a problem with the called party.
+var trunkFailoverCauses = map[int32]bool{
+	2:   true, // no route to specified transit network
+	3:   true, // no route to destination
+	27:  true, // destination out of order
+	34:  true, // no circuit/channel available
+	38:  true, // network out of order
+	41:  true, // temporary failure
+	42:  true, // switching equipment congestion
+	44:  true, // requested channel not available
+	47:  true, // resource unavailable
+	58:  true, // bearer capability not available
+	63:  true, // service or option not available
+	66:  true, // channel type not implemented
+	79:  true, // service or option not implemented
+	102: true, // recovery on timer expiry
+	111: true, // protocol error
+	127: true, // interworking
+}
+
+// DefaultTrunkFailover reports whether a failed attempt should be retried on the next trunk.
+// Request errors, setup timeouts, CHANUNAVAIL/CONGESTION dial statuses and network-related hangup
+// causes fail over; busy, unanswered and rejected calls do not.
+func DefaultTrunkFailover(attempt TrunkAttempt) bool {
+	if attempt.Err != nil {
+		return !errors.Is(attempt.Err, ErrRingTimeout)
+	}
+	switch attempt.Dialstatus {
+	case "CHANUNAVAIL", "CONGESTION":
+		return true
+	case "BUSY", "NOANSWER", "CANCEL", "DONTCALL", "TORTURE":
+		return false
+	}
+	return trunkFailoverCauses[attempt.Cause]
+}
+
+// TrunkRouter originates calls over a prioritized list of trunks, failing over to the next
+// trunk when an attempt fails for a trunk-related reason.
+//
+// The router learns about call progress from events, so every event received on the
+// websocket of the application passed in TrunkRouteOpts.App must be fed to HandleEvent.
+type TrunkRouter struct {
+	client *APIClient
+	trunks []Trunk
+
+	// Failover decides whether a failed attempt is retried on the next trunk.
+	// Defaults to DefaultTrunkFailover.
+	Failover func(TrunkAttempt) bool
+	// Limiter, if set, skips trunks that reached their TrunkLimitKey limit.
+	// Answered calls hold their slot until the limiter sees the channel destroyed.
+	Limiter *CallLimiter
+	// Pacer, if set, throttles attempts per trunk using TrunkLimitKey as the pacing key.
+	Pacer *OriginatePacer
+
+	mu      sync.Mutex
+	waiters map[string]chan StasisEvent
+	rnd     *mathrand.Rand
+}
+
+// NewTrunkRouter creates a router using the given trunks in priority order.
+func NewTrunkRouter(client *APIClient, trunks []Trunk) *TrunkRouter {
+	return &TrunkRouter{
+		client:   client,
+		trunks:   trunks,
+		Failover: DefaultTrunkFailover,
+		waiters:  make(map[string]chan StasisEvent),
+		rnd:      mathrand.New(mathrand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// HandleEvent feeds an event received from Asterisk into the router.
+func (r *TrunkRouter) HandleEvent(ev StasisEvent) {
+	id := ev.Channel.Id
+	if ev.Type == "Dial" && ev.Peer != nil {
+		id = ev.Peer.Id
+	}
+	if id == "" {
+		return
+	}
+
+	r.mu.Lock()
+	ch, ok := r.waiters[id]
+	r.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	select {
+	case ch <- ev:
+	default:
+		r.client.logger.Warnf("trunk router: dropping %s event for channel %s, waiter is busy", ev.Type, id)
+	}
+}
+
+// Originate calls number over the configured trunks until one of them answers.
+// It returns ErrNoFailover if an attempt failed for a reason that must not be retried
+// and ErrAllTrunksFailed if every trunk was exhausted. The result always lists the attempts made.
+func (r *TrunkRouter) Originate(ctx context.Context, number string, opts *TrunkRouteOpts) (TrunkRouteResult, error) {
+	var result TrunkRouteResult
+	if opts == nil || opts.App == "" {
+		return result, errors.New("trunk router: App is required")
+	}
+
+	trunks := opts.Trunks
+	if trunks == nil {
+		trunks = r.trunks
+	}
+	if len(trunks) == 0 {
+		return result, errors.New("trunk router: no trunks configured")
+	}
+	if opts.Selection == TrunkSelectionWeighted {
+		trunks = r.weightedOrder(trunks)
+	}
+
+	failover := r.Failover
+	if failover == nil {
+		failover = DefaultTrunkFailover
+	}
+
+	for _, trunk := range trunks {
+		channel, attempt, err := r.attempt(ctx, trunk, number, opts)
+		result.Attempts = append(result.Attempts, attempt)
+		if err != nil {
+			return result, err
+		}
+		if attempt.Err == nil && attempt.Cause == 0 && attempt.Dialstatus == "ANSWER" {
+			result.Trunk = trunk.Name
+			result.Channel = channel
+			return result, nil
+		}
+
+		r.client.logger.Debugf("trunk router: attempt on trunk %s failed: dialstatus=%s cause=%d err=%v",
+			trunk.Name, attempt.Dialstatus, attempt.Cause, attempt.Err)
+		if !failover(attempt) {
+			return result, ErrNoFailover
+		}
+	}
+
+	return result, ErrAllTrunksFailed
+}
+
+// attempt originates a single call over trunk and waits for it to be answered or to fail.
+// The returned error is only set when ctx is done.
+func (r *TrunkRouter) attempt(ctx context.Context, trunk Trunk, number string, opts *TrunkRouteOpts) (Channel, TrunkAttempt, error) {
+	channelId := newResourceId("trunk")
+	attempt := TrunkAttempt{Trunk: trunk.Name, ChannelId: channelId}
+
+	events := make(chan StasisEvent, 8)
+	r.mu.Lock()
+	r.waiters[channelId] = events
+	r.mu.Unlock()
+	r.client.TrackResource(ResourceSubscription, "trunk_router", 1)
+	defer func() {
+		r.mu.Lock()
+		delete(r.waiters, channelId)
+		r.mu.Unlock()
+		r.client.TrackResource(ResourceSubscription, "trunk_router", -1)
+	}()
+
+	variables := map[string]string{TrunkVariable: trunk.Name}
+	for k, v := range opts.Variables {
+		variables[k] = v
+	}
+	originateOpts := &ChannelsApiOriginateWithIdOpts{
+		App:       optional.NewString(opts.App),
+		Variables: optional.NewInterface(Containers{Variables: variables}),
+	}
+	if opts.AppArgs != "" {
+		originateOpts.AppArgs = optional.NewString(opts.AppArgs)
+	}
+	if opts.CallerId != "" {
+		originateOpts.CallerId = optional.NewString(opts.CallerId)
+	}
+	if opts.Timeout != 0 {
+		originateOpts.Timeout = optional.NewInt32(opts.Timeout)
+	}
+
+	if r.Pacer != nil {
+		if err := r.Pacer.Wait(ctx, TrunkLimitKey(trunk.Name)); err != nil {
+			return Channel{}, attempt, err
+		}
+	}
+	if r.Limiter != nil {
+		if err := r.Limiter.TryAcquire(channelId, TrunkLimitKey(trunk.Name)); err != nil {
+			attempt.Err = err
+			return Channel{}, attempt, nil
+		}
+	}
+	answered := false
+	defer func() {
+		if r.Limiter != nil && !answered {
+			r.Limiter.Release(channelId)
+		}
+	}()
+
+	r.client.markOriginated(channelId)
+	channel, resp, err := r.client.ChannelsApi.OriginateWithId(ctx, channelId, trunk.dialString(number), originateOpts)
+	if err != nil {
+		r.client.originateFailed(channelId, resp)
+		if ctx.Err() != nil {
+			return channel, attempt, ctx.Err()
+		}
+		attempt.Err = err
+		return channel, attempt, nil
+	}
+
+	timer := newPhaseTimer(r.client.clock(), OriginateTimeouts{Setup: opts.SetupTimeout, Ringing: opts.RingTimeout})
+	defer timer.stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// Do not leave the attempt ringing after the caller gave up.
+			r.client.ChannelsApi.Hangup(context.Background(), channelId, nil)
+			return channel, attempt, ctx.Err()
+		case <-timer.C():
+			r.client.ChannelsApi.Hangup(context.Background(), channelId, nil)
+			r.client.metrics().IncCounter("ari_originate_timeouts_total", map[string]string{"phase": timer.phase}, 1)
+			attempt.Err = &OriginateTimeoutError{Phase: timer.phase, ChannelId: channelId, Timeout: timer.current()}
+			if timer.phase == PhaseRinging {
+				attempt.Dialstatus = "NOANSWER"
+			}
+			return channel, attempt, nil
+		case ev := <-events:
+			if timer.phase == PhaseSetup && callProgressed(ev) {
+				timer.enter(PhaseRinging)
+			}
+			switch ev.Type {
+			case "StasisStart":
+				attempt.Dialstatus = "ANSWER"
+				answered = true
+				return ev.Channel, attempt, nil
+			case "Dial":
+				attempt.Dialstatus = ev.Dialstatus
+			case "ChannelDestroyed":
+				attempt.Cause = ev.Cause
+				attempt.CauseTxt = ev.CauseTxt
+				if attempt.Dialstatus == "ANSWER" {
+					attempt.Dialstatus = ""
+				}
+				return channel, attempt, nil
+			}
+		}
+	}
+}
+
+// weightedOrder returns the trunks shuffled proportionally to their weights.
+// Trunks without weight keep their relative order after the weighted ones.
+func (r *TrunkRouter) weightedOrder(trunks []Trunk) []Trunk {
+	var weighted, rest []Trunk
+	total := 0
+	for _, t := range trunks {
+		if t.Weight > 0 {
+			weighted = append(weighted, t)
+			total += t.Weight
+		} else {
+			rest = append(rest, t)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ordered := make([]Trunk, 0, len(trunks))
+	for len(weighted) > 0 {
+		n := r.rnd.Intn(total)
+		for i, t := range weighted {
+			if n < t.Weight {
+				ordered = append(ordered, t)
+				total -= t.Weight
+				weighted = append(weighted[:i], weighted[i+1:]...)
+				break
+			}
+			n -= t.Weight
+		}
+	}
+	return append(ordered, rest...)
+}
+
+// newResourceId returns a random identifier for a client-created resource, prefixed with prefix.
+func newResourceId(prefix string) string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
+	}
+	return prefix + "-" + hex.EncodeToString(b)
+}
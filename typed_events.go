@@ -0,0 +1,208 @@
+package asterisk_ari_go
+
+// TypedEvent is an event with a static Go type, used as the type parameter of Subscribe. Every
+// typed event embeds the StasisEvent it was received as; StasisEvent itself stands for any event.
+type TypedEvent interface {
+	// EventType returns the ARI type of the event, or "" for StasisEvent.
+	EventType() string
+	fromStasis(ev StasisEvent) TypedEvent
+}
+
+// EventType returns "": a subscription to StasisEvent receives every event.
+func (StasisEvent) EventType() string { return "" }
+
+func (StasisEvent) fromStasis(ev StasisEvent) TypedEvent { return ev }
+
+// StasisStartEvent is a StasisStart event.
+type StasisStartEvent struct{ StasisEvent }
+
+// EventType returns "StasisStart".
+func (StasisStartEvent) EventType() string { return "StasisStart" }
+
+func (StasisStartEvent) fromStasis(ev StasisEvent) TypedEvent { return StasisStartEvent{ev} }
+
+// StasisEndEvent is a StasisEnd event.
+type StasisEndEvent struct{ StasisEvent }
+
+// EventType returns "StasisEnd".
+func (StasisEndEvent) EventType() string { return "StasisEnd" }
+
+func (StasisEndEvent) fromStasis(ev StasisEvent) TypedEvent { return StasisEndEvent{ev} }
+
+// ChannelCreatedEvent is a ChannelCreated event.
+type ChannelCreatedEvent struct{ StasisEvent }
+
+// EventType returns "ChannelCreated".
+func (ChannelCreatedEvent) EventType() string { return "ChannelCreated" }
+
+func (ChannelCreatedEvent) fromStasis(ev StasisEvent) TypedEvent { return ChannelCreatedEvent{ev} }
+
+// ChannelStateChangeEvent is a ChannelStateChange event.
+type ChannelStateChangeEvent struct{ StasisEvent }
+
+// EventType returns "ChannelStateChange".
+func (ChannelStateChangeEvent) EventType() string { return "ChannelStateChange" }
+
+func (ChannelStateChangeEvent) fromStasis(ev StasisEvent) TypedEvent {
+	return ChannelStateChangeEvent{ev}
+}
+
+// ChannelDtmfReceivedEvent is a ChannelDtmfReceived event.
+type ChannelDtmfReceivedEvent struct{ StasisEvent }
+
+// EventType returns "ChannelDtmfReceived".
+func (ChannelDtmfReceivedEvent) EventType() string { return "ChannelDtmfReceived" }
+
+func (ChannelDtmfReceivedEvent) fromStasis(ev StasisEvent) TypedEvent {
+	return ChannelDtmfReceivedEvent{ev}
+}
+
+// ChannelHangupRequestEvent is a ChannelHangupRequest event.
+type ChannelHangupRequestEvent struct{ StasisEvent }
+
+// EventType returns "ChannelHangupRequest".
+func (ChannelHangupRequestEvent) EventType() string { return "ChannelHangupRequest" }
+
+func (ChannelHangupRequestEvent) fromStasis(ev StasisEvent) TypedEvent {
+	return ChannelHangupRequestEvent{ev}
+}
+
+// ChannelDestroyedEvent is a ChannelDestroyed event.
+type ChannelDestroyedEvent struct{ StasisEvent }
+
+// EventType returns "ChannelDestroyed".
+func (ChannelDestroyedEvent) EventType() string { return "ChannelDestroyed" }
+
+func (ChannelDestroyedEvent) fromStasis(ev StasisEvent) TypedEvent { return ChannelDestroyedEvent{ev} }
+
+// ChannelVarsetEvent is a ChannelVarset event.
+type ChannelVarsetEvent struct{ StasisEvent }
+
+// EventType returns "ChannelVarset".
+func (ChannelVarsetEvent) EventType() string { return "ChannelVarset" }
+
+func (ChannelVarsetEvent) fromStasis(ev StasisEvent) TypedEvent { return ChannelVarsetEvent{ev} }
+
+// ChannelUsereventEvent is a ChannelUserevent event.
+type ChannelUsereventEvent struct{ StasisEvent }
+
+// EventType returns "ChannelUserevent".
+func (ChannelUsereventEvent) EventType() string { return "ChannelUserevent" }
+
+func (ChannelUsereventEvent) fromStasis(ev StasisEvent) TypedEvent { return ChannelUsereventEvent{ev} }
+
+// ChannelEnteredBridgeEvent is a ChannelEnteredBridge event.
+type ChannelEnteredBridgeEvent struct{ StasisEvent }
+
+// EventType returns "ChannelEnteredBridge".
+func (ChannelEnteredBridgeEvent) EventType() string { return "ChannelEnteredBridge" }
+
+func (ChannelEnteredBridgeEvent) fromStasis(ev StasisEvent) TypedEvent {
+	return ChannelEnteredBridgeEvent{ev}
+}
+
+// ChannelLeftBridgeEvent is a ChannelLeftBridge event.
+type ChannelLeftBridgeEvent struct{ StasisEvent }
+
+// EventType returns "ChannelLeftBridge".
+func (ChannelLeftBridgeEvent) EventType() string { return "ChannelLeftBridge" }
+
+func (ChannelLeftBridgeEvent) fromStasis(ev StasisEvent) TypedEvent {
+	return ChannelLeftBridgeEvent{ev}
+}
+
+// ChannelHoldEvent is a ChannelHold event.
+type ChannelHoldEvent struct{ StasisEvent }
+
+// EventType returns "ChannelHold".
+func (ChannelHoldEvent) EventType() string { return "ChannelHold" }
+
+func (ChannelHoldEvent) fromStasis(ev StasisEvent) TypedEvent { return ChannelHoldEvent{ev} }
+
+// ChannelUnholdEvent is a ChannelUnhold event.
+type ChannelUnholdEvent struct{ StasisEvent }
+
+// EventType returns "ChannelUnhold".
+func (ChannelUnholdEvent) EventType() string { return "ChannelUnhold" }
+
+func (ChannelUnholdEvent) fromStasis(ev StasisEvent) TypedEvent { return ChannelUnholdEvent{ev} }
+
+// ChannelTalkingStartedEvent is a ChannelTalkingStarted event.
+type ChannelTalkingStartedEvent struct{ StasisEvent }
+
+// EventType returns "ChannelTalkingStarted".
+func (ChannelTalkingStartedEvent) EventType() string { return "ChannelTalkingStarted" }
+
+func (ChannelTalkingStartedEvent) fromStasis(ev StasisEvent) TypedEvent {
+	return ChannelTalkingStartedEvent{ev}
+}
+
+// ChannelTalkingFinishedEvent is a ChannelTalkingFinished event.
+type ChannelTalkingFinishedEvent struct{ StasisEvent }
+
+// EventType returns "ChannelTalkingFinished".
+func (ChannelTalkingFinishedEvent) EventType() string { return "ChannelTalkingFinished" }
+
+func (ChannelTalkingFinishedEvent) fromStasis(ev StasisEvent) TypedEvent {
+	return ChannelTalkingFinishedEvent{ev}
+}
+
+// DialEvent is a Dial event.
+type DialEvent struct{ StasisEvent }
+
+// EventType returns "Dial".
+func (DialEvent) EventType() string { return "Dial" }
+
+func (DialEvent) fromStasis(ev StasisEvent) TypedEvent { return DialEvent{ev} }
+
+// PlaybackStartedEvent is a PlaybackStarted event.
+type PlaybackStartedEvent struct{ StasisEvent }
+
+// EventType returns "PlaybackStarted".
+func (PlaybackStartedEvent) EventType() string { return "PlaybackStarted" }
+
+func (PlaybackStartedEvent) fromStasis(ev StasisEvent) TypedEvent { return PlaybackStartedEvent{ev} }
+
+// PlaybackContinuingEvent is a PlaybackContinuing event.
+type PlaybackContinuingEvent struct{ StasisEvent }
+
+// EventType returns "PlaybackContinuing".
+func (PlaybackContinuingEvent) EventType() string { return "PlaybackContinuing" }
+
+func (PlaybackContinuingEvent) fromStasis(ev StasisEvent) TypedEvent {
+	return PlaybackContinuingEvent{ev}
+}
+
+// PlaybackFinishedEvent is a PlaybackFinished event.
+type PlaybackFinishedEvent struct{ StasisEvent }
+
+// EventType returns "PlaybackFinished".
+func (PlaybackFinishedEvent) EventType() string { return "PlaybackFinished" }
+
+func (PlaybackFinishedEvent) fromStasis(ev StasisEvent) TypedEvent { return PlaybackFinishedEvent{ev} }
+
+// RecordingStartedEvent is a RecordingStarted event.
+type RecordingStartedEvent struct{ StasisEvent }
+
+// EventType returns "RecordingStarted".
+func (RecordingStartedEvent) EventType() string { return "RecordingStarted" }
+
+func (RecordingStartedEvent) fromStasis(ev StasisEvent) TypedEvent { return RecordingStartedEvent{ev} }
+
+// RecordingFinishedEvent is a RecordingFinished event.
+type RecordingFinishedEvent struct{ StasisEvent }
+
+// EventType returns "RecordingFinished".
+func (RecordingFinishedEvent) EventType() string { return "RecordingFinished" }
+
+func (RecordingFinishedEvent) fromStasis(ev StasisEvent) TypedEvent {
+	return RecordingFinishedEvent{ev}
+}
+
+// RecordingFailedEvent is a RecordingFailed event.
+type RecordingFailedEvent struct{ StasisEvent }
+
+// EventType returns "RecordingFailed".
+func (RecordingFailedEvent) EventType() string { return "RecordingFailed" }
+
+func (RecordingFailedEvent) fromStasis(ev StasisEvent) TypedEvent { return RecordingFailedEvent{ev} }
@@ -0,0 +1,78 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+)
+
+// NotifyRejected is a one-time code the callee failed to echo back, see CallCode.
+const NotifyRejected NotifyStatus = "rejected"
+
+// VoiceCodeOpts configures CallCode.
+type VoiceCodeOpts struct {
+	// Call configures the call: App is required; MachineDetection and LeaveVoicemail apply, Ack,
+	// FanOut and TextToSpeech do not.
+	Call NotifyOpts
+	// Intro is played before the code, e.g. "your verification code is".
+	Intro []string
+	// Repeats is how many times the code is said. Defaults to 2.
+	Repeats int
+	// Confirm requires the callee to enter the code back with DTMF. The prompts and the number of
+	// attempts are those of PIN, see AuthenticatePIN; the length defaults to the length of the
+	// code.
+	Confirm bool
+	PIN     *PINOpts
+}
+
+// CallCode calls destination and says code, a one-time code of digits, with SayDigits. With
+// opts.Confirm, the callee must then enter the code back: the result is NotifyAcknowledged when
+// they do and NotifyRejected when they fail, otherwise NotifyDelivered once the code is said. The
+// code is never logged, and redacted from the media of the playbacks in the audit log.
+func (w *Waits) CallCode(ctx context.Context, destination string, code string, opts *VoiceCodeOpts) (NotifyResult, error) {
+	if opts == nil || opts.Call.App == "" {
+		return NotifyResult{}, errors.New("voice code: App is required")
+	}
+	if code == "" || !digitsOnly.MatchString(code) {
+		return NotifyResult{}, errors.New("voice code: the code must be digits")
+	}
+	repeats := opts.Repeats
+	if repeats <= 0 {
+		repeats = 2
+	}
+	media := append([]string(nil), opts.Intro...)
+	for i := 0; i < repeats; i++ {
+		media = append(media, SayDigits(code))
+	}
+
+	r := w.notifyCall(WithAuditRedacted(ctx, "media"), destination, media, &opts.Call, func(ctx context.Context, r *NotifyResult) error {
+		if _, err := w.PlayAndWait(ctx, r.ChannelId, media, nil); err != nil {
+			return err
+		}
+		if !opts.Confirm {
+			r.Status = NotifyDelivered
+			return nil
+		}
+		pinOpts := PINOpts{}
+		if opts.PIN != nil {
+			pinOpts = *opts.PIN
+		}
+		if pinOpts.Length == 0 {
+			pinOpts.Length = len(code)
+		}
+		err := w.AuthenticatePIN(ctx, r.ChannelId, func(ctx context.Context, entered string) (bool, error) {
+			return subtle.ConstantTimeCompare([]byte(entered), []byte(code)) == 1, nil
+		}, &pinOpts)
+		switch {
+		case err == nil:
+			r.Status = NotifyAcknowledged
+		case errors.Is(err, ErrPINRejected) || errors.Is(err, ErrPINLockedOut):
+			r.Status, r.Err = NotifyRejected, err
+		default:
+			return err
+		}
+		return nil
+	})
+	w.client.metrics().IncCounter("ari_voice_code_results_total", map[string]string{"status": string(r.Status)}, 1)
+	return r, ctx.Err()
+}
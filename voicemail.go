@@ -0,0 +1,242 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/antihax/optional"
+)
+
+// Voicemail folders.
+const (
+	VoicemailInbox = "INBOX"
+	VoicemailOld   = "Old"
+)
+
+// Greeting kinds.
+const (
+	GreetingUnavailable = "unavail"
+	GreetingBusy        = "busy"
+	GreetingName        = "greet"
+)
+
+// VoicemailSounds are the prompts played by the voicemail blocks.
+type VoicemailSounds struct {
+	// DefaultGreeting is played when a mailbox has no recorded greeting.
+	DefaultGreeting string
+	// ReviewMenu offers 1 to listen, 2 to record again, 3 to save.
+	ReviewMenu string
+	Saved      string
+	Invalid    string
+}
+
+// DefaultVoicemailSounds uses the prompts shipped with Asterisk.
+var DefaultVoicemailSounds = VoicemailSounds{
+	DefaultGreeting: "sound:vm-intro",
+	ReviewMenu:      "sound:vm-review",
+	Saved:           "sound:vm-msgsaved",
+	Invalid:         "sound:vm-sorry",
+}
+
+// Voicemail provides the building blocks of a custom voicemail: greetings, recording with review,
+// message storage per mailbox and folder, and message waiting indication through MailboxesApi.
+//
+// Greetings and messages are stored recordings named "<Prefix>-<mailbox>-<folder>-<id>", greetings
+// using the greeting kind as folder. The waits must receive every event of the application.
+type Voicemail struct {
+	client *APIClient
+	waits  *Waits
+
+	// Prefix of the stored recordings. Defaults to "vm".
+	Prefix string
+	// Format of the recordings. Defaults to "wav".
+	Format string
+	// MaxMessageSeconds and MaxSilenceSeconds bound message recordings.
+	MaxMessageSeconds int32
+	MaxSilenceSeconds int32
+	Sounds            VoicemailSounds
+}
+
+// NewVoicemail creates the voicemail blocks.
+func NewVoicemail(client *APIClient, waits *Waits) *Voicemail {
+	return &Voicemail{
+		client:            client,
+		waits:             waits,
+		Prefix:            "vm",
+		Format:            "wav",
+		MaxMessageSeconds: 180,
+		MaxSilenceSeconds: 5,
+		Sounds:            DefaultVoicemailSounds,
+	}
+}
+
+// folderPrefix is the name prefix of the recordings of mailbox in folder.
+func (v *Voicemail) folderPrefix(mailbox string, folder string) string {
+	return v.Prefix + "-" + mailbox + "-" + folder + "-"
+}
+
+// ParseName splits the name of a stored recording made by the voicemail into mailbox and folder.
+// ok is false for other recordings.
+func (v *Voicemail) ParseName(name string) (mailbox string, folder string, ok bool) {
+	if !strings.HasPrefix(name, v.Prefix+"-") {
+		return "", "", false
+	}
+	parts := strings.Split(strings.TrimPrefix(name, v.Prefix+"-"), "-")
+	if len(parts) < 3 {
+		return "", "", false
+	}
+	n := len(parts)
+	return strings.Join(parts[:n-2], "-"), parts[n-2], true
+}
+
+// GreetingName returns the stored recording name of a greeting of mailbox.
+func (v *Voicemail) GreetingName(mailbox string, kind string) string {
+	return v.folderPrefix(mailbox, kind) + "0"
+}
+
+// PlayGreeting plays the greeting of mailbox, falling back to Sounds.DefaultGreeting.
+func (v *Voicemail) PlayGreeting(ctx context.Context, channelId string, mailbox string, kind string) error {
+	media := v.Sounds.DefaultGreeting
+	name := v.GreetingName(mailbox, kind)
+	if _, resp, err := v.client.RecordingsApi.GetStored(ctx, name); err == nil {
+		media = "recording:" + name
+	} else if resp == nil || resp.StatusCode != http.StatusNotFound {
+		return err
+	}
+	_, err := v.waits.PlayAndWait(ctx, channelId, []string{media}, nil)
+	return err
+}
+
+// RecordGreeting records a greeting of mailbox with review.
+func (v *Voicemail) RecordGreeting(ctx context.Context, channelId string, mailbox string, kind string) (StoredRecording, error) {
+	return v.RecordWithReview(ctx, channelId, v.GreetingName(mailbox, kind))
+}
+
+// RecordWithReview records the caller, then lets them listen (1), record again (2) or save (3).
+// The recording is stored as name once saved.
+func (v *Voicemail) RecordWithReview(ctx context.Context, channelId string, name string) (StoredRecording, error) {
+	draft := newResourceId(v.Prefix + "-draft")
+	defer v.client.RecordingsApi.DeleteStored(context.Background(), draft)
+
+	record := true
+	for {
+		if record {
+			if _, err := v.record(ctx, channelId, draft); err != nil {
+				return StoredRecording{}, err
+			}
+			record = false
+		}
+
+		choice, err := v.waits.CollectDigits(ctx, channelId, &CollectOpts{Max: 1, Prompt: []string{v.Sounds.ReviewMenu}})
+		if err != nil && !errors.Is(err, ErrNoInput) {
+			return StoredRecording{}, err
+		}
+		switch choice {
+		case "1":
+			if _, err := v.waits.PlayAndWait(ctx, channelId, []string{"recording:" + draft}, nil); err != nil {
+				return StoredRecording{}, err
+			}
+		case "2":
+			record = true
+		case "3":
+			v.client.RecordingsApi.DeleteStored(ctx, name)
+			saved, _, err := v.client.RecordingsApi.CopyStored(ctx, draft, name)
+			if err != nil {
+				return saved, err
+			}
+			_, err = v.waits.PlayAndWait(ctx, channelId, []string{v.Sounds.Saved}, nil)
+			return saved, err
+		default:
+			if _, err := v.waits.PlayAndWait(ctx, channelId, []string{v.Sounds.Invalid}, nil); err != nil {
+				return StoredRecording{}, err
+			}
+		}
+	}
+}
+
+// LeaveMessage plays the unavailable greeting of mailbox, records a message into its inbox and
+// updates the message waiting indication.
+func (v *Voicemail) LeaveMessage(ctx context.Context, channelId string, mailbox string) (StoredRecording, error) {
+	if err := v.PlayGreeting(ctx, channelId, mailbox, GreetingUnavailable); err != nil {
+		return StoredRecording{}, err
+	}
+	name := newResourceId(strings.TrimSuffix(v.folderPrefix(mailbox, VoicemailInbox), "-"))
+	recording, err := v.record(ctx, channelId, name)
+	// A caller hanging up ends the message, it is kept.
+	if err != nil && !errors.Is(err, ErrChannelGone) {
+		return StoredRecording{}, err
+	}
+	if err := v.UpdateMWI(context.Background(), mailbox); err != nil {
+		v.client.logger.Warnf("voicemail: updating MWI of %s: %v", mailbox, err)
+	}
+	return StoredRecording{Name: name, Format: recording.Format}, nil
+}
+
+// record records channelId under name with a beep, ending on silence, duration or "#".
+func (v *Voicemail) record(ctx context.Context, channelId string, name string) (LiveRecording, error) {
+	return v.waits.RecordAndWait(ctx, channelId, name, v.Format, &ChannelsApiRecordchannelOpts{
+		MaxDurationSeconds: optional.NewInt32(v.MaxMessageSeconds),
+		MaxSilenceSeconds:  optional.NewInt32(v.MaxSilenceSeconds),
+		IfExists:           optional.NewString("overwrite"),
+		Beep:               optional.NewBool(true),
+		TerminateOn:        optional.NewString("#"),
+	})
+}
+
+// Messages lists the messages of mailbox in folder.
+func (v *Voicemail) Messages(ctx context.Context, mailbox string, folder string) ([]StoredRecording, error) {
+	recordings, _, err := v.client.RecordingsApi.ListStored(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prefix := v.folderPrefix(mailbox, folder)
+	var messages []StoredRecording
+	for _, r := range recordings {
+		if strings.HasPrefix(r.Name, prefix) {
+			messages = append(messages, r)
+		}
+	}
+	return messages, nil
+}
+
+// MoveMessage moves a message of mailbox to folder, e.g. to VoicemailOld once listened to,
+// and updates the message waiting indication.
+func (v *Voicemail) MoveMessage(ctx context.Context, mailbox string, name string, folder string) (StoredRecording, error) {
+	i := strings.LastIndex(name, "-")
+	if i < 0 {
+		return StoredRecording{}, errors.New("voicemail: invalid message name " + name)
+	}
+	moved, _, err := v.client.RecordingsApi.CopyStored(ctx, name, v.folderPrefix(mailbox, folder)+name[i+1:])
+	if err != nil {
+		return moved, err
+	}
+	if _, err := v.client.RecordingsApi.DeleteStored(ctx, name); err != nil {
+		return moved, err
+	}
+	return moved, v.UpdateMWI(ctx, mailbox)
+}
+
+// DeleteMessage deletes a message of mailbox and updates the message waiting indication.
+func (v *Voicemail) DeleteMessage(ctx context.Context, mailbox string, name string) error {
+	if _, err := v.client.RecordingsApi.DeleteStored(ctx, name); err != nil {
+		return err
+	}
+	return v.UpdateMWI(ctx, mailbox)
+}
+
+// UpdateMWI publishes the number of new and old messages of mailbox, lighting the message
+// waiting lamp of the phones subscribed to it.
+func (v *Voicemail) UpdateMWI(ctx context.Context, mailbox string) error {
+	inbox, err := v.Messages(ctx, mailbox, VoicemailInbox)
+	if err != nil {
+		return err
+	}
+	old, err := v.Messages(ctx, mailbox, VoicemailOld)
+	if err != nil {
+		return err
+	}
+	_, err = v.client.MailboxesApi.Updatemailbox(ctx, mailbox, int32(len(old)), int32(len(inbox)))
+	return err
+}
@@ -0,0 +1,194 @@
+package asterisk_ari_go
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/smtp"
+	"net/textproto"
+	"time"
+)
+
+// VoicemailNotification describes a new voicemail message.
+type VoicemailNotification struct {
+	Mailbox      string    `json:"mailbox"`
+	Recording    string    `json:"recording"`
+	Format       string    `json:"format"`
+	ChannelId    string    `json:"channel_id"`
+	CallerNumber string    `json:"caller_number,omitempty"`
+	Received     time.Time `json:"received"`
+}
+
+// VoicemailEmail configures the delivery of voicemail messages by email.
+type VoicemailEmail struct {
+	// Addr is the SMTP server as host:port.
+	Addr string
+	// Auth, if set, authenticates to the server, e.g. smtp.PlainAuth.
+	Auth smtp.Auth
+	From string
+	// To returns the recipients of the messages of mailbox.
+	To func(mailbox string) ([]string, error)
+	// Subject is formatted with the mailbox and the caller number. Defaults to
+	// "New voicemail in mailbox %s from %s".
+	Subject string
+}
+
+// voicemailMessages runs deliver on every new message of the call, i.e. every recording of the job
+// in an inbox, with its audio.
+func (v *Voicemail) voicemailMessages(name string, deliver func(ctx context.Context, n VoicemailNotification, audio []byte) error) PostCallStage {
+	return RecordingStage(name, func(ctx context.Context, job *PostCallJob, recording string) error {
+		mailbox, folder, ok := v.ParseName(recording)
+		if !ok || folder != VoicemailInbox {
+			return nil
+		}
+		key := name + ":" + recording
+		if _, done := job.Get(key); done {
+			return nil
+		}
+		stored, _, err := v.client.RecordingsApi.GetStored(ctx, recording)
+		if err != nil {
+			return err
+		}
+		body, _, err := v.client.RecordingsApi.OpenStoredFile(ctx, recording)
+		if err != nil {
+			return err
+		}
+		audio, err := ioutil.ReadAll(body)
+		body.Close()
+		if err != nil {
+			return err
+		}
+		n := VoicemailNotification{
+			Mailbox:      mailbox,
+			Recording:    recording,
+			Format:       stored.Format,
+			ChannelId:    job.Record.ChannelId,
+			CallerNumber: job.Record.CallerNumber,
+			Received:     job.Record.End,
+		}
+		if err := deliver(ctx, n, audio); err != nil {
+			return err
+		}
+		job.Set(key, true)
+		return nil
+	})
+}
+
+// EmailStage returns a post-call stage emailing the new messages of the call with the recording
+// attached. The recordings of the call must be listed under PostCallRecordingsKey, e.g. by adding
+// the name returned by LeaveMessage to the data of the call.
+func (v *Voicemail) EmailStage(cfg VoicemailEmail) PostCallStage {
+	return v.voicemailMessages("voicemail_email", func(ctx context.Context, n VoicemailNotification, audio []byte) error {
+		to, err := cfg.To(n.Mailbox)
+		if err != nil {
+			return err
+		}
+		if len(to) == 0 {
+			return nil
+		}
+		msg, err := voicemailEmail(cfg, to, n, audio)
+		if err != nil {
+			return err
+		}
+		// net/smtp does not take a context: the stage Timeout only applies to the download.
+		return smtp.SendMail(cfg.Addr, cfg.Auth, cfg.From, to, msg)
+	})
+}
+
+// voicemailEmail builds the MIME message of a voicemail email.
+func voicemailEmail(cfg VoicemailEmail, to []string, n VoicemailNotification, audio []byte) ([]byte, error) {
+	subject := cfg.Subject
+	if subject == "" {
+		subject = "New voicemail in mailbox %s from %s"
+	}
+	caller := n.CallerNumber
+	if caller == "" {
+		caller = "unknown caller"
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "From: %s\r\n", cfg.From)
+	for _, rcpt := range to {
+		fmt.Fprintf(&buf, "To: %s\r\n", rcpt)
+	}
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", fmt.Sprintf(subject, n.Mailbox, caller)))
+	fmt.Fprintf(&buf, "Date: %s\r\n", n.Received.Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mw.Boundary())
+
+	text, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=utf-8"}})
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(text, "Mailbox: %s\r\nFrom: %s\r\nReceived: %s\r\n", n.Mailbox, caller, n.Received.Format(time.RFC1123))
+
+	contentType := mime.TypeByExtension("." + n.Format)
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	attachment, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {fmt.Sprintf("attachment; filename=%q", n.Recording+"."+n.Format)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	encoded := base64.StdEncoding.EncodeToString(audio)
+	for len(encoded) > 76 {
+		fmt.Fprintf(attachment, "%s\r\n", encoded[:76])
+		encoded = encoded[76:]
+	}
+	fmt.Fprintf(attachment, "%s\r\n", encoded)
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WebhookStage returns a post-call stage posting the new messages of the call to url as
+// multipart/form-data, with the VoicemailNotification as JSON in the "metadata" field and the
+// recording in the "audio" file. A nil client uses http.DefaultClient. See EmailStage for the
+// recordings of the call.
+func (v *Voicemail) WebhookStage(url string, header http.Header, client *http.Client) PostCallStage {
+	return v.voicemailMessages("voicemail_webhook", func(ctx context.Context, n VoicemailNotification, audio []byte) error {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		metadata, err := json.Marshal(n)
+		if err != nil {
+			return err
+		}
+		if err := mw.WriteField("metadata", string(metadata)); err != nil {
+			return err
+		}
+		file, err := mw.CreateFormFile("audio", n.Recording+"."+n.Format)
+		if err != nil {
+			return err
+		}
+		file.Write(audio)
+		if err := mw.Close(); err != nil {
+			return err
+		}
+
+		req, err := http.NewRequest(http.MethodPost, url, &body)
+		if err != nil {
+			return err
+		}
+		req = req.WithContext(ctx)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		if err := doUpload(client, req); err != nil {
+			return fmt.Errorf("voicemail webhook: %w", err)
+		}
+		return nil
+	})
+}
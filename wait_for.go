@@ -0,0 +1,127 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"sync"
+)
+
+// eventWaiters holds the predicates of pending WaitFor calls.
+type eventWaiters struct {
+	mu      sync.Mutex
+	next    int
+	pending map[int]*eventWaiter
+}
+
+type eventWaiter struct {
+	match func(StasisEvent) bool
+	found chan StasisEvent
+}
+
+// HandleEvent feeds an event received from Asterisk into the client, waking up the WaitFor calls
+// it matches, then calling the handlers registered with On.
+func (c *APIClient) HandleEvent(ev StasisEvent) {
+	c.activity.touch(c.clock().Now())
+	c.waiters.mu.Lock()
+	for id, w := range c.waiters.pending {
+		if w.match(ev) {
+			w.found <- ev
+			delete(c.waiters.pending, id)
+		}
+	}
+	c.waiters.mu.Unlock()
+	if ev.Type == "ChannelDestroyed" {
+		c.forgetOriginated(ev.Channel.Id)
+	}
+	c.dispatch(ev)
+}
+
+// WaitFor blocks until an event matching match is passed to HandleEvent, or ctx is done. Only
+// events received after the call are considered. MatchEvent builds common predicates.
+func (c *APIClient) WaitFor(ctx context.Context, match func(StasisEvent) bool) (StasisEvent, error) {
+	w := &eventWaiter{match: match, found: make(chan StasisEvent, 1)}
+	c.waiters.mu.Lock()
+	if c.waiters.pending == nil {
+		c.waiters.pending = make(map[int]*eventWaiter)
+	}
+	id := c.waiters.next
+	c.waiters.next++
+	c.waiters.pending[id] = w
+	c.waiters.mu.Unlock()
+	c.TrackResource(ResourceSubscription, "wait_for", 1)
+	defer c.TrackResource(ResourceSubscription, "wait_for", -1)
+
+	select {
+	case ev := <-w.found:
+		return ev, nil
+	case <-ctx.Done():
+		c.waiters.mu.Lock()
+		delete(c.waiters.pending, id)
+		c.waiters.mu.Unlock()
+		// The event may have matched while ctx was being cancelled.
+		select {
+		case ev := <-w.found:
+			return ev, nil
+		default:
+			return StasisEvent{}, ctx.Err()
+		}
+	}
+}
+
+// MatchBuilder builds an event predicate from criteria that must all hold.
+type MatchBuilder struct {
+	criteria []func(StasisEvent) bool
+}
+
+// MatchEvent starts a predicate matching every event.
+func MatchEvent() *MatchBuilder {
+	return &MatchBuilder{}
+}
+
+// Type restricts the match to the given event types.
+func (m *MatchBuilder) Type(types ...string) *MatchBuilder {
+	return m.Where(func(ev StasisEvent) bool {
+		for _, t := range types {
+			if ev.Type == t {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Channel restricts the match to events of channelId, including as dialed peer.
+func (m *MatchBuilder) Channel(channelId string) *MatchBuilder {
+	return m.Where(func(ev StasisEvent) bool {
+		return ev.Channel.Id == channelId || (ev.Peer != nil && ev.Peer.Id == channelId)
+	})
+}
+
+// Playback restricts the match to events of playbackId.
+func (m *MatchBuilder) Playback(playbackId string) *MatchBuilder {
+	return m.Where(func(ev StasisEvent) bool {
+		return ev.Playback != nil && ev.Playback.Id == playbackId
+	})
+}
+
+// Recording restricts the match to events of the recording called name.
+func (m *MatchBuilder) Recording(name string) *MatchBuilder {
+	return m.Where(func(ev StasisEvent) bool {
+		return ev.Recording != nil && ev.Recording.Name == name
+	})
+}
+
+// Where adds a custom criterion.
+func (m *MatchBuilder) Where(criterion func(StasisEvent) bool) *MatchBuilder {
+	m.criteria = append(m.criteria, criterion)
+	return m
+}
+
+// Match reports whether ev meets every criterion. Pass it to WaitFor.
+func (m *MatchBuilder) Match(ev StasisEvent) bool {
+	for _, criterion := range m.criteria {
+		if !criterion(ev) {
+			return false
+		}
+	}
+	return true
+}
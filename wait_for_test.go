@@ -0,0 +1,79 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestWaitFor(t *testing.T) {
+	client := NewAPIClient(NewConfiguration("/"), NewStdLogger(ioutil.Discard))
+	match := MatchEvent().Type("PlaybackFinished").Playback("pb1").Match
+	found := make(chan StasisEvent, 1)
+	go func() {
+		ev, err := client.WaitFor(context.Background(), match)
+		if err != nil {
+			t.Error(err)
+		}
+		found <- ev
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		client.waiters.mu.Lock()
+		waiting := len(client.waiters.pending)
+		client.waiters.mu.Unlock()
+		if waiting == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("WaitFor not waiting")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	client.HandleEvent(StasisEvent{Type: "PlaybackStarted", Playback: &Playback{Id: "pb1"}})
+	client.HandleEvent(StasisEvent{Type: "PlaybackFinished", Playback: &Playback{Id: "pb2"}})
+	client.HandleEvent(StasisEvent{Type: "PlaybackFinished", Playback: &Playback{Id: "pb1", State: "done"}})
+
+	select {
+	case ev := <-found:
+		if ev.Playback.Id != "pb1" || ev.Type != "PlaybackFinished" {
+			t.Errorf("found %s of %s, want PlaybackFinished of pb1", ev.Type, ev.Playback.Id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("WaitFor did not return")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.WaitFor(ctx, match); err != context.Canceled {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if n := len(client.waiters.pending); n != 0 {
+		t.Errorf("%d waiters left", n)
+	}
+}
+
+func TestMatchEvent(t *testing.T) {
+	match := MatchEvent().Channel("c1").Where(func(ev StasisEvent) bool { return ev.Digit == "#" })
+	for _, tc := range []struct {
+		ev   StasisEvent
+		want bool
+	}{
+		{StasisEvent{Channel: Channel{Id: "c1"}, Digit: "#"}, true},
+		{StasisEvent{Channel: Channel{Id: "c2"}, Peer: &Channel{Id: "c1"}, Digit: "#"}, true},
+		{StasisEvent{Channel: Channel{Id: "c1"}, Digit: "1"}, false},
+		{StasisEvent{Channel: Channel{Id: "c2"}, Digit: "#"}, false},
+	} {
+		if got := match.Match(tc.ev); got != tc.want {
+			t.Errorf("Match(%+v) = %v, want %v", tc.ev, got, tc.want)
+		}
+	}
+	if !MatchEvent().Match(StasisEvent{}) {
+		t.Error("empty predicate does not match")
+	}
+	if MatchEvent().Recording("r1").Match(StasisEvent{}) {
+		t.Error("recording predicate matches an event without recording")
+	}
+}
@@ -0,0 +1,332 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrChannelGone is returned when an operation cannot complete because its channel hung up.
+var ErrChannelGone = errors.New("channel is gone")
+
+// HangupError is the ErrChannelGone of a channel that hung up with a known cause, e.g. 17 when a
+// called party is busy.
+type HangupError struct {
+	Cause    int32
+	CauseTxt string
+}
+
+// Error implements the error interface.
+func (e *HangupError) Error() string {
+	return ErrChannelGone.Error() + ": " + e.CauseTxt
+}
+
+// Unwrap makes errors.Is(err, ErrChannelGone) hold.
+func (e *HangupError) Unwrap() error {
+	return ErrChannelGone
+}
+
+// ErrFuturePending is returned by Future.Result before the operation completes.
+var ErrFuturePending = errors.New("operation still pending")
+
+// DefaultWaitsPrefix is the ID prefix of the resources created by Waits.
+const DefaultWaitsPrefix = "ari-wait"
+
+// Waits runs operations and blocks until the event completing them is received. When the context
+// of a call is done, the server-side resource is torn down: the playback is stopped, the recording
+// is stopped, the originated channel is hung up. Every event must be fed to HandleEvent.
+//
+// Resources are created with client-chosen IDs starting with Prefix, so that Sweep can clean up
+// what a previous run of the application left behind.
+type Waits struct {
+	client *APIClient
+
+	// Prefix of the IDs of created resources. Use a value unique to the application instance.
+	Prefix string
+
+	mu      sync.Mutex
+	waiters map[string][]chan StasisEvent
+}
+
+// NewWaits creates the *AndWait helpers.
+func NewWaits(client *APIClient) *Waits {
+	return &Waits{client: client, Prefix: DefaultWaitsPrefix, waiters: make(map[string][]chan StasisEvent)}
+}
+
+// HandleEvent feeds an event received from Asterisk into the waiters.
+func (w *Waits) HandleEvent(ev StasisEvent) {
+	keys := make([]string, 0, 3)
+	if ev.Channel.Id != "" {
+		keys = append(keys, "channel:"+ev.Channel.Id)
+	}
+	if ev.Playback != nil {
+		keys = append(keys, "playback:"+ev.Playback.Id)
+	}
+	if ev.Recording != nil {
+		keys = append(keys, "recording:"+ev.Recording.Name)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, key := range keys {
+		for _, ch := range w.waiters[key] {
+			select {
+			case ch <- ev:
+			default:
+				w.client.logger.Warnf("waits: dropping %s event for %s, waiter is busy", ev.Type, key)
+			}
+		}
+	}
+}
+
+// subscribe registers a waiter for the events concerning keys. The returned function unregisters it.
+func (w *Waits) subscribe(keys ...string) (<-chan StasisEvent, func()) {
+	ch := make(chan StasisEvent, 16)
+	w.mu.Lock()
+	for _, key := range keys {
+		w.waiters[key] = append(w.waiters[key], ch)
+	}
+	w.mu.Unlock()
+	w.client.TrackResource(ResourceSubscription, "waits", 1)
+
+	return ch, func() {
+		w.mu.Lock()
+		for _, key := range keys {
+			list := w.waiters[key]
+			for i, c := range list {
+				if c == ch {
+					list = append(list[:i], list[i+1:]...)
+					break
+				}
+			}
+			if len(list) == 0 {
+				delete(w.waiters, key)
+			} else {
+				w.waiters[key] = list
+			}
+		}
+		w.mu.Unlock()
+		w.client.TrackResource(ResourceSubscription, "waits", -1)
+	}
+}
+
+// cleanup tears down a resource after the context of its operation is done.
+// It uses a fresh context since the original one is no longer usable.
+func (w *Waits) cleanup(what string, f func(ctx context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := f(ctx); err != nil {
+		w.client.logger.Debugf("waits: cleaning up %s: %v", what, err)
+	}
+}
+
+// PlayAndWait plays media on channelId and waits for the playback to finish.
+func (w *Waits) PlayAndWait(ctx context.Context, channelId string, media []string, opts *ChannelsApiPlaySoundWithIdOpts) (Playback, error) {
+	playbackId := newResourceId(w.Prefix)
+	events, done := w.subscribe("playback:"+playbackId, "channel:"+channelId)
+	defer done()
+
+	playback, _, err := w.client.ChannelsApi.PlaySoundWithId(ctx, channelId, playbackId, media, opts)
+	if err != nil {
+		return playback, err
+	}
+	for {
+		select {
+		case ev := <-events:
+			switch ev.Type {
+			case "PlaybackFinished":
+				return *ev.Playback, nil
+			case "ChannelDestroyed", "StasisEnd":
+				return playback, ErrChannelGone
+			}
+		case <-ctx.Done():
+			w.cleanup("playback "+playbackId, func(c context.Context) error {
+				_, err := w.client.PlaybacksApi.Stop(c, playbackId)
+				return err
+			})
+			return playback, ctx.Err()
+		}
+	}
+}
+
+// PlayBridgeAndWait plays media in bridgeId and waits for the playback to finish.
+func (w *Waits) PlayBridgeAndWait(ctx context.Context, bridgeId string, media []string, opts *BridgesApiPlayWithIdOpts) (Playback, error) {
+	playbackId := newResourceId(w.Prefix)
+	events, done := w.subscribe("playback:" + playbackId)
+	defer done()
+
+	playback, _, err := w.client.BridgesApi.PlayWithId(ctx, bridgeId, playbackId, media, opts)
+	if err != nil {
+		return playback, err
+	}
+	for {
+		select {
+		case ev := <-events:
+			if ev.Type == "PlaybackFinished" {
+				return *ev.Playback, nil
+			}
+		case <-ctx.Done():
+			w.cleanup("playback "+playbackId, func(c context.Context) error {
+				_, err := w.client.PlaybacksApi.Stop(c, playbackId)
+				return err
+			})
+			return playback, ctx.Err()
+		}
+	}
+}
+
+// RecordAndWait records channelId under name and waits for the recording to end, e.g. on
+// maxDurationSeconds, maxSilenceSeconds or the terminate digit.
+func (w *Waits) RecordAndWait(ctx context.Context, channelId string, name string, format string, opts *ChannelsApiRecordchannelOpts) (LiveRecording, error) {
+	events, done := w.subscribe("recording:"+name, "channel:"+channelId)
+	defer done()
+
+	recording, _, err := w.client.ChannelsApi.Recordchannel(ctx, channelId, name, format, opts)
+	if err != nil {
+		return recording, err
+	}
+	for {
+		select {
+		case ev := <-events:
+			switch ev.Type {
+			case "RecordingFinished":
+				return *ev.Recording, nil
+			case "RecordingFailed":
+				return *ev.Recording, fmt.Errorf("recording %s failed: %s", name, ev.Recording.Cause)
+			case "ChannelDestroyed", "StasisEnd":
+				// The recording is finished by Asterisk when the channel leaves; its events may
+				// still arrive but the caller only needs to know the channel is gone.
+				return recording, ErrChannelGone
+			}
+		case <-ctx.Done():
+			w.cleanup("recording "+name, func(c context.Context) error {
+				_, err := w.client.RecordingsApi.Stoprecording(c, name)
+				return err
+			})
+			return recording, ctx.Err()
+		}
+	}
+}
+
+// OriginateAndWait originates a call to endpoint and waits for it to enter the Stasis
+// application, i.e. to be answered. The channel ID is chosen by Waits.
+func (w *Waits) OriginateAndWait(ctx context.Context, endpoint string, opts *ChannelsApiOriginateWithIdOpts) (Channel, error) {
+	return w.OriginateWithTimeouts(ctx, endpoint, opts, OriginateTimeouts{})
+}
+
+// CollectOpts holds the optional parameters of CollectDigits.
+type CollectOpts struct {
+	// Max is the number of digits after which collection stops. Zero means no limit.
+	Max int
+	// Terminator ends the collection early, e.g. "#". It is not part of the result.
+	Terminator string
+	// FirstTimeout is the wait for the first digit. Defaults to 5 seconds.
+	FirstTimeout time.Duration
+	// InterTimeout is the wait between digits. Defaults to 3 seconds.
+	InterTimeout time.Duration
+	// Prompt is played while waiting for the first digit and stopped when it is pressed.
+	Prompt []string
+}
+
+// ErrNoInput is returned by CollectDigits when no digit was pressed in time.
+var ErrNoInput = errors.New("no digits collected")
+
+// CollectDigits collects DTMF digits from channelId until Max digits, the terminator or a timeout.
+// A timeout after at least one digit ends the collection normally.
+func (w *Waits) CollectDigits(ctx context.Context, channelId string, opts *CollectOpts) (string, error) {
+	if opts == nil {
+		opts = &CollectOpts{}
+	}
+	first, inter := opts.FirstTimeout, opts.InterTimeout
+	if first <= 0 {
+		first = 5 * time.Second
+	}
+	if inter <= 0 {
+		inter = 3 * time.Second
+	}
+
+	// PlaybackFinished carries no channel: the prompt is subscribed to by its own ID.
+	keys := []string{"channel:" + channelId}
+	var playbackId string
+	if len(opts.Prompt) > 0 {
+		playbackId = newResourceId(w.Prefix)
+		keys = append(keys, "playback:"+playbackId)
+	}
+	events, done := w.subscribe(keys...)
+	defer done()
+
+	// The first digit timeout starts once the prompt is over.
+	timer := w.client.clock().NewTimer(first)
+	defer timer.Stop()
+	if playbackId != "" {
+		timer.Stop()
+		if _, _, err := w.client.ChannelsApi.PlaySoundWithId(ctx, channelId, playbackId, opts.Prompt, nil); err != nil {
+			return "", err
+		}
+	}
+	stopPrompt := func() {
+		if playbackId != "" {
+			w.cleanup("playback "+playbackId, func(c context.Context) error {
+				_, err := w.client.PlaybacksApi.Stop(c, playbackId)
+				return err
+			})
+			playbackId = ""
+		}
+	}
+	defer stopPrompt()
+
+	var digits strings.Builder
+	for {
+		select {
+		case ev := <-events:
+			switch ev.Type {
+			case "PlaybackFinished":
+				if ev.Playback != nil && ev.Playback.Id == playbackId {
+					playbackId = ""
+					timer.Reset(first)
+				}
+			case "ChannelDtmfReceived":
+				stopPrompt()
+				if opts.Terminator != "" && ev.Digit == opts.Terminator {
+					return digits.String(), nil
+				}
+				digits.WriteString(ev.Digit)
+				if opts.Max > 0 && digits.Len() >= opts.Max {
+					return digits.String(), nil
+				}
+				timer.Reset(inter)
+			case "ChannelDestroyed", "StasisEnd":
+				return digits.String(), ErrChannelGone
+			}
+		case <-timer.C():
+			if digits.Len() == 0 {
+				return "", ErrNoInput
+			}
+			return digits.String(), nil
+		case <-ctx.Done():
+			return digits.String(), ctx.Err()
+		}
+	}
+}
+
+// resetTimer restarts t with d, discarding an expiration that was not received yet.
+func resetTimer(t *time.Timer, d time.Duration) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+	t.Reset(d)
+}
+
+// Sweep hangs up the channels left by a previous run, identified by Prefix. Playbacks and
+// recordings end with their channel. Call it on startup, before originating new calls.
+func (w *Waits) Sweep(ctx context.Context) (int, error) {
+	reaper := &Reaper{client: w.client, Channels: []string{w.Prefix + "-"}}
+	report, err := reaper.Reap(ctx)
+	return len(report.Channels), err
+}
@@ -0,0 +1,97 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"testing"
+	"time"
+)
+
+// waitsClient returns Waits on a fake clock, talking to a server answering every request with an
+// empty JSON object and reporting the played playback IDs on plays.
+func waitsClient(t *testing.T) (*Waits, *FakeClock, <-chan string) {
+	t.Helper()
+	plays := make(chan string, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if path.Base(path.Dir(r.URL.Path)) == "play" {
+			plays <- path.Base(r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+	}))
+	t.Cleanup(server.Close)
+	u, _ := url.Parse(server.URL)
+	cfg := NewConfiguration("/")
+	cfg.Host, cfg.Scheme = u.Host, u.Scheme
+	clock := NewFakeClock(time.Unix(0, 0))
+	cfg.Clock = clock
+	return NewWaits(NewAPIClient(cfg, NewStdLogger(ioutil.Discard))), clock, plays
+}
+
+// waitTimers waits for the code under test to have n pending timers.
+func waitTimers(t *testing.T, clock *FakeClock, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for clock.Timers() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d timers pending, want %d", clock.Timers(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestCollectDigitsPromptNoInput covers a prompt finishing without input: PlaybackFinished has
+// no channel, and the first digit timeout must still start.
+func TestCollectDigitsPromptNoInput(t *testing.T) {
+	w, clock, plays := waitsClient(t)
+	result := make(chan error, 1)
+	go func() {
+		_, err := w.CollectDigits(context.Background(), "c1", &CollectOpts{Prompt: []string{"sound:enter-pin"}, FirstTimeout: time.Second})
+		result <- err
+	}()
+
+	playbackId := <-plays
+	waitTimers(t, clock, 0)
+	w.HandleEvent(StasisEvent{Type: "PlaybackFinished", Playback: &Playback{Id: playbackId}})
+	waitTimers(t, clock, 1)
+	clock.Advance(time.Second)
+
+	select {
+	case err := <-result:
+		if !errors.Is(err, ErrNoInput) {
+			t.Errorf("err = %v, want ErrNoInput", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("CollectDigits did not return after the prompt finished")
+	}
+}
+
+func TestCollectDigitsTerminator(t *testing.T) {
+	w, _, _ := waitsClient(t)
+	result := make(chan string, 1)
+	go func() {
+		digits, _ := w.CollectDigits(context.Background(), "c1", &CollectOpts{Terminator: "#"})
+		result <- digits
+	}()
+
+	for {
+		w.mu.Lock()
+		n := len(w.waiters["channel:c1"])
+		w.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	for _, d := range []string{"4", "2", "#"} {
+		w.HandleEvent(StasisEvent{Type: "ChannelDtmfReceived", Channel: Channel{Id: "c1"}, Digit: d})
+	}
+	if digits := <-result; digits != "42" {
+		t.Errorf("digits = %q, want %q", digits, "42")
+	}
+}
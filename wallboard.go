@@ -0,0 +1,147 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultWallboardInterval is how often a Wallboard publishes by default.
+const DefaultWallboardInterval = 2 * time.Second
+
+// WallboardUpdate is the periodic snapshot published by a Wallboard.
+type WallboardUpdate struct {
+	Time   time.Time    `json:"time"`
+	Queues []QueueStats `json:"queues"`
+	Agents []AgentInfo  `json:"agents,omitempty"`
+}
+
+// Wallboard publishes the statistics of queues and the state of agents every Interval, so that
+// real-time dashboards update without polling Stats. Updates are delivered to the channels
+// returned by Subscribe, to OnUpdate, e.g. to forward them to NATS, and to the server-sent event
+// clients of ServeHTTP. A subscriber that falls behind misses updates rather than stalling the
+// others; the next update supersedes them anyway.
+type Wallboard struct {
+	client *APIClient
+	queues []*Queue
+	agents *AgentManager
+
+	// Interval between updates. Defaults to DefaultWallboardInterval.
+	Interval time.Duration
+	// OnUpdate, if set, receives every update.
+	OnUpdate func(u WallboardUpdate)
+
+	mu   sync.Mutex
+	next int
+	subs map[int]chan WallboardUpdate
+	last *WallboardUpdate
+}
+
+// NewWallboard creates a wallboard of queues; agents may be nil.
+func NewWallboard(client *APIClient, agents *AgentManager, queues ...*Queue) *Wallboard {
+	return &Wallboard{
+		client:   client,
+		queues:   queues,
+		agents:   agents,
+		Interval: DefaultWallboardInterval,
+		subs:     make(map[int]chan WallboardUpdate),
+	}
+}
+
+// Run publishes updates until ctx is done.
+func (w *Wallboard) Run(ctx context.Context) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultWallboardInterval
+	}
+	timer := w.client.clock().NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C():
+			w.publish(w.Snapshot())
+			timer.Reset(interval)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Snapshot returns the current statistics.
+func (w *Wallboard) Snapshot() WallboardUpdate {
+	u := WallboardUpdate{Time: w.client.clock().Now(), Queues: make([]QueueStats, 0, len(w.queues))}
+	for _, q := range w.queues {
+		u.Queues = append(u.Queues, q.Stats())
+	}
+	if w.agents != nil {
+		u.Agents = w.agents.Agents()
+	}
+	return u
+}
+
+func (w *Wallboard) publish(u WallboardUpdate) {
+	w.mu.Lock()
+	w.last = &u
+	for _, ch := range w.subs {
+		select {
+		case ch <- u:
+		default:
+			w.client.metrics().IncCounter("ari_wallboard_dropped_total", nil, 1)
+		}
+	}
+	w.mu.Unlock()
+	if w.OnUpdate != nil {
+		w.OnUpdate(u)
+	}
+}
+
+// Subscribe returns a channel receiving the updates, starting with the last one published, until
+// ctx is done.
+func (w *Wallboard) Subscribe(ctx context.Context) <-chan WallboardUpdate {
+	ch := make(chan WallboardUpdate, 4)
+	w.mu.Lock()
+	id := w.next
+	w.next++
+	w.subs[id] = ch
+	if w.last != nil {
+		ch <- *w.last
+	}
+	w.mu.Unlock()
+	w.client.goTracked("wallboard", func() {
+		<-ctx.Done()
+		w.mu.Lock()
+		delete(w.subs, id)
+		close(ch)
+		w.mu.Unlock()
+	})
+	return ch
+}
+
+// ServeHTTP streams the updates as server-sent events named "wallboard", for a dashboard to
+// consume with EventSource.
+func (w *Wallboard) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		http.Error(rw, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.Header().Set("Connection", "keep-alive")
+	rw.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for u := range w.Subscribe(req.Context()) {
+		data, err := json.Marshal(u)
+		if err != nil {
+			continue
+		}
+		if _, err := fmt.Fprintf(rw, "event: wallboard\ndata: %s\n\n", data); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,82 @@
+package asterisk_ari_go
+
+import (
+	"context"
+	"strings"
+
+	"github.com/antihax/optional"
+)
+
+// DefaultWebRTCFormats are the codecs offered to browsers by WebRTCFormats when none are given:
+// Opus first, G.711 as the fallback every browser supports.
+var DefaultWebRTCFormats = []string{"opus", "ulaw", "alaw"}
+
+// WebRTCFormats returns the Formats originate option offering formats in order of preference, or
+// DefaultWebRTCFormats. Asterisk only transcodes when the browser picks a codec the other leg
+// does not have.
+//
+//	opts := &ChannelsApiOriginateWithIdOpts{App: optional.NewString(app), Formats: WebRTCFormats()}
+func WebRTCFormats(formats ...string) optional.String {
+	if len(formats) == 0 {
+		formats = DefaultWebRTCFormats
+	}
+	return optional.NewString(strings.Join(formats, ","))
+}
+
+// WebRTCStatus describes the transport and media security of a WebRTC channel, i.e. a PJSIP
+// channel of an endpoint with webrtc=yes.
+type WebRTCStatus struct {
+	// Endpoint is the PJSIP endpoint of the channel.
+	Endpoint string `json:"endpoint"`
+	// WebRTC is the webrtc option of the endpoint.
+	WebRTC bool `json:"webrtc"`
+	// SecureSignalling is true when signalling arrived over TLS or WSS.
+	SecureSignalling bool `json:"secure_signalling"`
+	// MediaEncryption is the media_encryption option of the endpoint, "dtls" for browsers.
+	MediaEncryption string `json:"media_encryption"`
+	// ICE is the ice_support option of the endpoint.
+	ICE bool `json:"ice"`
+	// RemoteRTP is the address media is exchanged with, once ICE has selected a candidate pair.
+	RemoteRTP string `json:"remote_rtp,omitempty"`
+	// NativeFormat is the negotiated audio codec.
+	NativeFormat string `json:"native_format,omitempty"`
+}
+
+// Ready reports whether the channel can carry browser media: WebRTC with DTLS-SRTP and ICE.
+func (s WebRTCStatus) Ready() bool {
+	return s.WebRTC && s.ICE && s.MediaEncryption == "dtls"
+}
+
+// WebRTCStatus reads the WebRTC status of the channel from channel variables and the endpoint
+// configuration. It returns ErrNotPJSIP for channels of other technologies.
+func (h *ChannelHandle) WebRTCStatus(ctx context.Context) (WebRTCStatus, error) {
+	var s WebRTCStatus
+	if name := h.Channel().Name; name != "" && !strings.HasPrefix(name, "PJSIP/") {
+		return s, ErrNotPJSIP
+	}
+	endpoint, err := h.GetVar(ctx, "CHANNEL(endpoint)")
+	if err != nil {
+		return s, err
+	}
+	s.Endpoint = endpoint
+
+	vars := []struct {
+		name string
+		set  func(v string)
+	}{
+		{"PJSIP_ENDPOINT(" + endpoint + ",webrtc)", func(v string) { s.WebRTC = isTrue(v) }},
+		{"PJSIP_ENDPOINT(" + endpoint + ",media_encryption)", func(v string) { s.MediaEncryption = v }},
+		{"PJSIP_ENDPOINT(" + endpoint + ",ice_support)", func(v string) { s.ICE = isTrue(v) }},
+		{"CHANNEL(pjsip,secure)", func(v string) { s.SecureSignalling = isTrue(v) }},
+		{"CHANNEL(rtp,dest)", func(v string) { s.RemoteRTP = v }},
+		{"CHANNEL(audionativeformat)", func(v string) { s.NativeFormat = v }},
+	}
+	for _, v := range vars {
+		value, err := h.GetVar(ctx, v.name)
+		if err != nil {
+			return s, err
+		}
+		v.set(value)
+	}
+	return s, nil
+}